@@ -0,0 +1,12 @@
+// Package api содержит спецификации API календаря, встраиваемые в бинарный файл.
+package api
+
+import _ "embed"
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+// OpenAPI возвращает спецификацию HTTP API в формате OpenAPI 3 (YAML).
+func OpenAPI() []byte {
+	return openAPI
+}
@@ -1,63 +1,191 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
-	"github.com/spf13/viper"
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	internalhttp "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
 )
 
-// При желании конфигурацию можно вынести в internal/config.
+// Общие загрузка и вывод конфигурации вынесены в internal/config, а сами структуры остаются здесь.
 // Организация конфига в main принуждает нас сужать API компонентов, использовать
 // при их конструировании только необходимые параметры, а также уменьшает вероятность циклической зависимости.
 type Config struct {
-	Logger      LoggerConfig     `mapstructure:"logger"`
-	GrpcServer  GrpcServerConfig `mapstructure:"grpcServer"`
-	HTTPServer  HTTPServerConfig `mapstructure:"httpServer"`
-	Database    DatabaseConfig   `mapstructure:"database"`
-	StorageType string           `mapstructure:"storage"`
-	Timezone    string           `mapstructure:"timezone"`
+	Logger      LoggerConfig      `mapstructure:"logger" validate:"nested"`
+	GrpcServer  GrpcServerConfig  `mapstructure:"grpcServer" validate:"nested"`
+	HTTPServer  HTTPServerConfig  `mapstructure:"httpServer" validate:"nested"`
+	Database    DatabaseConfig    `mapstructure:"database" validate:"nested"`
+	Memory      MemoryConfig      `mapstructure:"memory" validate:"nested"`
+	StorageType string            `mapstructure:"storage" validate:"in:SQL,MEMORY"`
+	Timezone    string            `mapstructure:"timezone"`
+	RateLimit   RateLimitConfig   `mapstructure:"rateLimit" validate:"nested"`
+	Quota       QuotaConfig       `mapstructure:"quota" validate:"nested"`
+	Resources   ResourcesConfig   `mapstructure:"resources"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency" validate:"nested"`
+	Shutdown    ShutdownConfig    `mapstructure:"shutdown" validate:"nested"`
 }
 
 type LoggerConfig struct {
-	Level string `mapstructure:"level"`
+	Level string `mapstructure:"level" validate:"required"`
 }
 
 type GrpcServerConfig struct {
-	Port string `mapstructure:"port"`
+	Port string    `mapstructure:"port" validate:"regexp:^[0-9]+$"`
+	TLS  TLSConfig `mapstructure:"tls"`
 }
 
 type HTTPServerConfig struct {
-	Host        string        `mapstructure:"host"`
-	Port        string        `mapstructure:"port"`
-	ReadTimeout time.Duration `mapstructure:"readTimeout"`
+	Host        string           `mapstructure:"host"`
+	Port        string           `mapstructure:"port" validate:"regexp:^[0-9]+$"`
+	ReadTimeout time.Duration    `mapstructure:"readTimeout" validate:"min:0"`
+	Mode        string           `mapstructure:"mode"`
+	TLS         TLSConfig        `mapstructure:"tls"`
+	GatewayTLS  GatewayTLSConfig `mapstructure:"gatewayTls"`
+	// ValidateResponses включает проверку ответов по спецификации OpenAPI (для отладки, ответы буферизуются)
+	ValidateResponses bool `mapstructure:"validateResponses"`
+}
+
+type TLSConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CertFile     string `mapstructure:"certFile"`
+	KeyFile      string `mapstructure:"keyFile"`
+	ClientCAFile string `mapstructure:"clientCaFile"`
+	MinVersion   string `mapstructure:"minVersion"`
+	// GatewayCommonName - CommonName сертификата сервиса, которому при mTLS разрешено передавать X-USER-ID
+	GatewayCommonName string `mapstructure:"gatewayCommonName"`
+}
+
+type GatewayTLSConfig struct {
+	CertFile   string `mapstructure:"certFile"`
+	KeyFile    string `mapstructure:"keyFile"`
+	CAFile     string `mapstructure:"caFile"`
+	ServerName string `mapstructure:"serverName"`
 }
 
 type DatabaseConfig struct {
 	Driver string `mapstructure:"driver"`
-	URI    string `mapstructure:"uri"`
+	URI    string `mapstructure:"uri" redact:"uri"`
 }
 
-func NewConfig(path string) (*Config, error) {
-	parser := viper.New()
-	parser.SetConfigFile(path)
+type MemoryConfig struct {
+	Dir            string        `mapstructure:"dir"`
+	SnapshotPeriod time.Duration `mapstructure:"snapshotPeriod" validate:"min:0"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool                   `mapstructure:"enabled"`
+	Rate    float64                `mapstructure:"rate" validate:"min:0"`
+	Burst   int                    `mapstructure:"burst" validate:"min:0"`
+	Routes  map[string]LimitConfig `mapstructure:"routes"`
+}
+
+type LimitConfig struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+type QuotaConfig struct {
+	MaxEventsPerUser int `mapstructure:"maxEventsPerUser" validate:"min:0"`
+}
+
+// ResourcesConfig - администраторы ресурсов: могут менять и удалять любые ресурсы, а не только созданные ими.
+type ResourcesConfig struct {
+	Admins []uint64 `mapstructure:"admins"`
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration `mapstructure:"ttl" validate:"min:0"`
+}
+
+type ShutdownConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout" validate:"min:0"`
+	DrainTimeout time.Duration `mapstructure:"drainTimeout" validate:"min:0"`
+}
+
+// envPrefix - префикс переменных окружения, переопределяющих конфигурацию (CALENDAR_DATABASE_URI и т.п.).
+const envPrefix = "CALENDAR"
 
-	err := parser.ReadInConfig()
+// NewConfig читает конфигурацию и проверяет её.
+func NewConfig(path string) (*Config, error) {
+	config, err := LoadConfig(path)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read config file: %w", err)
+		return nil, err
 	}
-
-	for _, key := range parser.AllKeys() {
-		value := parser.GetString(key)
-		parser.Set(key, os.ExpandEnv(value))
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config is not valid: %w", err)
 	}
+	return config, nil
+}
 
+// LoadConfig читает конфигурацию без проверки (для --print-config).
+func LoadConfig(path string) (*Config, error) {
 	var config Config
-	err = parser.Unmarshal(&config)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	if err := internalconfig.Load(path, envPrefix, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate проверяет конфигурацию: правила из тэгов validate и проверки, которые не выражаются тэгами.
+func (c *Config) Validate() error {
+	var errs validator.ValidationErrors
+	if err := validator.Validate(c); err != nil && !errors.As(err, &errs) {
+		return err
 	}
 
-	return &config, err
+	var err error
+	if c.Logger.Level != "" {
+		_, err = logger.ParseLevel(c.Logger.Level)
+		errs.Add("logger.level", err)
+	}
+	_, err = time.LoadLocation(c.Timezone)
+	errs.Add("timezone", err)
+	if c.StorageType == "SQL" && c.Database.URI == "" {
+		errs.Add("database.uri", validator.ErrStringRequired)
+	}
+	// пустые режим и драйвер означают значения по умолчанию (legacy и pgx)
+	switch c.HTTPServer.Mode {
+	case "", internalhttp.ModeLegacy, internalhttp.ModeGateway, internalhttp.ModeCompat:
+	default:
+		errs.Add("httpServer.mode", validator.ErrStringNotInValues)
+	}
+	switch c.Database.Driver {
+	case "", "pgx", sqlitestorage.DriverName:
+	default:
+		errs.Add("database.driver", validator.ErrStringNotInValues)
+	}
+	c.GrpcServer.TLS.validate(&errs, "grpcServer.tls")
+	c.HTTPServer.TLS.validate(&errs, "httpServer.tls")
+	if c.GrpcServer.TLS.Enabled && c.HTTPServer.Mode != "" && c.HTTPServer.Mode != internalhttp.ModeLegacy {
+		gw := c.HTTPServer.GatewayTLS
+		if (gw.CertFile == "") != (gw.KeyFile == "") {
+			errs.Add("httpServer.gatewayTls", tlsconfig.ErrNoCertificate)
+		}
+	}
+	if c.RateLimit.Enabled {
+		_, _, err = rateLimits(c.RateLimit)
+		errs.Add("rateLimit.routes", err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c TLSConfig) validate(errs *validator.ValidationErrors, field string) {
+	if !c.Enabled {
+		return
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		errs.Add(field, tlsconfig.ErrNoCertificate)
+	}
+	_, err := tlsconfig.ParseVersion(c.MinVersion)
+	errs.Add(field+".minVersion", err)
 }
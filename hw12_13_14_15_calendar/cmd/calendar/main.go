@@ -2,25 +2,49 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
 	internalgrpc "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
 	internalhttp "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http"
 	memorystorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
 	sqlstorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
-var configFile string
+var (
+	configFile  string
+	autoMigrate bool
+	printConfig bool
+	checkConfig bool
+)
+
+type appStorage interface {
+	app.Storage
+	Connect(ctx context.Context) error
+	Close(ctx context.Context) error
+}
 
 func init() {
 	flag.StringVar(&configFile, "config", "/etc/calendar/config.yaml", "Path to configuration file")
+	flag.BoolVar(&autoMigrate, "auto-migrate", false, "Apply database migrations on start")
+	flag.BoolVar(&printConfig, "print-config", false, "Print effective configuration with secrets redacted and exit")
+	flag.BoolVar(&checkConfig, "check-config", false, "Validate configuration and exit")
 }
 
 func main() {
@@ -31,6 +55,14 @@ func main() {
 		return
 	}
 
+	if printConfig || checkConfig {
+		config, err := LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("failed reading config %v", err)
+		}
+		os.Exit(internalconfig.Inspect(os.Stdout, os.Stderr, config, printConfig))
+	}
+
 	config, err := NewConfig(configFile)
 	if err != nil {
 		log.Fatalf("failed reading config %v", err)
@@ -50,66 +82,191 @@ func main() {
 		return
 	}
 
+	// calendar migrate up|down|status|redo
+	if flag.Arg(0) == "migrate" {
+		lines, err := migrate(context.Background(), config.Database, flag.Arg(1))
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		if err != nil {
+			log.Fatalf("failed to migrate %v", err)
+		}
+		return
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	// storage
-	var storage app.Storage
-	if config.StorageType == "SQL" {
-		sqlStorage := sqlstorage.New(config.Database.Driver, config.Database.URI)
-		if err := sqlStorage.Connect(ctx); err != nil {
-			logg.Error(ctx, err, "failed to connect to db")
+	// SIGHUP перечитывает конфигурацию; подписываемся сразу, чтобы сигнал во время запуска не завершил процесс
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+
+	// migrations
+	if autoMigrate && config.StorageType == "SQL" {
+		lines, err := migrate(ctx, config.Database, "up")
+		for _, line := range lines {
+			logg.Info(ctx, "migration: "+line)
+		}
+		if err != nil {
+			logg.Error(ctx, err, "failed to migrate db")
 			return
 		}
-		defer func() {
-			if err := sqlStorage.Close(ctx); err != nil {
-				logg.Error(ctx, err, "failed to close sql storage")
-			}
-		}()
-		storage = sqlStorage
-	} else {
-		storage = memorystorage.New()
 	}
 
+	// остановка: http-сервер (в т.ч. grpc-gateway), grpc-сервер, хранилище
+	shutdown := lifecycle.New(logg, config.Shutdown.Timeout)
+	defer func() {
+		_ = shutdown.Shutdown(context.Background())
+	}()
+
+	// storage
+	storage := newStorage(logg, config)
+	if err := storage.Connect(ctx); err != nil {
+		logg.Error(ctx, err, "failed to connect to storage")
+		return
+	}
+	shutdown.Add("storage", 0, storage.Close)
+
 	// app
-	calendar := app.New(logg, storage)
+	calendar := app.New(logg, storage).
+		WithQuota(config.Quota.MaxEventsPerUser).
+		WithIdempotencyTTL(config.Idempotency.TTL).
+		WithResourceAdmins(config.Resources.Admins)
 
-	// http server
-	httpServerAddr := fmt.Sprintf("%s:%s", config.HTTPServer.Host, config.HTTPServer.Port)
-	httpServer := internalhttp.NewServer(logg, calendar, httpServerAddr, config.HTTPServer.ReadTimeout)
+	// rate limiter, общий для http и grpc серверов
+	var limiter *ratelimit.Limiter
+	if config.RateLimit.Enabled {
+		defaultLimit, routes, err := rateLimits(config.RateLimit)
+		if err != nil {
+			logg.Error(ctx, err, "failed to configure rate limiter")
+			return
+		}
+		limiter = ratelimit.New(defaultLimit, routes)
+	}
 
-	go func() {
-		<-ctx.Done()
+	// перечитывание конфигурации по SIGHUP и при изменении файла
+	go reload.Watch(ctx, logg, configFile, reload.DefaultPeriod, reloadSignals, applyConfig(logg, limiter))
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
-		defer cancel()
+	// tls
+	httpTLS, grpcTLS, gatewayTLS, err := newTLSConfigs(ctx, logg, config)
+	if err != nil {
+		logg.Error(ctx, err, "failed to load tls certificates")
+		return
+	}
 
-		if err := httpServer.Stop(ctx); err != nil {
-			logg.Error(ctx, err, "failed to stop http server")
-		}
-	}()
+	// http server
+	httpServerAddr := fmt.Sprintf("%s:%s", config.HTTPServer.Host, config.HTTPServer.Port)
+	httpServer := internalhttp.NewServer(logg, calendar, httpServerAddr, config.HTTPServer.ReadTimeout)
+	if config.HTTPServer.Mode != "" {
+		httpServer.WithGateway(config.HTTPServer.Mode, fmt.Sprintf("localhost:%s", config.GrpcServer.Port))
+	}
+	if limiter != nil {
+		httpServer.WithRateLimiter(limiter)
+	}
+	if config.HTTPServer.ValidateResponses {
+		httpServer.WithResponseValidation()
+	}
+	if httpTLS != nil {
+		httpServer.WithTLS(httpTLS).WithTrustedGateway(config.HTTPServer.TLS.GatewayCommonName)
+	}
+	if gatewayTLS != nil {
+		httpServer.WithGatewayTLS(gatewayTLS)
+	}
 
 	// grpc server
 	grpcServer := internalgrpc.NewServer(logg, calendar, config.GrpcServer.Port)
+	if limiter != nil {
+		grpcServer.WithRateLimiter(limiter)
+	}
+	if grpcTLS != nil {
+		grpcServer.WithTLS(grpcTLS).WithTrustedGateway(config.GrpcServer.TLS.GatewayCommonName)
+	}
 
 	go func() {
-		if err = grpcServer.Start(ctx); err != nil {
+		if err := grpcServer.Start(ctx); err != nil {
 			logg.Error(ctx, err, "grpc failed to serve")
 			cancel()
 		}
 	}()
+	shutdown.Add("grpc server", config.Shutdown.DrainTimeout, grpcServer.Stop)
+
+	go func() {
+		if err := httpServer.Start(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logg.Error(ctx, err, "http server failed to serve")
+			cancel()
+		}
+	}()
+	shutdown.Add("http server", config.Shutdown.DrainTimeout, httpServer.Stop)
 
 	logg.Info(ctx, "calendar is running...")
 
-	if err := httpServer.Start(ctx); err != nil {
-		logg.Error(ctx, err, "http server stopped")
-		cancel()
+	<-ctx.Done()
+}
+
+func newStorage(logg *logger.Logger, config *Config) appStorage {
+	switch {
+	case config.StorageType == "SQL" && config.Database.Driver == sqlitestorage.DriverName:
+		return sqlitestorage.New(config.Database.URI)
+	case config.StorageType == "SQL":
+		return sqlstorage.New(config.Database.Driver, config.Database.URI)
+	case config.Memory.Dir != "":
+		return memorystorage.NewPersistent(logg, config.Memory.Dir, config.Memory.SnapshotPeriod)
+	default:
+		return memorystorage.New()
 	}
+}
 
-	<-ctx.Done()
+func rateLimits(config RateLimitConfig) (ratelimit.Limit, map[string]ratelimit.Limit, error) {
+	defaultLimit := ratelimit.Limit{Rate: config.Rate, Burst: config.Burst}
+	if err := defaultLimit.Validate(); err != nil {
+		return ratelimit.Limit{}, nil, err
+	}
+	routes := make(map[string]ratelimit.Limit, len(config.Routes))
+	for route, limit := range config.Routes {
+		routeLimit := ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+		if err := routeLimit.Validate(); err != nil {
+			return ratelimit.Limit{}, nil, fmt.Errorf("route %s: %w", route, err)
+		}
+		routes[route] = routeLimit
+	}
+	return defaultLimit, routes, nil
+}
+
+// newTLSConfigs загружает сертификаты серверов и запускает их перечитывание при изменении файлов.
+// Для выключенного TLS возвращается nil; подключение gateway шифруется, только если TLS включён у gRPC-сервера.
+func newTLSConfigs(ctx context.Context, logg *logger.Logger, config *Config) (httpTLS, grpcTLS, gatewayTLS *tls.Config, err error) {
+	watch := func(reloader *tlsconfig.Reloader, err error) (*tlsconfig.Reloader, error) {
+		if err != nil {
+			return nil, err
+		}
+		go reloader.Watch(ctx, logg, tlsconfig.DefaultReloadPeriod)
+		return reloader, nil
+	}
+
+	if cfg := config.HTTPServer.TLS; cfg.Enabled {
+		reloader, err := watch(tlsconfig.New(tlsconfig.Files{CertFile: cfg.CertFile, KeyFile: cfg.KeyFile, CAFile: cfg.ClientCAFile}, cfg.MinVersion))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("http server: %w", err)
+		}
+		httpTLS = reloader.ServerConfig()
+	}
 
-	if err := grpcServer.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop grpc server")
+	if cfg := config.GrpcServer.TLS; cfg.Enabled {
+		reloader, err := watch(tlsconfig.New(tlsconfig.Files{CertFile: cfg.CertFile, KeyFile: cfg.KeyFile, CAFile: cfg.ClientCAFile}, cfg.MinVersion))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("grpc server: %w", err)
+		}
+		grpcTLS = reloader.ServerConfig()
+
+		gw := config.HTTPServer.GatewayTLS
+		reloader, err = watch(tlsconfig.NewClient(tlsconfig.Files{CertFile: gw.CertFile, KeyFile: gw.KeyFile, CAFile: gw.CAFile}, cfg.MinVersion))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("grpc gateway: %w", err)
+		}
+		gatewayTLS = reloader.ClientConfig(gw.ServerName)
 	}
+
+	return httpTLS, grpcTLS, gatewayTLS, nil
 }
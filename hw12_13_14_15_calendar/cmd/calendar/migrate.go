@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/migrator"
+	"github.com/pressly/goose/v3"
+)
+
+// выполняет команду миграции (up, down, status, redo) и возвращает строки с результатом.
+func migrate(ctx context.Context, config DatabaseConfig, command string) (lines []string, err error) {
+	m := migrator.New(config.Driver, config.URI)
+	if err := m.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := m.Close(ctx); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var results []*goose.MigrationResult
+	switch command {
+	case "up":
+		results, err = m.Up(ctx)
+	case "down":
+		var result *goose.MigrationResult
+		result, err = m.Down(ctx)
+		if result != nil {
+			results = append(results, result)
+		}
+	case "redo":
+		results, err = m.Redo(ctx)
+	case "status":
+		var statuses []*goose.MigrationStatus
+		statuses, err = m.Status(ctx)
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			lines = append(lines, fmt.Sprintf("%-20s %s", appliedAt, status.Source.Path))
+		}
+		return lines, err
+	default:
+		return nil, fmt.Errorf("unknown migrate command %q, expected up|down|status|redo", command)
+	}
+
+	for _, result := range results {
+		lines = append(lines, result.String())
+	}
+	if err == nil && len(lines) == 0 {
+		lines = append(lines, "no migrations to run")
+	}
+	return lines, err
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
+)
+
+// applyConfig перечитывает конфигурацию и применяет настройки, которые можно менять на лету:
+// уровень логирования и лимиты частоты запросов. Остальные изменения вступают в силу после перезапуска.
+// Новые настройки сначала проверяются целиком и применяются, только если все они корректны.
+func applyConfig(logg *logger.Logger, limiter *ratelimit.Limiter) reload.ApplyFunc {
+	return func(ctx context.Context) error {
+		config, err := NewConfig(configFile)
+		if err != nil {
+			return err
+		}
+		level, err := logger.ParseLevel(config.Logger.Level)
+		if err != nil {
+			return err
+		}
+		defaultLimit, routes, err := rateLimits(config.RateLimit)
+		if err != nil {
+			return err
+		}
+
+		// дальше ничего не может завершиться ошибкой: перечитывание применяется целиком или не применяется вовсе
+		logg.SetLevel(level)
+		switch {
+		case limiter != nil && config.RateLimit.Enabled:
+			limiter.SetLimits(defaultLimit, routes)
+		case limiter != nil || config.RateLimit.Enabled:
+			logg.Warn(ctx, "rate limiter can be enabled or disabled only on restart")
+		}
+		return nil
+	}
+}
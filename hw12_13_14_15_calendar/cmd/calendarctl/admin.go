@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func runAdmin(ctx context.Context, client pb.AdminServiceClient, printer *printer, args []string) error {
+	switch args[0] {
+	case "notify-status":
+		return listNotifyStatus(ctx, client, printer, args[1:])
+	case "run":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: run requires exactly one job: notify / clear", errUsage)
+		}
+		return runJob(ctx, client, printer, args[1])
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+func listNotifyStatus(ctx context.Context, client pb.AdminServiceClient, printer *printer, args []string) error {
+	now := time.Now()
+	fs := newFlagSet("notify-status")
+	from := fs.String("from", now.Format(time.DateTime), "Start of the notify date period")
+	to := fs.String("to", now.Add(24*time.Hour).Format(time.DateTime), "End of the notify date period")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	startDate, err := parseDate(*from)
+	if err != nil {
+		return err
+	}
+	endDate, err := parseDate(*to)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := client.ListNotifyStatus(ctx, &pb.NotifyStatusRequest{
+		StartDate: timestamppb.New(startDate),
+		EndDate:   timestamppb.New(endDate),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printer.printNotifyStatuses(statuses.Statuses)
+}
+
+func runJob(ctx context.Context, client pb.AdminServiceClient, printer *printer, job string) error {
+	var err error
+	switch job {
+	case "notify":
+		_, err = client.RunNotify(ctx, &emptypb.Empty{})
+	case "clear":
+		_, err = client.RunClear(ctx, &emptypb.Empty{})
+	default:
+		return fmt.Errorf("%w: unknown job %q", errUsage, job)
+	}
+	if err != nil {
+		return err
+	}
+
+	printer.printMessage(fmt.Sprintf("%s completed", job))
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func runEvents(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: events subcommand is required", errUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return listEvents(ctx, client, printer, args[1:])
+	case "get":
+		return getEvent(ctx, client, printer, args[1:])
+	case "create":
+		return createEvent(ctx, client, printer, args[1:])
+	case "update":
+		return updateEvent(ctx, client, printer, args[1:])
+	case "delete":
+		return deleteEvent(ctx, client, printer, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown events subcommand %q", errUsage, args[0])
+	}
+}
+
+func listEvents(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	fs := newFlagSet("events list")
+	period := fs.String("period", "day", "Period: day / week / month")
+	date := fs.String("date", time.Now().Format(time.DateTime), "Start date of the period")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	startDate, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+	req := &pb.EventListRequest{StartDate: timestamppb.New(startDate)}
+
+	var events *pb.EventList
+	switch *period {
+	case "day":
+		events, err = client.EventListForDay(ctx, req)
+	case "week":
+		events, err = client.EventListForWeek(ctx, req)
+	case "month":
+		events, err = client.EventListForMonth(ctx, req)
+	default:
+		return fmt.Errorf("%w: unknown period %q", errUsage, *period)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printer.printEvents(events.Events)
+}
+
+func getEvent(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	fs := newFlagSet("events get")
+	id := fs.Uint64("id", 0, "Event ID")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("%w: -id is required", errUsage)
+	}
+
+	event, err := client.GetEvent(ctx, &pb.GetEventRequest{Id: *id})
+	if err != nil {
+		return err
+	}
+
+	return printer.printEvents([]*pb.Event{event})
+}
+
+func createEvent(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	fs := newFlagSet("events create")
+	event := &pb.Event{}
+	bindEventFlags(fs, event)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if event.Title == "" || event.StartDate == nil || event.EndDate == nil {
+		return fmt.Errorf("%w: -title, -start and -end are required", errUsage)
+	}
+
+	resp, err := client.CreateEvent(ctx, &pb.CreateEventRequest{Event: event})
+	if err != nil {
+		return err
+	}
+	printer.warnConflicts(resp.Conflicts)
+
+	event.Id = resp.Id
+	return printer.printEvents([]*pb.Event{event})
+}
+
+func updateEvent(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	fs := newFlagSet("events update")
+	id := fs.Uint64("id", 0, "Event ID")
+	changes := &pb.Event{}
+	bindEventFlags(fs, changes)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("%w: -id is required", errUsage)
+	}
+
+	// обновляются только переданные поля, остальные берутся из текущего события
+	event, err := client.GetEvent(ctx, &pb.GetEventRequest{Id: *id})
+	if err != nil {
+		return err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			event.Title = changes.Title
+		case "start":
+			event.StartDate = changes.StartDate
+		case "end":
+			event.EndDate = changes.EndDate
+		case "description":
+			event.Description = changes.Description
+		case "notify-before":
+			event.NotifyBefore = changes.NotifyBefore
+		case "transparent":
+			event.Transparent = changes.Transparent
+		}
+	})
+
+	resp, err := client.UpdateEvent(ctx, &pb.UpdateEventRequest{Event: event})
+	if err != nil {
+		return err
+	}
+	printer.warnConflicts(resp.Conflicts)
+
+	return printer.printEvents([]*pb.Event{event})
+}
+
+func deleteEvent(ctx context.Context, client pb.EventServiceClient, printer *printer, args []string) error {
+	fs := newFlagSet("events delete")
+	id := fs.Uint64("id", 0, "Event ID")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("%w: -id is required", errUsage)
+	}
+
+	if _, err := client.DeleteEvent(ctx, &pb.DeleteEventRequest{Id: *id}); err != nil {
+		return err
+	}
+
+	printer.printMessage(fmt.Sprintf("event %d deleted", *id))
+	return nil
+}
+
+func bindEventFlags(fs *flag.FlagSet, event *pb.Event) {
+	fs.StringVar(&event.Title, "title", "", "Event title")
+	fs.Func("start", "Event start date", func(value string) error {
+		date, err := parseDate(value)
+		event.StartDate = timestamppb.New(date)
+		return err
+	})
+	fs.Func("end", "Event end date", func(value string) error {
+		date, err := parseDate(value)
+		event.EndDate = timestamppb.New(date)
+		return err
+	})
+	fs.StringVar(&event.Description, "description", "", "Event description")
+	fs.Func("notify-before", "Notify before event start, e.g. 1h30m", func(value string) error {
+		notifyBefore, err := time.ParseDuration(value)
+		event.NotifyBefore = durationpb.New(notifyBefore)
+		return err
+	})
+	fs.BoolVar(&event.Transparent, "transparent", false, "Event does not occupy time")
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return fmt.Errorf("%w: %s: %w", errUsage, fs.Name(), err)
+	}
+	if err == nil && fs.NArg() > 0 {
+		return fmt.Errorf("%w: %s: unexpected arguments %v", errUsage, fs.Name(), fs.Args())
+	}
+	return err
+}
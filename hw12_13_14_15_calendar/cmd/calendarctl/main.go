@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const userIDHeader = "x-user-id"
+
+var (
+	addr      string
+	eventsTLS tlsconfig.Files
+	adminAddr string
+	adminTLS  tlsconfig.Files
+	userID    uint64
+	output    string
+	timeout   time.Duration
+)
+
+var errUsage = errors.New("invalid usage")
+
+func init() {
+	flag.StringVar(&addr, "addr", "localhost:8081", "Calendar grpc server address")
+	flag.StringVar(&eventsTLS.CAFile, "ca", "", "CA certificate of the calendar server; enables TLS")
+	flag.StringVar(&eventsTLS.CertFile, "cert", "", "Client certificate for the calendar server (mTLS)")
+	flag.StringVar(&eventsTLS.KeyFile, "key", "", "Client key for the calendar server (mTLS)")
+	flag.StringVar(&adminAddr, "admin-addr", "localhost:8082", "Scheduler admin grpc server address")
+	flag.StringVar(&adminTLS.CAFile, "admin-ca", "", "CA certificate of the admin server; enables TLS")
+	flag.StringVar(&adminTLS.CertFile, "admin-cert", "", "Client certificate for the admin server (mTLS)")
+	flag.StringVar(&adminTLS.KeyFile, "admin-key", "", "Client key for the admin server (mTLS)")
+	flag.Uint64Var(&userID, "user", 0, "User ID for event commands")
+	flag.StringVar(&output, "output", formatTable, "Output format: table / json / yaml")
+	flag.DurationVar(&timeout, "timeout", 10*time.Second, "Request timeout")
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: calendarctl [flags] <command> [args]
+
+Commands:
+  events list [-period day|week|month] [-date "2006-01-02 15:04:05"]
+  events get -id ID
+  events create -title TITLE -start DATE -end DATE [-description TEXT] [-notify-before DURATION] [-transparent]
+  events update -id ID [-title TITLE] [-start DATE] [-end DATE] [-description TEXT] [-notify-before DURATION] [-transparent]
+  events delete -id ID
+  notify-status [-from DATE] [-to DATE]
+  run notify|clear
+  version
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Parse()
+
+	if flag.Arg(0) == "version" {
+		printVersion()
+		return
+	}
+
+	printer, err := newPrinter(os.Stdout, output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = run(ctx, printer, flag.Args())
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, printer *printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: command is required", errUsage)
+	}
+
+	switch args[0] {
+	case "events":
+		if userID == 0 {
+			return fmt.Errorf("%w: -user is required for event commands", errUsage)
+		}
+		creds, err := transportCredentials(addr, eventsTLS, "")
+		if err != nil {
+			return err
+		}
+		conn, err := dial(addr, creds)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx = metadata.AppendToOutgoingContext(ctx, userIDHeader, strconv.FormatUint(userID, 10))
+		return runEvents(ctx, pb.NewEventServiceClient(conn), printer, args[1:])
+	case "notify-status", "run":
+		creds, err := transportCredentials(adminAddr, adminTLS, "admin-")
+		if err != nil {
+			return err
+		}
+		conn, err := dial(adminAddr, creds)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		return runAdmin(ctx, pb.NewAdminServiceClient(conn), printer, args)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+func dial(target string, creds credentials.TransportCredentials) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", target, err)
+	}
+	return conn, nil
+}
+
+// transportCredentials возвращает TLS (mTLS, если задан сертификат клиента), если задан CA сервера,
+// и соединение без TLS для локального API. flagPrefix - префикс флагов сервера для сообщений об ошибках.
+func transportCredentials(target string, files tlsconfig.Files, flagPrefix string) (credentials.TransportCredentials, error) {
+	if files == (tlsconfig.Files{}) {
+		return insecure.NewCredentials(), nil
+	}
+	if files.CAFile == "" {
+		return nil, fmt.Errorf("%w: -%sca is required with -%scert and -%skey", errUsage, flagPrefix, flagPrefix, flagPrefix)
+	}
+	reloader, err := tlsconfig.NewClient(files, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot load certificates for %s: %w", target, err)
+	}
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		return nil, fmt.Errorf("%w: -%saddr: %w", errUsage, flagPrefix, err)
+	}
+	return credentials.NewTLS(reloader.ClientConfig(host)), nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(time.DateTime, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q must be in format %q", errUsage, value, time.DateTime)
+	}
+	return date, nil
+}
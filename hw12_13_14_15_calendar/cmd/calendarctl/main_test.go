@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventServer - сервер событий с одним событием; запоминает пользователя и последний запрос на изменение.
+type eventServer struct {
+	pb.UnimplementedEventServiceServer
+	mu      sync.Mutex
+	event   *pb.Event
+	userIDs []string
+	updated *pb.Event
+	deleted uint64
+}
+
+func (s *eventServer) remember(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userIDs = append(s.userIDs, md.Get(userIDHeader)...)
+}
+
+func (s *eventServer) EventListForDay(ctx context.Context, _ *pb.EventListRequest) (*pb.EventList, error) {
+	s.remember(ctx)
+	return &pb.EventList{Events: []*pb.Event{s.event}}, nil
+}
+
+func (s *eventServer) GetEvent(ctx context.Context, req *pb.GetEventRequest) (*pb.Event, error) {
+	s.remember(ctx)
+	if req.Id != s.event.Id {
+		return nil, status.Error(codes.NotFound, "event not found")
+	}
+	return s.event, nil
+}
+
+func (s *eventServer) CreateEvent(ctx context.Context, req *pb.CreateEventRequest) (*pb.CreateEventResponse, error) {
+	s.remember(ctx)
+	s.updated = req.Event
+	return &pb.CreateEventResponse{Id: 2}, nil
+}
+
+func (s *eventServer) UpdateEvent(ctx context.Context, req *pb.UpdateEventRequest) (*pb.UpdateEventResponse, error) {
+	s.remember(ctx)
+	s.updated = req.Event
+	return &pb.UpdateEventResponse{}, nil
+}
+
+func (s *eventServer) DeleteEvent(ctx context.Context, req *pb.DeleteEventRequest) (*emptypb.Empty, error) {
+	s.remember(ctx)
+	s.deleted = req.Id
+	return &emptypb.Empty{}, nil
+}
+
+// adminServer - административный API с одним статусом уведомления; RunClear завершается ошибкой.
+type adminServer struct {
+	pb.UnimplementedAdminServiceServer
+	status  *pb.NotifyStatus
+	request *pb.NotifyStatusRequest
+}
+
+func (s *adminServer) ListNotifyStatus(_ context.Context, req *pb.NotifyStatusRequest) (*pb.NotifyStatusList, error) {
+	s.request = req
+	return &pb.NotifyStatusList{Statuses: []*pb.NotifyStatus{s.status}}, nil
+}
+
+func (s *adminServer) RunNotify(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (s *adminServer) RunClear(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Internal, "db is unavailable")
+}
+
+func TestRunEvents(t *testing.T) {
+	event := &pb.Event{
+		Id:           1,
+		Title:        "my event",
+		StartDate:    timestamppb.New(getTime(t, "2024-07-06 10:00:00")),
+		EndDate:      timestamppb.New(getTime(t, "2024-07-06 12:00:00")),
+		Description:  "description",
+		NotifyBefore: durationpb.New(time.Hour),
+	}
+	server := &eventServer{event: event}
+	setFlags(t, startServer(t, func(s *grpc.Server) { pb.RegisterEventServiceServer(s, server) }), "", 12345)
+
+	t.Run("list", func(t *testing.T) {
+		out, err := runCommand(t, formatTable, "events", "list", "-date", "2024-07-06 00:00:00")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		require.Equal(t, []string{"ID", "TITLE", "START", "END", "NOTIFY", "BEFORE", "TRANSPARENT"}, strings.Fields(lines[0]))
+		require.Equal(t, []string{"1", "my", "event", "2024-07-06", "10:00:00", "2024-07-06", "12:00:00", "1h0m0s", "false"},
+			strings.Fields(lines[1]))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		out, err := runCommand(t, formatJSON, "events", "create",
+			"-title", "new event", "-start", "2024-07-07 10:00:00", "-end", "2024-07-07 11:00:00", "-transparent")
+		require.NoError(t, err)
+		require.Contains(t, out, `"id": 2`)
+		require.Equal(t, "new event", server.updated.Title)
+		require.True(t, server.updated.Transparent)
+		require.True(t, getTime(t, "2024-07-07 10:00:00").Equal(server.updated.StartDate.AsTime()))
+	})
+
+	t.Run("update changes only passed fields", func(t *testing.T) {
+		_, err := runCommand(t, formatTable, "events", "update", "-id", "1", "-title", "renamed")
+		require.NoError(t, err)
+		require.Equal(t, "renamed", server.updated.Title)
+		require.Equal(t, event.Description, server.updated.Description)
+		require.Equal(t, time.Hour, server.updated.NotifyBefore.AsDuration())
+		require.True(t, event.StartDate.AsTime().Equal(server.updated.StartDate.AsTime()))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		out, err := runCommand(t, formatYAML, "events", "delete", "-id", "1")
+		require.NoError(t, err)
+		require.Equal(t, "message: event 1 deleted\n", out)
+		require.Equal(t, uint64(1), server.deleted)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := runCommand(t, formatTable, "events", "get", "-id", "7")
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	require.NotEmpty(t, server.userIDs)
+	for _, userID := range server.userIDs {
+		require.Equal(t, "12345", userID)
+	}
+}
+
+func TestRunEventsTLS(t *testing.T) {
+	server := &eventServer{event: &pb.Event{Id: 1, Title: "my event"}}
+	certFile, cert := newServerCert(t)
+	serverAddr := startServer(t, func(s *grpc.Server) { pb.RegisterEventServiceServer(s, server) },
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})))
+	setFlags(t, serverAddr, "", 12345)
+
+	t.Run("without ca", func(t *testing.T) {
+		_, err := runCommand(t, formatTable, "events", "get", "-id", "1")
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("with ca", func(t *testing.T) {
+		prevTLS := eventsTLS
+		eventsTLS = tlsconfig.Files{CAFile: certFile}
+		t.Cleanup(func() {
+			eventsTLS = prevTLS
+		})
+
+		out, err := runCommand(t, formatJSON, "events", "get", "-id", "1")
+		require.NoError(t, err)
+		require.Contains(t, out, `"title": "my event"`)
+	})
+}
+
+func TestRunAdmin(t *testing.T) {
+	server := &adminServer{status: &pb.NotifyStatus{
+		EventId:    1,
+		UserId:     12345,
+		Title:      "my event",
+		StartDate:  timestamppb.New(getTime(t, "2024-07-06 10:00:00")),
+		NotifyDate: timestamppb.New(getTime(t, "2024-07-06 09:00:00")),
+		Status:     "NOTIFIED",
+	}}
+	setFlags(t, "", startServer(t, func(s *grpc.Server) { pb.RegisterAdminServiceServer(s, server) }), 0)
+
+	t.Run("notify status", func(t *testing.T) {
+		out, err := runCommand(t, formatJSON, "notify-status", "-from", "2024-07-06 00:00:00", "-to", "2024-07-07 00:00:00")
+		require.NoError(t, err)
+		require.Contains(t, out, `"eventId": 1`)
+		require.Contains(t, out, `"status": "NOTIFIED"`)
+		require.True(t, getTime(t, "2024-07-06 00:00:00").Equal(server.request.StartDate.AsTime()))
+		require.True(t, getTime(t, "2024-07-07 00:00:00").Equal(server.request.EndDate.AsTime()))
+	})
+
+	t.Run("run notify", func(t *testing.T) {
+		out, err := runCommand(t, formatTable, "run", "notify")
+		require.NoError(t, err)
+		require.Equal(t, "notify completed\n", out)
+	})
+
+	t.Run("run clear with error", func(t *testing.T) {
+		_, err := runCommand(t, formatTable, "run", "clear")
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestRunUsage(t *testing.T) {
+	setFlags(t, "localhost:1", "localhost:1", 0)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command"},
+		{name: "unknown command", args: []string{"unknown"}},
+		{name: "events without user", args: []string{"events", "list"}},
+		{name: "unknown job", args: []string{"run", "backup"}},
+		{name: "run without job", args: []string{"run"}},
+		{name: "invalid date", args: []string{"notify-status", "-from", "yesterday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCommand(t, formatTable, tt.args...)
+			require.ErrorIs(t, err, errUsage)
+		})
+	}
+
+	t.Run("events with invalid flags", func(t *testing.T) {
+		setFlags(t, "localhost:1", "localhost:1", 12345)
+		for _, args := range [][]string{
+			{"events"},
+			{"events", "get"},
+			{"events", "list", "-period", "year"},
+			{"events", "create", "-title", "no dates"},
+			{"events", "delete", "-id", "1", "extra"},
+		} {
+			_, err := runCommand(t, formatTable, args...)
+			require.ErrorIs(t, err, errUsage, args)
+		}
+	})
+}
+
+func TestTransportCredentials(t *testing.T) {
+	t.Parallel()
+
+	creds, err := transportCredentials("localhost:8082", tlsconfig.Files{}, "admin-")
+	require.NoError(t, err)
+	require.Equal(t, insecure.NewCredentials().Info().SecurityProtocol, creds.Info().SecurityProtocol)
+
+	_, err = transportCredentials("localhost:8082", tlsconfig.Files{CertFile: "client.crt", KeyFile: "client.key"}, "admin-")
+	require.ErrorIs(t, err, errUsage)
+	require.ErrorContains(t, err, "-admin-ca is required")
+
+	_, err = transportCredentials("localhost:8081", tlsconfig.Files{CertFile: "client.crt", KeyFile: "client.key"}, "")
+	require.ErrorContains(t, err, "-ca is required")
+
+	_, err = transportCredentials("localhost:8082", tlsconfig.Files{CAFile: "/nonexistent/ca.crt"}, "admin-")
+	require.Error(t, err)
+
+	certFile, _ := newServerCert(t)
+	creds, err = transportCredentials("localhost:8081", tlsconfig.Files{CAFile: certFile}, "")
+	require.NoError(t, err)
+	require.Equal(t, "tls", creds.Info().SecurityProtocol)
+}
+
+func TestPrintError(t *testing.T) {
+	t.Parallel()
+
+	st, err := status.New(codes.InvalidArgument, "validation failed").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "title", Description: "is required"}},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	printError(&buf, st.Err())
+	require.Equal(t, "error: InvalidArgument: validation failed\n  title: is required\n", buf.String())
+
+	buf.Reset()
+	printError(&buf, errUsage)
+	require.Equal(t, "error: invalid usage\n", buf.String())
+}
+
+// startServer запускает grpc-сервер на свободном порту и возвращает его адрес.
+func startServer(t *testing.T, register func(s *grpc.Server), opts ...grpc.ServerOption) string {
+	t.Helper()
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(opts...)
+	register(srv)
+	go func() {
+		_ = srv.Serve(lsn)
+	}()
+	t.Cleanup(srv.Stop)
+	return lsn.Addr().String()
+}
+
+// newServerCert создаёт самоподписанный сертификат сервера для 127.0.0.1 и возвращает путь к нему
+// (он же служит CA для клиента) и сам сертификат.
+func newServerCert(t *testing.T) (string, tls.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "calendar"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return certFile, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// setFlags подменяет значения флагов командной строки на время теста.
+func setFlags(t *testing.T, eventsAddr string, adminServerAddr string, user uint64) {
+	t.Helper()
+	prevAddr, prevAdminAddr, prevUserID := addr, adminAddr, userID
+	addr, adminAddr, userID = eventsAddr, adminServerAddr, user
+	t.Cleanup(func() {
+		addr, adminAddr, userID = prevAddr, prevAdminAddr, prevUserID
+	})
+}
+
+func runCommand(t *testing.T, format string, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	printer, err := newPrinter(&buf, format)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = run(ctx, printer, args)
+	return buf.String(), err
+}
+
+func getTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := parseDate(value)
+	require.NoError(t, err)
+	return date
+}
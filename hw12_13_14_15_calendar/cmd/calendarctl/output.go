@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{w: w, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+type eventView struct {
+	ID           uint64     `json:"id" yaml:"id"`
+	Title        string     `json:"title" yaml:"title"`
+	StartDate    time.Time  `json:"startDate" yaml:"startDate"`
+	EndDate      time.Time  `json:"endDate" yaml:"endDate"`
+	Description  string     `json:"description,omitempty" yaml:"description,omitempty"`
+	NotifyBefore string     `json:"notifyBefore,omitempty" yaml:"notifyBefore,omitempty"`
+	Transparent  bool       `json:"transparent" yaml:"transparent"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty" yaml:"deletedAt,omitempty"`
+}
+
+type notifyStatusView struct {
+	EventID    uint64    `json:"eventId" yaml:"eventId"`
+	UserID     uint64    `json:"userId" yaml:"userId"`
+	Title      string    `json:"title" yaml:"title"`
+	StartDate  time.Time `json:"startDate" yaml:"startDate"`
+	NotifyDate time.Time `json:"notifyDate" yaml:"notifyDate"`
+	Status     string    `json:"status" yaml:"status"`
+}
+
+type messageView struct {
+	Message string `json:"message" yaml:"message"`
+}
+
+func newEventView(event *pb.Event) eventView {
+	view := eventView{
+		ID:          event.Id,
+		Title:       event.Title,
+		StartDate:   event.StartDate.AsTime().In(time.Local),
+		EndDate:     event.EndDate.AsTime().In(time.Local),
+		Description: event.Description,
+		Transparent: event.Transparent,
+	}
+	if event.NotifyBefore != nil && event.NotifyBefore.AsDuration() != 0 {
+		view.NotifyBefore = event.NotifyBefore.AsDuration().String()
+	}
+	if event.DeletedAt != nil {
+		deletedAt := event.DeletedAt.AsTime().In(time.Local)
+		view.DeletedAt = &deletedAt
+	}
+	return view
+}
+
+func (p *printer) printEvents(events []*pb.Event) error {
+	views := make([]eventView, 0, len(events))
+	for _, event := range events {
+		views = append(views, newEventView(event))
+	}
+
+	if p.format != formatTable {
+		return p.encode(views)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tSTART\tEND\tNOTIFY BEFORE\tTRANSPARENT")
+	for _, view := range views {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%t\n",
+			view.ID, view.Title, view.StartDate.Format(time.DateTime), view.EndDate.Format(time.DateTime),
+			view.NotifyBefore, view.Transparent)
+	}
+	return tw.Flush()
+}
+
+func (p *printer) printNotifyStatuses(statuses []*pb.NotifyStatus) error {
+	views := make([]notifyStatusView, 0, len(statuses))
+	for _, status := range statuses {
+		views = append(views, notifyStatusView{
+			EventID:    status.EventId,
+			UserID:     status.UserId,
+			Title:      status.Title,
+			StartDate:  status.StartDate.AsTime().In(time.Local),
+			NotifyDate: status.NotifyDate.AsTime().In(time.Local),
+			Status:     status.Status,
+		})
+	}
+
+	if p.format != formatTable {
+		return p.encode(views)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "EVENT ID\tUSER ID\tTITLE\tSTART\tNOTIFY\tSTATUS")
+	for _, view := range views {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n",
+			view.EventID, view.UserID, view.Title, view.StartDate.Format(time.DateTime),
+			view.NotifyDate.Format(time.DateTime), view.Status)
+	}
+	return tw.Flush()
+}
+
+func (p *printer) printMessage(message string) {
+	if p.format != formatTable {
+		_ = p.encode(messageView{Message: message})
+		return
+	}
+	fmt.Fprintln(p.w, message)
+}
+
+// warnConflicts сообщает о пересечениях, допущенных политикой пользователя.
+// Предупреждение пишется в stderr, чтобы не портить основной вывод.
+func (p *printer) warnConflicts(conflicts []*pb.Event) {
+	if len(conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: event overlaps with %d event(s):\n", len(conflicts))
+	printConflicts(os.Stderr, conflicts)
+}
+
+func (p *printer) encode(value any) error {
+	if p.format == formatYAML {
+		encoder := yaml.NewEncoder(p.w)
+		defer encoder.Close()
+		return encoder.Encode(value)
+	}
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// printError выводит ошибку, ошибки полей и, для занятого времени, список пересекающихся событий.
+func printError(w io.Writer, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+
+	fmt.Fprintf(w, "error: %s: %s\n", st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *pb.EventList:
+			printConflicts(w, detail.Events)
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				fmt.Fprintf(w, "  %s: %s\n", violation.GetField(), violation.GetDescription())
+			}
+		}
+	}
+}
+
+func printConflicts(w io.Writer, conflicts []*pb.Event) {
+	for _, conflict := range conflicts {
+		view := newEventView(conflict)
+		fmt.Fprintf(w, "  %d %q %s - %s\n",
+			view.ID, view.Title, view.StartDate.Format(time.DateTime), view.EndDate.Format(time.DateTime))
+	}
+}
@@ -1,65 +1,167 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"net"
 	"time"
 
-	"github.com/spf13/viper"
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
 )
 
 type Config struct {
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Queue    QueueConfig    `mapstructure:"queue"`
-	Schedule ScheduleConfig `mapstructure:"schedule"`
-	Timezone string         `mapstructure:"timezone"`
+	Logger     LoggerConfig     `mapstructure:"logger" validate:"nested"`
+	GrpcServer GrpcServerConfig `mapstructure:"grpcServer" validate:"nested"`
+	Database   DatabaseConfig   `mapstructure:"database" validate:"nested"`
+	Queue      QueueConfig      `mapstructure:"queue" validate:"nested"`
+	Schedule   ScheduleConfig   `mapstructure:"schedule" validate:"nested"`
+	Timezone   string           `mapstructure:"timezone"`
+	Shutdown   ShutdownConfig   `mapstructure:"shutdown" validate:"nested"`
 }
 
 type LoggerConfig struct {
-	Level string `mapstructure:"level"`
+	Level string `mapstructure:"level" validate:"required"`
+}
+
+type GrpcServerConfig struct {
+	Host string `mapstructure:"host"`
+	// Port - порт административного API; пустой порт отключает API
+	Port string    `mapstructure:"port" validate:"regexp:^([0-9]+)?$"`
+	TLS  TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig - mTLS административного API: без него API доступен только с loopback-адреса.
+type TLSConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CertFile     string `mapstructure:"certFile"`
+	KeyFile      string `mapstructure:"keyFile"`
+	ClientCAFile string `mapstructure:"clientCaFile"`
+	MinVersion   string `mapstructure:"minVersion"`
 }
 
 type DatabaseConfig struct {
 	Driver string `mapstructure:"driver"`
-	URI    string `mapstructure:"uri"`
+	URI    string `mapstructure:"uri" validate:"required" redact:"uri"`
 }
 
 type QueueConfig struct {
-	URI          string `mapstructure:"uri"`
-	ExchangeName string `mapstructure:"exchangeName"`
+	URI          string `mapstructure:"uri" validate:"required|uri" redact:"uri"`
+	ExchangeName string `mapstructure:"exchangeName" validate:"required"`
 	ExchangeType string `mapstructure:"exchangeType"`
-	QueueName    string `mapstructure:"queueName"`
-	RoutingKey   string `mapstructure:"routingKey"`
+	QueueName    string `mapstructure:"queueName" validate:"required"`
+	RoutingKey   string `mapstructure:"routingKey" validate:"required"`
 }
 
 type ScheduleConfig struct {
 	NotifyCron       string        `mapstructure:"notifyCron"`
 	ClearCron        string        `mapstructure:"clearCron"`
-	NotifyPeriod     time.Duration `mapstructure:"notifyPeriod"`
-	NotifyScanPeriod time.Duration `mapstructure:"notifyScanPeriod"`
-	ClearPeriod      time.Duration `mapstructure:"clearPeriod"`
+	DigestCron       string        `mapstructure:"digestCron"`
+	DigestHour       int           `mapstructure:"digestHour" validate:"min:0|max:23"`
+	NotifyPeriod     time.Duration `mapstructure:"notifyPeriod" validate:"min:0"`
+	NotifyScanPeriod time.Duration `mapstructure:"notifyScanPeriod" validate:"min:0"`
+	ClearPeriod      time.Duration `mapstructure:"clearPeriod" validate:"min:1"` // 0 удалил бы все прошедшие события
+	TrashPeriod      time.Duration `mapstructure:"trashPeriod" validate:"min:1"` // 0 очистил бы всю корзину
 }
 
-func NewConfig(path string) (*Config, error) {
-	parser := viper.New()
-	parser.SetConfigFile(path)
+type ShutdownConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout" validate:"min:0"`
+	DrainTimeout time.Duration `mapstructure:"drainTimeout" validate:"min:0"`
+}
+
+var (
+	errAdminNotLoopback = errors.New("admin api without tls must listen on a loopback address")
+	errNoClientCA       = errors.New("admin api requires client certificates: clientCaFile is required")
+)
 
-	err := parser.ReadInConfig()
+// envPrefix - префикс переменных окружения, переопределяющих конфигурацию (SCHEDULER_QUEUE_URI и т.п.).
+const envPrefix = "SCHEDULER"
+
+// NewConfig читает конфигурацию и проверяет её.
+func NewConfig(path string) (*Config, error) {
+	config, err := LoadConfig(path)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read config file: %w", err)
+		return nil, err
 	}
-
-	for _, key := range parser.AllKeys() {
-		value := parser.GetString(key)
-		parser.Set(key, os.ExpandEnv(value))
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config is not valid: %w", err)
 	}
+	return config, nil
+}
 
+// LoadConfig читает конфигурацию без проверки (для --print-config).
+func LoadConfig(path string) (*Config, error) {
 	var config Config
-	err = parser.Unmarshal(&config)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	if err := internalconfig.Load(path, envPrefix, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate проверяет конфигурацию: правила из тэгов validate и проверки, которые не выражаются тэгами.
+func (c *Config) Validate() error {
+	var errs validator.ValidationErrors
+	if err := validator.Validate(c); err != nil && !errors.As(err, &errs) {
+		return err
+	}
+
+	var err error
+	if c.Logger.Level != "" {
+		_, err = logger.ParseLevel(c.Logger.Level)
+		errs.Add("logger.level", err)
+	}
+	_, err = time.LoadLocation(c.Timezone)
+	errs.Add("timezone", err)
+	// пустой драйвер означает pgx
+	switch c.Database.Driver {
+	case "", "pgx", sqlitestorage.DriverName:
+	default:
+		errs.Add("database.driver", validator.ErrStringNotInValues)
+	}
+	if c.GrpcServer.Port != "" {
+		c.GrpcServer.validate(&errs)
+	}
+	errs.Add("schedule.notifyCron", scheduler.ValidateCron(c.Schedule.NotifyCron))
+	errs.Add("schedule.clearCron", scheduler.ValidateCron(c.Schedule.ClearCron))
+	// пустое расписание отключает сводки
+	if c.Schedule.DigestCron != "" {
+		errs.Add("schedule.digestCron", scheduler.ValidateDigestCron(c.Schedule.DigestCron))
 	}
 
-	return &config, err
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate проверяет защиту административного API: он запускает задачи планировщика
+// и показывает события всех пользователей, поэтому открыт наружу только с mTLS.
+func (c GrpcServerConfig) validate(errs *validator.ValidationErrors) {
+	if !c.TLS.Enabled {
+		if !isLoopback(c.Host) {
+			errs.Add("grpcServer.host", errAdminNotLoopback)
+		}
+		return
+	}
+	if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+		errs.Add("grpcServer.tls", tlsconfig.ErrNoCertificate)
+	}
+	if c.TLS.ClientCAFile == "" {
+		errs.Add("grpcServer.tls.clientCaFile", errNoClientCA)
+	}
+	_, err := tlsconfig.ParseVersion(c.TLS.MinVersion)
+	errs.Add("grpcServer.tls.minVersion", err)
+}
+
+// isLoopback сообщает, что адрес доступен только с этого хоста; пустой адрес - все интерфейсы.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
@@ -2,28 +2,56 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/health"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/queue/rabbit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
+	internalgrpc "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
 	sqlstorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
-var configFile string
+var (
+	configFile  string
+	printConfig bool
+	checkConfig bool
+)
+
+type sqlStorage interface {
+	scheduler.Storage
+	Connect(ctx context.Context) error
+	Close(ctx context.Context) error
+}
 
 func init() {
 	flag.StringVar(&configFile, "config", "/etc/scheduler/config.yaml", "Path to configuration file")
+	flag.BoolVar(&printConfig, "print-config", false, "Print effective configuration with secrets redacted and exit")
+	flag.BoolVar(&checkConfig, "check-config", false, "Validate configuration and exit")
 }
 
 func main() {
 	flag.Parse()
 
+	if printConfig || checkConfig {
+		config, err := LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("failed reading config %v", err)
+		}
+		os.Exit(internalconfig.Inspect(os.Stdout, os.Stderr, config, printConfig))
+	}
+
 	config, err := NewConfig(configFile)
 	if err != nil {
 		log.Fatalf("failed reading config %v", err)
@@ -44,20 +72,27 @@ func main() {
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// SIGHUP перечитывает конфигурацию; подписываемся сразу, чтобы сигнал во время запуска не завершил процесс
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+
+	// остановка: административный API, задачи планировщика, очередь, БД
+	shutdown := lifecycle.New(logg, config.Shutdown.Timeout)
+	defer func() {
+		_ = shutdown.Shutdown(context.Background())
+	}()
+
 	// storage
-	sqlStorage := sqlstorage.New(config.Database.Driver, config.Database.URI)
+	sqlStorage := newSQLStorage(config.Database)
 	if err := sqlStorage.Connect(ctx); err != nil {
 		logg.Error(ctx, err, "failed to connect to db")
 		return
 	}
-	defer func() {
-		if err := sqlStorage.Close(ctx); err != nil {
-			logg.Error(ctx, err, "failed to close sql storage")
-		}
-	}()
+	shutdown.Add("storage", 0, sqlStorage.Close)
 
 	// queue
 	queue := rabbit.NewQueue(
@@ -73,24 +108,49 @@ func main() {
 		logg.Error(ctx, err, "queue failed to start")
 		return
 	}
+	shutdown.Add("queue", 0, queue.Stop)
 
-	// scheduler
+	// scheduler; задачи не прерываются сигналом остановки, а дорабатывают до срока drainTimeout
 	scheduler := scheduler.NewScheduler(
-		ctx,
+		context.WithoutCancel(ctx),
 		logg,
 		sqlStorage,
 		publisher,
 		config.Schedule.NotifyCron,
 		config.Schedule.ClearCron,
+		config.Schedule.DigestCron,
+		config.Schedule.DigestHour,
 		config.Schedule.NotifyPeriod,
 		config.Schedule.NotifyScanPeriod,
 		config.Schedule.ClearPeriod,
+		config.Schedule.TrashPeriod,
 	)
 
 	if err := scheduler.Start(ctx); err != nil {
 		logg.Error(ctx, err, "scheduler failed to start")
 		return
 	}
+	shutdown.Add("scheduler", config.Shutdown.DrainTimeout, scheduler.Stop)
+
+	// перечитывание конфигурации по SIGHUP и при изменении файла
+	go reload.Watch(ctx, logg, configFile, reload.DefaultPeriod, reloadSignals, applyConfig(logg, scheduler))
+
+	// административный API
+	if config.GrpcServer.Port != "" {
+		adminTLS, err := newAdminTLSConfig(ctx, logg, config.GrpcServer.TLS)
+		if err != nil {
+			logg.Error(ctx, err, "failed to load admin tls certificates")
+			return
+		}
+		adminServer := internalgrpc.NewAdminServer(logg, scheduler, config.GrpcServer.Host, config.GrpcServer.Port, adminTLS)
+		go func() {
+			if err := adminServer.Start(ctx); err != nil {
+				logg.Error(ctx, err, "admin grpc server failed to start")
+				cancel()
+			}
+		}()
+		shutdown.Add("admin grpc server", config.Shutdown.DrainTimeout, adminServer.Stop)
+	}
 
 	if err = health.FileHealthcheck(ctx, logg); err != nil {
 		logg.Error(ctx, err, "healthcheck failed to start")
@@ -100,12 +160,29 @@ func main() {
 	logg.Info(ctx, "scheduler is running...")
 
 	<-ctx.Done()
+}
 
-	if err := queue.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop queue")
+func newSQLStorage(config DatabaseConfig) sqlStorage {
+	if config.Driver == sqlitestorage.DriverName {
+		return sqlitestorage.New(config.URI)
 	}
+	return sqlstorage.New(config.Driver, config.URI)
+}
 
-	if err := scheduler.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop scheduler")
+// newAdminTLSConfig загружает сертификаты административного API (nil, если TLS выключен)
+// и перечитывает их при изменении файлов.
+func newAdminTLSConfig(ctx context.Context, logg *logger.Logger, config TLSConfig) (*tls.Config, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	reloader, err := tlsconfig.New(tlsconfig.Files{
+		CertFile: config.CertFile,
+		KeyFile:  config.KeyFile,
+		CAFile:   config.ClientCAFile,
+	}, config.MinVersion)
+	if err != nil {
+		return nil, err
 	}
+	go reloader.Watch(ctx, logg, tlsconfig.DefaultReloadPeriod)
+	return reloader.ServerConfig(), nil
 }
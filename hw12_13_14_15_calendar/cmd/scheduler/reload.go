@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/scheduler"
+)
+
+// applyConfig перечитывает конфигурацию и применяет настройки, которые можно менять на лету:
+// уровень логирования, расписания и периоды задач планировщика, час сводок.
+// Остальные изменения вступают в силу после перезапуска.
+// Новые настройки сначала проверяются и разбираются целиком и применяются, только если все они корректны.
+func applyConfig(logg *logger.Logger, sched *scheduler.Scheduler) reload.ApplyFunc {
+	return func(_ context.Context) error {
+		config, err := NewConfig(configFile)
+		if err != nil {
+			return err
+		}
+		level, err := logger.ParseLevel(config.Logger.Level)
+		if err != nil {
+			return err
+		}
+		schedules, err := scheduler.ParseSchedules(config.Schedule.NotifyCron, config.Schedule.ClearCron, config.Schedule.DigestCron)
+		if err != nil {
+			return err
+		}
+
+		// дальше ничего не может завершиться ошибкой: перечитывание применяется целиком или не применяется вовсе
+		logg.SetLevel(level)
+		sched.Reschedule(schedules)
+		sched.SetPeriods(
+			config.Schedule.NotifyPeriod,
+			config.Schedule.NotifyScanPeriod,
+			config.Schedule.ClearPeriod,
+			config.Schedule.TrashPeriod,
+		)
+		sched.SetDigestHour(config.Schedule.DigestHour)
+		return nil
+	}
+}
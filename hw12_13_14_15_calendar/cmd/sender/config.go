@@ -1,56 +1,94 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"time"
 
-	"github.com/spf13/viper"
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
 )
 
 type Config struct {
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Queue    QueueConfig    `mapstructure:"queue"`
+	Logger   LoggerConfig   `mapstructure:"logger" validate:"nested"`
+	Database DatabaseConfig `mapstructure:"database" validate:"nested"`
+	Queue    QueueConfig    `mapstructure:"queue" validate:"nested"`
 	Timezone string         `mapstructure:"timezone"`
+	Shutdown ShutdownConfig `mapstructure:"shutdown" validate:"nested"`
 }
 
 type LoggerConfig struct {
-	Level string `mapstructure:"level"`
+	Level string `mapstructure:"level" validate:"required"`
 }
 
 type DatabaseConfig struct {
 	Driver string `mapstructure:"driver"`
-	URI    string `mapstructure:"uri"`
+	URI    string `mapstructure:"uri" validate:"required" redact:"uri"`
 }
 
 type QueueConfig struct {
-	URI          string `mapstructure:"uri"`
-	ExchangeName string `mapstructure:"exchangeName"`
+	URI          string `mapstructure:"uri" validate:"required|uri" redact:"uri"`
+	ExchangeName string `mapstructure:"exchangeName" validate:"required"`
 	ExchangeType string `mapstructure:"exchangeType"`
-	QueueName    string `mapstructure:"queueName"`
-	RoutingKey   string `mapstructure:"routingKey"`
-	ConsumerTag  string `mapstructure:"consumerTag"`
+	QueueName    string `mapstructure:"queueName" validate:"required"`
+	RoutingKey   string `mapstructure:"routingKey" validate:"required"`
+	ConsumerTag  string `mapstructure:"consumerTag" validate:"required"`
 }
 
-func NewConfig(path string) (*Config, error) {
-	parser := viper.New()
-	parser.SetConfigFile(path)
+type ShutdownConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout" validate:"min:0"`
+	DrainTimeout time.Duration `mapstructure:"drainTimeout" validate:"min:0"`
+}
 
-	err := parser.ReadInConfig()
+// envPrefix - префикс переменных окружения, переопределяющих конфигурацию (SENDER_QUEUE_URI и т.п.).
+const envPrefix = "SENDER"
+
+// NewConfig читает конфигурацию и проверяет её.
+func NewConfig(path string) (*Config, error) {
+	config, err := LoadConfig(path)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read config file: %w", err)
+		return nil, err
 	}
-
-	for _, key := range parser.AllKeys() {
-		value := parser.GetString(key)
-		parser.Set(key, os.ExpandEnv(value))
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config is not valid: %w", err)
 	}
+	return config, nil
+}
 
+// LoadConfig читает конфигурацию без проверки (для --print-config).
+func LoadConfig(path string) (*Config, error) {
 	var config Config
-	err = parser.Unmarshal(&config)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	if err := internalconfig.Load(path, envPrefix, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate проверяет конфигурацию: правила из тэгов validate и проверки, которые не выражаются тэгами.
+func (c *Config) Validate() error {
+	var errs validator.ValidationErrors
+	if err := validator.Validate(c); err != nil && !errors.As(err, &errs) {
+		return err
 	}
 
-	return &config, err
+	var err error
+	if c.Logger.Level != "" {
+		_, err = logger.ParseLevel(c.Logger.Level)
+		errs.Add("logger.level", err)
+	}
+	_, err = time.LoadLocation(c.Timezone)
+	errs.Add("timezone", err)
+	// пустой драйвер означает pgx
+	switch c.Database.Driver {
+	case "", "pgx", sqlitestorage.DriverName:
+	default:
+		errs.Add("database.driver", validator.ErrStringNotInValues)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
@@ -4,26 +4,51 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	internalconfig "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/config"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/health"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/queue/rabbit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/sender"
 	sqlstorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
 )
 
-var configFile string
+var (
+	configFile  string
+	printConfig bool
+	checkConfig bool
+)
+
+type sqlStorage interface {
+	sender.Storage
+	Connect(ctx context.Context) error
+	Close(ctx context.Context) error
+}
 
 func init() {
 	flag.StringVar(&configFile, "config", "/etc/sender/config.yaml", "Path to configuration file")
+	flag.BoolVar(&printConfig, "print-config", false, "Print effective configuration with secrets redacted and exit")
+	flag.BoolVar(&checkConfig, "check-config", false, "Validate configuration and exit")
 }
 
 func main() {
 	flag.Parse()
 
+	if printConfig || checkConfig {
+		config, err := LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("failed reading config %v", err)
+		}
+		os.Exit(internalconfig.Inspect(os.Stdout, os.Stderr, config, printConfig))
+	}
+
 	config, err := NewConfig(configFile)
 	if err != nil {
 		log.Fatalf("failed reading config %v", err)
@@ -44,20 +69,27 @@ func main() {
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// SIGHUP перечитывает конфигурацию; подписываемся сразу, чтобы сигнал во время запуска не завершил процесс
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+
+	// остановка: consumer, обработка полученных уведомлений, очередь, БД
+	shutdown := lifecycle.New(logg, config.Shutdown.Timeout)
+	defer func() {
+		_ = shutdown.Shutdown(context.Background())
+	}()
+
 	// storage
-	sqlStorage := sqlstorage.New(config.Database.Driver, config.Database.URI)
+	sqlStorage := newSQLStorage(config.Database)
 	if err := sqlStorage.Connect(ctx); err != nil {
 		logg.Error(ctx, err, "failed to connect to db")
 		return
 	}
-	defer func() {
-		if err := sqlStorage.Close(ctx); err != nil {
-			logg.Error(ctx, err, "failed to close sql storage")
-		}
-	}()
+	shutdown.Add("storage", 0, sqlStorage.Close)
 
 	// queue
 	queue := rabbit.NewQueue(
@@ -73,10 +105,11 @@ func main() {
 		logg.Error(ctx, err, "queue failed to start")
 		return
 	}
+	shutdown.Add("queue", 0, queue.Stop)
 
-	// sender
+	// sender; обновление статусов не прерывается сигналом остановки, чтобы полученные уведомления были обработаны
 	sender := sender.NewSender(
-		ctx,
+		context.WithoutCancel(ctx),
 		logg,
 		sqlStorage,
 		consumer,
@@ -86,6 +119,11 @@ func main() {
 		logg.Error(ctx, err, "sender failed to start")
 		return
 	}
+	shutdown.Add("sender", config.Shutdown.DrainTimeout, sender.Stop)
+	shutdown.Add("consumer", 0, consumer.Stop)
+
+	// перечитывание конфигурации по SIGHUP и при изменении файла
+	go reload.Watch(ctx, logg, configFile, reload.DefaultPeriod, reloadSignals, applyConfig(logg))
 
 	if err = health.FileHealthcheck(ctx, logg); err != nil {
 		logg.Error(ctx, err, "healthcheck failed to start")
@@ -95,16 +133,11 @@ func main() {
 	logg.Info(ctx, "sender is running...")
 
 	<-ctx.Done()
+}
 
-	if err := consumer.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop consumer")
-	}
-
-	if err := queue.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop queue")
-	}
-
-	if err := sender.Stop(ctx); err != nil {
-		logg.Error(ctx, err, "failed to stop sender")
+func newSQLStorage(config DatabaseConfig) sqlStorage {
+	if config.Driver == sqlitestorage.DriverName {
+		return sqlitestorage.New(config.URI)
 	}
+	return sqlstorage.New(config.Driver, config.URI)
 }
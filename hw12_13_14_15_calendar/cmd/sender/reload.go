@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/reload"
+)
+
+// applyConfig перечитывает конфигурацию и применяет настройки, которые можно менять на лету, - уровень логирования.
+// Остальные изменения вступают в силу после перезапуска.
+func applyConfig(logg *logger.Logger) reload.ApplyFunc {
+	return func(_ context.Context) error {
+		config, err := NewConfig(configFile)
+		if err != nil {
+			return err
+		}
+		level, err := logger.ParseLevel(config.Logger.Level)
+		if err != nil {
+			return err
+		}
+
+		logg.SetLevel(level)
+		return nil
+	}
+}
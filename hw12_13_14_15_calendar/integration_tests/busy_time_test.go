@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *IntegrationTestSuite) TestEventBusyTimeConcurrentCreate() {
+	t := s.T()
+
+	const workers = 20
+
+	now := time.Now()
+	userID := uint64(now.UnixNano())
+	startDate := now.Add(24 * time.Hour).Truncate(time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// параллельное создание пересекающихся событий
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		created    []uint64
+		otherErrs  []error
+		busyErrCnt int
+	)
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			event := storage.Event{
+				Title:        "my event",
+				StartDate:    startDate.Add(time.Duration(i) * time.Minute),
+				EndDate:      startDate.Add(time.Hour + time.Duration(i)*time.Minute),
+				Description:  "my event description",
+				UserID:       userID,
+				NotifyBefore: time.Hour,
+			}
+			<-start
+
+			eventID, err := s.storage.Create(ctx, &event)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created = append(created, eventID)
+			case errors.Is(err, storage.ErrBusyTime):
+				busyErrCnt++
+			default:
+				otherErrs = append(otherErrs, err)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	require.Empty(t, otherErrs)
+	require.Len(t, created, 1)
+	require.Equal(t, workers-1, busyErrCnt)
+
+	// в БД сохранилось только одно событие
+	events, err := s.storage.ListForPeriod(ctx, userID, storage.EventFilter{}, startDate.Add(-time.Hour), startDate.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, created[0], events[0].ID)
+}
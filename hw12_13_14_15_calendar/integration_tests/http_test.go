@@ -36,14 +36,14 @@ func (s *IntegrationTestSuite) TestHttpProcessEvent() {
 		StartDate:    event.StartDate,
 		EndDate:      event.EndDate,
 		Description:  event.Description,
-		NotifyBefore: event.NotifyBefore,
+		NotifyBefore: &event.NotifyBefore,
 	}
 	eventDtoUpdated := app.EventDto{
 		Title:        event.Title + " v2",
 		StartDate:    event.StartDate.Add(time.Minute * 20),
 		EndDate:      event.EndDate.Add(time.Minute * 30),
 		Description:  event.Description + " v2",
-		NotifyBefore: time.Minute * 10,
+		NotifyBefore: ptr(time.Minute * 10),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
@@ -75,7 +75,7 @@ func (s *IntegrationTestSuite) TestHttpProcessEvent() {
 	require.NoError(t, err)
 	require.True(t, resp.IsError())
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
-	require.Contains(t, resp.String(), "userID is not valid")
+	require.Contains(t, resp.String(), "header.X-USER-ID")
 
 	// попытка добавления пересекающегося события
 	resp, err = s.httpClient.R().
@@ -194,21 +194,21 @@ func (s *IntegrationTestSuite) TestHttpListEvents() {
 		StartDate:    event.StartDate,
 		EndDate:      event.EndDate,
 		Description:  event.Description,
-		NotifyBefore: event.NotifyBefore,
+		NotifyBefore: &event.NotifyBefore,
 	}
 	eventDto2 := app.EventDto{
 		Title:        event.Title + " v2",
 		StartDate:    event.StartDate.Add(time.Hour * 24 * 5),
 		EndDate:      event.EndDate.Add(time.Hour * 25 * 5),
 		Description:  event.Description + " v2",
-		NotifyBefore: time.Minute * 10,
+		NotifyBefore: ptr(time.Minute * 10),
 	}
 	eventDto3 := app.EventDto{
 		Title:        event.Title + " v3",
 		StartDate:    event.StartDate.Add(time.Hour * 24 * 15),
 		EndDate:      event.EndDate.Add(time.Hour * 25 * 15),
 		Description:  event.Description + " v3",
-		NotifyBefore: time.Minute * 30,
+		NotifyBefore: ptr(time.Minute * 30),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
@@ -296,3 +296,7 @@ func (s *IntegrationTestSuite) TestHttpListEvents() {
 		require.True(t, resp.IsSuccess())
 	}
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
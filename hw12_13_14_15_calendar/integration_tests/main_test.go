@@ -13,7 +13,9 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	sqlstorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sql"
+	sqlitestorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/sqlite"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -23,11 +25,25 @@ const userIDHeader = "x-user-id"
 
 var globalConfig *Config
 
+type Storage interface {
+	Connect(ctx context.Context) error
+	Close(ctx context.Context) error
+	Create(ctx context.Context, event *storage.Event) (uint64, error)
+	GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error)
+	ListForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
+}
+
 type IntegrationTestSuite struct {
 	suite.Suite
 	cfg        *Config
 	logg       *logger.Logger
-	storage    *sqlstorage.Storage
+	storage    Storage
 	grpcClient pb.EventServiceClient
 	grpcConn   *grpc.ClientConn
 	httpClient *resty.Client
@@ -46,7 +62,11 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	}
 
 	// sql storage
-	s.storage = sqlstorage.New(s.cfg.Database.Driver, s.cfg.Database.URI)
+	if s.cfg.Database.Driver == sqlitestorage.DriverName {
+		s.storage = sqlitestorage.New(s.cfg.Database.URI)
+	} else {
+		s.storage = sqlstorage.New(s.cfg.Database.Driver, s.cfg.Database.URI)
+	}
 	if err := s.storage.Connect(ctx); err != nil {
 		s.logg.Error(ctx, err, "failed to connect to db")
 		os.Exit(1)
@@ -2,14 +2,19 @@ package app
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 )
 
 type App struct {
-	logger  Logger
-	storage Storage
+	logger           Logger
+	storage          Storage
+	maxEventsPerUser int
+	idempotencyTTL   time.Duration
+	resourceAdmins   map[uint64]struct{}
 }
 
 type Logger interface {
@@ -21,62 +26,412 @@ type Logger interface {
 
 type Storage interface {
 	Create(ctx context.Context, event *storage.Event) (uint64, error)
+	// GetIdempotencyKey возвращает событие, созданное с ключом не раньше notBefore (ErrIdempotencyKeyNotFound, если его нет).
+	GetIdempotencyKey(ctx context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error)
+	// CreateIdempotent создаёт событие или возвращает ID события, созданного с тем же ключом не раньше notBefore;
+	// если ключ использован для запроса с другим хэшем, возвращает ErrIdempotencyKeyReused.
+	CreateIdempotent(ctx context.Context, event *storage.Event, key string, requestHash string, notBefore time.Time) (uint64, bool, error)
 	GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error)
 	Update(ctx context.Context, event *storage.Event) error
 	Delete(ctx context.Context, userID uint64, eventID uint64) error
-	ListForPeriod(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) ([]*storage.Event, error)
+	Restore(ctx context.Context, userID uint64, eventID uint64) error
+	ListDeleted(ctx context.Context, userID uint64) ([]*storage.Event, error)
+	CountByUser(ctx context.Context, userID uint64) (int, error)
+	ListForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
+	CreateAuditRecord(ctx context.Context, record *storage.AuditRecord) error
+	ListAuditRecords(ctx context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error)
+	ListConflicts(ctx context.Context, event *storage.Event) ([]*storage.Event, error)
+	GetUserSettings(ctx context.Context, userID uint64) (*storage.UserSettings, error)
+	SaveUserSettings(ctx context.Context, settings *storage.UserSettings) error
+	CreateCalendar(ctx context.Context, calendar *storage.Calendar) (uint64, error)
+	GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error)
+	// GetDefaultCalendar возвращает календарь пользователя по умолчанию, создавая его при первом обращении.
+	GetDefaultCalendar(ctx context.Context, userID uint64) (*storage.Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar *storage.Calendar) error
+	DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error
+	ListCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error)
+	// ListSharedCalendars возвращает календари других пользователей, открытые пользователю.
+	ListSharedCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error)
+	// GetSharedByID возвращает событие из календаря, открытого пользователю.
+	GetSharedByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error)
+	// ListSharedForPeriod возвращает события из календарей, открытых пользователю, пересекающиеся с периодом.
+	ListSharedForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
+	CreateResource(ctx context.Context, resource *storage.Resource) (uint64, error)
+	GetResource(ctx context.Context, resourceID uint64) (*storage.Resource, error)
+	UpdateResource(ctx context.Context, resource *storage.Resource) error
+	DeleteResource(ctx context.Context, resourceID uint64) error
+	ListResources(ctx context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error)
+	// ListResourceBusy возвращает брони ресурса, пересекающиеся с периодом.
+	ListResourceBusy(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*storage.BusyInterval, error)
 }
 
 func New(logger Logger, storage Storage) *App {
 	return &App{
-		logger:  logger,
-		storage: storage,
+		logger:         logger,
+		storage:        storage,
+		idempotencyTTL: DefaultIdempotencyTTL,
 	}
 }
 
-func (a *App) Create(ctx context.Context, eventDto EventDto) (uint64, error) {
-	return a.storage.Create(ctx, convertEventToModel(&eventDto))
+// WithQuota ограничивает число событий пользователя (включая события в корзине); 0 - без ограничения.
+func (a *App) WithQuota(maxEventsPerUser int) *App {
+	a.maxEventsPerUser = maxEventsPerUser
+	return a
 }
 
+// WithIdempotencyTTL задаёт, сколько хранится результат создания события по ключу идемпотентности.
+func (a *App) WithIdempotencyTTL(ttl time.Duration) *App {
+	if ttl > 0 {
+		a.idempotencyTTL = ttl
+	}
+	return a
+}
+
+// WithResourceAdmins задаёт пользователей, которые могут менять и удалять любые ресурсы, а не только созданные ими.
+func (a *App) WithResourceAdmins(userIDs []uint64) *App {
+	a.resourceAdmins = make(map[uint64]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		a.resourceAdmins[userID] = struct{}{}
+	}
+	return a
+}
+
+// Create добавляет событие и возвращает его ID, а также пересекающиеся события, если пользователь
+// разрешил пересечения с предупреждением, и предупреждения о событии вне рабочих часов или
+// в период отсутствия, если пользователь выбрал для них политику WARN.
+// При повторе запроса с ключом идемпотентности (см. ContextWithIdempotencyKey) возвращается ID
+// ранее созданного события. Событие без календаря попадает в календарь по умолчанию,
+// событие без заданного напоминания (NotifyBefore == nil) получает напоминание по умолчанию
+// своего календаря; NotifyBefore == 0 создаёт событие без напоминания.
+func (a *App) Create(ctx context.Context, eventDto EventDto) (uint64, []*EventDto, []WarningDto, error) {
+	if err := validateEvent(&eventDto); err != nil {
+		return 0, nil, nil, err
+	}
+	key := idempotencyKeyFromContext(ctx)
+	if len(key) > MaxIdempotencyKeyLen {
+		return 0, nil, nil, ErrNotValidIdempotencyKey
+	}
+	// повтор запроса не проверяет квоту и доступность заново: событие уже создано
+	var hash string
+	if key != "" {
+		hash = requestHash(eventDto)
+		eventID, conflicts, replayed, err := a.replayCreate(ctx, eventDto, key, hash)
+		if err != nil || replayed {
+			return eventID, conflicts, nil, err
+		}
+	}
+	if err := a.checkQuota(ctx, eventDto.UserID); err != nil {
+		return 0, nil, nil, err
+	}
+	calendar, err := a.eventCalendar(ctx, eventDto.UserID, eventDto.CalendarID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	event := convertEventToModel(&eventDto)
+	event.CalendarID = calendar.ID
+	if eventDto.NotifyBefore == nil {
+		event.NotifyBefore = calendar.NotifyBefore
+	}
+	settings, err := a.storage.GetUserSettings(ctx, eventDto.UserID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	warnings, err := checkAvailability(settings, event)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	eventID, replayed, err := a.createEvent(ctx, event, key, hash)
+	if err != nil {
+		return 0, nil, nil, convertError(err)
+	}
+	event.ID = eventID
+	if !replayed {
+		a.audit(ctx, storage.AuditCreate, eventDto.UserID, nil, event)
+	}
+
+	return eventID, a.listConflicts(ctx, settings, event), warnings, nil
+}
+
+// GetByID возвращает событие пользователя или событие из календаря, открытого ему другим пользователем.
 func (a *App) GetByID(ctx context.Context, userID uint64, eventID uint64) (*EventDto, error) {
 	event, err := a.storage.GetByID(ctx, userID, eventID)
+	if errors.Is(err, storage.ErrEventNotFound) {
+		event, err = a.storage.GetSharedByID(ctx, userID, eventID)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return convertEventToDto(event), nil
 }
 
-func (a *App) Update(ctx context.Context, eventDto EventDto) error {
-	return a.storage.Update(ctx, convertEventToModel(&eventDto))
+// Update обновляет событие и возвращает пересекающиеся события и предупреждения так же, как Create.
+// Рабочие часы и периоды отсутствия проверяются, только если событие заняло другое время.
+// Без календаря событие остаётся в прежнем календаре, без напоминания - с прежним напоминанием.
+func (a *App) Update(ctx context.Context, eventDto EventDto) ([]*EventDto, []WarningDto, error) {
+	if err := validateEvent(&eventDto); err != nil {
+		return nil, nil, err
+	}
+	before, err := a.storage.GetByID(ctx, eventDto.UserID, eventDto.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	before = copyEvent(before)
+
+	event := convertEventToModel(&eventDto)
+	if event.CalendarID == 0 {
+		event.CalendarID = before.CalendarID
+	} else if event.CalendarID != before.CalendarID {
+		if _, err := a.storage.GetCalendar(ctx, event.UserID, event.CalendarID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if eventDto.NotifyBefore == nil {
+		event.NotifyBefore = before.NotifyBefore
+	}
+	settings, err := a.storage.GetUserSettings(ctx, event.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []WarningDto
+	if !event.StartDate.Equal(before.StartDate) || !event.EndDate.Equal(before.EndDate) ||
+		(before.Transparent && !event.Transparent) {
+		if warnings, err = checkAvailability(settings, event); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := a.storage.Update(ctx, event); err != nil {
+		return nil, nil, convertError(err)
+	}
+	a.audit(ctx, storage.AuditUpdate, eventDto.UserID, before, event)
+
+	return a.listConflicts(ctx, settings, event), warnings, nil
 }
 
 func (a *App) Delete(ctx context.Context, userID uint64, eventID uint64) error {
-	return a.storage.Delete(ctx, userID, eventID)
+	before, err := a.storage.GetByID(ctx, userID, eventID)
+	if err != nil {
+		return err
+	}
+	before = copyEvent(before)
+
+	if err := a.storage.Delete(ctx, userID, eventID); err != nil {
+		return err
+	}
+	a.audit(ctx, storage.AuditDelete, userID, before, nil)
+	return nil
+}
+
+func (a *App) Restore(ctx context.Context, userID uint64, eventID uint64) error {
+	before, err := a.getDeleted(ctx, userID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.storage.Restore(ctx, userID, eventID); err != nil {
+		return convertError(err)
+	}
+
+	after, err := a.storage.GetByID(ctx, userID, eventID)
+	if err != nil {
+		// событие уже восстановлено, поэтому ошибку чтения не возвращаем
+		a.logger.Error(ctx, err, "failed to get restored event", "eventID", eventID)
+		after = copyEvent(before)
+		after.DeletedAt = nil
+	}
+	a.audit(ctx, storage.AuditRestore, userID, before, after)
+	return nil
+}
+
+// проверяет, что событие пользователя существует (в том числе в корзине).
+func (a *App) checkEventExists(ctx context.Context, userID uint64, eventID uint64) error {
+	_, err := a.storage.GetByID(ctx, userID, eventID)
+	if !errors.Is(err, storage.ErrEventNotFound) {
+		return err
+	}
+	_, err = a.getDeleted(ctx, userID, eventID)
+	return err
 }
 
-func (a *App) ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*EventDto, error) {
+// возвращает событие пользователя из корзины (ErrEventNotFound, если его там нет).
+func (a *App) getDeleted(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	events, err := a.storage.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.ID == eventID {
+			return copyEvent(event), nil
+		}
+	}
+	return nil, storage.ErrEventNotFound
+}
+
+// GetHistory возвращает журнал изменений события. Журнал ведётся best-effort (см. App.audit):
+// при сбое записи или параллельных изменениях история может быть неполной.
+func (a *App) GetHistory(ctx context.Context, userID uint64, eventID uint64) ([]*AuditRecordDto, error) {
+	records, err := a.storage.ListAuditRecords(ctx, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		// у событий, созданных до появления журнала, записей нет
+		if err := a.checkEventExists(ctx, userID, eventID); err != nil {
+			return nil, err
+		}
+		return []*AuditRecordDto{}, nil
+	}
+	recordsDto := make([]*AuditRecordDto, len(records))
+	for i, record := range records {
+		recordsDto[i] = convertAuditRecordToDto(record)
+	}
+	return recordsDto, nil
+}
+
+func (a *App) ListDeleted(ctx context.Context, userID uint64) ([]*EventDto, error) {
+	events, err := a.storage.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return convertEventsToDto(events), nil
+}
+
+func (a *App) GetUserSettings(ctx context.Context, userID uint64) (*UserSettingsDto, error) {
+	settings, err := a.storage.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return convertUserSettingsToDto(settings), nil
+}
+
+// UpdateUserSettings сохраняет настройки пользователя. Запретить пересечения нельзя,
+// пока события пользователя пересекаются: возвращается storage.ErrBusyTime.
+func (a *App) UpdateUserSettings(ctx context.Context, settingsDto UserSettingsDto) error {
+	if settingsDto.AvailabilityPolicy == "" {
+		settingsDto.AvailabilityPolicy = string(storage.OverlapAllow)
+	}
+	if settingsDto.Digest == "" {
+		settingsDto.Digest = string(storage.DigestNone)
+	}
+	settings := convertUserSettingsToModel(&settingsDto)
+	if !settings.OverlapPolicy.Valid() {
+		return ErrNotValidOverlapPolicy
+	}
+	if err := validateUserSettings(&settingsDto); err != nil {
+		return err
+	}
+	return a.storage.SaveUserSettings(ctx, settings)
+}
+
+// ListForDay возвращает события за день, удовлетворяющие фильтру, вместе с событиями из календарей,
+// открытых пользователю. Так же выбираются события в ListForWeek и ListForMonth.
+func (a *App) ListForDay(ctx context.Context, userID uint64, date time.Time, filter EventFilter) ([]*EventDto, error) {
 	endDateExclusive := date.Add(24 * time.Hour)
-	return a.listForPeriod(ctx, userID, date, endDateExclusive)
+	return a.listForPeriod(ctx, userID, filter, date, endDateExclusive)
 }
 
-func (a *App) ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*EventDto, error) {
+func (a *App) ListForWeek(ctx context.Context, userID uint64, startDate time.Time, filter EventFilter) ([]*EventDto, error) {
 	endDateExclusive := startDate.AddDate(0, 0, 7)
-	return a.listForPeriod(ctx, userID, startDate, endDateExclusive)
+	return a.listForPeriod(ctx, userID, filter, startDate, endDateExclusive)
 }
 
-func (a *App) ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*EventDto, error) {
+func (a *App) ListForMonth(ctx context.Context, userID uint64, startDate time.Time, filter EventFilter) ([]*EventDto, error) {
 	endDateExclusive := startDate.AddDate(0, 1, 0)
-	return a.listForPeriod(ctx, userID, startDate, endDateExclusive)
+	return a.listForPeriod(ctx, userID, filter, startDate, endDateExclusive)
 }
 
-func (a *App) listForPeriod(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) ([]*EventDto, error) {
-	events, err := a.storage.ListForPeriod(ctx, userID, startDate, endDateExclusive)
+func (a *App) listForPeriod(
+	ctx context.Context,
+	userID uint64,
+	filter EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*EventDto, error) {
+	events, err := a.storage.ListForPeriod(ctx, userID, storage.EventFilter(filter), startDate, endDateExclusive)
+	if err != nil {
+		return nil, err
+	}
+	sharedEvents, err := a.storage.ListSharedForPeriod(ctx, userID, storage.EventFilter(filter), startDate, endDateExclusive)
 	if err != nil {
 		return nil, err
 	}
-	eventsDto := make([]*EventDto, len(events))
-	for i, event := range events {
-		eventsDto[i] = convertEventToDto(event)
+	if len(sharedEvents) > 0 {
+		events = append(events, sharedEvents...)
+		slices.SortStableFunc(events, func(e1, e2 *storage.Event) int {
+			if c := e1.StartDate.Compare(e2.StartDate); c != 0 {
+				return c
+			}
+			return e1.EndDate.Compare(e2.EndDate)
+		})
+	}
+	return convertEventsToDto(events), nil
+}
+
+// возвращает пересечения события, только если пользователь выбрал политику предупреждения.
+// Событие к этому моменту уже сохранено, поэтому ошибка только логируется и пересечения не возвращаются:
+// иначе клиент счёл бы запрос неуспешным и при повторе создал бы дубликат.
+func (a *App) listConflicts(ctx context.Context, settings *storage.UserSettings, event *storage.Event) []*EventDto {
+	if settings.OverlapPolicy != storage.OverlapWarn {
+		return nil
+	}
+
+	conflicts, err := a.storage.ListConflicts(ctx, event)
+	if err != nil {
+		a.logger.Error(ctx, err, "failed to list conflicts", "eventID", event.ID)
+		return nil
+	}
+	return convertEventsToDto(conflicts)
+}
+
+// checkAvailability проверяет, что событие не выходит за рабочие часы и не попадает в период отсутствия:
+// при политике REJECT возвращает ошибку, при WARN - предупреждения. Прозрачные события время не занимают
+// и не проверяются.
+func checkAvailability(settings *storage.UserSettings, event *storage.Event) ([]WarningDto, error) {
+	policy := settings.AvailabilityPolicy
+	if event.Transparent || (policy != storage.OverlapReject && policy != storage.OverlapWarn) {
+		return nil, nil
+	}
+
+	var warnings []WarningDto
+	if period := settings.OutOfOfficeAt(event.StartDate, event.EndDate); period != nil {
+		err := &OutOfOfficeError{Message: period.Message}
+		if policy == storage.OverlapReject {
+			return nil, err
+		}
+		warnings = append(warnings, WarningDto{Code: WarningOutOfOffice, Message: err.Error()})
+	}
+	if !settings.WithinWorkingHours(event.StartDate, event.EndDate) {
+		if policy == storage.OverlapReject {
+			return nil, ErrOutsideWorkingHours
+		}
+		warnings = append(warnings, WarningDto{Code: WarningOutsideWorkingHours, Message: ErrOutsideWorkingHours.Error()})
+	}
+	return warnings, nil
+}
+
+// checkQuota проверяет, что пользователь может добавить ещё одно событие.
+// Проверка не атомарна с созданием, поэтому при параллельных запросах квота может быть немного превышена.
+func (a *App) checkQuota(ctx context.Context, userID uint64) error {
+	if a.maxEventsPerUser <= 0 {
+		return nil
+	}
+	count, err := a.storage.CountByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= a.maxEventsPerUser {
+		return ErrQuotaExceeded
 	}
-	return eventsDto, nil
+	return nil
 }
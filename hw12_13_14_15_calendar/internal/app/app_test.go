@@ -2,11 +2,16 @@ package app
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app/mocks"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,6 +24,7 @@ func TestApp(t *testing.T) {
 	t.Parallel()
 
 	userID := uint64(12345)
+	calendar := storage.Calendar{ID: 7, UserID: userID, Name: "Work", Color: "#00ff00"}
 	event := storage.Event{
 		ID:           1,
 		Title:        "my event",
@@ -26,6 +32,7 @@ func TestApp(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
+		CalendarID:   calendar.ID,
 		NotifyBefore: time.Hour * 24,
 	}
 	eventDto := EventDto{
@@ -35,7 +42,8 @@ func TestApp(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 24,
+		CalendarID:   calendar.ID,
+		NotifyBefore: ptr(time.Hour * 24),
 	}
 
 	ctx := context.Background()
@@ -49,11 +57,334 @@ func TestApp(t *testing.T) {
 		event := event
 		eventID := uint64(1000)
 
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return record.EventID == eventID && record.UserID == userID && record.ActorID == userID &&
+				record.Action == storage.AuditCreate && len(record.Changes) == 6
+		})).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, conflicts, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+		require.Nil(t, conflicts)
+	})
+
+	t.Run("create event with audit error", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		event := event
+		eventID := uint64(1000)
+		auditErr := errors.New("audit error")
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(auditErr)
+		mockedLogger.EXPECT().Error(ctx, auditErr, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, _, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+	})
+
+	t.Run("create event with busy time", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		event := event
+
+		conflictingEvent := event
+		conflictingEvent.ID = 2
+		conflictingEventDto := eventDto
+		conflictingEventDto.ID = 2
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(0, &storage.BusyTimeError{Conflicts: []*storage.Event{&conflictingEvent}})
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, storage.ErrBusyTime)
+
+		var busyTimeErr *BusyTimeError
+		require.ErrorAs(t, err, &busyTimeErr)
+		require.Equal(t, []*EventDto{&conflictingEventDto}, busyTimeErr.Conflicts)
+	})
+
+	t.Run("create event with overlap warning", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		event := event
+		eventID := uint64(1000)
+		conflictingEvent := event
+		conflictingEvent.ID = 2
+		conflictingEventDto := eventDto
+		conflictingEventDto.ID = 2
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(&storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapWarn}, nil)
+		mockedStorage.EXPECT().ListConflicts(ctx, mock.Anything).Return([]*storage.Event{&conflictingEvent}, nil)
+
+		actualEventID, conflicts, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+		require.Equal(t, []*EventDto{&conflictingEventDto}, conflicts)
+	})
+
+	t.Run("create event with conflicts error", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		event := event
+		eventID := uint64(1000)
+		conflictsErr := errors.New("conflicts error")
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(&storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapWarn}, nil)
+		mockedStorage.EXPECT().ListConflicts(ctx, mock.Anything).Return(nil, conflictsErr)
+		mockedLogger.EXPECT().Error(ctx, conflictsErr, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		// событие уже создано, поэтому его ID возвращается без пересечений
+		actualEventID, conflicts, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+		require.Nil(t, conflicts)
+	})
+
+	t.Run("create event in default calendar", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		defaultCalendar := storage.NewDefaultCalendar(userID)
+		defaultCalendar.ID = 3
+		defaultCalendar.NotifyBefore = time.Hour
+
+		eventDto := eventDto
+		eventDto.CalendarID = 0
+		eventDto.NotifyBefore = nil
+		event := event
+		event.CalendarID = defaultCalendar.ID
+		event.NotifyBefore = defaultCalendar.NotifyBefore
+		eventID := uint64(1000)
+
+		mockedStorage.EXPECT().GetDefaultCalendar(ctx, userID).Return(defaultCalendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, _, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+	})
+
+	t.Run("create event without reminder", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		calendar := calendar
+		calendar.NotifyBefore = time.Hour
+
+		eventDto := eventDto
+		eventDto.NotifyBefore = ptr(time.Duration(0))
+		event := event
+		event.NotifyBefore = 0
+		eventID := uint64(1000)
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, _, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+	})
+
+	t.Run("create event in not existing calendar", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(nil, storage.ErrCalendarNotFound)
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, storage.ErrCalendarNotFound)
+	})
+
+	t.Run("create not valid event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.Title = strings.Repeat("я", 257)
+		eventDto.EndDate = eventDto.StartDate.Add(-time.Hour)
+		eventDto.NotifyBefore = ptr(-time.Hour)
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, ErrNotValidEvent)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "title", Err: validator.ErrStringTooLong},
+			{Field: "endDate", Err: validator.ErrTimeNotAfter},
+			{Field: "notifyBefore", Err: validator.ErrIntMin},
+		}, validationErrs)
+	})
+
+	t.Run("create event with details", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		lat, lon := 55.75, 37.62
+		eventDto := eventDto
+		eventDto.Location = &LocationDto{Text: "Office", Geo: &GeoPointDto{Lat: lat, Lon: lon}}
+		eventDto.Category = "work"
+		eventDto.Tags = []string{"urgent"}
+		eventDto.Attachments = []AttachmentDto{{URL: "https://example.com/a.pdf", Name: "a.pdf", Size: 1024, MimeType: "application/pdf"}}
+		event := event
+		event.Location = "Office"
+		event.Latitude = &lat
+		event.Longitude = &lon
+		event.Category = "work"
+		event.Tags = storage.Tags{"urgent"}
+		event.Attachments = storage.Attachments{{URL: "https://example.com/a.pdf", Name: "a.pdf", Size: 1024, MimeType: "application/pdf"}}
+		eventID := uint64(1000)
+
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
 		mockedStorage.EXPECT().Create(ctx, &event).Return(eventID, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return len(record.Changes) == 10 && record.Changes[6] == storage.FieldChange{Field: "location", After: "Office;55.75,37.62"}
+		})).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, _, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+		require.Equal(t, &eventDto, convertEventToDto(&event))
+	})
+
+	t.Run("create event with not valid details", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.Location = &LocationDto{Text: "Office", Geo: &GeoPointDto{Lat: 91, Lon: -181}}
+		eventDto.Tags = []string{""}
+		eventDto.Attachments = []AttachmentDto{{URL: "a.pdf", Size: -1, MimeType: "pdf"}}
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, ErrNotValidEvent)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "location.geo.lat", Err: validator.ErrFloatMax},
+			{Field: "location.geo.lon", Err: validator.ErrFloatMin},
+			{Field: "tags index=0", Err: validator.ErrStringTooShort},
+			{Field: "attachments.0.url", Err: validator.ErrStringNotURI},
+			{Field: "attachments.0.size", Err: validator.ErrIntMin},
+			{Field: "attachments.0.mimeType", Err: validator.ErrStringNotMatchRegexp},
+		}, validationErrs)
+	})
+
+	t.Run("create event over quota", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage).WithQuota(10)
+
+		mockedStorage.EXPECT().CountByUser(ctx, userID).Return(10, nil)
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("create event with idempotency key", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		event := event
+		eventID := uint64(1000)
+		ctx := ContextWithIdempotencyKey(ctx, "key")
+		hash := requestHash(eventDto)
+
+		mockedStorage.EXPECT().GetIdempotencyKey(ctx, userID, "key", mock.AnythingOfType("time.Time")).Return(nil, storage.ErrIdempotencyKeyNotFound)
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+		mockedStorage.EXPECT().CreateIdempotent(ctx, &event, "key", hash, mock.AnythingOfType("time.Time")).Return(eventID, true, nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		actualEventID, conflicts, _, err := app.Create(ctx, eventDto)
+		require.NoError(t, err)
+		require.Equal(t, eventID, actualEventID)
+		require.Nil(t, conflicts)
+	})
+
+	t.Run("repeat create event with idempotency key", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		// квота уже исчерпана созданным событием, но повтор её не проверяет
+		app := New(mockedLogger, mockedStorage).WithQuota(1)
+
+		eventID := uint64(1000)
+		ctx := ContextWithIdempotencyKey(ctx, "key")
+		idempotencyKey := &storage.IdempotencyKey{EventID: eventID, RequestHash: requestHash(eventDto)}
+
+		mockedStorage.EXPECT().GetIdempotencyKey(ctx, userID, "key", mock.AnythingOfType("time.Time")).Return(idempotencyKey, nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
 
-		actualEventID, err := app.Create(ctx, eventDto)
+		actualEventID, conflicts, _, err := app.Create(ctx, eventDto)
 		require.NoError(t, err)
 		require.Equal(t, eventID, actualEventID)
+		require.Nil(t, conflicts)
+	})
+
+	t.Run("create event with reused idempotency key", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		ctx := ContextWithIdempotencyKey(ctx, "key")
+		idempotencyKey := &storage.IdempotencyKey{EventID: 1000, RequestHash: requestHash(eventDto)}
+		changedDto := eventDto
+		changedDto.Title = "other event"
+
+		mockedStorage.EXPECT().GetIdempotencyKey(ctx, userID, "key", mock.AnythingOfType("time.Time")).Return(idempotencyKey, nil)
+
+		_, _, _, err := app.Create(ctx, changedDto)
+		require.ErrorIs(t, err, storage.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("create event with too long idempotency key", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		ctx := ContextWithIdempotencyKey(ctx, strings.Repeat("k", MaxIdempotencyKeyLen+1))
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		require.ErrorIs(t, err, ErrNotValidIdempotencyKey)
 	})
 
 	t.Run("update event", func(t *testing.T) {
@@ -63,26 +394,472 @@ func TestApp(t *testing.T) {
 
 		eventDto := eventDto
 		event := event
+		existingEvent := event
+		existingEvent.Title = "my old event"
+
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&existingEvent, nil)
+		mockedStorage.EXPECT().Update(ctx, &event).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return record.EventID == event.ID && record.Action == storage.AuditUpdate &&
+				reflect.DeepEqual(record.Changes, storage.AuditChanges{{Field: "title", Before: "my old event", After: "my event"}})
+		})).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		conflicts, _, err := app.Update(ctx, eventDto)
+		require.NoError(t, err)
+		require.Nil(t, conflicts)
+	})
+
+	t.Run("move event to another calendar", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.CalendarID = 8
+		event := event
+		event.CalendarID = 8
+		existingEvent := event
+		existingEvent.CalendarID = calendar.ID
+
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&existingEvent, nil)
+		mockedStorage.EXPECT().GetCalendar(ctx, userID, uint64(8)).Return(&storage.Calendar{ID: 8, UserID: userID}, nil)
+		mockedStorage.EXPECT().Update(ctx, &event).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return reflect.DeepEqual(record.Changes, storage.AuditChanges{{Field: "calendarId", Before: "7", After: "8"}})
+		})).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		_, _, err := app.Update(ctx, eventDto)
+		require.NoError(t, err)
+	})
+
+	t.Run("make event transparent", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.Transparent = true
+		event := event
+		event.Transparent = true
+		existingEvent := event
+		existingEvent.Transparent = false
+
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&existingEvent, nil)
+		mockedStorage.EXPECT().Update(ctx, &event).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return reflect.DeepEqual(record.Changes, storage.AuditChanges{{Field: "transparent", Before: "false", After: "true"}})
+		})).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		_, _, err := app.Update(ctx, eventDto)
+		require.NoError(t, err)
+	})
+
+	t.Run("update event without calendar keeps calendar", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.CalendarID = 0
+		event := event
+		existingEvent := event
 
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&existingEvent, nil)
 		mockedStorage.EXPECT().Update(ctx, &event).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
 
-		err := app.Update(ctx, eventDto)
+		_, _, err := app.Update(ctx, eventDto)
 		require.NoError(t, err)
 	})
 
+	t.Run("update event without reminder keeps reminder", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.NotifyBefore = nil
+		event := event
+		existingEvent := event
+
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&existingEvent, nil)
+		mockedStorage.EXPECT().Update(ctx, &event).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		_, _, err := app.Update(ctx, eventDto)
+		require.NoError(t, err)
+	})
+
+	t.Run("update not valid event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+		eventDto.Title = ""
+
+		_, _, err := app.Update(ctx, eventDto)
+		require.ErrorIs(t, err, ErrNotValidEvent)
+		require.ErrorIs(t, err, validator.ErrStringTooShort)
+	})
+
+	t.Run("update not existing event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventDto := eventDto
+
+		mockedStorage.EXPECT().GetByID(ctx, userID, eventDto.ID).Return(nil, storage.ErrEventNotFound)
+
+		_, _, err := app.Update(ctx, eventDto)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
 	t.Run("delete event", func(t *testing.T) {
 		mockedLogger := mocks.NewLogger(t)
 		mockedStorage := mocks.NewStorage(t)
 		app := New(mockedLogger, mockedStorage)
 
 		eventID := uint64(1000)
+		event := event
+		event.ID = eventID
 
+		mockedStorage.EXPECT().GetByID(ctx, userID, eventID).Return(&event, nil)
 		mockedStorage.EXPECT().Delete(ctx, userID, eventID).Return(nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return record.EventID == eventID && record.Action == storage.AuditDelete &&
+				len(record.Changes) == 6 && record.Changes[0].Before == event.Title && record.Changes[0].After == ""
+		})).Return(nil)
 
 		err := app.Delete(ctx, userID, eventID)
 		require.NoError(t, err)
 	})
 
+	t.Run("get user settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+
+		settings, err := app.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, UserSettingsDto{UserID: userID, OverlapPolicy: "REJECT", AvailabilityPolicy: "ALLOW", Digest: "NONE"}, *settings)
+	})
+
+	t.Run("update user settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		mockedStorage.EXPECT().SaveUserSettings(ctx, &storage.UserSettings{
+			UserID:             userID,
+			OverlapPolicy:      storage.OverlapAllow,
+			AvailabilityPolicy: storage.OverlapAllow,
+			Digest:             storage.DigestNone,
+		}).Return(nil)
+
+		err := app.UpdateUserSettings(ctx, UserSettingsDto{UserID: userID, OverlapPolicy: "ALLOW"})
+		require.NoError(t, err)
+
+		err = app.UpdateUserSettings(ctx, UserSettingsDto{UserID: userID, OverlapPolicy: "SOMETIMES"})
+		require.ErrorIs(t, err, ErrNotValidOverlapPolicy)
+	})
+
+	t.Run("update user availability settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		settingsDto := UserSettingsDto{
+			UserID:        userID,
+			OverlapPolicy: "REJECT",
+			TimeZone:      "Europe/Moscow",
+			WorkingHours:  []WorkingIntervalDto{{Weekday: 1, Start: "09:00", End: "13:00"}, {Weekday: 1, Start: "14:00", End: "24:00"}},
+			OutOfOffice: []OutOfOfficeDto{
+				{StartDate: getTime(t, "2024-07-15 00:00:00"), EndDate: getTime(t, "2024-07-29 00:00:00"), Message: "vacation"},
+			},
+			AvailabilityPolicy: "WARN",
+			Digest:             "WEEKLY",
+		}
+		mockedStorage.EXPECT().SaveUserSettings(ctx, &storage.UserSettings{
+			UserID:        userID,
+			OverlapPolicy: storage.OverlapReject,
+			TimeZone:      "Europe/Moscow",
+			WorkingHours: storage.WorkingHours{
+				{Weekday: time.Monday, Start: "09:00", End: "13:00"},
+				{Weekday: time.Monday, Start: "14:00", End: "24:00"},
+			},
+			OutOfOffice:        storage.OutOfOffice{storage.OutOfOfficePeriod(settingsDto.OutOfOffice[0])},
+			AvailabilityPolicy: storage.OverlapWarn,
+			Digest:             storage.DigestWeekly,
+		}).Return(nil)
+
+		err := app.UpdateUserSettings(ctx, settingsDto)
+		require.NoError(t, err)
+
+		settingsDto.TimeZone = "Mars/Olympus"
+		settingsDto.WorkingHours = []WorkingIntervalDto{{Weekday: 7, Start: "9:00", End: "25:00"}, {Weekday: 2, Start: "18:00", End: "09:00"}}
+		// период отсутствия заканчивается раньше, чем начинается, и без сообщения
+		settingsDto.OutOfOffice[0].EndDate = settingsDto.OutOfOffice[0].StartDate.Add(-time.Hour)
+		settingsDto.OutOfOffice[0].Message = ""
+		settingsDto.AvailabilityPolicy = "SOMETIMES"
+		settingsDto.Digest = "HOURLY"
+
+		err = app.UpdateUserSettings(ctx, settingsDto)
+		require.ErrorIs(t, err, ErrNotValidSettings)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "workingHours.0.weekday", Err: validator.ErrIntMax},
+			{Field: "outOfOffice.0.endDate", Err: validator.ErrTimeNotAfter},
+			{Field: "outOfOffice.0.message", Err: validator.ErrStringRequired},
+			{Field: "availabilityPolicy", Err: validator.ErrStringNotInValues},
+			{Field: "digest", Err: validator.ErrStringNotInValues},
+			{Field: "timeZone", Err: ErrNotValidTimeZone},
+			{Field: "workingHours.0.start", Err: ErrNotValidClock},
+			{Field: "workingHours.0.end", Err: ErrNotValidClock},
+			{Field: "workingHours.1.end", Err: ErrNotValidWorkingHours},
+		}, validationErrs)
+	})
+
+	t.Run("create event with availability policy", func(t *testing.T) {
+		// рабочие часы по Москве: понедельник 09:00-18:00 (06:00-15:00 UTC)
+		settings := &storage.UserSettings{
+			UserID:        userID,
+			OverlapPolicy: storage.OverlapReject,
+			TimeZone:      "Europe/Moscow",
+			WorkingHours:  storage.WorkingHours{{Weekday: time.Monday, Start: "09:00", End: "18:00"}},
+			OutOfOffice: storage.OutOfOffice{
+				{StartDate: getTime(t, "2024-07-15 00:00:00"), EndDate: getTime(t, "2024-07-29 00:00:00"), Message: "vacation"},
+			},
+			AvailabilityPolicy: storage.OverlapReject,
+		}
+		warnSettings := *settings
+		warnSettings.AvailabilityPolicy = storage.OverlapWarn
+		// круглосуточно с понедельника на вторник
+		nightSettings := *settings
+		nightSettings.WorkingHours = storage.WorkingHours{
+			{Weekday: time.Monday, Start: "00:00", End: "24:00"},
+			{Weekday: time.Tuesday, Start: "00:00", End: "24:00"},
+		}
+
+		tests := []struct {
+			name             string
+			settings         *storage.UserSettings
+			startDate        string
+			endDate          string
+			transparent      bool
+			expectedErr      error
+			expectedWarnings []WarningDto
+		}{
+			{name: "within working hours", settings: settings, startDate: "2024-07-08 06:00:00", endDate: "2024-07-08 15:00:00"},
+			{
+				name: "outside working hours", settings: settings, startDate: "2024-07-08 14:00:00", endDate: "2024-07-08 16:00:00",
+				expectedErr: ErrOutsideWorkingHours,
+			},
+			{name: "across midnight", settings: &nightSettings, startDate: "2024-07-08 20:00:00", endDate: "2024-07-08 23:00:00"},
+			{
+				name: "across midnight into day off", settings: &nightSettings, startDate: "2024-07-09 20:00:00", endDate: "2024-07-09 23:00:00",
+				expectedErr: ErrOutsideWorkingHours,
+			},
+			{name: "day off", settings: settings, startDate: "2024-07-09 07:00:00", endDate: "2024-07-09 08:00:00", expectedErr: ErrOutsideWorkingHours},
+			{name: "out of office", settings: settings, startDate: "2024-07-22 07:00:00", endDate: "2024-07-22 08:00:00", expectedErr: ErrOutOfOffice},
+			{name: "transparent", settings: settings, startDate: "2024-07-22 20:00:00", endDate: "2024-07-22 21:00:00", transparent: true},
+			{
+				name: "warnings", settings: &warnSettings, startDate: "2024-07-22 20:00:00", endDate: "2024-07-22 21:00:00",
+				expectedWarnings: []WarningDto{
+					{Code: WarningOutOfOffice, Message: "user is out of office: vacation"},
+					{Code: WarningOutsideWorkingHours, Message: ErrOutsideWorkingHours.Error()},
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockedLogger := mocks.NewLogger(t)
+				mockedStorage := mocks.NewStorage(t)
+				app := New(mockedLogger, mockedStorage)
+
+				eventDto := eventDto
+				eventDto.StartDate = getTime(t, tt.startDate)
+				eventDto.EndDate = getTime(t, tt.endDate)
+				eventDto.Transparent = tt.transparent
+				eventID := uint64(1000)
+
+				mockedStorage.EXPECT().GetCalendar(ctx, userID, calendar.ID).Return(&calendar, nil)
+				mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(tt.settings, nil)
+				if tt.expectedErr == nil {
+					mockedStorage.EXPECT().Create(ctx, mock.Anything).Return(eventID, nil)
+					mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.Anything).Return(nil)
+				}
+
+				_, _, warnings, err := app.Create(ctx, eventDto)
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.Equal(t, tt.expectedWarnings, warnings)
+			})
+		}
+	})
+
+	t.Run("get event history", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventID := uint64(1000)
+		createdAt := getTime(t, "2024-07-11 00:00:00")
+		record := storage.AuditRecord{
+			ID:        1,
+			EventID:   eventID,
+			UserID:    userID,
+			ActorID:   userID,
+			Action:    storage.AuditUpdate,
+			Changes:   storage.AuditChanges{{Field: "title", Before: "my old event", After: "my event"}},
+			Source:    SourceHTTP,
+			CreatedAt: createdAt,
+		}
+		expectedRecord := AuditRecordDto{
+			ID:        1,
+			EventID:   eventID,
+			ActorID:   userID,
+			Action:    "UPDATE",
+			Changes:   []FieldChange{{Field: "title", Before: "my old event", After: "my event"}},
+			Source:    SourceHTTP,
+			CreatedAt: createdAt,
+		}
+
+		mockedStorage.EXPECT().ListAuditRecords(ctx, userID, eventID).Return([]*storage.AuditRecord{&record}, nil)
+
+		actualRecords, err := app.GetHistory(ctx, userID, eventID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(actualRecords))
+		require.Equal(t, expectedRecord, *actualRecords[0])
+	})
+
+	t.Run("get history of not existing event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		eventID := uint64(1000)
+
+		mockedStorage.EXPECT().ListAuditRecords(ctx, userID, eventID).Return([]*storage.AuditRecord{}, nil)
+		mockedStorage.EXPECT().GetByID(ctx, userID, eventID).Return(nil, storage.ErrEventNotFound)
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{}, nil)
+
+		_, err := app.GetHistory(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("get history of event without records", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		event := event
+
+		mockedStorage.EXPECT().ListAuditRecords(ctx, userID, event.ID).Return([]*storage.AuditRecord{}, nil)
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&event, nil)
+
+		actualRecords, err := app.GetHistory(ctx, userID, event.ID)
+		require.NoError(t, err)
+		require.Empty(t, actualRecords)
+	})
+
+	t.Run("get history of deleted event without records", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		deletedAt := getTime(t, "2024-07-11 00:00:00")
+		event := event
+		event.DeletedAt = &deletedAt
+
+		mockedStorage.EXPECT().ListAuditRecords(ctx, userID, event.ID).Return([]*storage.AuditRecord{}, nil)
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(nil, storage.ErrEventNotFound)
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{&event}, nil)
+
+		actualRecords, err := app.GetHistory(ctx, userID, event.ID)
+		require.NoError(t, err)
+		require.Empty(t, actualRecords)
+	})
+
+	t.Run("restore event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		deletedAt := getTime(t, "2024-07-11 00:00:00")
+		deletedEvent := event
+		deletedEvent.DeletedAt = &deletedAt
+		restoredEvent := event
+
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{&deletedEvent}, nil)
+		mockedStorage.EXPECT().Restore(ctx, userID, event.ID).Return(nil)
+		mockedStorage.EXPECT().GetByID(ctx, userID, event.ID).Return(&restoredEvent, nil)
+		mockedStorage.EXPECT().CreateAuditRecord(ctx, mock.MatchedBy(func(record *storage.AuditRecord) bool {
+			return record.EventID == event.ID && record.UserID == userID && record.Action == storage.AuditRestore &&
+				reflect.DeepEqual(record.Changes, storage.AuditChanges{{Field: "deletedAt", Before: "2024-07-11T00:00:00Z", After: ""}})
+		})).Return(nil)
+
+		err := app.Restore(ctx, userID, event.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("restore event with busy time", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		deletedAt := getTime(t, "2024-07-11 00:00:00")
+		deletedEvent := event
+		deletedEvent.DeletedAt = &deletedAt
+
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{&deletedEvent}, nil)
+		mockedStorage.EXPECT().Restore(ctx, userID, event.ID).Return(storage.ErrBusyTime)
+
+		err := app.Restore(ctx, userID, event.ID)
+		require.ErrorIs(t, err, storage.ErrBusyTime)
+	})
+
+	t.Run("restore not deleted event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{}, nil)
+
+		err := app.Restore(ctx, userID, event.ID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("list deleted events", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		deletedAt := getTime(t, "2024-07-11 00:00:00")
+
+		eventDto := eventDto
+		eventDto.DeletedAt = &deletedAt
+		event := event
+		event.DeletedAt = &deletedAt
+
+		mockedStorage.EXPECT().ListDeleted(ctx, userID).Return([]*storage.Event{&event}, nil)
+
+		actualEvents, err := app.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(actualEvents))
+		require.Equal(t, eventDto, *actualEvents[0])
+	})
+
 	t.Run("get event", func(t *testing.T) {
 		mockedLogger := mocks.NewLogger(t)
 		mockedStorage := mocks.NewStorage(t)
@@ -108,11 +885,29 @@ func TestApp(t *testing.T) {
 		eventID := uint64(1000)
 
 		mockedStorage.EXPECT().GetByID(ctx, userID, eventID).Return(nil, storage.ErrEventNotFound)
+		mockedStorage.EXPECT().GetSharedByID(ctx, userID, eventID).Return(nil, storage.ErrEventNotFound)
 
 		actualEventDto, err := app.GetByID(ctx, userID, eventID)
 		require.Nil(t, actualEventDto)
 		require.ErrorIs(t, err, storage.ErrEventNotFound)
 	})
+
+	t.Run("get event from shared calendar", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		otherUserID := userID + 1
+		eventDto := eventDto
+		event := event
+
+		mockedStorage.EXPECT().GetByID(ctx, otherUserID, event.ID).Return(nil, storage.ErrEventNotFound)
+		mockedStorage.EXPECT().GetSharedByID(ctx, otherUserID, event.ID).Return(&event, nil)
+
+		actualEventDto, err := app.GetByID(ctx, otherUserID, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, eventDto, *actualEventDto)
+	})
 }
 
 func TestAppListEvents(t *testing.T) {
@@ -140,7 +935,7 @@ func TestAppListEvents(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 24,
+		NotifyBefore: ptr(time.Hour * 24),
 	}
 	eventDto1 := eventDto0
 	eventDto1.ID = 2
@@ -162,9 +957,10 @@ func TestAppListEvents(t *testing.T) {
 		event0 := event0
 		event1 := event1
 
-		mockedStorage.EXPECT().ListForPeriod(ctx, userID, startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		mockedStorage.EXPECT().ListSharedForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{}, nil)
 
-		actualEvents, err := app.ListForDay(ctx, userID, startDate)
+		actualEvents, err := app.ListForDay(ctx, userID, startDate, EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, 2, len(actualEvents))
 		require.Equal(t, eventDto0, *actualEvents[0])
@@ -184,9 +980,11 @@ func TestAppListEvents(t *testing.T) {
 		event0 := event0
 		event1 := event1
 
-		mockedStorage.EXPECT().ListForPeriod(ctx, userID, startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		filter := EventFilter{CalendarIDs: []uint64{3, 5}, Category: "work", Tags: []string{"urgent", "team"}}
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter(filter), startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		mockedStorage.EXPECT().ListSharedForPeriod(ctx, userID, storage.EventFilter(filter), startDate, endDate).Return([]*storage.Event{}, nil)
 
-		actualEvents, err := app.ListForWeek(ctx, userID, startDate)
+		actualEvents, err := app.ListForWeek(ctx, userID, startDate, filter)
 		require.NoError(t, err)
 		require.Equal(t, 2, len(actualEvents))
 		require.Equal(t, eventDto0, *actualEvents[0])
@@ -206,14 +1004,40 @@ func TestAppListEvents(t *testing.T) {
 		event0 := event0
 		event1 := event1
 
-		mockedStorage.EXPECT().ListForPeriod(ctx, userID, startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{&event0, &event1}, nil)
+		mockedStorage.EXPECT().ListSharedForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{}, nil)
 
-		actualEvents, err := app.ListForMonth(ctx, userID, startDate)
+		actualEvents, err := app.ListForMonth(ctx, userID, startDate, EventFilter{})
 		require.NoError(t, err)
 		require.Equal(t, 2, len(actualEvents))
 		require.Equal(t, eventDto0, *actualEvents[0])
 		require.Equal(t, eventDto1, *actualEvents[1])
 	})
+
+	t.Run("list events with shared calendars", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mockedLogger, mockedStorage)
+
+		startDate := getTime(t, "2024-07-05 00:00:00")
+		endDate := getTime(t, "2024-07-06 00:00:00")
+
+		event0 := event0
+		event1 := event1
+		// событие другого пользователя из открытого календаря начинается раньше событий пользователя
+		sharedEvent := event1
+		sharedEvent.ID = 3
+		sharedEvent.UserID = userID + 1
+		sharedEvent.StartDate = getTime(t, "2024-07-05 09:00:00")
+
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{&event1, &event0}, nil)
+		mockedStorage.EXPECT().ListSharedForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return([]*storage.Event{&sharedEvent}, nil)
+
+		actualEvents, err := app.ListForDay(ctx, userID, startDate, EventFilter{})
+		require.NoError(t, err)
+		require.Equal(t, []uint64{3, 2, 1}, []uint64{actualEvents[0].ID, actualEvents[1].ID, actualEvents[2].ID})
+		require.Equal(t, userID+1, actualEvents[0].UserID)
+	})
 }
 
 func getTime(t *testing.T, value string) time.Time {
@@ -222,3 +1046,7 @@ func getTime(t *testing.T, value string) time.Time {
 	require.NoError(t, err)
 	return time
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	SourceHTTP = "HTTP"
+	SourceGRPC = "GRPC"
+)
+
+type sourceCtxKey struct{}
+
+// ContextWithSource сохраняет в контексте API, через которое пришёл запрос.
+func ContextWithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceCtxKey{}, source)
+}
+
+func sourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(sourceCtxKey{}).(string)
+	return source
+}
+
+// сохраняет запись в журнал изменений события. Журнал ведётся по принципу best-effort:
+//   - запись сохраняется после изменения, не в его транзакции, и ошибка только логируется,
+//     т.к. само изменение уже выполнено - запись в журнале может отсутствовать;
+//   - состояние до изменения читается отдельно от него (в Update и Delete), поэтому при параллельных
+//     изменениях одного события в before могут попасть изменения другого запроса.
+//
+// Журнал служит для просмотра истории, а не для аудита с гарантией полноты.
+func (a *App) audit(ctx context.Context, action storage.AuditAction, actorID uint64, before *storage.Event, after *storage.Event) {
+	event := after
+	if event == nil {
+		event = before
+	}
+
+	record := &storage.AuditRecord{
+		EventID:   event.ID,
+		UserID:    event.UserID,
+		ActorID:   actorID,
+		Action:    action,
+		Changes:   diffEvents(before, after),
+		Source:    sourceFromContext(ctx),
+		CreatedAt: time.Now(),
+	}
+
+	if err := a.storage.CreateAuditRecord(ctx, record); err != nil {
+		a.logger.Error(ctx, err, "failed to save audit record", "eventID", event.ID, "action", action)
+	}
+}
+
+// возвращает список изменённых полей события; before или after могут быть nil.
+func diffEvents(before *storage.Event, after *storage.Event) storage.AuditChanges {
+	var b, a storage.Event
+	if before != nil {
+		b = *before
+	}
+	if after != nil {
+		a = *after
+	}
+
+	fields := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"title", b.Title, a.Title},
+		{"startDate", formatTime(b.StartDate), formatTime(a.StartDate)},
+		{"endDate", formatTime(b.EndDate), formatTime(a.EndDate)},
+		{"description", b.Description, a.Description},
+		{"calendarId", formatID(b.CalendarID), formatID(a.CalendarID)},
+		{"notifyBefore", formatDuration(b.NotifyBefore), formatDuration(a.NotifyBefore)},
+		{"location", formatLocation(&b), formatLocation(&a)},
+		{"category", b.Category, a.Category},
+		{"tags", strings.Join(b.Tags, ","), strings.Join(a.Tags, ",")},
+		{"attachments", formatAttachments(b.Attachments), formatAttachments(a.Attachments)},
+		{"resourceIds", formatIDs(b.ResourceIDs), formatIDs(a.ResourceIDs)},
+		{"transparent", strconv.FormatBool(b.Transparent), strconv.FormatBool(a.Transparent)},
+		{"deletedAt", formatTimePtr(b.DeletedAt), formatTimePtr(a.DeletedAt)},
+	}
+
+	changes := make(storage.AuditChanges, 0, len(fields))
+	for _, field := range fields {
+		if field.before != field.after {
+			changes = append(changes, storage.FieldChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+	return changes
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func formatID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+func formatIDs(ids []uint64) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(values, ",")
+}
+
+// место записывается текстом, а координаты - после него через точку с запятой.
+func formatLocation(event *storage.Event) string {
+	if event.Latitude == nil || event.Longitude == nil {
+		return event.Location
+	}
+	return fmt.Sprintf("%s;%g,%g", event.Location, *event.Latitude, *event.Longitude)
+}
+
+func formatAttachments(attachments storage.Attachments) string {
+	urls := make([]string, len(attachments))
+	for i, attachment := range attachments {
+		urls[i] = attachment.URL
+	}
+	return strings.Join(urls, ",")
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
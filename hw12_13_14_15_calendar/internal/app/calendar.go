@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (a *App) CreateCalendar(ctx context.Context, calendarDto CalendarDto) (uint64, error) {
+	if err := validateCalendar(&calendarDto); err != nil {
+		return 0, err
+	}
+	// календарь по умолчанию создаётся только автоматически
+	return a.storage.CreateCalendar(ctx, convertCalendarToModel(&calendarDto))
+}
+
+// GetCalendar возвращает календарь пользователя или календарь, открытый ему другим пользователем.
+func (a *App) GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*CalendarDto, error) {
+	calendar, err := a.storage.GetCalendar(ctx, userID, calendarID)
+	if err == nil {
+		return convertCalendarToDto(calendar), nil
+	}
+	if !errors.Is(err, storage.ErrCalendarNotFound) {
+		return nil, err
+	}
+
+	sharedCalendars, err := a.listSharedCalendars(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sharedCalendar := range sharedCalendars {
+		if sharedCalendar.ID == calendarID {
+			return sharedCalendar, nil
+		}
+	}
+	return nil, storage.ErrCalendarNotFound
+}
+
+// UpdateCalendar меняет название, цвет, напоминание по умолчанию и пользователей, которым открыт календарь.
+// Новое напоминание действует для событий, создаваемых после изменения; список пользователей заменяется целиком.
+func (a *App) UpdateCalendar(ctx context.Context, calendarDto CalendarDto) error {
+	if err := validateCalendar(&calendarDto); err != nil {
+		return err
+	}
+	return a.storage.UpdateCalendar(ctx, convertCalendarToModel(&calendarDto))
+}
+
+// DeleteCalendar удаляет пустой календарь; события сначала нужно перенести в другие календари или удалить из корзины.
+func (a *App) DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error {
+	return a.storage.DeleteCalendar(ctx, userID, calendarID)
+}
+
+// ListCalendars возвращает календари пользователя, а за ними - календари, открытые ему другими пользователями;
+// календарь по умолчанию создаётся, если его ещё нет.
+func (a *App) ListCalendars(ctx context.Context, userID uint64) ([]*CalendarDto, error) {
+	if _, err := a.storage.GetDefaultCalendar(ctx, userID); err != nil {
+		return nil, err
+	}
+	calendars, err := a.storage.ListCalendars(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	calendarsDto := make([]*CalendarDto, len(calendars))
+	for i, calendar := range calendars {
+		calendarsDto[i] = convertCalendarToDto(calendar)
+	}
+
+	sharedCalendars, err := a.listSharedCalendars(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(calendarsDto, sharedCalendars...), nil
+}
+
+// listSharedCalendars возвращает календари, открытые пользователю, без списка пользователей, которым они открыты.
+func (a *App) listSharedCalendars(ctx context.Context, userID uint64) ([]*CalendarDto, error) {
+	calendars, err := a.storage.ListSharedCalendars(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	calendarsDto := make([]*CalendarDto, len(calendars))
+	for i, calendar := range calendars {
+		calendarsDto[i] = convertCalendarToDto(calendar)
+		calendarsDto[i].SharedWith = nil
+	}
+	return calendarsDto, nil
+}
+
+// eventCalendar возвращает календарь события: указанный календарь пользователя или календарь по умолчанию.
+func (a *App) eventCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error) {
+	if calendarID == 0 {
+		return a.storage.GetDefaultCalendar(ctx, userID)
+	}
+	return a.storage.GetCalendar(ctx, userID, calendarID)
+}
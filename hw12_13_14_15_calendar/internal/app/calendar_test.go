@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendars(t *testing.T) {
+	t.Parallel()
+
+	userID := uint64(12345)
+	calendar := storage.Calendar{ID: 7, UserID: userID, Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour}
+	calendarDto := CalendarDto{ID: 7, UserID: userID, Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour}
+
+	ctx := context.Background()
+
+	t.Run("create calendar", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		// календарь по умолчанию нельзя создать явно
+		calendarDto := calendarDto
+		calendarDto.ID = 0
+		calendarDto.IsDefault = true
+		calendar := calendar
+		calendar.ID = 0
+
+		mockedStorage.EXPECT().CreateCalendar(ctx, &calendar).Return(7, nil)
+
+		calendarID, err := app.CreateCalendar(ctx, calendarDto)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), calendarID)
+	})
+
+	t.Run("create not valid calendar", func(t *testing.T) {
+		app := New(mocks.NewLogger(t), mocks.NewStorage(t))
+
+		calendarDto := calendarDto
+		calendarDto.Name = ""
+		calendarDto.Color = "green"
+		calendarDto.NotifyBefore = -time.Hour
+
+		_, err := app.CreateCalendar(ctx, calendarDto)
+		require.ErrorIs(t, err, ErrNotValidCalendar)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "name", Err: validator.ErrStringTooShort},
+			{Field: "color", Err: validator.ErrStringNotMatchRegexp},
+			{Field: "notifyBefore", Err: validator.ErrIntMin},
+		}, validationErrs)
+	})
+
+	t.Run("update calendar", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		calendar := calendar
+		mockedStorage.EXPECT().UpdateCalendar(ctx, &calendar).Return(nil)
+
+		require.NoError(t, app.UpdateCalendar(ctx, calendarDto))
+	})
+
+	t.Run("list calendars creates default calendar", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		defaultCalendar := storage.NewDefaultCalendar(userID)
+		defaultCalendar.ID = 1
+		calendar := calendar
+
+		mockedStorage.EXPECT().GetDefaultCalendar(ctx, userID).Return(defaultCalendar, nil)
+		mockedStorage.EXPECT().ListCalendars(ctx, userID).Return([]*storage.Calendar{defaultCalendar, &calendar}, nil)
+		mockedStorage.EXPECT().ListSharedCalendars(ctx, userID).Return([]*storage.Calendar{}, nil)
+
+		calendars, err := app.ListCalendars(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, []*CalendarDto{
+			{ID: 1, UserID: userID, Name: storage.DefaultCalendarName, IsDefault: true},
+			&calendarDto,
+		}, calendars)
+	})
+
+	t.Run("share calendar", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		calendarDto := calendarDto
+		calendarDto.SharedWith = []uint64{userID + 1, userID + 2}
+		calendar := calendar
+		calendar.SharedWith = storage.CalendarShares{userID + 1, userID + 2}
+		mockedStorage.EXPECT().UpdateCalendar(ctx, &calendar).Return(nil)
+
+		require.NoError(t, app.UpdateCalendar(ctx, calendarDto))
+	})
+
+	t.Run("share calendar with owner", func(t *testing.T) {
+		app := New(mocks.NewLogger(t), mocks.NewStorage(t))
+
+		calendarDto := calendarDto
+		calendarDto.SharedWith = []uint64{userID + 1, userID, 0}
+
+		err := app.UpdateCalendar(ctx, calendarDto)
+		require.ErrorIs(t, err, ErrNotValidCalendar)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "sharedWith.1", Err: ErrNotValidCalendarShare},
+			{Field: "sharedWith.2", Err: ErrNotValidCalendarShare},
+		}, validationErrs)
+	})
+
+	t.Run("shared calendars", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		otherUserID := userID + 1
+		defaultCalendar := storage.NewDefaultCalendar(otherUserID)
+		defaultCalendar.ID = 1
+		// другим пользователям не показывается, кому ещё открыт календарь
+		calendar := calendar
+		calendar.SharedWith = storage.CalendarShares{otherUserID, otherUserID + 1}
+
+		mockedStorage.EXPECT().GetDefaultCalendar(ctx, otherUserID).Return(defaultCalendar, nil)
+		mockedStorage.EXPECT().ListCalendars(ctx, otherUserID).Return([]*storage.Calendar{defaultCalendar}, nil)
+		mockedStorage.EXPECT().GetCalendar(ctx, otherUserID, calendar.ID).Return(nil, storage.ErrCalendarNotFound)
+		mockedStorage.EXPECT().GetCalendar(ctx, otherUserID, uint64(100)).Return(nil, storage.ErrCalendarNotFound)
+		mockedStorage.EXPECT().ListSharedCalendars(ctx, otherUserID).Return([]*storage.Calendar{&calendar}, nil)
+
+		calendars, err := app.ListCalendars(ctx, otherUserID)
+		require.NoError(t, err)
+		require.Equal(t, []*CalendarDto{
+			{ID: 1, UserID: otherUserID, Name: storage.DefaultCalendarName, IsDefault: true},
+			&calendarDto,
+		}, calendars)
+
+		actualCalendar, err := app.GetCalendar(ctx, otherUserID, calendar.ID)
+		require.NoError(t, err)
+		require.Equal(t, &calendarDto, actualCalendar)
+
+		_, err = app.GetCalendar(ctx, otherUserID, 100)
+		require.ErrorIs(t, err, storage.ErrCalendarNotFound)
+	})
+
+	t.Run("delete not empty calendar", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		mockedStorage.EXPECT().DeleteCalendar(ctx, userID, calendar.ID).Return(storage.ErrCalendarNotEmpty)
+
+		err := app.DeleteCalendar(ctx, userID, calendar.ID)
+		require.ErrorIs(t, err, storage.ErrCalendarNotEmpty)
+	})
+}
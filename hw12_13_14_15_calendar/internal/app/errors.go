@@ -0,0 +1,125 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+)
+
+var (
+	ErrNotValidOverlapPolicy  = errors.New("overlap policy is not valid")
+	ErrNotValidEvent          = errors.New("event is not valid")
+	ErrQuotaExceeded          = errors.New("events quota exceeded")
+	ErrNotValidIdempotencyKey = errors.New("idempotency key is not valid")
+	ErrNotValidCalendar       = errors.New("calendar is not valid")
+	ErrNotValidCalendarShare  = errors.New("calendar can be shared only with other users")
+	ErrNotValidResource       = errors.New("resource is not valid")
+	ErrNotResourceOwner       = errors.New("resource can be changed only by its creator")
+	ErrNotValidPeriod         = errors.New("period is not valid")
+	ErrNotValidSettings       = errors.New("settings are not valid")
+	ErrNotValidTimeZone       = errors.New("time zone is not valid")
+	ErrNotValidClock          = errors.New("time must be in HH:MM format")
+	ErrNotValidWorkingHours   = errors.New("working interval must end after it starts")
+	ErrOutsideWorkingHours    = errors.New("event is outside working hours")
+	ErrOutOfOffice            = errors.New("user is out of office")
+)
+
+// BusyTimeError содержит события, с которыми пересекается добавляемое событие.
+type BusyTimeError struct {
+	Conflicts []*EventDto
+}
+
+func (e *BusyTimeError) Error() string {
+	return storage.ErrBusyTime.Error()
+}
+
+func (e *BusyTimeError) Unwrap() error {
+	return storage.ErrBusyTime
+}
+
+// OutOfOfficeError - событие попадает в период отсутствия пользователя; Message - сообщение пользователя.
+type OutOfOfficeError struct {
+	Message string
+}
+
+func (e *OutOfOfficeError) Error() string {
+	if e.Message == "" {
+		return ErrOutOfOffice.Error()
+	}
+	return ErrOutOfOffice.Error() + ": " + e.Message
+}
+
+func (e *OutOfOfficeError) Unwrap() error {
+	return ErrOutOfOffice
+}
+
+// validateEvent проверяет событие по тэгам validate; ошибки полей доступны через validator.ValidationErrors.
+func validateEvent(eventDto *EventDto) error {
+	if err := validator.Validate(eventDto); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValidEvent, err)
+	}
+	return nil
+}
+
+// validateCalendar проверяет календарь по тэгам validate, а также пользователей, которым он открыт.
+func validateCalendar(calendarDto *CalendarDto) error {
+	var validationErrs validator.ValidationErrors
+	if err := validator.Validate(calendarDto); err != nil && !errors.As(err, &validationErrs) {
+		return err
+	}
+	for i, userID := range calendarDto.SharedWith {
+		if userID == 0 || userID == calendarDto.UserID {
+			validationErrs.Add(fmt.Sprintf("sharedWith.%d", i), ErrNotValidCalendarShare)
+		}
+	}
+	if len(validationErrs) > 0 {
+		return fmt.Errorf("%w: %w", ErrNotValidCalendar, validationErrs)
+	}
+	return nil
+}
+
+// validateResource проверяет ресурс по тэгам validate.
+func validateResource(resourceDto *ResourceDto) error {
+	if err := validator.Validate(resourceDto); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValidResource, err)
+	}
+	return nil
+}
+
+// validateUserSettings проверяет настройки по тэгам validate, а также часовой пояс и рабочие часы.
+func validateUserSettings(settingsDto *UserSettingsDto) error {
+	var validationErrs validator.ValidationErrors
+	if err := validator.Validate(settingsDto); err != nil && !errors.As(err, &validationErrs) {
+		return err
+	}
+	if _, err := time.LoadLocation(settingsDto.TimeZone); err != nil {
+		validationErrs.Add("timeZone", ErrNotValidTimeZone)
+	}
+	for i, interval := range settingsDto.WorkingHours {
+		start, end := storage.ClockMinutes(interval.Start), storage.ClockMinutes(interval.End)
+		if start < 0 {
+			validationErrs.Add(fmt.Sprintf("workingHours.%d.start", i), ErrNotValidClock)
+		}
+		if end < 0 {
+			validationErrs.Add(fmt.Sprintf("workingHours.%d.end", i), ErrNotValidClock)
+		}
+		if start >= 0 && end >= 0 && start >= end {
+			validationErrs.Add(fmt.Sprintf("workingHours.%d.end", i), ErrNotValidWorkingHours)
+		}
+	}
+	if len(validationErrs) > 0 {
+		return fmt.Errorf("%w: %w", ErrNotValidSettings, validationErrs)
+	}
+	return nil
+}
+
+func convertError(err error) error {
+	var busyTimeErr *storage.BusyTimeError
+	if errors.As(err, &busyTimeErr) {
+		return &BusyTimeError{Conflicts: convertEventsToDto(busyTimeErr.Conflicts)}
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// GetFreeBusy возвращает занятость пользователя в периоде [startDate, endDateExclusive) по его событиям,
+// рабочим часам и периодам отсутствия. Запросить занятость можно для любого пользователя, поэтому
+// события не раскрываются; прозрачные события время не занимают.
+func (a *App) GetFreeBusy(
+	ctx context.Context,
+	userID uint64,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) (*FreeBusyDto, error) {
+	if !endDateExclusive.After(startDate) {
+		return nil, ErrNotValidPeriod
+	}
+	settings, err := a.storage.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.storage.ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDateExclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	freeBusy := &FreeBusyDto{
+		Busy:        []*BusyIntervalDto{},
+		OutOfOffice: []OutOfOfficeDto{},
+		Free:        []*BusyIntervalDto{},
+	}
+	for _, event := range events {
+		if event.Transparent {
+			continue
+		}
+		if interval := clipInterval(event.StartDate, event.EndDate, startDate, endDateExclusive); interval != nil {
+			freeBusy.Busy = append(freeBusy.Busy, interval)
+		}
+	}
+	freeBusy.Busy = mergeIntervals(freeBusy.Busy)
+
+	unavailable := slices.Clone(freeBusy.Busy)
+	for _, period := range settings.OutOfOffice {
+		interval := clipInterval(period.StartDate, period.EndDate, startDate, endDateExclusive)
+		if interval == nil {
+			continue
+		}
+		freeBusy.OutOfOffice = append(freeBusy.OutOfOffice, OutOfOfficeDto{
+			StartDate: interval.StartDate,
+			EndDate:   interval.EndDate,
+			Message:   period.Message,
+		})
+		unavailable = append(unavailable, interval)
+	}
+	unavailable = mergeIntervals(unavailable)
+
+	for _, period := range settings.WorkingPeriods(startDate, endDateExclusive) {
+		freeBusy.Free = append(freeBusy.Free, subtractIntervals(period.StartDate, period.EndDate, unavailable)...)
+	}
+	return freeBusy, nil
+}
+
+// clipInterval обрезает интервал по периоду; для интервала вне периода возвращает nil.
+func clipInterval(startDate, endDate, periodStart, periodEnd time.Time) *BusyIntervalDto {
+	if startDate.Before(periodStart) {
+		startDate = periodStart
+	}
+	if endDate.After(periodEnd) {
+		endDate = periodEnd
+	}
+	if !endDate.After(startDate) {
+		return nil
+	}
+	return &BusyIntervalDto{StartDate: startDate, EndDate: endDate}
+}
+
+// mergeIntervals сортирует интервалы по началу и склеивает пересекающиеся и соседние.
+func mergeIntervals(intervals []*BusyIntervalDto) []*BusyIntervalDto {
+	slices.SortFunc(intervals, func(a, b *BusyIntervalDto) int { return a.StartDate.Compare(b.StartDate) })
+	merged := make([]*BusyIntervalDto, 0, len(intervals))
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.StartDate.After(merged[last].EndDate) {
+			if interval.EndDate.After(merged[last].EndDate) {
+				merged[last] = &BusyIntervalDto{StartDate: merged[last].StartDate, EndDate: interval.EndDate}
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// subtractIntervals возвращает части [startDate, endDate), не покрытые отсортированными непересекающимися интервалами.
+func subtractIntervals(startDate, endDate time.Time, intervals []*BusyIntervalDto) []*BusyIntervalDto {
+	var free []*BusyIntervalDto
+	for _, interval := range intervals {
+		if !interval.EndDate.After(startDate) {
+			continue
+		}
+		if !interval.StartDate.Before(endDate) {
+			break
+		}
+		if interval.StartDate.After(startDate) {
+			free = append(free, &BusyIntervalDto{StartDate: startDate, EndDate: interval.StartDate})
+		}
+		startDate = interval.EndDate
+	}
+	if endDate.After(startDate) {
+		free = append(free, &BusyIntervalDto{StartDate: startDate, EndDate: endDate})
+	}
+	return free
+}
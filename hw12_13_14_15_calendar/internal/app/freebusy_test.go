@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFreeBusy(t *testing.T) {
+	t.Parallel()
+
+	userID := uint64(12345)
+	ctx := context.Background()
+	// 2024-07-08 - понедельник
+	at := func(value string) time.Time {
+		date, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return date
+	}
+	startDate := at("2024-07-08T00:00:00Z")
+	endDate := at("2024-07-09T00:00:00Z")
+
+	t.Run("free busy", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		settings := storage.DefaultUserSettings(userID)
+		settings.WorkingHours = storage.WorkingHours{
+			{Weekday: time.Monday, Start: "09:00", End: "13:00"},
+			{Weekday: time.Monday, Start: "14:00", End: "18:00"},
+		}
+		settings.OutOfOffice = storage.OutOfOffice{
+			{StartDate: at("2024-07-01T00:00:00Z"), EndDate: at("2024-07-05T00:00:00Z"), Message: "Sick leave"},
+			{StartDate: at("2024-07-08T16:30:00Z"), EndDate: at("2024-07-10T00:00:00Z"), Message: "On vacation"},
+		}
+		events := []*storage.Event{
+			{ID: 1, UserID: userID, StartDate: at("2024-07-08T10:00:00Z"), EndDate: at("2024-07-08T10:30:00Z")},
+			{ID: 2, UserID: userID, StartDate: at("2024-07-08T08:00:00Z"), EndDate: at("2024-07-08T10:00:00Z")},
+			{ID: 3, UserID: userID, StartDate: at("2024-07-08T15:00:00Z"), EndDate: at("2024-07-08T16:00:00Z"), Transparent: true},
+			{ID: 4, UserID: userID, StartDate: at("2024-07-07T23:00:00Z"), EndDate: at("2024-07-08T01:00:00Z")},
+		}
+
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(settings, nil)
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return(events, nil)
+
+		freeBusy, err := app.GetFreeBusy(ctx, userID, startDate, endDate)
+		require.NoError(t, err)
+		require.Equal(t, &FreeBusyDto{
+			Busy: []*BusyIntervalDto{
+				{StartDate: at("2024-07-08T00:00:00Z"), EndDate: at("2024-07-08T01:00:00Z")},
+				{StartDate: at("2024-07-08T08:00:00Z"), EndDate: at("2024-07-08T10:30:00Z")},
+			},
+			OutOfOffice: []OutOfOfficeDto{
+				{StartDate: at("2024-07-08T16:30:00Z"), EndDate: endDate, Message: "On vacation"},
+			},
+			Free: []*BusyIntervalDto{
+				{StartDate: at("2024-07-08T10:30:00Z"), EndDate: at("2024-07-08T13:00:00Z")},
+				{StartDate: at("2024-07-08T14:00:00Z"), EndDate: at("2024-07-08T16:30:00Z")},
+			},
+		}, freeBusy)
+	})
+
+	t.Run("free busy without working hours", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		events := []*storage.Event{
+			{ID: 1, UserID: userID, StartDate: at("2024-07-08T10:00:00Z"), EndDate: at("2024-07-08T11:00:00Z")},
+		}
+
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+		mockedStorage.EXPECT().ListForPeriod(ctx, userID, storage.EventFilter{}, startDate, endDate).Return(events, nil)
+
+		freeBusy, err := app.GetFreeBusy(ctx, userID, startDate, endDate)
+		require.NoError(t, err)
+		require.Equal(t, &FreeBusyDto{
+			Busy:        []*BusyIntervalDto{{StartDate: at("2024-07-08T10:00:00Z"), EndDate: at("2024-07-08T11:00:00Z")}},
+			OutOfOffice: []OutOfOfficeDto{},
+			Free: []*BusyIntervalDto{
+				{StartDate: startDate, EndDate: at("2024-07-08T10:00:00Z")},
+				{StartDate: at("2024-07-08T11:00:00Z"), EndDate: endDate},
+			},
+		}, freeBusy)
+	})
+
+	t.Run("free busy for not valid period", func(t *testing.T) {
+		app := New(mocks.NewLogger(t), mocks.NewStorage(t))
+
+		_, err := app.GetFreeBusy(ctx, userID, endDate, startDate)
+		require.ErrorIs(t, err, ErrNotValidPeriod)
+	})
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	// MaxIdempotencyKeyLen - максимальная длина ключа идемпотентности.
+	MaxIdempotencyKeyLen = 255
+	// DefaultIdempotencyTTL - сколько хранится результат создания события по ключу идемпотентности.
+	DefaultIdempotencyTTL = 24 * time.Hour
+)
+
+type idempotencyKeyCtxKey struct{}
+
+// ContextWithIdempotencyKey сохраняет в контексте ключ идемпотентности запроса на создание события.
+// Повторный запрос с тем же ключом в течение TTL возвращает ID ранее созданного события,
+// а запрос с тем же ключом, но другим содержимым отклоняется с ошибкой storage.ErrIdempotencyKeyReused.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+// requestHash - хэш содержимого запроса на создание события, сохраняемый вместе с ключом идемпотентности.
+func requestHash(eventDto EventDto) string {
+	// EventDto состоит из сериализуемых полей, поэтому ошибки быть не может
+	data, _ := json.Marshal(eventDto)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// replayCreate возвращает результат предыдущего запроса с тем же ключом идемпотентности: ID события
+// и пересечения, как при создании. replayed == false, если ключ ещё не использовался.
+func (a *App) replayCreate(
+	ctx context.Context,
+	eventDto EventDto,
+	key string,
+	hash string,
+) (eventID uint64, conflicts []*EventDto, replayed bool, err error) {
+	idempotencyKey, err := a.storage.GetIdempotencyKey(ctx, eventDto.UserID, key, a.idempotencyNotBefore())
+	if errors.Is(err, storage.ErrIdempotencyKeyNotFound) {
+		return 0, nil, false, nil
+	}
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if !idempotencyKey.Matches(hash) {
+		return 0, nil, false, storage.ErrIdempotencyKeyReused
+	}
+
+	settings, err := a.storage.GetUserSettings(ctx, eventDto.UserID)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	event := convertEventToModel(&eventDto)
+	event.ID = idempotencyKey.EventID
+	return event.ID, a.listConflicts(ctx, settings, event), true, nil
+}
+
+// создаёт событие с учётом ключа идемпотентности; replayed означает, что событие было создано
+// параллельным запросом с тем же ключом.
+func (a *App) createEvent(ctx context.Context, event *storage.Event, key string, hash string) (eventID uint64, replayed bool, err error) {
+	if key == "" {
+		eventID, err = a.storage.Create(ctx, event)
+		return eventID, false, err
+	}
+	return a.storage.CreateIdempotent(ctx, event, key, hash, a.idempotencyNotBefore())
+}
+
+// idempotencyNotBefore - время создания самого старого действующего ключа идемпотентности.
+func (a *App) idempotencyNotBefore() time.Time {
+	return time.Now().Add(-a.idempotencyTTL)
+}
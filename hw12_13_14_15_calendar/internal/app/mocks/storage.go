@@ -23,6 +23,63 @@ func (_m *Storage) EXPECT() *Storage_Expecter {
 	return &Storage_Expecter{mock: &_m.Mock}
 }
 
+// CountByUser provides a mock function with given fields: ctx, userID
+func (_m *Storage) CountByUser(ctx context.Context, userID uint64) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByUser")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_CountByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountByUser'
+type Storage_CountByUser_Call struct {
+	*mock.Call
+}
+
+// CountByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) CountByUser(ctx interface{}, userID interface{}) *Storage_CountByUser_Call {
+	return &Storage_CountByUser_Call{Call: _e.mock.On("CountByUser", ctx, userID)}
+}
+
+func (_c *Storage_CountByUser_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_CountByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_CountByUser_Call) Return(_a0 int, _a1 error) *Storage_CountByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_CountByUser_Call) RunAndReturn(run func(context.Context, uint64) (int, error)) *Storage_CountByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function with given fields: ctx, event
 func (_m *Storage) Create(ctx context.Context, event *storage.Event) (uint64, error) {
 	ret := _m.Called(ctx, event)
@@ -80,6 +137,234 @@ func (_c *Storage_Create_Call) RunAndReturn(run func(context.Context, *storage.E
 	return _c
 }
 
+// CreateAuditRecord provides a mock function with given fields: ctx, record
+func (_m *Storage) CreateAuditRecord(ctx context.Context, record *storage.AuditRecord) error {
+	ret := _m.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuditRecord")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.AuditRecord) error); ok {
+		r0 = rf(ctx, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_CreateAuditRecord_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAuditRecord'
+type Storage_CreateAuditRecord_Call struct {
+	*mock.Call
+}
+
+// CreateAuditRecord is a helper method to define mock.On call
+//   - ctx context.Context
+//   - record *storage.AuditRecord
+func (_e *Storage_Expecter) CreateAuditRecord(ctx interface{}, record interface{}) *Storage_CreateAuditRecord_Call {
+	return &Storage_CreateAuditRecord_Call{Call: _e.mock.On("CreateAuditRecord", ctx, record)}
+}
+
+func (_c *Storage_CreateAuditRecord_Call) Run(run func(ctx context.Context, record *storage.AuditRecord)) *Storage_CreateAuditRecord_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.AuditRecord))
+	})
+	return _c
+}
+
+func (_c *Storage_CreateAuditRecord_Call) Return(_a0 error) *Storage_CreateAuditRecord_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_CreateAuditRecord_Call) RunAndReturn(run func(context.Context, *storage.AuditRecord) error) *Storage_CreateAuditRecord_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateCalendar provides a mock function with given fields: ctx, calendar
+func (_m *Storage) CreateCalendar(ctx context.Context, calendar *storage.Calendar) (uint64, error) {
+	ret := _m.Called(ctx, calendar)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCalendar")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Calendar) (uint64, error)); ok {
+		return rf(ctx, calendar)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Calendar) uint64); ok {
+		r0 = rf(ctx, calendar)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *storage.Calendar) error); ok {
+		r1 = rf(ctx, calendar)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_CreateCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateCalendar'
+type Storage_CreateCalendar_Call struct {
+	*mock.Call
+}
+
+// CreateCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - calendar *storage.Calendar
+func (_e *Storage_Expecter) CreateCalendar(ctx interface{}, calendar interface{}) *Storage_CreateCalendar_Call {
+	return &Storage_CreateCalendar_Call{Call: _e.mock.On("CreateCalendar", ctx, calendar)}
+}
+
+func (_c *Storage_CreateCalendar_Call) Run(run func(ctx context.Context, calendar *storage.Calendar)) *Storage_CreateCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Calendar))
+	})
+	return _c
+}
+
+func (_c *Storage_CreateCalendar_Call) Return(_a0 uint64, _a1 error) *Storage_CreateCalendar_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_CreateCalendar_Call) RunAndReturn(run func(context.Context, *storage.Calendar) (uint64, error)) *Storage_CreateCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateIdempotent provides a mock function with given fields: ctx, event, key, requestHash, notBefore
+func (_m *Storage) CreateIdempotent(ctx context.Context, event *storage.Event, key string, requestHash string, notBefore time.Time) (uint64, bool, error) {
+	ret := _m.Called(ctx, event, key, requestHash, notBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIdempotent")
+	}
+
+	var r0 uint64
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Event, string, string, time.Time) (uint64, bool, error)); ok {
+		return rf(ctx, event, key, requestHash, notBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Event, string, string, time.Time) uint64); ok {
+		r0 = rf(ctx, event, key, requestHash, notBefore)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *storage.Event, string, string, time.Time) bool); ok {
+		r1 = rf(ctx, event, key, requestHash, notBefore)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *storage.Event, string, string, time.Time) error); ok {
+		r2 = rf(ctx, event, key, requestHash, notBefore)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Storage_CreateIdempotent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateIdempotent'
+type Storage_CreateIdempotent_Call struct {
+	*mock.Call
+}
+
+// CreateIdempotent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - event *storage.Event
+//   - key string
+//   - requestHash string
+//   - notBefore time.Time
+func (_e *Storage_Expecter) CreateIdempotent(ctx interface{}, event interface{}, key interface{}, requestHash interface{}, notBefore interface{}) *Storage_CreateIdempotent_Call {
+	return &Storage_CreateIdempotent_Call{Call: _e.mock.On("CreateIdempotent", ctx, event, key, requestHash, notBefore)}
+}
+
+func (_c *Storage_CreateIdempotent_Call) Run(run func(ctx context.Context, event *storage.Event, key string, requestHash string, notBefore time.Time)) *Storage_CreateIdempotent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Event), args[2].(string), args[3].(string), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_CreateIdempotent_Call) Return(_a0 uint64, _a1 bool, _a2 error) *Storage_CreateIdempotent_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Storage_CreateIdempotent_Call) RunAndReturn(run func(context.Context, *storage.Event, string, string, time.Time) (uint64, bool, error)) *Storage_CreateIdempotent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateResource provides a mock function with given fields: ctx, resource
+func (_m *Storage) CreateResource(ctx context.Context, resource *storage.Resource) (uint64, error) {
+	ret := _m.Called(ctx, resource)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateResource")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Resource) (uint64, error)); ok {
+		return rf(ctx, resource)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Resource) uint64); ok {
+		r0 = rf(ctx, resource)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *storage.Resource) error); ok {
+		r1 = rf(ctx, resource)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_CreateResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateResource'
+type Storage_CreateResource_Call struct {
+	*mock.Call
+}
+
+// CreateResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resource *storage.Resource
+func (_e *Storage_Expecter) CreateResource(ctx interface{}, resource interface{}) *Storage_CreateResource_Call {
+	return &Storage_CreateResource_Call{Call: _e.mock.On("CreateResource", ctx, resource)}
+}
+
+func (_c *Storage_CreateResource_Call) Run(run func(ctx context.Context, resource *storage.Resource)) *Storage_CreateResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Resource))
+	})
+	return _c
+}
+
+func (_c *Storage_CreateResource_Call) Return(_a0 uint64, _a1 error) *Storage_CreateResource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_CreateResource_Call) RunAndReturn(run func(context.Context, *storage.Resource) (uint64, error)) *Storage_CreateResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Delete provides a mock function with given fields: ctx, userID, eventID
 func (_m *Storage) Delete(ctx context.Context, userID uint64, eventID uint64) error {
 	ret := _m.Called(ctx, userID, eventID)
@@ -128,6 +413,101 @@ func (_c *Storage_Delete_Call) RunAndReturn(run func(context.Context, uint64, ui
 	return _c
 }
 
+// DeleteCalendar provides a mock function with given fields: ctx, userID, calendarID
+func (_m *Storage) DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error {
+	ret := _m.Called(ctx, userID, calendarID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCalendar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, calendarID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCalendar'
+type Storage_DeleteCalendar_Call struct {
+	*mock.Call
+}
+
+// DeleteCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - calendarID uint64
+func (_e *Storage_Expecter) DeleteCalendar(ctx interface{}, userID interface{}, calendarID interface{}) *Storage_DeleteCalendar_Call {
+	return &Storage_DeleteCalendar_Call{Call: _e.mock.On("DeleteCalendar", ctx, userID, calendarID)}
+}
+
+func (_c *Storage_DeleteCalendar_Call) Run(run func(ctx context.Context, userID uint64, calendarID uint64)) *Storage_DeleteCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteCalendar_Call) Return(_a0 error) *Storage_DeleteCalendar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteCalendar_Call) RunAndReturn(run func(context.Context, uint64, uint64) error) *Storage_DeleteCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteResource provides a mock function with given fields: ctx, resourceID
+func (_m *Storage) DeleteResource(ctx context.Context, resourceID uint64) error {
+	ret := _m.Called(ctx, resourceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteResource")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, resourceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteResource'
+type Storage_DeleteResource_Call struct {
+	*mock.Call
+}
+
+// DeleteResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceID uint64
+func (_e *Storage_Expecter) DeleteResource(ctx interface{}, resourceID interface{}) *Storage_DeleteResource_Call {
+	return &Storage_DeleteResource_Call{Call: _e.mock.On("DeleteResource", ctx, resourceID)}
+}
+
+func (_c *Storage_DeleteResource_Call) Run(run func(ctx context.Context, resourceID uint64)) *Storage_DeleteResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteResource_Call) Return(_a0 error) *Storage_DeleteResource_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteResource_Call) RunAndReturn(run func(context.Context, uint64) error) *Storage_DeleteResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByID provides a mock function with given fields: ctx, userID, eventID
 func (_m *Storage) GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
 	ret := _m.Called(ctx, userID, eventID)
@@ -188,29 +568,29 @@ func (_c *Storage_GetByID_Call) RunAndReturn(run func(context.Context, uint64, u
 	return _c
 }
 
-// ListForPeriod provides a mock function with given fields: ctx, userID, startDate, endDateExclusive
-func (_m *Storage) ListForPeriod(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) ([]*storage.Event, error) {
-	ret := _m.Called(ctx, userID, startDate, endDateExclusive)
+// GetCalendar provides a mock function with given fields: ctx, userID, calendarID
+func (_m *Storage) GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error) {
+	ret := _m.Called(ctx, userID, calendarID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListForPeriod")
+		panic("no return value specified for GetCalendar")
 	}
 
-	var r0 []*storage.Event
+	var r0 *storage.Calendar
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) ([]*storage.Event, error)); ok {
-		return rf(ctx, userID, startDate, endDateExclusive)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (*storage.Calendar, error)); ok {
+		return rf(ctx, userID, calendarID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) []*storage.Event); ok {
-		r0 = rf(ctx, userID, startDate, endDateExclusive)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) *storage.Calendar); ok {
+		r0 = rf(ctx, userID, calendarID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*storage.Event)
+			r0 = ret.Get(0).(*storage.Calendar)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, time.Time) error); ok {
-		r1 = rf(ctx, userID, startDate, endDateExclusive)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, calendarID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -218,33 +598,965 @@ func (_m *Storage) ListForPeriod(ctx context.Context, userID uint64, startDate t
 	return r0, r1
 }
 
-// Storage_ListForPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForPeriod'
-type Storage_ListForPeriod_Call struct {
+// Storage_GetCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCalendar'
+type Storage_GetCalendar_Call struct {
 	*mock.Call
 }
 
-// ListForPeriod is a helper method to define mock.On call
+// GetCalendar is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID uint64
-//   - startDate time.Time
-//   - endDateExclusive time.Time
-func (_e *Storage_Expecter) ListForPeriod(ctx interface{}, userID interface{}, startDate interface{}, endDateExclusive interface{}) *Storage_ListForPeriod_Call {
-	return &Storage_ListForPeriod_Call{Call: _e.mock.On("ListForPeriod", ctx, userID, startDate, endDateExclusive)}
+//   - calendarID uint64
+func (_e *Storage_Expecter) GetCalendar(ctx interface{}, userID interface{}, calendarID interface{}) *Storage_GetCalendar_Call {
+	return &Storage_GetCalendar_Call{Call: _e.mock.On("GetCalendar", ctx, userID, calendarID)}
 }
 
-func (_c *Storage_ListForPeriod_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time)) *Storage_ListForPeriod_Call {
+func (_c *Storage_GetCalendar_Call) Run(run func(ctx context.Context, userID uint64, calendarID uint64)) *Storage_GetCalendar_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(time.Time))
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
 	})
 	return _c
 }
 
-func (_c *Storage_ListForPeriod_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListForPeriod_Call {
+func (_c *Storage_GetCalendar_Call) Return(_a0 *storage.Calendar, _a1 error) *Storage_GetCalendar_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Storage_ListForPeriod_Call) RunAndReturn(run func(context.Context, uint64, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListForPeriod_Call {
+func (_c *Storage_GetCalendar_Call) RunAndReturn(run func(context.Context, uint64, uint64) (*storage.Calendar, error)) *Storage_GetCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDefaultCalendar provides a mock function with given fields: ctx, userID
+func (_m *Storage) GetDefaultCalendar(ctx context.Context, userID uint64) (*storage.Calendar, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDefaultCalendar")
+	}
+
+	var r0 *storage.Calendar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*storage.Calendar, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *storage.Calendar); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Calendar)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_GetDefaultCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDefaultCalendar'
+type Storage_GetDefaultCalendar_Call struct {
+	*mock.Call
+}
+
+// GetDefaultCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) GetDefaultCalendar(ctx interface{}, userID interface{}) *Storage_GetDefaultCalendar_Call {
+	return &Storage_GetDefaultCalendar_Call{Call: _e.mock.On("GetDefaultCalendar", ctx, userID)}
+}
+
+func (_c *Storage_GetDefaultCalendar_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_GetDefaultCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_GetDefaultCalendar_Call) Return(_a0 *storage.Calendar, _a1 error) *Storage_GetDefaultCalendar_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_GetDefaultCalendar_Call) RunAndReturn(run func(context.Context, uint64) (*storage.Calendar, error)) *Storage_GetDefaultCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetIdempotencyKey provides a mock function with given fields: ctx, userID, key, notBefore
+func (_m *Storage) GetIdempotencyKey(ctx context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error) {
+	ret := _m.Called(ctx, userID, key, notBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotencyKey")
+	}
+
+	var r0 *storage.IdempotencyKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, time.Time) (*storage.IdempotencyKey, error)); ok {
+		return rf(ctx, userID, key, notBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, time.Time) *storage.IdempotencyKey); ok {
+		r0 = rf(ctx, userID, key, notBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.IdempotencyKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, string, time.Time) error); ok {
+		r1 = rf(ctx, userID, key, notBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_GetIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIdempotencyKey'
+type Storage_GetIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// GetIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - key string
+//   - notBefore time.Time
+func (_e *Storage_Expecter) GetIdempotencyKey(ctx interface{}, userID interface{}, key interface{}, notBefore interface{}) *Storage_GetIdempotencyKey_Call {
+	return &Storage_GetIdempotencyKey_Call{Call: _e.mock.On("GetIdempotencyKey", ctx, userID, key, notBefore)}
+}
+
+func (_c *Storage_GetIdempotencyKey_Call) Run(run func(ctx context.Context, userID uint64, key string, notBefore time.Time)) *Storage_GetIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_GetIdempotencyKey_Call) Return(_a0 *storage.IdempotencyKey, _a1 error) *Storage_GetIdempotencyKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_GetIdempotencyKey_Call) RunAndReturn(run func(context.Context, uint64, string, time.Time) (*storage.IdempotencyKey, error)) *Storage_GetIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResource provides a mock function with given fields: ctx, resourceID
+func (_m *Storage) GetResource(ctx context.Context, resourceID uint64) (*storage.Resource, error) {
+	ret := _m.Called(ctx, resourceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResource")
+	}
+
+	var r0 *storage.Resource
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*storage.Resource, error)); ok {
+		return rf(ctx, resourceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *storage.Resource); ok {
+		r0 = rf(ctx, resourceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Resource)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, resourceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_GetResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResource'
+type Storage_GetResource_Call struct {
+	*mock.Call
+}
+
+// GetResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceID uint64
+func (_e *Storage_Expecter) GetResource(ctx interface{}, resourceID interface{}) *Storage_GetResource_Call {
+	return &Storage_GetResource_Call{Call: _e.mock.On("GetResource", ctx, resourceID)}
+}
+
+func (_c *Storage_GetResource_Call) Run(run func(ctx context.Context, resourceID uint64)) *Storage_GetResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_GetResource_Call) Return(_a0 *storage.Resource, _a1 error) *Storage_GetResource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_GetResource_Call) RunAndReturn(run func(context.Context, uint64) (*storage.Resource, error)) *Storage_GetResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSharedByID provides a mock function with given fields: ctx, userID, eventID
+func (_m *Storage) GetSharedByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	ret := _m.Called(ctx, userID, eventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSharedByID")
+	}
+
+	var r0 *storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (*storage.Event, error)); ok {
+		return rf(ctx, userID, eventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) *storage.Event); ok {
+		r0 = rf(ctx, userID, eventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, eventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_GetSharedByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSharedByID'
+type Storage_GetSharedByID_Call struct {
+	*mock.Call
+}
+
+// GetSharedByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - eventID uint64
+func (_e *Storage_Expecter) GetSharedByID(ctx interface{}, userID interface{}, eventID interface{}) *Storage_GetSharedByID_Call {
+	return &Storage_GetSharedByID_Call{Call: _e.mock.On("GetSharedByID", ctx, userID, eventID)}
+}
+
+func (_c *Storage_GetSharedByID_Call) Run(run func(ctx context.Context, userID uint64, eventID uint64)) *Storage_GetSharedByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_GetSharedByID_Call) Return(_a0 *storage.Event, _a1 error) *Storage_GetSharedByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_GetSharedByID_Call) RunAndReturn(run func(context.Context, uint64, uint64) (*storage.Event, error)) *Storage_GetSharedByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserSettings provides a mock function with given fields: ctx, userID
+func (_m *Storage) GetUserSettings(ctx context.Context, userID uint64) (*storage.UserSettings, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserSettings")
+	}
+
+	var r0 *storage.UserSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*storage.UserSettings, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *storage.UserSettings); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.UserSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_GetUserSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserSettings'
+type Storage_GetUserSettings_Call struct {
+	*mock.Call
+}
+
+// GetUserSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) GetUserSettings(ctx interface{}, userID interface{}) *Storage_GetUserSettings_Call {
+	return &Storage_GetUserSettings_Call{Call: _e.mock.On("GetUserSettings", ctx, userID)}
+}
+
+func (_c *Storage_GetUserSettings_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_GetUserSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_GetUserSettings_Call) Return(_a0 *storage.UserSettings, _a1 error) *Storage_GetUserSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_GetUserSettings_Call) RunAndReturn(run func(context.Context, uint64) (*storage.UserSettings, error)) *Storage_GetUserSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAuditRecords provides a mock function with given fields: ctx, userID, eventID
+func (_m *Storage) ListAuditRecords(ctx context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error) {
+	ret := _m.Called(ctx, userID, eventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAuditRecords")
+	}
+
+	var r0 []*storage.AuditRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) ([]*storage.AuditRecord, error)); ok {
+		return rf(ctx, userID, eventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) []*storage.AuditRecord); ok {
+		r0 = rf(ctx, userID, eventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.AuditRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, eventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListAuditRecords_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAuditRecords'
+type Storage_ListAuditRecords_Call struct {
+	*mock.Call
+}
+
+// ListAuditRecords is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - eventID uint64
+func (_e *Storage_Expecter) ListAuditRecords(ctx interface{}, userID interface{}, eventID interface{}) *Storage_ListAuditRecords_Call {
+	return &Storage_ListAuditRecords_Call{Call: _e.mock.On("ListAuditRecords", ctx, userID, eventID)}
+}
+
+func (_c *Storage_ListAuditRecords_Call) Run(run func(ctx context.Context, userID uint64, eventID uint64)) *Storage_ListAuditRecords_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_ListAuditRecords_Call) Return(_a0 []*storage.AuditRecord, _a1 error) *Storage_ListAuditRecords_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListAuditRecords_Call) RunAndReturn(run func(context.Context, uint64, uint64) ([]*storage.AuditRecord, error)) *Storage_ListAuditRecords_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCalendars provides a mock function with given fields: ctx, userID
+func (_m *Storage) ListCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCalendars")
+	}
+
+	var r0 []*storage.Calendar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]*storage.Calendar, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []*storage.Calendar); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Calendar)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListCalendars_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCalendars'
+type Storage_ListCalendars_Call struct {
+	*mock.Call
+}
+
+// ListCalendars is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) ListCalendars(ctx interface{}, userID interface{}) *Storage_ListCalendars_Call {
+	return &Storage_ListCalendars_Call{Call: _e.mock.On("ListCalendars", ctx, userID)}
+}
+
+func (_c *Storage_ListCalendars_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_ListCalendars_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_ListCalendars_Call) Return(_a0 []*storage.Calendar, _a1 error) *Storage_ListCalendars_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListCalendars_Call) RunAndReturn(run func(context.Context, uint64) ([]*storage.Calendar, error)) *Storage_ListCalendars_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListConflicts provides a mock function with given fields: ctx, event
+func (_m *Storage) ListConflicts(ctx context.Context, event *storage.Event) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListConflicts")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Event) ([]*storage.Event, error)); ok {
+		return rf(ctx, event)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Event) []*storage.Event); ok {
+		r0 = rf(ctx, event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *storage.Event) error); ok {
+		r1 = rf(ctx, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListConflicts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListConflicts'
+type Storage_ListConflicts_Call struct {
+	*mock.Call
+}
+
+// ListConflicts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - event *storage.Event
+func (_e *Storage_Expecter) ListConflicts(ctx interface{}, event interface{}) *Storage_ListConflicts_Call {
+	return &Storage_ListConflicts_Call{Call: _e.mock.On("ListConflicts", ctx, event)}
+}
+
+func (_c *Storage_ListConflicts_Call) Run(run func(ctx context.Context, event *storage.Event)) *Storage_ListConflicts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Event))
+	})
+	return _c
+}
+
+func (_c *Storage_ListConflicts_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListConflicts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListConflicts_Call) RunAndReturn(run func(context.Context, *storage.Event) ([]*storage.Event, error)) *Storage_ListConflicts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDeleted provides a mock function with given fields: ctx, userID
+func (_m *Storage) ListDeleted(ctx context.Context, userID uint64) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeleted")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]*storage.Event, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []*storage.Event); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDeleted'
+type Storage_ListDeleted_Call struct {
+	*mock.Call
+}
+
+// ListDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) ListDeleted(ctx interface{}, userID interface{}) *Storage_ListDeleted_Call {
+	return &Storage_ListDeleted_Call{Call: _e.mock.On("ListDeleted", ctx, userID)}
+}
+
+func (_c *Storage_ListDeleted_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_ListDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_ListDeleted_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListDeleted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListDeleted_Call) RunAndReturn(run func(context.Context, uint64) ([]*storage.Event, error)) *Storage_ListDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForPeriod provides a mock function with given fields: ctx, userID, filter, startDate, endDateExclusive
+func (_m *Storage) ListForPeriod(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, userID, filter, startDate, endDateExclusive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForPeriod")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, userID, filter, startDate, endDateExclusive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListForPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForPeriod'
+type Storage_ListForPeriod_Call struct {
+	*mock.Call
+}
+
+// ListForPeriod is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - filter storage.EventFilter
+//   - startDate time.Time
+//   - endDateExclusive time.Time
+func (_e *Storage_Expecter) ListForPeriod(ctx interface{}, userID interface{}, filter interface{}, startDate interface{}, endDateExclusive interface{}) *Storage_ListForPeriod_Call {
+	return &Storage_ListForPeriod_Call{Call: _e.mock.On("ListForPeriod", ctx, userID, filter, startDate, endDateExclusive)}
+}
+
+func (_c *Storage_ListForPeriod_Call) Run(run func(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time)) *Storage_ListForPeriod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(storage.EventFilter), args[3].(time.Time), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListForPeriod_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListForPeriod_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListForPeriod_Call) RunAndReturn(run func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListForPeriod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListResourceBusy provides a mock function with given fields: ctx, resourceID, startDate, endDateExclusive
+func (_m *Storage) ListResourceBusy(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*storage.BusyInterval, error) {
+	ret := _m.Called(ctx, resourceID, startDate, endDateExclusive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListResourceBusy")
+	}
+
+	var r0 []*storage.BusyInterval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) ([]*storage.BusyInterval, error)); ok {
+		return rf(ctx, resourceID, startDate, endDateExclusive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) []*storage.BusyInterval); ok {
+		r0 = rf(ctx, resourceID, startDate, endDateExclusive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.BusyInterval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, resourceID, startDate, endDateExclusive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListResourceBusy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListResourceBusy'
+type Storage_ListResourceBusy_Call struct {
+	*mock.Call
+}
+
+// ListResourceBusy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceID uint64
+//   - startDate time.Time
+//   - endDateExclusive time.Time
+func (_e *Storage_Expecter) ListResourceBusy(ctx interface{}, resourceID interface{}, startDate interface{}, endDateExclusive interface{}) *Storage_ListResourceBusy_Call {
+	return &Storage_ListResourceBusy_Call{Call: _e.mock.On("ListResourceBusy", ctx, resourceID, startDate, endDateExclusive)}
+}
+
+func (_c *Storage_ListResourceBusy_Call) Run(run func(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time)) *Storage_ListResourceBusy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListResourceBusy_Call) Return(_a0 []*storage.BusyInterval, _a1 error) *Storage_ListResourceBusy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListResourceBusy_Call) RunAndReturn(run func(context.Context, uint64, time.Time, time.Time) ([]*storage.BusyInterval, error)) *Storage_ListResourceBusy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListResources provides a mock function with given fields: ctx, filter
+func (_m *Storage) ListResources(ctx context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListResources")
+	}
+
+	var r0 []*storage.Resource
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ResourceFilter) ([]*storage.Resource, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, storage.ResourceFilter) []*storage.Resource); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Resource)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, storage.ResourceFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListResources_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListResources'
+type Storage_ListResources_Call struct {
+	*mock.Call
+}
+
+// ListResources is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter storage.ResourceFilter
+func (_e *Storage_Expecter) ListResources(ctx interface{}, filter interface{}) *Storage_ListResources_Call {
+	return &Storage_ListResources_Call{Call: _e.mock.On("ListResources", ctx, filter)}
+}
+
+func (_c *Storage_ListResources_Call) Run(run func(ctx context.Context, filter storage.ResourceFilter)) *Storage_ListResources_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(storage.ResourceFilter))
+	})
+	return _c
+}
+
+func (_c *Storage_ListResources_Call) Return(_a0 []*storage.Resource, _a1 error) *Storage_ListResources_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListResources_Call) RunAndReturn(run func(context.Context, storage.ResourceFilter) ([]*storage.Resource, error)) *Storage_ListResources_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSharedCalendars provides a mock function with given fields: ctx, userID
+func (_m *Storage) ListSharedCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSharedCalendars")
+	}
+
+	var r0 []*storage.Calendar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]*storage.Calendar, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []*storage.Calendar); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Calendar)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListSharedCalendars_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSharedCalendars'
+type Storage_ListSharedCalendars_Call struct {
+	*mock.Call
+}
+
+// ListSharedCalendars is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Storage_Expecter) ListSharedCalendars(ctx interface{}, userID interface{}) *Storage_ListSharedCalendars_Call {
+	return &Storage_ListSharedCalendars_Call{Call: _e.mock.On("ListSharedCalendars", ctx, userID)}
+}
+
+func (_c *Storage_ListSharedCalendars_Call) Run(run func(ctx context.Context, userID uint64)) *Storage_ListSharedCalendars_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_ListSharedCalendars_Call) Return(_a0 []*storage.Calendar, _a1 error) *Storage_ListSharedCalendars_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListSharedCalendars_Call) RunAndReturn(run func(context.Context, uint64) ([]*storage.Calendar, error)) *Storage_ListSharedCalendars_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSharedForPeriod provides a mock function with given fields: ctx, userID, filter, startDate, endDateExclusive
+func (_m *Storage) ListSharedForPeriod(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, userID, filter, startDate, endDateExclusive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSharedForPeriod")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, userID, filter, startDate, endDateExclusive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListSharedForPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSharedForPeriod'
+type Storage_ListSharedForPeriod_Call struct {
+	*mock.Call
+}
+
+// ListSharedForPeriod is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - filter storage.EventFilter
+//   - startDate time.Time
+//   - endDateExclusive time.Time
+func (_e *Storage_Expecter) ListSharedForPeriod(ctx interface{}, userID interface{}, filter interface{}, startDate interface{}, endDateExclusive interface{}) *Storage_ListSharedForPeriod_Call {
+	return &Storage_ListSharedForPeriod_Call{Call: _e.mock.On("ListSharedForPeriod", ctx, userID, filter, startDate, endDateExclusive)}
+}
+
+func (_c *Storage_ListSharedForPeriod_Call) Run(run func(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time)) *Storage_ListSharedForPeriod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(storage.EventFilter), args[3].(time.Time), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListSharedForPeriod_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListSharedForPeriod_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListSharedForPeriod_Call) RunAndReturn(run func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListSharedForPeriod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: ctx, userID, eventID
+func (_m *Storage) Restore(ctx context.Context, userID uint64, eventID uint64) error {
+	ret := _m.Called(ctx, userID, eventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, eventID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type Storage_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - eventID uint64
+func (_e *Storage_Expecter) Restore(ctx interface{}, userID interface{}, eventID interface{}) *Storage_Restore_Call {
+	return &Storage_Restore_Call{Call: _e.mock.On("Restore", ctx, userID, eventID)}
+}
+
+func (_c *Storage_Restore_Call) Run(run func(ctx context.Context, userID uint64, eventID uint64)) *Storage_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Storage_Restore_Call) Return(_a0 error) *Storage_Restore_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_Restore_Call) RunAndReturn(run func(context.Context, uint64, uint64) error) *Storage_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUserSettings provides a mock function with given fields: ctx, settings
+func (_m *Storage) SaveUserSettings(ctx context.Context, settings *storage.UserSettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUserSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.UserSettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_SaveUserSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUserSettings'
+type Storage_SaveUserSettings_Call struct {
+	*mock.Call
+}
+
+// SaveUserSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - settings *storage.UserSettings
+func (_e *Storage_Expecter) SaveUserSettings(ctx interface{}, settings interface{}) *Storage_SaveUserSettings_Call {
+	return &Storage_SaveUserSettings_Call{Call: _e.mock.On("SaveUserSettings", ctx, settings)}
+}
+
+func (_c *Storage_SaveUserSettings_Call) Run(run func(ctx context.Context, settings *storage.UserSettings)) *Storage_SaveUserSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.UserSettings))
+	})
+	return _c
+}
+
+func (_c *Storage_SaveUserSettings_Call) Return(_a0 error) *Storage_SaveUserSettings_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_SaveUserSettings_Call) RunAndReturn(run func(context.Context, *storage.UserSettings) error) *Storage_SaveUserSettings_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -296,6 +1608,100 @@ func (_c *Storage_Update_Call) RunAndReturn(run func(context.Context, *storage.E
 	return _c
 }
 
+// UpdateCalendar provides a mock function with given fields: ctx, calendar
+func (_m *Storage) UpdateCalendar(ctx context.Context, calendar *storage.Calendar) error {
+	ret := _m.Called(ctx, calendar)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCalendar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Calendar) error); ok {
+		r0 = rf(ctx, calendar)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_UpdateCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateCalendar'
+type Storage_UpdateCalendar_Call struct {
+	*mock.Call
+}
+
+// UpdateCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - calendar *storage.Calendar
+func (_e *Storage_Expecter) UpdateCalendar(ctx interface{}, calendar interface{}) *Storage_UpdateCalendar_Call {
+	return &Storage_UpdateCalendar_Call{Call: _e.mock.On("UpdateCalendar", ctx, calendar)}
+}
+
+func (_c *Storage_UpdateCalendar_Call) Run(run func(ctx context.Context, calendar *storage.Calendar)) *Storage_UpdateCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Calendar))
+	})
+	return _c
+}
+
+func (_c *Storage_UpdateCalendar_Call) Return(_a0 error) *Storage_UpdateCalendar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_UpdateCalendar_Call) RunAndReturn(run func(context.Context, *storage.Calendar) error) *Storage_UpdateCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateResource provides a mock function with given fields: ctx, resource
+func (_m *Storage) UpdateResource(ctx context.Context, resource *storage.Resource) error {
+	ret := _m.Called(ctx, resource)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateResource")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.Resource) error); ok {
+		r0 = rf(ctx, resource)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_UpdateResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateResource'
+type Storage_UpdateResource_Call struct {
+	*mock.Call
+}
+
+// UpdateResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resource *storage.Resource
+func (_e *Storage_Expecter) UpdateResource(ctx interface{}, resource interface{}) *Storage_UpdateResource_Call {
+	return &Storage_UpdateResource_Call{Call: _e.mock.On("UpdateResource", ctx, resource)}
+}
+
+func (_c *Storage_UpdateResource_Call) Run(run func(ctx context.Context, resource *storage.Resource)) *Storage_UpdateResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.Resource))
+	})
+	return _c
+}
+
+func (_c *Storage_UpdateResource_Call) Return(_a0 error) *Storage_UpdateResource_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_UpdateResource_Call) RunAndReturn(run func(context.Context, *storage.Resource) error) *Storage_UpdateResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStorage(t interface {
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// CreateResource добавляет ресурс; resourceDto.UserID становится его создателем.
+func (a *App) CreateResource(ctx context.Context, resourceDto ResourceDto) (uint64, error) {
+	if err := validateResource(&resourceDto); err != nil {
+		return 0, err
+	}
+	return a.storage.CreateResource(ctx, convertResourceToModel(&resourceDto))
+}
+
+func (a *App) GetResource(ctx context.Context, resourceID uint64) (*ResourceDto, error) {
+	resource, err := a.storage.GetResource(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return convertResourceToDto(resource), nil
+}
+
+// UpdateResource меняет свойства ресурса; существующие брони сохраняются, даже если вместимость уменьшилась.
+// Менять ресурс могут его создатель и администраторы ресурсов (см. WithResourceAdmins),
+// для остальных пользователей (resourceDto.UserID) возвращается ErrNotResourceOwner.
+func (a *App) UpdateResource(ctx context.Context, resourceDto ResourceDto) error {
+	if err := validateResource(&resourceDto); err != nil {
+		return err
+	}
+	if err := a.checkResourceOwner(ctx, resourceDto.UserID, resourceDto.ID); err != nil {
+		return err
+	}
+	return a.storage.UpdateResource(ctx, convertResourceToModel(&resourceDto))
+}
+
+// DeleteResource удаляет ресурс без броней; события с ресурсом сначала нужно изменить или удалить из корзины.
+// Удалить ресурс могут его создатель и администраторы ресурсов.
+func (a *App) DeleteResource(ctx context.Context, userID uint64, resourceID uint64) error {
+	if err := a.checkResourceOwner(ctx, userID, resourceID); err != nil {
+		return err
+	}
+	return a.storage.DeleteResource(ctx, resourceID)
+}
+
+// checkResourceOwner проверяет, что пользователь создал ресурс или администрирует ресурсы.
+// Ресурсы без создателя (созданные до учёта создателей) меняют только администраторы.
+func (a *App) checkResourceOwner(ctx context.Context, userID uint64, resourceID uint64) error {
+	resource, err := a.storage.GetResource(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	if _, admin := a.resourceAdmins[userID]; admin {
+		return nil
+	}
+	if resource.UserID == 0 || resource.UserID != userID {
+		return ErrNotResourceOwner
+	}
+	return nil
+}
+
+// ListResources возвращает ресурсы, удовлетворяющие фильтру, например переговорные не меньше заданной вместимости.
+func (a *App) ListResources(ctx context.Context, filter ResourceFilter) ([]*ResourceDto, error) {
+	resources, err := a.storage.ListResources(ctx, storage.ResourceFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	resourcesDto := make([]*ResourceDto, len(resources))
+	for i, resource := range resources {
+		resourcesDto[i] = convertResourceToDto(resource)
+	}
+	return resourcesDto, nil
+}
+
+// GetResourceAvailability возвращает занятое время ресурса в периоде [startDate, endDateExclusive);
+// время вне возвращённых интервалов свободно. События, занимающие ресурс, не раскрываются.
+func (a *App) GetResourceAvailability(
+	ctx context.Context,
+	resourceID uint64,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*BusyIntervalDto, error) {
+	if !endDateExclusive.After(startDate) {
+		return nil, ErrNotValidPeriod
+	}
+	if _, err := a.storage.GetResource(ctx, resourceID); err != nil {
+		return nil, err
+	}
+	intervals, err := a.storage.ListResourceBusy(ctx, resourceID, startDate, endDateExclusive)
+	if err != nil {
+		return nil, err
+	}
+	intervalsDto := make([]*BusyIntervalDto, len(intervals))
+	for i, interval := range intervals {
+		intervalsDto[i] = &BusyIntervalDto{StartDate: interval.StartDate, EndDate: interval.EndDate}
+	}
+	return intervalsDto, nil
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResources(t *testing.T) {
+	t.Parallel()
+
+	userID := uint64(12345)
+	resource := storage.Resource{ID: 3, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: storage.ResourceAttributes{"floor": "2"}}
+	resourceDto := ResourceDto{ID: 3, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: map[string]string{"floor": "2"}}
+
+	ctx := context.Background()
+
+	t.Run("create resource", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		resourceDto := resourceDto
+		resourceDto.ID = 0
+		resource := resource
+		resource.ID = 0
+
+		mockedStorage.EXPECT().CreateResource(ctx, &resource).Return(3, nil)
+
+		resourceID, err := app.CreateResource(ctx, resourceDto)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), resourceID)
+	})
+
+	t.Run("create not valid resource", func(t *testing.T) {
+		app := New(mocks.NewLogger(t), mocks.NewStorage(t))
+
+		resourceDto := resourceDto
+		resourceDto.Name = ""
+		resourceDto.Capacity = -1
+
+		_, err := app.CreateResource(ctx, resourceDto)
+		require.ErrorIs(t, err, ErrNotValidResource)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		require.Equal(t, validator.ValidationErrors{
+			{Field: "name", Err: validator.ErrStringTooShort},
+			{Field: "capacity", Err: validator.ErrIntMin},
+		}, validationErrs)
+	})
+
+	t.Run("update and delete own resource", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		resource := resource
+		resource.UserID = userID
+		resourceDto := resourceDto
+		resourceDto.UserID = userID
+		resourceDto.Capacity = 12
+		updated := resource
+		updated.Capacity = 12
+
+		mockedStorage.EXPECT().GetResource(ctx, resource.ID).Return(&resource, nil)
+		mockedStorage.EXPECT().UpdateResource(ctx, &updated).Return(nil)
+		mockedStorage.EXPECT().DeleteResource(ctx, resource.ID).Return(nil)
+
+		require.NoError(t, app.UpdateResource(ctx, resourceDto))
+		require.NoError(t, app.DeleteResource(ctx, userID, resource.ID))
+	})
+
+	t.Run("update and delete resource of another user", func(t *testing.T) {
+		for name, ownerID := range map[string]uint64{"other creator": userID + 1, "no creator": 0} {
+			t.Run(name, func(t *testing.T) {
+				mockedStorage := mocks.NewStorage(t)
+				app := New(mocks.NewLogger(t), mockedStorage)
+
+				resource := resource
+				resource.UserID = ownerID
+				resourceDto := resourceDto
+				resourceDto.UserID = userID
+
+				mockedStorage.EXPECT().GetResource(ctx, resource.ID).Return(&resource, nil)
+
+				require.ErrorIs(t, app.UpdateResource(ctx, resourceDto), ErrNotResourceOwner)
+				require.ErrorIs(t, app.DeleteResource(ctx, userID, resource.ID), ErrNotResourceOwner)
+			})
+		}
+	})
+
+	t.Run("resource admin updates and deletes any resource", func(t *testing.T) {
+		adminID := uint64(1)
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage).WithResourceAdmins([]uint64{adminID})
+
+		resource := resource
+		resource.UserID = userID
+		resourceDto := resourceDto
+		resourceDto.UserID = adminID
+		updated := resource
+		updated.UserID = adminID
+
+		mockedStorage.EXPECT().GetResource(ctx, resource.ID).Return(&resource, nil)
+		mockedStorage.EXPECT().UpdateResource(ctx, &updated).Return(nil)
+		mockedStorage.EXPECT().DeleteResource(ctx, resource.ID).Return(nil)
+
+		require.NoError(t, app.UpdateResource(ctx, resourceDto))
+		require.NoError(t, app.DeleteResource(ctx, adminID, resource.ID))
+	})
+
+	t.Run("list resources", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		resource := resource
+		mockedStorage.EXPECT().ListResources(ctx, storage.ResourceFilter{Kind: "room", MinCapacity: 5}).
+			Return([]*storage.Resource{&resource}, nil)
+
+		resources, err := app.ListResources(ctx, ResourceFilter{Kind: "room", MinCapacity: 5})
+		require.NoError(t, err)
+		require.Equal(t, []*ResourceDto{&resourceDto}, resources)
+	})
+
+	t.Run("resource availability", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		start := getTime(t, "2024-07-06 00:00:00")
+		end := getTime(t, "2024-07-07 00:00:00")
+		interval := storage.BusyInterval{StartDate: getTime(t, "2024-07-06 10:00:00"), EndDate: getTime(t, "2024-07-06 12:00:00")}
+
+		resource := resource
+		mockedStorage.EXPECT().GetResource(ctx, resource.ID).Return(&resource, nil)
+		mockedStorage.EXPECT().ListResourceBusy(ctx, resource.ID, start, end).Return([]*storage.BusyInterval{&interval}, nil)
+
+		busy, err := app.GetResourceAvailability(ctx, resource.ID, start, end)
+		require.NoError(t, err)
+		require.Equal(t, []*BusyIntervalDto{{StartDate: interval.StartDate, EndDate: interval.EndDate}}, busy)
+
+		_, err = app.GetResourceAvailability(ctx, resource.ID, end, start)
+		require.ErrorIs(t, err, ErrNotValidPeriod)
+	})
+
+	t.Run("create event with busy resources", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		app := New(mocks.NewLogger(t), mockedStorage)
+
+		calendar := storage.NewDefaultCalendar(userID)
+		calendar.ID = 1
+		eventDto := EventDto{
+			Title:       "meeting",
+			StartDate:   getTime(t, "2024-07-06 10:00:00"),
+			EndDate:     getTime(t, "2024-07-06 12:00:00"),
+			UserID:      userID,
+			ResourceIDs: []uint64{5, 3, 5},
+		}
+		// ресурсы события упорядочиваются и не повторяются
+		event := storage.Event{
+			Title:       "meeting",
+			StartDate:   eventDto.StartDate,
+			EndDate:     eventDto.EndDate,
+			UserID:      userID,
+			CalendarID:  calendar.ID,
+			ResourceIDs: storage.ResourceIDs{3, 5},
+		}
+
+		mockedStorage.EXPECT().GetDefaultCalendar(ctx, userID).Return(calendar, nil)
+		mockedStorage.EXPECT().GetUserSettings(ctx, userID).Return(storage.DefaultUserSettings(userID), nil)
+		mockedStorage.EXPECT().Create(ctx, &event).Return(0, &storage.ResourceBusyError{ResourceIDs: []uint64{5}})
+
+		_, _, _, err := app.Create(ctx, eventDto)
+		var resourceBusyErr *storage.ResourceBusyError
+		require.ErrorAs(t, err, &resourceBusyErr)
+		require.Equal(t, []uint64{5}, resourceBusyErr.ResourceIDs)
+	})
+}
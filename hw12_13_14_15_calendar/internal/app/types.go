@@ -1,41 +1,338 @@
 package app
 
 import (
+	"slices"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 )
 
+// EventDto - событие; правила тэга validate проверяются в App.Create и App.Update.
 type EventDto struct {
+	ID           uint64          `json:"id"`
+	Title        string          `json:"title" validate:"minlen:1|maxlen:256"`
+	StartDate    time.Time       `json:"startDate" validate:"required"`
+	EndDate      time.Time       `json:"endDate" validate:"required|after:StartDate"`
+	Description  string          `json:"description"`
+	UserID       uint64          `json:"userId"`
+	CalendarID   uint64          `json:"calendarId"`
+	NotifyBefore *time.Duration  `json:"notifyBefore,omitempty" validate:"min:0"` // nil - не задано, 0 - без напоминания
+	Transparent  bool            `json:"transparent"`
+	DeletedAt    *time.Time      `json:"deletedAt,omitempty"`
+	Location     *LocationDto    `json:"location,omitempty" validate:"nested"`
+	Category     string          `json:"category" validate:"maxlen:64"`
+	Tags         []string        `json:"tags,omitempty" validate:"minlen:1|maxlen:64"`
+	Attachments  []AttachmentDto `json:"attachments,omitempty" validate:"nested"`
+	ResourceIDs  []uint64        `json:"resourceIds,omitempty"`
+}
+
+// LocationDto - место события: произвольный текст и необязательные координаты.
+type LocationDto struct {
+	Text string       `json:"text" validate:"maxlen:512"`
+	Geo  *GeoPointDto `json:"geo,omitempty" validate:"nested"`
+}
+
+type GeoPointDto struct {
+	Lat float64 `json:"lat" validate:"min:-90|max:90"`
+	Lon float64 `json:"lon" validate:"min:-180|max:180"`
+}
+
+// AttachmentDto - ссылка на вложение; календарь хранит только ссылку и метаданные файла.
+type AttachmentDto struct {
+	URL      string `json:"url" validate:"required|uri|maxlen:2048"`
+	Name     string `json:"name,omitempty" validate:"maxlen:256"`
+	Size     int64  `json:"size,omitempty" validate:"min:0"`
+	MimeType string `json:"mimeType,omitempty" validate:"regexp:^([\\w.+-]+/[\\w.+-]+)?$"`
+}
+
+// EventFilter - условия отбора событий в ListForDay, ListForWeek и ListForMonth;
+// пустые условия не ограничивают выборку, а событие должно содержать все теги из Tags.
+type EventFilter struct {
+	CalendarIDs []uint64
+	Category    string
+	Tags        []string
+}
+
+// CalendarDto - календарь; правила тэга validate проверяются в App.CreateCalendar и App.UpdateCalendar.
+type CalendarDto struct {
 	ID           uint64        `json:"id"`
-	Title        string        `json:"title"`
-	StartDate    time.Time     `json:"startDate"`
-	EndDate      time.Time     `json:"endDate"`
-	Description  string        `json:"description"`
 	UserID       uint64        `json:"userId"`
-	NotifyBefore time.Duration `json:"notifyBefore"`
+	Name         string        `json:"name" validate:"minlen:1|maxlen:256"`
+	Color        string        `json:"color" validate:"regexp:^(#[0-9a-fA-F]{6})?$"`
+	NotifyBefore time.Duration `json:"notifyBefore" validate:"min:0"`
+	IsDefault    bool          `json:"isDefault"`
+	// SharedWith - пользователи, которым календарь открыт на чтение; список видит только владелец календаря.
+	SharedWith []uint64 `json:"sharedWith,omitempty"`
+}
+
+// ResourceDto - бронируемый ресурс; правила тэга validate проверяются в App.CreateResource и App.UpdateResource.
+// UserID - создатель ресурса, при изменении - пользователь, который его меняет.
+type ResourceDto struct {
+	ID         uint64            `json:"id"`
+	UserID     uint64            `json:"userId"`
+	Name       string            `json:"name" validate:"minlen:1|maxlen:256"`
+	Kind       string            `json:"kind" validate:"maxlen:64"`
+	Capacity   int               `json:"capacity" validate:"min:0"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// ResourceFilter - условия отбора ресурсов в ListResources; пустые условия не ограничивают выборку.
+type ResourceFilter struct {
+	Kind        string
+	MinCapacity int
+}
+
+// BusyIntervalDto - время, на которое ресурс забронирован; в FreeBusyDto - занятое или свободное время пользователя.
+type BusyIntervalDto struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+// FreeBusyDto - занятость пользователя в периоде: Busy - время непрозрачных событий, OutOfOffice - периоды
+// отсутствия с сообщением, Free - рабочее время вне событий и отсутствия. Интервалы обрезаны по периоду.
+type FreeBusyDto struct {
+	Busy        []*BusyIntervalDto `json:"busy"`
+	OutOfOffice []OutOfOfficeDto   `json:"outOfOffice"`
+	Free        []*BusyIntervalDto `json:"free"`
+}
+
+// UserSettingsDto - настройки пользователя; правила тэга validate проверяются в App.UpdateUserSettings.
+type UserSettingsDto struct {
+	UserID        uint64 `json:"userId"`
+	OverlapPolicy string `json:"overlapPolicy"`
+	// TimeZone - часовой пояс рабочих часов и сводок в формате IANA, например Europe/Moscow; пустая строка - UTC.
+	TimeZone     string               `json:"timeZone" validate:"maxlen:64"`
+	WorkingHours []WorkingIntervalDto `json:"workingHours,omitempty" validate:"nested"`
+	OutOfOffice  []OutOfOfficeDto     `json:"outOfOffice,omitempty" validate:"nested"`
+	// AvailabilityPolicy - политика для событий вне рабочих часов и в периоды отсутствия; пустая строка - ALLOW.
+	AvailabilityPolicy string `json:"availabilityPolicy" validate:"in:REJECT,WARN,ALLOW"`
+	// Digest - сводка событий на день (DAILY) или на неделю (WEEKLY) в часовом поясе TimeZone; пустая строка - NONE.
+	Digest string `json:"digest" validate:"in:NONE,DAILY,WEEKLY"`
+}
+
+// WorkingIntervalDto - рабочее время в день недели (0 - воскресенье); Start и End в формате ЧЧ:ММ, End может быть 24:00.
+type WorkingIntervalDto struct {
+	Weekday int    `json:"weekday" validate:"min:0|max:6"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// OutOfOfficeDto - период отсутствия; Message возвращается в ошибке или предупреждении OUT_OF_OFFICE
+// при создании события в этот период. Автоотклонения приглашений нет: у событий нет участников.
+type OutOfOfficeDto struct {
+	StartDate time.Time `json:"startDate" validate:"required"`
+	EndDate   time.Time `json:"endDate" validate:"required|after:StartDate"`
+	Message   string    `json:"message" validate:"required|maxlen:512"`
+}
+
+// WarningDto - предупреждение о событии, сохранённом вопреки настройкам доступности пользователя.
+type WarningDto struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	WarningOutsideWorkingHours = "OUTSIDE_WORKING_HOURS"
+	WarningOutOfOffice         = "OUT_OF_OFFICE"
+)
+
+type AuditRecordDto struct {
+	ID        uint64        `json:"id"`
+	EventID   uint64        `json:"eventId"`
+	ActorID   uint64        `json:"actorId"`
+	Action    string        `json:"action"`
+	Changes   []FieldChange `json:"changes"`
+	Source    string        `json:"source"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
 }
 
 func convertEventToModel(dto *EventDto) *storage.Event {
-	return &storage.Event{
-		ID:           dto.ID,
-		Title:        dto.Title,
-		StartDate:    dto.StartDate,
-		EndDate:      dto.EndDate,
-		Description:  dto.Description,
-		UserID:       dto.UserID,
-		NotifyBefore: dto.NotifyBefore,
+	event := &storage.Event{
+		ID:          dto.ID,
+		Title:       dto.Title,
+		StartDate:   dto.StartDate,
+		EndDate:     dto.EndDate,
+		Description: dto.Description,
+		UserID:      dto.UserID,
+		CalendarID:  dto.CalendarID,
+		Transparent: dto.Transparent,
+		Category:    dto.Category,
+		Tags:        dto.Tags,
+		ResourceIDs: normalizeIDs(dto.ResourceIDs),
 	}
+	if dto.NotifyBefore != nil {
+		event.NotifyBefore = *dto.NotifyBefore
+	}
+	if dto.Location != nil {
+		event.Location = dto.Location.Text
+		if dto.Location.Geo != nil {
+			event.Latitude = &dto.Location.Geo.Lat
+			event.Longitude = &dto.Location.Geo.Lon
+		}
+	}
+	for _, attachment := range dto.Attachments {
+		event.Attachments = append(event.Attachments, storage.Attachment(attachment))
+	}
+	return event
 }
 
 func convertEventToDto(model *storage.Event) *EventDto {
-	return &EventDto{
+	notifyBefore := model.NotifyBefore
+	dto := &EventDto{
 		ID:           model.ID,
 		Title:        model.Title,
 		StartDate:    model.StartDate,
 		EndDate:      model.EndDate,
 		Description:  model.Description,
 		UserID:       model.UserID,
+		CalendarID:   model.CalendarID,
+		NotifyBefore: &notifyBefore,
+		Transparent:  model.Transparent,
+		DeletedAt:    model.DeletedAt,
+		Category:     model.Category,
+		Tags:         model.Tags,
+		ResourceIDs:  model.ResourceIDs,
+	}
+	if model.Location != "" || model.Latitude != nil {
+		dto.Location = &LocationDto{Text: model.Location}
+		if model.Latitude != nil && model.Longitude != nil {
+			dto.Location.Geo = &GeoPointDto{Lat: *model.Latitude, Lon: *model.Longitude}
+		}
+	}
+	for _, attachment := range model.Attachments {
+		dto.Attachments = append(dto.Attachments, AttachmentDto(attachment))
+	}
+	return dto
+}
+
+func convertEventsToDto(models []*storage.Event) []*EventDto {
+	eventsDto := make([]*EventDto, len(models))
+	for i, event := range models {
+		eventsDto[i] = convertEventToDto(event)
+	}
+	return eventsDto
+}
+
+func copyEvent(event *storage.Event) *storage.Event {
+	eventCopy := *event
+	return &eventCopy
+}
+
+func convertAuditRecordToDto(model *storage.AuditRecord) *AuditRecordDto {
+	changes := make([]FieldChange, len(model.Changes))
+	for i, change := range model.Changes {
+		changes[i] = FieldChange(change)
+	}
+	return &AuditRecordDto{
+		ID:        model.ID,
+		EventID:   model.EventID,
+		ActorID:   model.ActorID,
+		Action:    string(model.Action),
+		Changes:   changes,
+		Source:    model.Source,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func convertUserSettingsToModel(dto *UserSettingsDto) *storage.UserSettings {
+	settings := &storage.UserSettings{
+		UserID:             dto.UserID,
+		OverlapPolicy:      storage.OverlapPolicy(dto.OverlapPolicy),
+		TimeZone:           dto.TimeZone,
+		AvailabilityPolicy: storage.OverlapPolicy(dto.AvailabilityPolicy),
+		Digest:             storage.DigestPeriod(dto.Digest),
+	}
+	for _, interval := range dto.WorkingHours {
+		settings.WorkingHours = append(settings.WorkingHours, storage.WorkingInterval{
+			Weekday: time.Weekday(interval.Weekday),
+			Start:   interval.Start,
+			End:     interval.End,
+		})
+	}
+	for _, period := range dto.OutOfOffice {
+		settings.OutOfOffice = append(settings.OutOfOffice, storage.OutOfOfficePeriod(period))
+	}
+	return settings
+}
+
+func convertUserSettingsToDto(model *storage.UserSettings) *UserSettingsDto {
+	settings := &UserSettingsDto{
+		UserID:             model.UserID,
+		OverlapPolicy:      string(model.OverlapPolicy),
+		TimeZone:           model.TimeZone,
+		AvailabilityPolicy: string(model.AvailabilityPolicy),
+		Digest:             string(model.Digest),
+	}
+	for _, interval := range model.WorkingHours {
+		settings.WorkingHours = append(settings.WorkingHours, WorkingIntervalDto{
+			Weekday: int(interval.Weekday),
+			Start:   interval.Start,
+			End:     interval.End,
+		})
+	}
+	for _, period := range model.OutOfOffice {
+		settings.OutOfOffice = append(settings.OutOfOffice, OutOfOfficeDto(period))
+	}
+	return settings
+}
+
+func convertCalendarToModel(dto *CalendarDto) *storage.Calendar {
+	return &storage.Calendar{
+		ID:           dto.ID,
+		UserID:       dto.UserID,
+		Name:         dto.Name,
+		Color:        dto.Color,
+		NotifyBefore: dto.NotifyBefore,
+		SharedWith:   storage.CalendarShares(dto.SharedWith),
+	}
+}
+
+func convertCalendarToDto(model *storage.Calendar) *CalendarDto {
+	return &CalendarDto{
+		ID:           model.ID,
+		UserID:       model.UserID,
+		Name:         model.Name,
+		Color:        model.Color,
 		NotifyBefore: model.NotifyBefore,
+		IsDefault:    model.IsDefault,
+		SharedWith:   model.SharedWith,
+	}
+}
+
+// сортирует идентификаторы и удаляет повторы; пустой список заменяется на nil.
+func normalizeIDs(ids []uint64) []uint64 {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := slices.Clone(ids)
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+func convertResourceToModel(dto *ResourceDto) *storage.Resource {
+	return &storage.Resource{
+		ID:         dto.ID,
+		UserID:     dto.UserID,
+		Name:       dto.Name,
+		Kind:       dto.Kind,
+		Capacity:   dto.Capacity,
+		Attributes: dto.Attributes,
+	}
+}
+
+func convertResourceToDto(model *storage.Resource) *ResourceDto {
+	return &ResourceDto{
+		ID:         model.ID,
+		UserID:     model.UserID,
+		Name:       model.Name,
+		Kind:       model.Kind,
+		Capacity:   model.Capacity,
+		Attributes: model.Attributes,
 	}
 }
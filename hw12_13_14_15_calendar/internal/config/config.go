@@ -0,0 +1,71 @@
+// Package config - общие для сервисов календаря загрузка конфигурации из файла и окружения
+// и вывод действующей конфигурации. Сами структуры конфигурации и их проверки остаются в пакетах main.
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Load читает файл path в out. В значениях подставляются переменные окружения вида ${VAR},
+// а любое поле можно переопределить переменной PREFIX_SECTION_KEY, например CALENDAR_DATABASE_URI
+// (имена - по mapstructure-тэгам в верхнем регистре).
+func Load(path string, envPrefix string, out any) error {
+	parser := viper.New()
+	parser.SetConfigFile(path)
+	parser.SetEnvPrefix(envPrefix)
+	parser.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	err := parser.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	// явная привязка нужна, чтобы переопределялись и поля, которых нет в файле
+	for _, key := range keys(reflect.TypeOf(out), "") {
+		if err := parser.BindEnv(key); err != nil {
+			return fmt.Errorf("cannot bind env for %s: %w", key, err)
+		}
+	}
+
+	for _, key := range parser.AllKeys() {
+		value := parser.GetString(key)
+		if expanded := os.ExpandEnv(value); expanded != value {
+			parser.Set(key, expanded)
+		}
+	}
+
+	err = parser.Unmarshal(out)
+	if err != nil {
+		return fmt.Errorf("cannot parse config file: %w", err)
+	}
+	return nil
+}
+
+// ключи конфигурации по mapstructure-тэгам: вложенные структуры - через точку, map не раскрываются.
+func keys(t reflect.Type, prefix string) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var result []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		switch field.Type.Kind() { //nolint:exhaustive
+		case reflect.Struct:
+			result = append(result, keys(field.Type, prefix+name+".")...)
+		case reflect.Map:
+			// ключи map задаются только в файле
+		default:
+			result = append(result, prefix+name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	// пароль в DSN вида "host=localhost password=secret"
+	reDSNPassword = regexp.MustCompile(`(password=)\S+`)
+)
+
+// как в url.URL.Redacted.
+const redacted = "xxxxx"
+
+// Print выводит конфигурацию в YAML с ключами по mapstructure-тэгам, в порядке полей структуры.
+// В строках с тэгом redact:"uri" скрывается пароль (в URI или в DSN вида key=value).
+func Print(w io.Writer, cfg any) error {
+	node := encode(reflect.ValueOf(cfg), "")
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("cannot print config: %w", err)
+	}
+	return encoder.Close()
+}
+
+func encode(value reflect.Value, redact string) *yaml.Node {
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Type() == durationType:
+		return scalar(time.Duration(value.Int()).String(), "!!str")
+	case value.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := field.Tag.Get("mapstructure")
+			if name == "" || name == "-" {
+				continue
+			}
+			node.Content = append(node.Content, scalar(name, "!!str"), encode(value.Field(i), field.Tag.Get("redact")))
+		}
+		return node
+	case value.Kind() == reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		mapKeys := value.MapKeys()
+		sort.Slice(mapKeys, func(i, j int) bool { return mapKeys[i].String() < mapKeys[j].String() })
+		for _, key := range mapKeys {
+			node.Content = append(node.Content, scalar(key.String(), "!!str"), encode(value.MapIndex(key), ""))
+		}
+		return node
+	case value.Kind() == reflect.Slice:
+		// списки выводятся в одну строку, как в файлах configs: [1, 2]
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < value.Len(); i++ {
+			node.Content = append(node.Content, encode(value.Index(i), ""))
+		}
+		return node
+	case value.Kind() == reflect.String:
+		str := value.String()
+		if redact == "uri" {
+			str = redactURI(str)
+		}
+		return scalar(str, "!!str")
+	case value.Kind() == reflect.Bool:
+		return scalar(strconv.FormatBool(value.Bool()), "")
+	case value.CanInt():
+		return scalar(strconv.FormatInt(value.Int(), 10), "")
+	case value.CanUint():
+		return scalar(strconv.FormatUint(value.Uint(), 10), "")
+	case value.CanFloat():
+		return scalar(strconv.FormatFloat(value.Float(), 'g', -1, 64), "")
+	default:
+		return scalar(fmt.Sprint(value.Interface()), "!!str")
+	}
+}
+
+// scalar с пустым тэгом выводится как есть, без явного указания типа (числа, bool).
+func scalar(value string, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func redactURI(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return reDSNPassword.ReplaceAllString(uri, "${1}"+redacted)
+}
+
+// Validator - конфигурация, которая умеет себя проверять.
+type Validator interface {
+	Validate() error
+}
+
+// Inspect обрабатывает флаги --print-config и --check-config: при printCfg выводит конфигурацию в stdout,
+// затем проверяет её и пишет результат в stderr. Возвращает код завершения процесса.
+func Inspect(stdout io.Writer, stderr io.Writer, cfg Validator, printCfg bool) int {
+	if printCfg {
+		if err := Print(stdout, cfg); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "config is not valid:\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintln(stderr, "config is valid")
+	return 0
+}
@@ -0,0 +1,22 @@
+package lifecycle
+
+import "sync/atomic"
+
+// InFlight считает выполняющиеся операции (запросы, обработку уведомлений),
+// чтобы при остановке сообщить, сколько было прервано.
+type InFlight struct {
+	count atomic.Int64
+}
+
+// Start отмечает начало операции и возвращает функцию, отмечающую её завершение.
+func (f *InFlight) Start() func() {
+	f.count.Add(1)
+	return func() {
+		f.count.Add(-1)
+	}
+}
+
+// Count возвращает количество выполняющихся операций.
+func (f *InFlight) Count() int64 {
+	return f.count.Load()
+}
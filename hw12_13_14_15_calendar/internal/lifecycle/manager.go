@@ -0,0 +1,125 @@
+// Package lifecycle - упорядоченная остановка сервисов: каждый этап (серверы, обработчики, очередь, БД)
+// получает свой срок, а этапы, не уложившиеся в него, прерываются и попадают в отчёт об остановке.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout - общий срок остановки, если он не задан в конфигурации.
+const DefaultTimeout = 30 * time.Second
+
+// ErrCutOff - этап остановки не завершился в отведённое время.
+var ErrCutOff = errors.New("shutdown stage cut off")
+
+type Logger interface {
+	Info(ctx context.Context, msg string, args ...any)
+	Warn(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, err error, msg string, args ...any)
+}
+
+// StopFunc останавливает компонент. Функция должна завершиться к отмене ctx;
+// если она этого не делает, менеджер перестаёт её ждать.
+type StopFunc func(ctx context.Context) error
+
+// Manager хранит этапы остановки. Этапы выполняются в порядке, обратном регистрации,
+// как defer: компонент, запущенный последним, останавливается первым.
+type Manager struct {
+	logger  Logger
+	timeout time.Duration
+	mu      sync.Mutex
+	stages  []stage
+}
+
+type stage struct {
+	name    string
+	timeout time.Duration
+	stop    StopFunc
+}
+
+// New создаёт менеджер; timeout - общий срок остановки всех этапов (0 - DefaultTimeout).
+func New(logger Logger, timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Manager{
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Add регистрирует этап остановки. timeout - срок этапа; 0 - до истечения общего срока.
+func (m *Manager) Add(name string, timeout time.Duration, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stages = append(m.stages, stage{name: name, timeout: timeout, stop: stop})
+}
+
+// Shutdown выполняет этапы остановки. Ошибка объединяет ошибки этапов;
+// для прерванных по сроку этапов она содержит ErrCutOff или context.DeadlineExceeded.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	stages := make([]stage, len(m.stages))
+	copy(stages, m.stages)
+	m.mu.Unlock()
+
+	m.logger.Info(ctx, "shutting down", "stages", len(stages), "timeout", m.timeout)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	var errs []error
+	var cutOff []string
+	for i := len(stages) - 1; i >= 0; i-- {
+		st := stages[i]
+		err := m.runStage(ctx, st)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", st.name, err))
+		if errors.Is(err, ErrCutOff) || errors.Is(err, context.DeadlineExceeded) {
+			cutOff = append(cutOff, st.name)
+		}
+	}
+
+	if len(errs) > 0 {
+		err := errors.Join(errs...)
+		m.logger.Error(ctx, err, "shutdown finished with errors", "cutOff", cutOff, "elapsed", time.Since(start))
+		return err
+	}
+	m.logger.Info(ctx, "shutdown finished", "elapsed", time.Since(start))
+	return nil
+}
+
+func (m *Manager) runStage(ctx context.Context, st stage) error {
+	if st.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, st.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- st.stop(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("%w: %w", ErrCutOff, ctx.Err())
+	}
+
+	if err != nil {
+		m.logger.Warn(ctx, "shutdown stage failed", "stage", st.name, "error", err.Error(), "elapsed", time.Since(start))
+		return err
+	}
+	m.logger.Info(ctx, "shutdown stage finished", "stage", st.name, "elapsed", time.Since(start))
+	return nil
+}
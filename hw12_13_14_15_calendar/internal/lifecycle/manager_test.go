@@ -0,0 +1,97 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct{}
+
+func (l *testLogger) Info(context.Context, string, ...any)         {}
+func (l *testLogger) Warn(context.Context, string, ...any)         {}
+func (l *testLogger) Error(context.Context, error, string, ...any) {}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("stop in reverse order", func(t *testing.T) {
+		manager := New(&testLogger{}, time.Second)
+
+		var stopped []string
+		for _, name := range []string{"storage", "queue", "server"} {
+			manager.Add(name, 0, func(context.Context) error {
+				stopped = append(stopped, name)
+				return nil
+			})
+		}
+
+		require.NoError(t, manager.Shutdown(ctx))
+		require.Equal(t, []string{"server", "queue", "storage"}, stopped)
+	})
+
+	t.Run("cut off stage by its timeout", func(t *testing.T) {
+		manager := New(&testLogger{}, time.Second)
+
+		storageStopped := false
+		manager.Add("storage", 0, func(context.Context) error {
+			storageStopped = true
+			return nil
+		})
+		// этап не реагирует на отмену контекста
+		manager.Add("server", 10*time.Millisecond, func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		err := manager.Shutdown(ctx)
+		require.ErrorIs(t, err, ErrCutOff)
+		require.ErrorContains(t, err, "server")
+		require.True(t, storageStopped)
+	})
+
+	t.Run("stage respects deadline", func(t *testing.T) {
+		manager := New(&testLogger{}, 10*time.Millisecond)
+
+		manager.Add("server", 0, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		err := manager.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("join stage errors", func(t *testing.T) {
+		manager := New(&testLogger{}, 0)
+		require.Equal(t, DefaultTimeout, manager.timeout)
+
+		errStorage := errors.New("storage error")
+		errQueue := errors.New("queue error")
+		manager.Add("storage", 0, func(context.Context) error { return errStorage })
+		manager.Add("queue", 0, func(context.Context) error { return errQueue })
+
+		err := manager.Shutdown(ctx)
+		require.ErrorIs(t, err, errStorage)
+		require.ErrorIs(t, err, errQueue)
+		require.NotErrorIs(t, err, ErrCutOff)
+	})
+}
+
+func TestInFlight(t *testing.T) {
+	t.Parallel()
+
+	var inFlight InFlight
+	done := inFlight.Start()
+	done2 := inFlight.Start()
+	require.Equal(t, int64(2), inFlight.Count())
+
+	done()
+	done2()
+	require.Equal(t, int64(0), inFlight.Count())
+}
@@ -9,22 +9,40 @@ import (
 
 type Logger struct {
 	logger *slog.Logger
+	level  *slog.LevelVar
 }
 
 func New(level string) (*Logger, error) {
-	var slogLevel slog.Level
-	if err := slogLevel.UnmarshalText([]byte(level)); err != nil {
-		return nil, fmt.Errorf("cannot parse logger level: %w", err)
+	slogLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
 	}
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(slogLevel)
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slogLevel,
+		Level: levelVar,
 	}))
 	slog.SetDefault(logger)
 	return &Logger{
 		logger: logger,
+		level:  levelVar,
 	}, nil
 }
 
+// ParseLevel разбирает уровень логирования (DEBUG, INFO, WARN, ERROR).
+func ParseLevel(level string) (slog.Level, error) {
+	var slogLevel slog.Level
+	if err := slogLevel.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("cannot parse logger level: %w", err)
+	}
+	return slogLevel, nil
+}
+
+// SetLevel меняет уровень логирования на лету.
+func (l Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
 func (l Logger) Debug(_ context.Context, msg string, args ...any) {
 	l.logger.Debug(msg, args...)
 }
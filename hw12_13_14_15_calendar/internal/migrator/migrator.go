@@ -0,0 +1,103 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	_ "github.com/jackc/pgx/v4/stdlib" // for postgres
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	_ "modernc.org/sqlite" // for sqlite
+)
+
+var ErrUnsupportedDriver = errors.New("unsupported database driver")
+
+// Migrator применяет встроенные миграции к БД.
+type Migrator struct {
+	provider   *goose.Provider
+	driverName string
+	dsn        string
+}
+
+func New(driverName string, dsn string) *Migrator {
+	return &Migrator{
+		driverName: driverName,
+		dsn:        dsn,
+	}
+}
+
+func (m *Migrator) Connect(ctx context.Context) (err error) {
+	var (
+		dialect goose.Dialect
+		fsys    fs.FS
+		opts    []goose.ProviderOption
+	)
+	switch m.driverName {
+	case "pgx":
+		dialect, fsys = goose.DialectPostgres, migrations.Postgres()
+		// advisory lock не даёт нескольким репликам применять миграции одновременно;
+		// ожидание блокировки - до 5 минут
+		locker, err := lock.NewPostgresSessionLocker(lock.WithLockTimeout(5, 60))
+		if err != nil {
+			return fmt.Errorf("cannot create migrations lock: %w", err)
+		}
+		opts = append(opts, goose.WithSessionLocker(locker))
+	case "sqlite":
+		// SQLite сам блокирует файл БД на время записи
+		dialect, fsys = goose.DialectSQLite3, migrations.SQLite()
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.driverName)
+	}
+
+	db, err := sql.Open(m.driverName, m.dsn)
+	if err != nil {
+		return fmt.Errorf("cannot open db: %w", err)
+	}
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+
+	m.provider, err = goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("cannot create migrations provider: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) Close(_ context.Context) error {
+	return m.provider.Close()
+}
+
+// Up применяет все новые миграции.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down откатывает последнюю миграцию.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// Redo откатывает и заново применяет последнюю миграцию.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+	up, err := m.provider.UpByOne(ctx)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// Status возвращает состояние всех миграций.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
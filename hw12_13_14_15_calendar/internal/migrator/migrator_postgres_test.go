@@ -0,0 +1,74 @@
+//go:build integration
+// +build integration
+
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratorPostgres(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("up and down round trip", func(t *testing.T) {
+		m := New("pgx", storagetest.PostgresDSN(t))
+		require.NoError(t, m.Connect(ctx))
+		defer m.Close(ctx)
+
+		requireRoundTrip(ctx, t, m)
+	})
+	t.Run("busy time exclusion with overlapping events", func(t *testing.T) {
+		dsn := storagetest.PostgresDSN(t)
+		m := New("pgx", dsn)
+		require.NoError(t, m.Connect(ctx))
+		defer m.Close(ctx)
+
+		_, err := m.provider.UpTo(ctx, 20240825120000)
+		require.NoError(t, err)
+
+		db, err := sql.Open("pgx", dsn)
+		require.NoError(t, err)
+		defer db.Close()
+
+		// 2 пересекается с 1, 3 - только с 2, 4 удалено, 5 прозрачное, у пользователя 2 пересечения разрешены
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO user_settings (user_id, overlap_policy) VALUES (2, 'ALLOW');
+			INSERT INTO events (event_id, title, start_date, end_date, user_id, deleted_at, transparent) VALUES
+				(1, 'a', '2024-07-06 10:00:00Z', '2024-07-06 12:00:00Z', 1, NULL, false),
+				(2, 'b', '2024-07-06 11:00:00Z', '2024-07-06 13:00:00Z', 1, NULL, false),
+				(3, 'c', '2024-07-06 12:30:00Z', '2024-07-06 14:00:00Z', 1, NULL, false),
+				(4, 'd', '2024-07-06 10:00:00Z', '2024-07-06 12:00:00Z', 1, now(), false),
+				(5, 'e', '2024-07-06 10:00:00Z', '2024-07-06 12:00:00Z', 1, NULL, true),
+				(6, 'f', '2024-07-06 10:00:00Z', '2024-07-06 12:00:00Z', 2, NULL, false),
+				(7, 'g', '2024-07-06 10:00:00Z', '2024-07-06 12:00:00Z', 2, NULL, false);
+		`)
+		require.NoError(t, err)
+
+		_, err = m.provider.UpByOne(ctx)
+		require.NoError(t, err)
+
+		rows, err := db.QueryContext(ctx, "SELECT event_id FROM events WHERE exclusive ORDER BY event_id")
+		require.NoError(t, err)
+		defer rows.Close()
+		var exclusive []uint64
+		for rows.Next() {
+			var eventID uint64
+			require.NoError(t, rows.Scan(&eventID))
+			exclusive = append(exclusive, eventID)
+		}
+		require.NoError(t, rows.Err())
+		// удалённые события ограничение не проверяет, поэтому 4 остаётся эксклюзивным
+		require.Equal(t, []uint64{1, 3, 4}, exclusive)
+
+		// остальные миграции применяются поверх исправленных данных
+		_, err = m.Up(ctx)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+package migrator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("up and down round trip", func(t *testing.T) {
+		m := New("sqlite", filepath.Join(t.TempDir(), "calendar.db"))
+		require.NoError(t, m.Connect(ctx))
+		defer m.Close(ctx)
+
+		requireRoundTrip(ctx, t, m)
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		m := New("mysql", "")
+		require.ErrorIs(t, m.Connect(ctx), ErrUnsupportedDriver)
+	})
+}
+
+func requireStates(t *testing.T, statuses []*goose.MigrationStatus, applied int) {
+	t.Helper()
+	for i, status := range statuses {
+		if i < applied {
+			require.Equal(t, goose.StateApplied, status.State)
+		} else {
+			require.Equal(t, goose.StatePending, status.State)
+		}
+	}
+}
+
+// применяет все миграции, откатывает их по одной и применяет заново.
+func requireRoundTrip(ctx context.Context, t *testing.T, m *Migrator) {
+	t.Helper()
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	total := len(statuses)
+	requireStates(t, statuses, 0)
+
+	results, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, total)
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	requireStates(t, statuses, total)
+
+	// повторное применение ничего не делает
+	results, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	results, err = m.Redo(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "down", results[0].Direction)
+	require.Equal(t, "up", results[1].Direction)
+
+	for i := total; i > 0; i-- {
+		_, err = m.Down(ctx)
+		require.NoError(t, err)
+	}
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	requireStates(t, statuses, 0)
+
+	_, err = m.Down(ctx)
+	require.ErrorIs(t, err, goose.ErrNoNextVersion)
+
+	results, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, total)
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Типы сообщений в очереди; сообщения без типа - уведомления о событиях (от старых версий планировщика).
+const (
+	MessageTypeNotification = "notification"
+	MessageTypeDigest       = "digest"
+)
+
+// Message - общая часть сообщений очереди, по которой получатель определяет их тип.
+type Message struct {
+	Type string `json:"type"`
+}
+
+// DigestDto - сводка событий пользователя на день или неделю; Subject и Body уже отрисованы по шаблонам.
+// Время - в часовом поясе пользователя, EndDate не входит в период.
+type DigestDto struct {
+	Type      string           `json:"type"`
+	UserID    uint64           `json:"userId"`
+	Period    string           `json:"period"`
+	StartDate time.Time        `json:"startDate"`
+	EndDate   time.Time        `json:"endDate"`
+	Events    []DigestEventDto `json:"events"`
+	Subject   string           `json:"subject"`
+	Body      string           `json:"body"`
+}
+
+type DigestEventDto struct {
+	EventID   uint64    `json:"eventId"`
+	Title     string    `json:"title"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Location  string    `json:"location,omitempty"`
+}
+
+func ConvertEventsToDigest(
+	settings *storage.UserSettings,
+	startDate time.Time,
+	endDate time.Time,
+	events []*storage.Event,
+) *DigestDto {
+	loc := settings.Location()
+	digest := &DigestDto{
+		Type:      MessageTypeDigest,
+		UserID:    settings.UserID,
+		Period:    string(settings.Digest),
+		StartDate: startDate.In(loc),
+		EndDate:   endDate.In(loc),
+		Events:    make([]DigestEventDto, 0, len(events)),
+	}
+	for _, event := range events {
+		digest.Events = append(digest.Events, DigestEventDto{
+			EventID:   event.ID,
+			Title:     event.Title,
+			StartDate: event.StartDate.In(loc),
+			EndDate:   event.EndDate.In(loc),
+			Location:  event.Location,
+		})
+	}
+	return digest
+}
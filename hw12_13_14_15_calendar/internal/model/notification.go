@@ -7,17 +7,49 @@ import (
 )
 
 type NotificationDto struct {
-	EventID        uint64    `json:"eventId"`
-	EventTitle     string    `json:"eventTitle"`
-	EventStartDate time.Time `json:"eventStartDate"`
-	UserID         uint64    `json:"userId"`
+	Type           string          `json:"type,omitempty"`
+	EventID        uint64          `json:"eventId"`
+	EventTitle     string          `json:"eventTitle"`
+	EventStartDate time.Time       `json:"eventStartDate"`
+	UserID         uint64          `json:"userId"`
+	Location       string          `json:"location,omitempty"`
+	Geo            *GeoPointDto    `json:"geo,omitempty"`
+	Category       string          `json:"category,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+	Attachments    []AttachmentDto `json:"attachments,omitempty"`
+}
+
+// GeoPointDto - координаты места события.
+type GeoPointDto struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// AttachmentDto - ссылка на вложение события.
+type AttachmentDto struct {
+	URL      string `json:"url"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
 }
 
 func ConvertEventToNotification(event *storage.Event) *NotificationDto {
-	return &NotificationDto{
+	notification := &NotificationDto{
+		Type:           MessageTypeNotification,
 		EventID:        event.ID,
 		EventTitle:     event.Title,
 		EventStartDate: event.StartDate,
 		UserID:         event.UserID,
+		Location:       event.Location,
+		Category:       event.Category,
+		Tags:           event.Tags,
+	}
+	// координаты хранятся только парой
+	if event.Latitude != nil && event.Longitude != nil {
+		notification.Geo = &GeoPointDto{Lat: *event.Latitude, Lon: *event.Longitude}
+	}
+	for _, attachment := range event.Attachments {
+		notification.Attachments = append(notification.Attachments, AttachmentDto(attachment))
 	}
+	return notification
 }
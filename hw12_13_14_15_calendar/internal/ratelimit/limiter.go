@@ -0,0 +1,155 @@
+// Package ratelimit - ограничение частоты запросов к API по алгоритму token bucket.
+// Корзина заводится на каждую пару «ключ клиента - маршрут»; ключ - пользователь, подтверждённый сертификатом клиента, а если его нет - IP-адрес.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// корзины, к которым не обращались дольше idleTTL, удаляются: за это время они успевают заполниться.
+	idleTTL       = 10 * time.Minute
+	cleanupPeriod = time.Minute
+)
+
+var ErrNotValidLimit = errors.New("rate limit must not be negative")
+
+// Limit - параметры корзины: Rate запросов в секунду, не более Burst запросов подряд.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limiter ограничивает частоту запросов. Маршруты без собственного лимита
+// используют общую для клиента корзину с лимитом по умолчанию.
+type Limiter struct {
+	defaultLimit Limit
+	routes       map[string]Limit
+	mu           sync.Mutex
+	buckets      map[bucketKey]*bucket
+	lastCleanup  time.Time
+	now          func() time.Time
+}
+
+type bucketKey struct {
+	key   string
+	route string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// New создаёт ограничитель. Имена маршрутов сравниваются без учёта регистра,
+// так как viper приводит ключи конфигурации к нижнему регистру.
+func New(defaultLimit Limit, routes map[string]Limit) *Limiter {
+	return &Limiter{
+		defaultLimit: defaultLimit,
+		routes:       routeLimits(routes),
+		buckets:      make(map[bucketKey]*bucket),
+		now:          time.Now,
+	}
+}
+
+// Validate проверяет, что лимит не отрицательный.
+func (l Limit) Validate() error {
+	if l.Rate < 0 || l.Burst < 0 {
+		return fmt.Errorf("%w: rate %v, burst %d", ErrNotValidLimit, l.Rate, l.Burst)
+	}
+	return nil
+}
+
+// SetLimits меняет лимиты на лету. Существующие корзины сохраняют накопленные токены,
+// а корзины маршрутов, у которых появился или пропал собственный лимит, создаются заново.
+func (l *Limiter) SetLimits(defaultLimit Limit, routes map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.defaultLimit = defaultLimit
+	l.routes = routeLimits(routes)
+
+	now := l.now()
+	for k, b := range l.buckets {
+		limit, exists := l.routes[k.route]
+		switch {
+		case k.route == "":
+			limit = l.defaultLimit
+		case !exists:
+			delete(l.buckets, k)
+			continue
+		}
+		b.limiter.SetLimitAt(now, rate.Limit(limit.Rate))
+		b.limiter.SetBurstAt(now, limit.Burst)
+	}
+}
+
+func routeLimits(routes map[string]Limit) map[string]Limit {
+	result := make(map[string]Limit, len(routes))
+	for route, limit := range routes {
+		result[strings.ToLower(route)] = limit
+	}
+	return result
+}
+
+// UserKey и IPKey формируют ключ клиента.
+func UserKey(userID string) string {
+	return "user:" + userID
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Allow расходует токен из корзины клиента для маршрута.
+// Если токенов нет, возвращает false и время, через которое запрос можно повторить.
+func (l *Limiter) Allow(key string, route string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	route = strings.ToLower(route)
+	limit, exists := l.routes[route]
+	if !exists {
+		route = ""
+		limit = l.defaultLimit
+	}
+
+	now := l.now()
+	l.cleanup(now)
+
+	k := bucketKey{key: key, route: route}
+	b, exists := l.buckets[k]
+	if !exists {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[k] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, 0
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < cleanupPeriod {
+		return
+	}
+	l.lastCleanup = now
+	for k, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, k)
+		}
+	}
+}
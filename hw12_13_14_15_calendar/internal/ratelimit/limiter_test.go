@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("burst and refill", func(t *testing.T) {
+		now := time.Date(2024, 7, 6, 10, 0, 0, 0, time.UTC)
+		limiter := New(Limit{Rate: 1, Burst: 2}, nil)
+		limiter.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			allowed, _ := limiter.Allow(UserKey("1"), "GetEvent")
+			require.True(t, allowed)
+		}
+		allowed, retryAfter := limiter.Allow(UserKey("1"), "GetEvent")
+		require.False(t, allowed)
+		require.Equal(t, time.Second, retryAfter)
+
+		// отклонённый запрос не расходует токены
+		now = now.Add(time.Second)
+		allowed, _ = limiter.Allow(UserKey("1"), "GetEvent")
+		require.True(t, allowed)
+	})
+
+	t.Run("keys and routes are limited separately", func(t *testing.T) {
+		limiter := New(Limit{Rate: 1, Burst: 1}, map[string]Limit{"CreateEvent": {Rate: 1, Burst: 1}})
+
+		allowed, _ := limiter.Allow(UserKey("1"), "GetEvent")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow(UserKey("1"), "EventListForDay")
+		require.False(t, allowed, "routes without own limit share the default bucket")
+
+		allowed, _ = limiter.Allow(UserKey("1"), "createevent")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow(UserKey("1"), "CreateEvent")
+		require.False(t, allowed)
+
+		allowed, _ = limiter.Allow(UserKey("2"), "GetEvent")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow(IPKey("127.0.0.1"), "GetEvent")
+		require.True(t, allowed)
+	})
+
+	t.Run("idle buckets are removed", func(t *testing.T) {
+		now := time.Date(2024, 7, 6, 10, 0, 0, 0, time.UTC)
+		limiter := New(Limit{Rate: 1, Burst: 1}, nil)
+		limiter.now = func() time.Time { return now }
+
+		limiter.Allow(UserKey("1"), "GetEvent")
+		now = now.Add(idleTTL + cleanupPeriod)
+		limiter.Allow(UserKey("2"), "GetEvent")
+
+		require.Len(t, limiter.buckets, 1)
+	})
+	t.Run("change limits", func(t *testing.T) {
+		now := time.Date(2024, 7, 6, 10, 0, 0, 0, time.UTC)
+		limiter := New(Limit{Rate: 1, Burst: 1}, map[string]Limit{"CreateEvent": {Rate: 1, Burst: 1}})
+		limiter.now = func() time.Time { return now }
+
+		allowed, _ := limiter.Allow(UserKey("1"), "GetEvent")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow(UserKey("1"), "CreateEvent")
+		require.True(t, allowed)
+
+		limiter.SetLimits(Limit{Rate: 1, Burst: 3}, map[string]Limit{"UpdateEvent": {Rate: 1, Burst: 1}})
+
+		// корзина по умолчанию сохраняет расход и копит токены до нового burst
+		allowed, _ = limiter.Allow(UserKey("1"), "GetEvent")
+		require.False(t, allowed)
+		now = now.Add(5 * time.Second)
+		for i := 0; i < 3; i++ {
+			allowed, _ = limiter.Allow(UserKey("1"), "GetEvent")
+			require.True(t, allowed)
+		}
+		allowed, _ = limiter.Allow(UserKey("1"), "GetEvent")
+		require.False(t, allowed)
+
+		// у CreateEvent больше нет собственного лимита - он расходует корзину по умолчанию
+		allowed, _ = limiter.Allow(UserKey("1"), "CreateEvent")
+		require.False(t, allowed)
+		allowed, _ = limiter.Allow(UserKey("1"), "UpdateEvent")
+		require.True(t, allowed)
+	})
+
+	t.Run("validate limit", func(t *testing.T) {
+		require.NoError(t, Limit{Rate: 0, Burst: 0}.Validate())
+		require.ErrorIs(t, Limit{Rate: -1, Burst: 1}.Validate(), ErrNotValidLimit)
+		require.ErrorIs(t, Limit{Rate: 1, Burst: -1}.Validate(), ErrNotValidLimit)
+	})
+}
@@ -0,0 +1,65 @@
+// Package reload - перечитывание конфигурации без перезапуска: по сигналу SIGHUP
+// и при изменении файла конфигурации.
+package reload
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// DefaultPeriod - как часто проверяется время изменения файла конфигурации.
+const DefaultPeriod = 5 * time.Second
+
+type Logger interface {
+	Info(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, err error, msg string, args ...any)
+}
+
+// ApplyFunc читает конфигурацию, проверяет её и применяет. Если новая конфигурация некорректна,
+// функция должна вернуть ошибку, ничего не меняя: продолжит действовать прежняя конфигурация.
+type ApplyFunc func(ctx context.Context) error
+
+// Watch вызывает apply при получении сигнала из signals (SIGHUP) и при изменении файла path
+// (проверяется раз в period). Работает до отмены ctx. Подписываться на SIGHUP нужно заранее,
+// до запуска Watch в горутине: иначе сигнал, пришедший раньше, завершит процесс.
+func Watch(ctx context.Context, logger Logger, path string, period time.Duration, signals <-chan os.Signal, apply ApplyFunc) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	modTime := fileModTime(path)
+	reload := func(reason string) {
+		logger.Info(ctx, "reloading config", "path", path, "reason", reason)
+		if err := apply(ctx); err != nil {
+			logger.Error(ctx, err, "failed to reload config, keeping previous one", "path", path)
+			return
+		}
+		logger.Info(ctx, "config reloaded", "path", path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			modTime = fileModTime(path)
+			reload("signal")
+		case <-ticker.C:
+			current := fileModTime(path)
+			// файл мог быть временно удалён при замене (например, при обновлении ConfigMap) - ждём его появления
+			if current.IsZero() || current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+			reload("file changed")
+		}
+	}
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,69 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct{}
+
+func (l *testLogger) Info(context.Context, string, ...any)         {}
+func (l *testLogger) Error(context.Context, error, string, ...any) {}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("logger:\n  level: INFO\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	applied := make(chan struct{}, 10)
+	apply := func(context.Context) error {
+		calls.Add(1)
+		applied <- struct{}{}
+		return errors.New("not valid config")
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Watch(ctx, &testLogger{}, path, 10*time.Millisecond, signals, apply)
+	}()
+
+	// по сигналу
+	signals <- syscall.SIGHUP
+	waitApplied(t, applied)
+
+	// при изменении файла; ошибка применения не останавливает наблюдение
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	waitApplied(t, applied)
+
+	// без изменений файл повторно не применяется
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(2), calls.Load())
+
+	cancel()
+	<-done
+}
+
+func waitApplied(t *testing.T, applied <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-applied:
+	case <-time.After(time.Second):
+		require.Fail(t, "config was not applied")
+	}
+}
@@ -0,0 +1,83 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Queue is an autogenerated mock type for the Queue type
+type Queue struct {
+	mock.Mock
+}
+
+type Queue_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Queue) EXPECT() *Queue_Expecter {
+	return &Queue_Expecter{mock: &_m.Mock}
+}
+
+// SendData provides a mock function with given fields: ctx, data
+func (_m *Queue) SendData(ctx context.Context, data []byte) error {
+	ret := _m.Called(ctx, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendData")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Queue_SendData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendData'
+type Queue_SendData_Call struct {
+	*mock.Call
+}
+
+// SendData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - data []byte
+func (_e *Queue_Expecter) SendData(ctx interface{}, data interface{}) *Queue_SendData_Call {
+	return &Queue_SendData_Call{Call: _e.mock.On("SendData", ctx, data)}
+}
+
+func (_c *Queue_SendData_Call) Run(run func(ctx context.Context, data []byte)) *Queue_SendData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *Queue_SendData_Call) Return(_a0 error) *Queue_SendData_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Queue_SendData_Call) RunAndReturn(run func(context.Context, []byte) error) *Queue_SendData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewQueue creates a new instance of Queue. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQueue(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Queue {
+	mock := &Queue{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
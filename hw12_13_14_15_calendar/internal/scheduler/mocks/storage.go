@@ -0,0 +1,528 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+
+	time "time"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// ClaimDigest provides a mock function with given fields: ctx, userID, startDate
+func (_m *Storage) ClaimDigest(ctx context.Context, userID uint64, startDate time.Time) (bool, error) {
+	ret := _m.Called(ctx, userID, startDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDigest")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) (bool, error)); ok {
+		return rf(ctx, userID, startDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) bool); ok {
+		r0 = rf(ctx, userID, startDate)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time) error); ok {
+		r1 = rf(ctx, userID, startDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ClaimDigest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimDigest'
+type Storage_ClaimDigest_Call struct {
+	*mock.Call
+}
+
+// ClaimDigest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - startDate time.Time
+func (_e *Storage_Expecter) ClaimDigest(ctx interface{}, userID interface{}, startDate interface{}) *Storage_ClaimDigest_Call {
+	return &Storage_ClaimDigest_Call{Call: _e.mock.On("ClaimDigest", ctx, userID, startDate)}
+}
+
+func (_c *Storage_ClaimDigest_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time)) *Storage_ClaimDigest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ClaimDigest_Call) Return(_a0 bool, _a1 error) *Storage_ClaimDigest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ClaimDigest_Call) RunAndReturn(run func(context.Context, uint64, time.Time) (bool, error)) *Storage_ClaimDigest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByDeletedDate provides a mock function with given fields: ctx, maxDeletedDate
+func (_m *Storage) DeleteByDeletedDate(ctx context.Context, maxDeletedDate time.Time) error {
+	ret := _m.Called(ctx, maxDeletedDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByDeletedDate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, maxDeletedDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteByDeletedDate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByDeletedDate'
+type Storage_DeleteByDeletedDate_Call struct {
+	*mock.Call
+}
+
+// DeleteByDeletedDate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - maxDeletedDate time.Time
+func (_e *Storage_Expecter) DeleteByDeletedDate(ctx interface{}, maxDeletedDate interface{}) *Storage_DeleteByDeletedDate_Call {
+	return &Storage_DeleteByDeletedDate_Call{Call: _e.mock.On("DeleteByDeletedDate", ctx, maxDeletedDate)}
+}
+
+func (_c *Storage_DeleteByDeletedDate_Call) Run(run func(ctx context.Context, maxDeletedDate time.Time)) *Storage_DeleteByDeletedDate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteByDeletedDate_Call) Return(_a0 error) *Storage_DeleteByDeletedDate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteByDeletedDate_Call) RunAndReturn(run func(context.Context, time.Time) error) *Storage_DeleteByDeletedDate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByEndDate provides a mock function with given fields: ctx, maxEndDate
+func (_m *Storage) DeleteByEndDate(ctx context.Context, maxEndDate time.Time) error {
+	ret := _m.Called(ctx, maxEndDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByEndDate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, maxEndDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteByEndDate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByEndDate'
+type Storage_DeleteByEndDate_Call struct {
+	*mock.Call
+}
+
+// DeleteByEndDate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - maxEndDate time.Time
+func (_e *Storage_Expecter) DeleteByEndDate(ctx interface{}, maxEndDate interface{}) *Storage_DeleteByEndDate_Call {
+	return &Storage_DeleteByEndDate_Call{Call: _e.mock.On("DeleteByEndDate", ctx, maxEndDate)}
+}
+
+func (_c *Storage_DeleteByEndDate_Call) Run(run func(ctx context.Context, maxEndDate time.Time)) *Storage_DeleteByEndDate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteByEndDate_Call) Return(_a0 error) *Storage_DeleteByEndDate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteByEndDate_Call) RunAndReturn(run func(context.Context, time.Time) error) *Storage_DeleteByEndDate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByNotifyDate provides a mock function with given fields: ctx, startNotifyDate, endNotifyDate
+func (_m *Storage) ListByNotifyDate(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, startNotifyDate, endNotifyDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByNotifyDate")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, startNotifyDate, endNotifyDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListByNotifyDate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByNotifyDate'
+type Storage_ListByNotifyDate_Call struct {
+	*mock.Call
+}
+
+// ListByNotifyDate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - startNotifyDate time.Time
+//   - endNotifyDate time.Time
+func (_e *Storage_Expecter) ListByNotifyDate(ctx interface{}, startNotifyDate interface{}, endNotifyDate interface{}) *Storage_ListByNotifyDate_Call {
+	return &Storage_ListByNotifyDate_Call{Call: _e.mock.On("ListByNotifyDate", ctx, startNotifyDate, endNotifyDate)}
+}
+
+func (_c *Storage_ListByNotifyDate_Call) Run(run func(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time)) *Storage_ListByNotifyDate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListByNotifyDate_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListByNotifyDate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListByNotifyDate_Call) RunAndReturn(run func(context.Context, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListByNotifyDate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDigestSettings provides a mock function with given fields: ctx
+func (_m *Storage) ListDigestSettings(ctx context.Context) ([]*storage.UserSettings, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDigestSettings")
+	}
+
+	var r0 []*storage.UserSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*storage.UserSettings, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*storage.UserSettings); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.UserSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListDigestSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDigestSettings'
+type Storage_ListDigestSettings_Call struct {
+	*mock.Call
+}
+
+// ListDigestSettings is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Storage_Expecter) ListDigestSettings(ctx interface{}) *Storage_ListDigestSettings_Call {
+	return &Storage_ListDigestSettings_Call{Call: _e.mock.On("ListDigestSettings", ctx)}
+}
+
+func (_c *Storage_ListDigestSettings_Call) Run(run func(ctx context.Context)) *Storage_ListDigestSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Storage_ListDigestSettings_Call) Return(_a0 []*storage.UserSettings, _a1 error) *Storage_ListDigestSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListDigestSettings_Call) RunAndReturn(run func(context.Context) ([]*storage.UserSettings, error)) *Storage_ListDigestSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForNotify provides a mock function with given fields: ctx, startNotifyDate, endNotifyDate
+func (_m *Storage) ListForNotify(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, startNotifyDate, endNotifyDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForNotify")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, startNotifyDate, endNotifyDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListForNotify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForNotify'
+type Storage_ListForNotify_Call struct {
+	*mock.Call
+}
+
+// ListForNotify is a helper method to define mock.On call
+//   - ctx context.Context
+//   - startNotifyDate time.Time
+//   - endNotifyDate time.Time
+func (_e *Storage_Expecter) ListForNotify(ctx interface{}, startNotifyDate interface{}, endNotifyDate interface{}) *Storage_ListForNotify_Call {
+	return &Storage_ListForNotify_Call{Call: _e.mock.On("ListForNotify", ctx, startNotifyDate, endNotifyDate)}
+}
+
+func (_c *Storage_ListForNotify_Call) Run(run func(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time)) *Storage_ListForNotify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListForNotify_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListForNotify_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListForNotify_Call) RunAndReturn(run func(context.Context, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListForNotify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForPeriod provides a mock function with given fields: ctx, userID, filter, startDate, endDateExclusive
+func (_m *Storage) ListForPeriod(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, userID, filter, startDate, endDateExclusive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForPeriod")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, userID, filter, startDate, endDateExclusive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, userID, filter, startDate, endDateExclusive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListForPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForPeriod'
+type Storage_ListForPeriod_Call struct {
+	*mock.Call
+}
+
+// ListForPeriod is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - filter storage.EventFilter
+//   - startDate time.Time
+//   - endDateExclusive time.Time
+func (_e *Storage_Expecter) ListForPeriod(ctx interface{}, userID interface{}, filter interface{}, startDate interface{}, endDateExclusive interface{}) *Storage_ListForPeriod_Call {
+	return &Storage_ListForPeriod_Call{Call: _e.mock.On("ListForPeriod", ctx, userID, filter, startDate, endDateExclusive)}
+}
+
+func (_c *Storage_ListForPeriod_Call) Run(run func(ctx context.Context, userID uint64, filter storage.EventFilter, startDate time.Time, endDateExclusive time.Time)) *Storage_ListForPeriod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(storage.EventFilter), args[3].(time.Time), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ListForPeriod_Call) Return(_a0 []*storage.Event, _a1 error) *Storage_ListForPeriod_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListForPeriod_Call) RunAndReturn(run func(context.Context, uint64, storage.EventFilter, time.Time, time.Time) ([]*storage.Event, error)) *Storage_ListForPeriod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseDigest provides a mock function with given fields: ctx, userID, startDate
+func (_m *Storage) ReleaseDigest(ctx context.Context, userID uint64, startDate time.Time) error {
+	ret := _m.Called(ctx, userID, startDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseDigest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) error); ok {
+		r0 = rf(ctx, userID, startDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_ReleaseDigest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseDigest'
+type Storage_ReleaseDigest_Call struct {
+	*mock.Call
+}
+
+// ReleaseDigest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - startDate time.Time
+func (_e *Storage_Expecter) ReleaseDigest(ctx interface{}, userID interface{}, startDate interface{}) *Storage_ReleaseDigest_Call {
+	return &Storage_ReleaseDigest_Call{Call: _e.mock.On("ReleaseDigest", ctx, userID, startDate)}
+}
+
+func (_c *Storage_ReleaseDigest_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time)) *Storage_ReleaseDigest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Storage_ReleaseDigest_Call) Return(_a0 error) *Storage_ReleaseDigest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_ReleaseDigest_Call) RunAndReturn(run func(context.Context, uint64, time.Time) error) *Storage_ReleaseDigest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetNotifyStatus provides a mock function with given fields: ctx, eventIDs, notifyStatus
+func (_m *Storage) SetNotifyStatus(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error {
+	ret := _m.Called(ctx, eventIDs, notifyStatus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNotifyStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64, storage.NotifyStatus) error); ok {
+		r0 = rf(ctx, eventIDs, notifyStatus)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_SetNotifyStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetNotifyStatus'
+type Storage_SetNotifyStatus_Call struct {
+	*mock.Call
+}
+
+// SetNotifyStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - eventIDs []uint64
+//   - notifyStatus storage.NotifyStatus
+func (_e *Storage_Expecter) SetNotifyStatus(ctx interface{}, eventIDs interface{}, notifyStatus interface{}) *Storage_SetNotifyStatus_Call {
+	return &Storage_SetNotifyStatus_Call{Call: _e.mock.On("SetNotifyStatus", ctx, eventIDs, notifyStatus)}
+}
+
+func (_c *Storage_SetNotifyStatus_Call) Run(run func(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus)) *Storage_SetNotifyStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]uint64), args[2].(storage.NotifyStatus))
+	})
+	return _c
+}
+
+func (_c *Storage_SetNotifyStatus_Call) Return(_a0 error) *Storage_SetNotifyStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_SetNotifyStatus_Call) RunAndReturn(run func(context.Context, []uint64, storage.NotifyStatus) error) *Storage_SetNotifyStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
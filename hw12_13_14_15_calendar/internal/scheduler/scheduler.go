@@ -1,10 +1,17 @@
 package scheduler
 
 import (
+	"bytes"
 	"context"
+	"embed"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/model"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"github.com/robfig/cron/v3"
@@ -17,10 +24,69 @@ type Scheduler struct {
 	cron             *cron.Cron
 	notifyCron       string
 	clearCron        string
+	digestCron       string
+	digestHour       int
 	notifyPeriod     time.Duration
 	notifyScanPeriod time.Duration
 	clearPeriod      time.Duration
+	trashPeriod      time.Duration
 	ctx              context.Context
+	notifyMu         sync.Mutex
+	clearMu          sync.Mutex
+	digestMu         sync.Mutex
+	settingsMu       sync.RWMutex // защищает расписания и периоды, которые меняются при перечитывании конфигурации
+	notifyEntry      cron.EntryID
+	clearEntry       cron.EntryID
+	digestEntry      cron.EntryID
+}
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// digestTemplate содержит шаблоны "subject" и "body" для DigestDto.
+var digestTemplate = template.Must(template.ParseFS(templatesFS, "templates/digest.tmpl"))
+
+// расписания задаются с секундами, как в cron.WithSeconds.
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ErrDigestCronTooRare - расписание сводок запускается реже раза в час, и сводки опаздывали бы.
+var ErrDigestCronTooRare = errors.New("digest cron must fire at least hourly")
+
+// ValidateCron проверяет выражение расписания.
+func ValidateCron(spec string) error {
+	if _, err := cronParser.Parse(spec); err != nil {
+		return fmt.Errorf("cannot parse cron %q: %w", spec, err)
+	}
+	return nil
+}
+
+// ValidateDigestCron проверяет, что расписание сводок запускается не реже раза в час: сводку отправляет
+// первый запуск после часа сводки, и при более редком расписании она опаздывала бы на несколько часов.
+// Промежутки между запусками проверяются за неделю, а ограничения по дням месяца и месяцам -
+// по началу каждого часа високосного года.
+func ValidateDigestCron(spec string) error {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("cannot parse cron %q: %w", spec, err)
+	}
+	// расписание, которое никогда не запускается, возвращает нулевое время
+	tooRare := func(t time.Time, next time.Time) bool {
+		return next.IsZero() || next.Sub(t) > time.Hour
+	}
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for t, end := start, start.AddDate(0, 0, 7); t.Before(end); {
+		next := schedule.Next(t)
+		if tooRare(t, next) {
+			return fmt.Errorf("%w: no run within an hour after %s", ErrDigestCronTooRare, t.Format(time.DateTime))
+		}
+		t = next
+	}
+	for t, end := start, start.AddDate(1, 0, 0); t.Before(end); t = t.Add(time.Hour) {
+		if tooRare(t, schedule.Next(t)) {
+			return fmt.Errorf("%w: no run within an hour after %s", ErrDigestCronTooRare, t.Format(time.DateTime))
+		}
+	}
+	return nil
 }
 
 type Logger interface {
@@ -32,8 +98,20 @@ type Logger interface {
 
 type Storage interface {
 	ListForNotify(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error)
+	ListByNotifyDate(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error)
 	SetNotifyStatus(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error
 	DeleteByEndDate(ctx context.Context, maxEndDate time.Time) error
+	DeleteByDeletedDate(ctx context.Context, maxDeletedDate time.Time) error
+	ListDigestSettings(ctx context.Context) ([]*storage.UserSettings, error)
+	ClaimDigest(ctx context.Context, userID uint64, startDate time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, userID uint64, startDate time.Time) error
+	ListForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
 }
 
 type Queue interface {
@@ -47,20 +125,26 @@ func NewScheduler(
 	queue Queue,
 	notifyCron string,
 	clearCron string,
+	digestCron string,
+	digestHour int,
 	notifyPeriod time.Duration,
 	notifyScanPeriod time.Duration,
 	clearPeriod time.Duration,
+	trashPeriod time.Duration,
 ) *Scheduler {
 	return &Scheduler{
 		logger:           logger,
 		storage:          storage,
 		queue:            queue,
-		cron:             cron.New(cron.WithSeconds()),
+		cron:             cron.New(cron.WithParser(cronParser)),
 		notifyCron:       notifyCron,
 		clearCron:        clearCron,
+		digestCron:       digestCron,
+		digestHour:       digestHour,
 		notifyPeriod:     notifyPeriod,
 		notifyScanPeriod: notifyScanPeriod,
 		clearPeriod:      clearPeriod,
+		trashPeriod:      trashPeriod,
 		ctx:              ctx,
 	}
 }
@@ -68,46 +152,160 @@ func NewScheduler(
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.logger.Info(ctx, "starting scheduler")
 
-	s.cron.AddFunc(s.notifyCron, func() {
-		s.notifyEvents()
-	})
-	s.cron.AddFunc(s.clearCron, func() {
-		s.clearEvents()
-	})
+	schedules, err := ParseSchedules(s.notifyCron, s.clearCron, s.digestCron)
+	if err != nil {
+		return err
+	}
+	s.Reschedule(schedules)
 	s.cron.Start()
 
 	s.logger.Info(ctx, "started scheduler")
 	return nil
 }
 
+// Stop останавливает расписание и ждёт завершения запущенных задач, но не дольше отмены ctx.
 func (s *Scheduler) Stop(ctx context.Context) error {
 	s.logger.Info(ctx, "stopping scheduler")
 
-	ctx = s.cron.Stop()
-	<-ctx.Done()
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+		return fmt.Errorf("%w: scheduled jobs are still running", lifecycle.ErrCutOff)
+	}
 
 	s.logger.Info(ctx, "stopped scheduler")
 	return nil
 }
 
+// Schedules - разобранные расписания задач планировщика.
+type Schedules struct {
+	notifyCron     string
+	clearCron      string
+	digestCron     string
+	notifySchedule cron.Schedule
+	clearSchedule  cron.Schedule
+	digestSchedule cron.Schedule
+}
+
+// ParseSchedules разбирает расписания уведомления, очистки и сводок. Пустое расписание сводок отключает их.
+func ParseSchedules(notifyCron string, clearCron string, digestCron string) (Schedules, error) {
+	schedules := Schedules{notifyCron: notifyCron, clearCron: clearCron, digestCron: digestCron}
+	var err error
+	schedules.notifySchedule, err = cronParser.Parse(notifyCron)
+	if err != nil {
+		return Schedules{}, fmt.Errorf("cannot parse notify cron %q: %w", notifyCron, err)
+	}
+	schedules.clearSchedule, err = cronParser.Parse(clearCron)
+	if err != nil {
+		return Schedules{}, fmt.Errorf("cannot parse clear cron %q: %w", clearCron, err)
+	}
+	if digestCron != "" {
+		schedules.digestSchedule, err = cronParser.Parse(digestCron)
+		if err != nil {
+			return Schedules{}, fmt.Errorf("cannot parse digest cron %q: %w", digestCron, err)
+		}
+	}
+	return schedules, nil
+}
+
+// Reschedule заменяет расписания уведомления, очистки и сводок, в т.ч. у запущенного планировщика.
+// Расписания разбираются заранее (ParseSchedules), поэтому замена не может завершиться ошибкой
+// на полпути. Выполняющиеся задачи не прерываются.
+func (s *Scheduler) Reschedule(schedules Schedules) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	s.cron.Remove(s.notifyEntry)
+	s.cron.Remove(s.clearEntry)
+	s.cron.Remove(s.digestEntry)
+	// ошибки уже залогированы внутри задач
+	s.notifyEntry = s.cron.Schedule(schedules.notifySchedule, cron.FuncJob(func() {
+		_ = s.Notify(s.ctx)
+	}))
+	s.clearEntry = s.cron.Schedule(schedules.clearSchedule, cron.FuncJob(func() {
+		_ = s.Clear(s.ctx)
+	}))
+	s.digestEntry = 0
+	if schedules.digestSchedule != nil {
+		s.digestEntry = s.cron.Schedule(schedules.digestSchedule, cron.FuncJob(func() {
+			_ = s.Digest(s.ctx)
+		}))
+	}
+	s.notifyCron = schedules.notifyCron
+	s.clearCron = schedules.clearCron
+	s.digestCron = schedules.digestCron
+}
+
+// SetPeriods меняет периоды уведомления и очистки; они применяются со следующего запуска задач.
+func (s *Scheduler) SetPeriods(notifyPeriod time.Duration, notifyScanPeriod time.Duration, clearPeriod time.Duration, trashPeriod time.Duration) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	s.notifyPeriod = notifyPeriod
+	s.notifyScanPeriod = notifyScanPeriod
+	s.clearPeriod = clearPeriod
+	s.trashPeriod = trashPeriod
+}
+
+// SetDigestHour меняет час (по времени пользователя), в который отправляются сводки.
+func (s *Scheduler) SetDigestHour(digestHour int) {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	s.digestHour = digestHour
+}
+
+// Notify запускает уведомление о предстоящих событиях вне расписания.
+// Одновременно выполняется не более одного уведомления.
+func (s *Scheduler) Notify(ctx context.Context) error {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	return s.notifyEvents(ctx)
+}
+
+// Clear запускает очистку старых событий вне расписания.
+func (s *Scheduler) Clear(ctx context.Context) error {
+	s.clearMu.Lock()
+	defer s.clearMu.Unlock()
+	return s.clearEvents(ctx)
+}
+
+// Digest отправляет сводки событий пользователям, у которых наступило время сводки.
+// За один период пользователь получает не более одной сводки: отметки об отправке хранятся в БД,
+// поэтому сводку не дублируют ни повторные запуски, ни перезапуск, ни другие экземпляры планировщика.
+func (s *Scheduler) Digest(ctx context.Context) error {
+	s.digestMu.Lock()
+	defer s.digestMu.Unlock()
+	return s.sendDigests(ctx, time.Now())
+}
+
+// ListNotifyStatus возвращает события с датой уведомления в заданном периоде вне зависимости от статуса уведомления.
+func (s *Scheduler) ListNotifyStatus(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	return s.storage.ListByNotifyDate(ctx, startNotifyDate, endNotifyDate)
+}
+
 /*
  * Уведомление о предстоящих событиях.
  */
-func (s *Scheduler) notifyEvents() {
+func (s *Scheduler) notifyEvents(ctx context.Context) error {
+	s.settingsMu.RLock()
+	notifyScanPeriod, notifyPeriod := s.notifyScanPeriod, s.notifyPeriod
+	s.settingsMu.RUnlock()
+
 	now := time.Now()
-	startNotifyDate := now.Add(-s.notifyScanPeriod)
-	endNotifyDate := now.Add(s.notifyPeriod)
-	s.logger.Debug(s.ctx, "start notifying events", "startNotifyDate", startNotifyDate, "endNotifyDate", endNotifyDate)
+	startNotifyDate := now.Add(-notifyScanPeriod)
+	endNotifyDate := now.Add(notifyPeriod)
+	s.logger.Debug(ctx, "start notifying events", "startNotifyDate", startNotifyDate, "endNotifyDate", endNotifyDate)
 
-	events, err := s.storage.ListForNotify(s.ctx, startNotifyDate, endNotifyDate)
+	events, err := s.storage.ListForNotify(ctx, startNotifyDate, endNotifyDate)
 	if err != nil {
 		s.logger.Error(
-			s.ctx, err, "failed notifying events",
+			ctx, err, "failed notifying events",
 			"stage", "storage",
 			"startNotifyDate", startNotifyDate,
 			"endNotifyDate", endNotifyDate,
 		)
-		return
+		return err
 	}
 
 	eventIDs := make([]uint64, 0)
@@ -116,7 +314,7 @@ func (s *Scheduler) notifyEvents() {
 		notificationStr, err := json.Marshal(notification)
 		if err != nil {
 			s.logger.Error(
-				s.ctx, err, "failed notifying events",
+				ctx, err, "failed notifying events",
 				"stage", "marshal",
 				"eventId", event.ID,
 				"startNotifyDate", startNotifyDate,
@@ -125,10 +323,10 @@ func (s *Scheduler) notifyEvents() {
 			continue
 		}
 
-		err = s.queue.SendData(s.ctx, notificationStr)
+		err = s.queue.SendData(ctx, notificationStr)
 		if err != nil {
 			s.logger.Error(
-				s.ctx, err, "failed notifying events",
+				ctx, err, "failed notifying events",
 				"stage", "send",
 				"eventId", event.ID,
 				"startNotifyDate", startNotifyDate,
@@ -136,35 +334,176 @@ func (s *Scheduler) notifyEvents() {
 			)
 			continue
 		}
-		s.logger.Debug(s.ctx, "notification sent", "eventID", event.ID)
+		s.logger.Debug(ctx, "notification sent", "eventID", event.ID)
 
 		eventIDs = append(eventIDs, event.ID)
 	}
 
-	err = s.storage.SetNotifyStatus(s.ctx, eventIDs, storage.NotifyInProgress)
+	err = s.storage.SetNotifyStatus(ctx, eventIDs, storage.NotifyInProgress)
 	if err != nil {
 		s.logger.Error(
-			s.ctx, err, "failed notifying events",
+			ctx, err, "failed notifying events",
 			"stage", "marking",
 			"startNotifyDate", startNotifyDate,
 			"endNotifyDate", endNotifyDate,
 		)
-	} else {
-		s.logger.Debug(s.ctx, "succeeded notifying events")
+		return err
 	}
+	s.logger.Debug(ctx, "succeeded notifying events")
+	return nil
 }
 
 /*
- * Очистка старых событий.
+ * Сводки событий на день или неделю.
  */
-func (s *Scheduler) clearEvents() {
-	maxEndDateToDelete := time.Now().Add(-s.clearPeriod)
-	s.logger.Debug(s.ctx, "start clearing events", "maxEndDate", maxEndDateToDelete)
+func (s *Scheduler) sendDigests(ctx context.Context, now time.Time) error {
+	s.settingsMu.RLock()
+	digestHour := s.digestHour
+	s.settingsMu.RUnlock()
 
-	err := s.storage.DeleteByEndDate(s.ctx, maxEndDateToDelete)
+	s.logger.Debug(ctx, "start sending digests", "digestHour", digestHour)
+
+	settings, err := s.storage.ListDigestSettings(ctx)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed sending digests", "stage", "settings")
+		return err
+	}
+
+	for _, userSettings := range settings {
+		startDate, endDate, ok := digestPeriod(userSettings, now, digestHour)
+		if !ok {
+			continue
+		}
+		claimed, err := s.storage.ClaimDigest(ctx, userSettings.UserID, startDate)
+		if err != nil {
+			s.logger.Error(ctx, err, "failed sending digests", "stage", "claim", "userId", userSettings.UserID, "startDate", startDate)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		events, err := s.storage.ListForPeriod(ctx, userSettings.UserID, storage.EventFilter{}, startDate, endDate)
+		if err != nil {
+			s.logger.Error(
+				ctx, err, "failed sending digests",
+				"stage", "storage",
+				"userId", userSettings.UserID,
+				"startDate", startDate,
+				"endDate", endDate,
+			)
+			s.releaseDigest(ctx, userSettings.UserID, startDate)
+			continue
+		}
+		// пустые сводки не отправляются
+		if len(events) > 0 {
+			if err := s.sendDigest(ctx, userSettings, startDate, endDate, events); err != nil {
+				s.releaseDigest(ctx, userSettings.UserID, startDate)
+			}
+		}
+	}
+
+	s.logger.Debug(ctx, "succeeded sending digests")
+	return nil
+}
+
+// releaseDigest снимает отметку о сводке, которую не удалось отправить, чтобы её отправил следующий запуск.
+func (s *Scheduler) releaseDigest(ctx context.Context, userID uint64, startDate time.Time) {
+	if err := s.storage.ReleaseDigest(ctx, userID, startDate); err != nil {
+		s.logger.Error(ctx, err, "failed sending digests", "stage", "release", "userId", userID, "startDate", startDate)
+	}
+}
+
+func (s *Scheduler) sendDigest(
+	ctx context.Context,
+	settings *storage.UserSettings,
+	startDate time.Time,
+	endDate time.Time,
+	events []*storage.Event,
+) error {
+	digest := model.ConvertEventsToDigest(settings, startDate, endDate, events)
+	data, err := renderDigest(digest)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed sending digests", "stage", "render", "userId", settings.UserID)
+		return err
+	}
+
+	err = s.queue.SendData(ctx, data)
 	if err != nil {
-		s.logger.Error(s.ctx, err, "failed clearing events", "maxEndDate", maxEndDateToDelete)
+		s.logger.Error(ctx, err, "failed sending digests", "stage", "send", "userId", settings.UserID)
+		return err
+	}
+	s.logger.Debug(ctx, "digest sent", "userID", settings.UserID, "events", len(events))
+	return nil
+}
+
+// digestPeriod возвращает текущий период сводки в часовом поясе пользователя, если для него наступило время
+// сводки: для ежедневной - текущие сутки с часа сводки, для еженедельной - неделя с понедельника с часа сводки
+// в понедельник. Сводка, пропущенная в час сводки, отправляется следующим запуском в том же периоде.
+func digestPeriod(settings *storage.UserSettings, now time.Time, digestHour int) (time.Time, time.Time, bool) {
+	localNow := now.In(settings.Location())
+	startDate := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+	var endDate time.Time
+	switch settings.Digest {
+	case storage.DigestDaily:
+		endDate = startDate.AddDate(0, 0, 1)
+	case storage.DigestWeekly:
+		// неделя начинается с понедельника
+		startDate = startDate.AddDate(0, 0, -(int(localNow.Weekday())+6)%7)
+		endDate = startDate.AddDate(0, 0, 7)
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+	// несуществующий при переходе на летнее время час сводки сдвигается вперёд
+	digestTime := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), digestHour, 0, 0, 0, startDate.Location())
+	if localNow.Before(digestTime) {
+		return time.Time{}, time.Time{}, false
+	}
+	return startDate, endDate, true
+}
+
+// renderDigest заполняет тему и текст сводки по шаблонам и сериализует её для очереди.
+func renderDigest(digest *model.DigestDto) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := digestTemplate.ExecuteTemplate(&subject, "subject", digest); err != nil {
+		return nil, fmt.Errorf("cannot render digest subject: %w", err)
+	}
+	if err := digestTemplate.ExecuteTemplate(&body, "body", digest); err != nil {
+		return nil, fmt.Errorf("cannot render digest body: %w", err)
+	}
+	digest.Subject = subject.String()
+	digest.Body = body.String()
+	return json.Marshal(digest)
+}
+
+/*
+ * Очистка старых событий и событий, удалённых в корзину.
+ */
+func (s *Scheduler) clearEvents(ctx context.Context) error {
+	s.settingsMu.RLock()
+	clearPeriod, trashPeriod := s.clearPeriod, s.trashPeriod
+	s.settingsMu.RUnlock()
+
+	now := time.Now()
+	maxEndDateToDelete := now.Add(-clearPeriod)
+	s.logger.Debug(ctx, "start clearing events", "maxEndDate", maxEndDateToDelete)
+
+	errEnd := s.storage.DeleteByEndDate(ctx, maxEndDateToDelete)
+	if errEnd != nil {
+		s.logger.Error(ctx, errEnd, "failed clearing events", "maxEndDate", maxEndDateToDelete)
+	} else {
+		s.logger.Debug(ctx, "succeeded clearing events", "maxEndDate", maxEndDateToDelete)
+	}
+
+	maxDeletedDateToDelete := now.Add(-trashPeriod)
+	s.logger.Debug(ctx, "start clearing trash", "maxDeletedDate", maxDeletedDateToDelete)
+
+	errTrash := s.storage.DeleteByDeletedDate(ctx, maxDeletedDateToDelete)
+	if errTrash != nil {
+		s.logger.Error(ctx, errTrash, "failed clearing trash", "maxDeletedDate", maxDeletedDateToDelete)
 	} else {
-		s.logger.Debug(s.ctx, "succeeded clearing events", "maxEndDate", maxEndDateToDelete)
+		s.logger.Debug(ctx, "succeeded clearing trash", "maxDeletedDate", maxDeletedDateToDelete)
 	}
+
+	return errors.Join(errEnd, errTrash)
 }
@@ -0,0 +1,449 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/model"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/scheduler/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	memorystorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		notifyCron string
+		clearCron  string
+		digestCron string
+		valid      bool
+	}{
+		{name: "all schedules", notifyCron: "*/5 * * * * *", clearCron: "0 0 * * * *", digestCron: "0 0 * * * *", valid: true},
+		{name: "digests disabled", notifyCron: "*/5 * * * * *", clearCron: "0 0 * * * *", valid: true},
+		{name: "invalid notify cron", notifyCron: "* * *", clearCron: "0 0 * * * *"},
+		{name: "invalid clear cron", notifyCron: "*/5 * * * * *", clearCron: ""},
+		{name: "invalid digest cron", notifyCron: "*/5 * * * * *", clearCron: "0 0 * * * *", digestCron: "hourly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseSchedules(tt.notifyCron, tt.clearCron, tt.digestCron)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(context.Background(), nil, nil, nil, "*/5 * * * * *", "0 0 * * * *", "0 0 * * * *", 8, 0, 0, 0, 0)
+	schedules, err := ParseSchedules(s.notifyCron, s.clearCron, s.digestCron)
+	require.NoError(t, err)
+	s.Reschedule(schedules)
+	require.Len(t, s.cron.Entries(), 3)
+
+	schedules, err = ParseSchedules("*/10 * * * * *", "0 0 * * * *", "")
+	require.NoError(t, err)
+	s.Reschedule(schedules)
+	require.Len(t, s.cron.Entries(), 2)
+	require.Equal(t, "*/10 * * * * *", s.notifyCron)
+	require.Empty(t, s.digestCron)
+	require.Zero(t, s.digestEntry)
+}
+
+func TestValidateDigestCron(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec string
+		err  error
+	}{
+		{name: "hourly", spec: "0 0 * * * *"},
+		{name: "every half hour", spec: "0 */30 * * * *"},
+		{name: "hourly at half past", spec: "0 30 * * * *"},
+		{name: "every 5 seconds", spec: "*/5 * * * * *"},
+		{name: "descriptor", spec: "@every 15m"},
+		{name: "daily", spec: "0 0 8 * * *", err: ErrDigestCronTooRare},
+		{name: "every two hours", spec: "0 0 */2 * * *", err: ErrDigestCronTooRare},
+		{name: "working hours only", spec: "0 0 9-18 * * *", err: ErrDigestCronTooRare},
+		{name: "weekdays only", spec: "0 0 * * * 1-5", err: ErrDigestCronTooRare},
+		{name: "january only", spec: "0 0 * * 1 *", err: ErrDigestCronTooRare},
+		{name: "never", spec: "0 0 0 30 2 *", err: ErrDigestCronTooRare},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.ErrorIs(t, ValidateDigestCron(tt.spec), tt.err)
+		})
+	}
+
+	err := ValidateDigestCron("hourly")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrDigestCronTooRare)
+}
+
+func TestDigestPeriod(t *testing.T) {
+	t.Parallel()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	daily := func(timeZone string) *storage.UserSettings {
+		return &storage.UserSettings{TimeZone: timeZone, Digest: storage.DigestDaily}
+	}
+	weekly := func(timeZone string) *storage.UserSettings {
+		return &storage.UserSettings{TimeZone: timeZone, Digest: storage.DigestWeekly}
+	}
+
+	// 2024-07-08 - понедельник
+	tests := []struct {
+		name       string
+		settings   *storage.UserSettings
+		now        time.Time
+		digestHour int
+		ok         bool
+		startDate  time.Time
+		endDate    time.Time
+	}{
+		{
+			name: "daily before digest hour", settings: daily(""), digestHour: 8,
+			now: time.Date(2024, 7, 8, 7, 59, 0, 0, time.UTC),
+		},
+		{
+			name: "daily at digest hour", settings: daily(""), digestHour: 8, ok: true,
+			now:       time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daily after missed digest hour", settings: daily(""), digestHour: 8, ok: true,
+			now:       time.Date(2024, 7, 8, 23, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// в Москве уже следующий день
+			name: "daily in user time zone", settings: daily("Europe/Moscow"), digestHour: 0, ok: true,
+			now:       time.Date(2024, 7, 8, 22, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 9, 0, 0, 0, 0, moscow),
+			endDate:   time.Date(2024, 7, 10, 0, 0, 0, 0, moscow),
+		},
+		{
+			name: "daily before digest hour in user time zone", settings: daily("Europe/Moscow"), digestHour: 8,
+			now: time.Date(2024, 7, 8, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "unknown time zone is utc", settings: daily("Mars/Olympus"), digestHour: 8, ok: true,
+			now:       time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 2024-03-31 в Берлине после 02:00 сразу 03:00, в сутках 23 часа
+			name: "daily with missing digest hour on dst day", settings: daily("Europe/Berlin"), digestHour: 2, ok: true,
+			now:       time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 3, 31, 0, 0, 0, 0, berlin),
+			endDate:   time.Date(2024, 4, 1, 0, 0, 0, 0, berlin),
+		},
+		{
+			name: "weekly on monday", settings: weekly(""), digestHour: 8, ok: true,
+			now:       time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly on monday before digest hour", settings: weekly(""), digestHour: 8,
+			now: time.Date(2024, 7, 8, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly after missed monday", settings: weekly(""), digestHour: 8, ok: true,
+			now:       time.Date(2024, 7, 14, 23, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// в UTC ещё воскресенье, в Москве уже понедельник
+			name: "weekly on monday in user time zone", settings: weekly("Europe/Moscow"), digestHour: 0, ok: true,
+			now:       time.Date(2024, 7, 7, 21, 30, 0, 0, time.UTC),
+			startDate: time.Date(2024, 7, 8, 0, 0, 0, 0, moscow),
+			endDate:   time.Date(2024, 7, 15, 0, 0, 0, 0, moscow),
+		},
+		{
+			name: "weekly across dst", settings: weekly("Europe/Berlin"), digestHour: 8, ok: true,
+			now:       time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC),
+			startDate: time.Date(2024, 3, 25, 0, 0, 0, 0, berlin),
+			endDate:   time.Date(2024, 4, 1, 0, 0, 0, 0, berlin),
+		},
+		{
+			name: "no digest", settings: &storage.UserSettings{Digest: storage.DigestNone}, digestHour: 8,
+			now: time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			startDate, endDate, ok := digestPeriod(tt.settings, tt.now, tt.digestHour)
+			require.Equal(t, tt.ok, ok)
+			require.True(t, tt.startDate.Equal(startDate), "start date %s", startDate)
+			require.True(t, tt.endDate.Equal(endDate), "end date %s", endDate)
+		})
+	}
+}
+
+func TestRenderDigest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		period  storage.DigestPeriod
+		endDate time.Time
+		events  []*storage.Event
+		subject string
+		body    string
+	}{
+		{
+			name:    "daily",
+			period:  storage.DigestDaily,
+			endDate: time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC),
+			events: []*storage.Event{
+				{ID: 1, Title: "standup", StartDate: time.Date(2024, 7, 8, 7, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 8, 7, 15, 0, 0, time.UTC)},
+				{
+					ID: 2, Title: "review", Location: "Room 1",
+					StartDate: time.Date(2024, 7, 8, 11, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 8, 12, 0, 0, 0, time.UTC),
+				},
+			},
+			subject: "Events for Monday, 08.07.2024",
+			body:    "10:00 - 10:15  standup\n14:00 - 15:00  review (Room 1)\n",
+		},
+		{
+			name:    "weekly",
+			period:  storage.DigestWeekly,
+			endDate: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+			events: []*storage.Event{
+				{ID: 3, Title: "planning", StartDate: time.Date(2024, 7, 10, 6, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 10, 8, 0, 0, 0, time.UTC)},
+			},
+			subject: "Events for the week 08.07.2024 - 14.07.2024",
+			body:    "Wed 10.07 09:00 - 11:00  planning\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// время в сводке - по Москве (UTC+3)
+			settings := &storage.UserSettings{UserID: 12345, TimeZone: "Europe/Moscow", Digest: tt.period}
+			digest := model.ConvertEventsToDigest(settings, time.Date(2024, 7, 7, 21, 0, 0, 0, time.UTC), tt.endDate.Add(-3*time.Hour), tt.events)
+
+			data, err := renderDigest(digest)
+			require.NoError(t, err)
+			require.Equal(t, tt.subject, digest.Subject)
+			require.Equal(t, tt.body, digest.Body)
+
+			var sent model.DigestDto
+			require.NoError(t, json.Unmarshal(data, &sent))
+			require.Equal(t, model.MessageTypeDigest, sent.Type)
+			require.Equal(t, uint64(12345), sent.UserID)
+			require.Equal(t, string(tt.period), sent.Period)
+			require.Equal(t, tt.subject, sent.Subject)
+			require.Equal(t, tt.body, sent.Body)
+			require.Len(t, sent.Events, len(tt.events))
+		})
+	}
+}
+
+func TestNotifyEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logg, err := logger.New("ERROR")
+	require.NoError(t, err)
+
+	lat, lon := 55.7558, 37.6173
+	event := &storage.Event{
+		ID:        5,
+		Title:     "my event",
+		StartDate: time.Date(2024, 7, 8, 10, 0, 0, 0, time.UTC),
+		UserID:    12345,
+		Location:  "Moscow",
+		Latitude:  &lat,
+		Longitude: &lon,
+		Category:  "work",
+		Tags:      storage.Tags{"meeting"},
+		Attachments: storage.Attachments{
+			{URL: "https://example.com/agenda.pdf", Name: "agenda.pdf", Size: 1024, MimeType: "application/pdf"},
+		},
+	}
+
+	mockedStorage := mocks.NewStorage(t)
+	mockedStorage.EXPECT().ListForNotify(ctx, mock.Anything, mock.Anything).Return([]*storage.Event{event}, nil)
+	mockedStorage.EXPECT().SetNotifyStatus(ctx, []uint64{event.ID}, storage.NotifyInProgress).Return(nil)
+
+	var notification model.NotificationDto
+	queue := mocks.NewQueue(t)
+	queue.EXPECT().SendData(ctx, mock.Anything).RunAndReturn(func(_ context.Context, data []byte) error {
+		return json.Unmarshal(data, &notification)
+	})
+
+	s := NewScheduler(ctx, logg, mockedStorage, queue, "", "", "", 8, time.Hour, time.Hour, 0, 0)
+	require.NoError(t, s.notifyEvents(ctx))
+
+	require.Equal(t, model.MessageTypeNotification, notification.Type)
+	require.Equal(t, event.ID, notification.EventID)
+	require.Equal(t, "Moscow", notification.Location)
+	require.Equal(t, &model.GeoPointDto{Lat: lat, Lon: lon}, notification.Geo)
+	require.Equal(t, []model.AttachmentDto{
+		{URL: "https://example.com/agenda.pdf", Name: "agenda.pdf", Size: 1024, MimeType: "application/pdf"},
+	}, notification.Attachments)
+}
+
+func TestSendDigests(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logg, err := logger.New("ERROR")
+	require.NoError(t, err)
+
+	// 2024-07-08 - понедельник, сводки в 08:00 по времени пользователя
+	newStorage := func(t *testing.T) *memorystorage.Storage {
+		t.Helper()
+		s := memorystorage.New()
+		for _, settings := range []*storage.UserSettings{
+			{UserID: 1, OverlapPolicy: storage.OverlapAllow, Digest: storage.DigestDaily},
+			{UserID: 2, OverlapPolicy: storage.OverlapAllow, TimeZone: "Europe/Moscow", Digest: storage.DigestWeekly},
+			{UserID: 3, OverlapPolicy: storage.OverlapAllow, TimeZone: "America/New_York", Digest: storage.DigestDaily},
+		} {
+			require.NoError(t, s.SaveUserSettings(ctx, settings))
+		}
+		for _, event := range []*storage.Event{
+			{Title: "daily 1", UserID: 1, StartDate: time.Date(2024, 7, 8, 10, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 8, 11, 0, 0, 0, time.UTC)},
+			{Title: "daily 2", UserID: 1, StartDate: time.Date(2024, 7, 9, 10, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 9, 11, 0, 0, 0, time.UTC)},
+			{Title: "weekly", UserID: 2, StartDate: time.Date(2024, 7, 10, 10, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 10, 11, 0, 0, 0, time.UTC)},
+			{Title: "new york", UserID: 3, StartDate: time.Date(2024, 7, 8, 15, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 8, 16, 0, 0, 0, time.UTC)},
+		} {
+			_, err := s.Create(ctx, event)
+			require.NoError(t, err)
+		}
+		return s
+	}
+
+	type sentDigest struct {
+		userID    uint64
+		startDate string
+	}
+	// captureQueue возвращает очередь и функцию, которая отдаёт отправленные с прошлого вызова сводки
+	captureQueue := func(t *testing.T, sendErr error) (*mocks.Queue, func() []sentDigest) {
+		t.Helper()
+		var mu sync.Mutex
+		var sent []sentDigest
+		queue := mocks.NewQueue(t)
+		queue.EXPECT().SendData(ctx, mock.Anything).RunAndReturn(func(_ context.Context, data []byte) error {
+			if sendErr != nil {
+				return sendErr
+			}
+			var digest model.DigestDto
+			require.NoError(t, json.Unmarshal(data, &digest))
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, sentDigest{userID: digest.UserID, startDate: digest.StartDate.Format(time.DateOnly)})
+			return nil
+		}).Maybe()
+		return queue, func() []sentDigest {
+			mu.Lock()
+			defer mu.Unlock()
+			result := sent
+			sent = nil
+			return result
+		}
+	}
+
+	t.Run("one digest per period", func(t *testing.T) {
+		t.Parallel()
+		queue, sent := captureQueue(t, nil)
+		s := NewScheduler(ctx, logg, newStorage(t), queue, "", "", "", 8, 0, 0, 0, 0)
+
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC)))
+		require.Equal(t, []sentDigest{{userID: 1, startDate: "2024-07-08"}, {userID: 2, startDate: "2024-07-08"}}, sent())
+
+		// повторный запуск в том же периоде ничего не отправляет
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 8, 9, 0, 0, 0, time.UTC)))
+		require.Empty(t, sent())
+
+		// в Нью-Йорке наступило 08:00
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 8, 12, 0, 0, 0, time.UTC)))
+		require.Equal(t, []sentDigest{{userID: 3, startDate: "2024-07-08"}}, sent())
+
+		// на следующий день - только ежедневная сводка
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 9, 8, 0, 0, 0, time.UTC)))
+		require.Equal(t, []sentDigest{{userID: 1, startDate: "2024-07-09"}}, sent())
+	})
+
+	t.Run("digests are shared by scheduler instances", func(t *testing.T) {
+		t.Parallel()
+		queue, sent := captureQueue(t, nil)
+		sharedStorage := newStorage(t)
+		first := NewScheduler(ctx, logg, sharedStorage, queue, "", "", "", 8, 0, 0, 0, 0)
+		second := NewScheduler(ctx, logg, sharedStorage, queue, "", "", "", 8, 0, 0, 0, 0)
+
+		require.NoError(t, first.sendDigests(ctx, time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC)))
+		require.NoError(t, second.sendDigests(ctx, time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC)))
+		require.Equal(t, []sentDigest{{userID: 1, startDate: "2024-07-08"}, {userID: 2, startDate: "2024-07-08"}}, sent())
+	})
+
+	t.Run("failed digest is sent by next run", func(t *testing.T) {
+		t.Parallel()
+		digestStorage := newStorage(t)
+
+		failingQueue, _ := captureQueue(t, errors.New("queue is unavailable"))
+		s := NewScheduler(ctx, logg, digestStorage, failingQueue, "", "", "", 8, 0, 0, 0, 0)
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC)))
+
+		queue, sent := captureQueue(t, nil)
+		s = NewScheduler(ctx, logg, digestStorage, queue, "", "", "", 8, 0, 0, 0, 0)
+		require.NoError(t, s.sendDigests(ctx, time.Date(2024, 7, 8, 9, 0, 0, 0, time.UTC)))
+		require.Equal(t, []sentDigest{{userID: 1, startDate: "2024-07-08"}, {userID: 2, startDate: "2024-07-08"}}, sent())
+	})
+
+	t.Run("storage errors", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 7, 8, 8, 0, 0, 0, time.UTC)
+		startDate := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+		storageErr := errors.New("db is unavailable")
+		settings := []*storage.UserSettings{
+			{UserID: 1, Digest: storage.DigestDaily},
+			{UserID: 2, Digest: storage.DigestDaily},
+			{UserID: 3, Digest: storage.DigestDaily},
+		}
+
+		mockedStorage := mocks.NewStorage(t)
+		mockedStorage.EXPECT().ListDigestSettings(ctx).Return(settings, nil).Once()
+		// сводку пользователя 1 уже отправил другой экземпляр, для пользователя 2 отметку не удалось поставить
+		mockedStorage.EXPECT().ClaimDigest(ctx, uint64(1), startDate).Return(false, nil).Once()
+		mockedStorage.EXPECT().ClaimDigest(ctx, uint64(2), startDate).Return(false, storageErr).Once()
+		// события пользователя 3 не удалось получить: отметка снимается
+		mockedStorage.EXPECT().ClaimDigest(ctx, uint64(3), startDate).Return(true, nil).Once()
+		mockedStorage.EXPECT().ListForPeriod(ctx, uint64(3), storage.EventFilter{}, startDate, startDate.AddDate(0, 0, 1)).
+			Return(nil, storageErr).Once()
+		mockedStorage.EXPECT().ReleaseDigest(ctx, uint64(3), startDate).Return(nil).Once()
+
+		s := NewScheduler(ctx, logg, mockedStorage, mocks.NewQueue(t), "", "", "", 8, 0, 0, 0, 0)
+		require.NoError(t, s.sendDigests(ctx, now))
+
+		mockedStorage.EXPECT().ListDigestSettings(ctx).Return(nil, storageErr).Once()
+		require.ErrorIs(t, s.sendDigests(ctx, now), storageErr)
+	})
+}
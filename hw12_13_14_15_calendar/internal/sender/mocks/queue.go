@@ -0,0 +1,94 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Queue is an autogenerated mock type for the Queue type
+type Queue struct {
+	mock.Mock
+}
+
+type Queue_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Queue) EXPECT() *Queue_Expecter {
+	return &Queue_Expecter{mock: &_m.Mock}
+}
+
+// ReceiveData provides a mock function with given fields: ctx
+func (_m *Queue) ReceiveData(ctx context.Context) (<-chan []byte, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReceiveData")
+	}
+
+	var r0 <-chan []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan []byte, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan []byte); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan []byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Queue_ReceiveData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReceiveData'
+type Queue_ReceiveData_Call struct {
+	*mock.Call
+}
+
+// ReceiveData is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Queue_Expecter) ReceiveData(ctx interface{}) *Queue_ReceiveData_Call {
+	return &Queue_ReceiveData_Call{Call: _e.mock.On("ReceiveData", ctx)}
+}
+
+func (_c *Queue_ReceiveData_Call) Run(run func(ctx context.Context)) *Queue_ReceiveData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Queue_ReceiveData_Call) Return(_a0 <-chan []byte, _a1 error) *Queue_ReceiveData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Queue_ReceiveData_Call) RunAndReturn(run func(context.Context) (<-chan []byte, error)) *Queue_ReceiveData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewQueue creates a new instance of Queue. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQueue(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Queue {
+	mock := &Queue{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
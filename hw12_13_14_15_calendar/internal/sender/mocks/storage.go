@@ -0,0 +1,86 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+type Storage_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Storage) EXPECT() *Storage_Expecter {
+	return &Storage_Expecter{mock: &_m.Mock}
+}
+
+// SetNotifyStatus provides a mock function with given fields: ctx, eventIDs, notifyStatus
+func (_m *Storage) SetNotifyStatus(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error {
+	ret := _m.Called(ctx, eventIDs, notifyStatus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNotifyStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64, storage.NotifyStatus) error); ok {
+		r0 = rf(ctx, eventIDs, notifyStatus)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_SetNotifyStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetNotifyStatus'
+type Storage_SetNotifyStatus_Call struct {
+	*mock.Call
+}
+
+// SetNotifyStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - eventIDs []uint64
+//   - notifyStatus storage.NotifyStatus
+func (_e *Storage_Expecter) SetNotifyStatus(ctx interface{}, eventIDs interface{}, notifyStatus interface{}) *Storage_SetNotifyStatus_Call {
+	return &Storage_SetNotifyStatus_Call{Call: _e.mock.On("SetNotifyStatus", ctx, eventIDs, notifyStatus)}
+}
+
+func (_c *Storage_SetNotifyStatus_Call) Run(run func(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus)) *Storage_SetNotifyStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]uint64), args[2].(storage.NotifyStatus))
+	})
+	return _c
+}
+
+func (_c *Storage_SetNotifyStatus_Call) Return(_a0 error) *Storage_SetNotifyStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_SetNotifyStatus_Call) RunAndReturn(run func(context.Context, []uint64, storage.NotifyStatus) error) *Storage_SetNotifyStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,17 +3,20 @@ package sender
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/model"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 )
 
 type Sender struct {
-	logger  Logger
-	storage Storage
-	queue   Queue
-	done    chan struct{}
-	ctx     context.Context
+	logger   Logger
+	storage  Storage
+	queue    Queue
+	done     chan struct{}
+	ctx      context.Context
+	inFlight lifecycle.InFlight
 }
 
 type Logger interface {
@@ -58,11 +61,16 @@ func (s *Sender) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop ждёт, пока будут обработаны уже полученные уведомления, но не дольше отмены ctx.
+// Получение новых уведомлений должно быть остановлено раньше (остановкой consumer'а очереди).
 func (s *Sender) Stop(ctx context.Context) error {
 	s.logger.Info(ctx, "stopping sender")
 
-	<-ctx.Done()
-	<-s.done
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %d notifications in flight", lifecycle.ErrCutOff, s.inFlight.Count())
+	}
 
 	s.logger.Info(ctx, "stopped sender")
 	return nil
@@ -82,21 +90,57 @@ func (s *Sender) processEvents(ctx context.Context) error {
 		defer close(s.done)
 
 		for d := range data {
-			var notification model.NotificationDto
-
-			err := json.Unmarshal(d, &notification)
-			if err != nil {
-				s.logger.Error(ctx, err, "failed to read notification")
-			} else {
-				s.logger.Info(ctx, "received notification", "notification", notification)
-
-				err = s.storage.SetNotifyStatus(s.ctx, []uint64{notification.EventID}, storage.Notified)
-				if err != nil {
-					s.logger.Error(s.ctx, err, "failed setting notify status", "eventID", notification.EventID)
-				}
-			}
+			s.processEvent(ctx, d)
 		}
 	}()
 
 	return nil
 }
+
+func (s *Sender) processEvent(ctx context.Context, data []byte) {
+	defer s.inFlight.Start()()
+
+	var message model.Message
+
+	err := json.Unmarshal(data, &message)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to read message")
+		return
+	}
+	if message.Type == model.MessageTypeDigest {
+		s.processDigest(ctx, data)
+		return
+	}
+
+	var notification model.NotificationDto
+
+	err = json.Unmarshal(data, &notification)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to read notification")
+		return
+	}
+	s.logger.Info(ctx, "received notification", "notification", notification)
+
+	err = s.storage.SetNotifyStatus(s.ctx, []uint64{notification.EventID}, storage.Notified)
+	if err != nil {
+		s.logger.Error(s.ctx, err, "failed setting notify status", "eventID", notification.EventID)
+	}
+}
+
+// processDigest доставляет сводку событий тем же способом, что и уведомления; статусы событий не меняются.
+func (s *Sender) processDigest(ctx context.Context, data []byte) {
+	var digest model.DigestDto
+
+	err := json.Unmarshal(data, &digest)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to read digest")
+		return
+	}
+	s.logger.Info(
+		ctx, "received digest",
+		"userId", digest.UserID,
+		"period", digest.Period,
+		"subject", digest.Subject,
+		"body", digest.Body,
+	)
+}
@@ -0,0 +1,80 @@
+package sender
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/sender/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProcessEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		data   string
+		expect func(logger *mocks.Logger, mockedStorage *mocks.Storage)
+	}{
+		{
+			name: "notification",
+			data: `{"type":"notification","eventId":5,"eventTitle":"my event","userId":12345}`,
+			expect: func(logger *mocks.Logger, mockedStorage *mocks.Storage) {
+				logger.EXPECT().Info(ctx, "received notification", "notification", mock.Anything).Return().Once()
+				mockedStorage.EXPECT().SetNotifyStatus(ctx, []uint64{5}, storage.Notified).Return(nil).Once()
+			},
+		},
+		{
+			// от старых версий планировщика уведомления приходят без типа
+			name: "notification without type",
+			data: `{"eventId":6,"eventTitle":"my event","userId":12345}`,
+			expect: func(logger *mocks.Logger, mockedStorage *mocks.Storage) {
+				logger.EXPECT().Info(ctx, "received notification", "notification", mock.Anything).Return().Once()
+				mockedStorage.EXPECT().SetNotifyStatus(ctx, []uint64{6}, storage.Notified).Return(nil).Once()
+			},
+		},
+		{
+			// статусы событий по сводке не меняются
+			name: "digest",
+			data: `{"type":"digest","userId":12345,"period":"DAILY","events":[{"eventId":5}],` +
+				`"subject":"Events for Monday, 08.07.2024","body":"10:00 - 11:00  my event\n"}`,
+			expect: func(logger *mocks.Logger, _ *mocks.Storage) {
+				logger.EXPECT().Info(
+					ctx, "received digest",
+					"userId", uint64(12345),
+					"period", "DAILY",
+					"subject", "Events for Monday, 08.07.2024",
+					"body", "10:00 - 11:00  my event\n",
+				).Return().Once()
+			},
+		},
+		{
+			name: "invalid message",
+			data: `{"type":`,
+			expect: func(logger *mocks.Logger, _ *mocks.Storage) {
+				logger.EXPECT().Error(ctx, mock.Anything, "failed to read message").Return().Once()
+			},
+		},
+		{
+			name: "invalid digest",
+			data: `{"type":"digest","userId":"12345"}`,
+			expect: func(logger *mocks.Logger, _ *mocks.Storage) {
+				logger.EXPECT().Error(ctx, mock.Anything, "failed to read digest").Return().Once()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockedLogger := mocks.NewLogger(t)
+			mockedStorage := mocks.NewStorage(t)
+			tt.expect(mockedLogger, mockedStorage)
+
+			s := NewSender(ctx, mockedLogger, mockedStorage, mocks.NewQueue(t))
+			s.processEvent(ctx, []byte(tt.data))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package apierror - общий для HTTP и gRPC каталог ошибок API.
+// HTTP-сервер отдаёт ошибку как application/problem+json, gRPC-сервер - как google.rpc.Status с деталями.
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	"google.golang.org/grpc/codes"
+)
+
+// Code - код ошибки из каталога; передаётся клиенту в problem+json и в google.rpc.ErrorInfo.Reason.
+type Code string
+
+const (
+	CodeInvalidArgument Code = "INVALID_ARGUMENT"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeBusyTime        Code = "BUSY_TIME"
+	CodeRateLimited     Code = "RATE_LIMITED"
+	CodeQuotaExceeded   Code = "QUOTA_EXCEEDED"
+	// CodeUnauthenticated - сертификат клиента (mTLS) не определяет пользователя.
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+	// CodePermissionDenied - пользователь не может менять объект, например ресурс, созданный другим пользователем.
+	CodePermissionDenied Code = "PERMISSION_DENIED"
+	// CodeFailedPrecondition - операция невозможна в текущем состоянии ресурса, например удаление непустого календаря.
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	CodeInternal           Code = "INTERNAL"
+)
+
+// Domain - домен ошибок в google.rpc.ErrorInfo.
+const Domain = "calendar"
+
+const (
+	// сообщение для внутренних ошибок: подробности остаются в логах сервера.
+	internalMessage    = "internal error"
+	rateLimitedMessage = "too many requests"
+)
+
+// FieldViolation - ошибка отдельного поля запроса.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error - ошибка API: код из каталога, сообщение для клиента и подробности.
+// RetryAfter - через сколько можно повторить запрос (заголовок Retry-After, google.rpc.RetryInfo).
+type Error struct {
+	Code            Code
+	Message         string
+	FieldViolations []FieldViolation
+	Conflicts       []*app.EventDto
+	RetryAfter      time.Duration
+	cause           error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// InvalidArgument создаёт ошибку некорректного запроса с ошибками полей.
+func InvalidArgument(message string, violations ...FieldViolation) *Error {
+	return &Error{Code: CodeInvalidArgument, Message: message, FieldViolations: violations}
+}
+
+// InvalidField создаёт ошибку некорректного значения одного поля.
+func InvalidField(field string, err error) *Error {
+	return &Error{
+		Code:            CodeInvalidArgument,
+		Message:         err.Error(),
+		FieldViolations: []FieldViolation{{Field: field, Message: err.Error()}},
+		cause:           err,
+	}
+}
+
+// RateLimited создаёт ошибку превышения частоты запросов.
+func RateLimited(retryAfter time.Duration) *Error {
+	return &Error{Code: CodeRateLimited, Message: rateLimitedMessage, RetryAfter: retryAfter}
+}
+
+// Unauthenticated создаёт ошибку запроса, пользователь которого не подтверждён.
+func Unauthenticated(message string) *Error {
+	return &Error{Code: CodeUnauthenticated, Message: message}
+}
+
+// From сопоставляет ошибку приложения или хранилища коду из каталога.
+// Неизвестные ошибки считаются внутренними, их текст клиенту не передаётся.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var busyTimeErr *app.BusyTimeError
+	var resourceBusyErr *storage.ResourceBusyError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &busyTimeErr):
+		return &Error{Code: CodeBusyTime, Message: err.Error(), Conflicts: busyTimeErr.Conflicts, cause: err}
+	case errors.Is(err, storage.ErrBusyTime):
+		return &Error{Code: CodeBusyTime, Message: err.Error(), cause: err}
+	case errors.As(err, &resourceBusyErr):
+		// события, занимающие ресурс, могут принадлежать другим пользователям, поэтому передаются только ресурсы
+		violations := make([]FieldViolation, len(resourceBusyErr.ResourceIDs))
+		for i, resourceID := range resourceBusyErr.ResourceIDs {
+			violations[i] = FieldViolation{Field: "event.resourceIds", Message: fmt.Sprintf("resource %d is busy", resourceID)}
+		}
+		return &Error{Code: CodeBusyTime, Message: err.Error(), FieldViolations: violations, cause: err}
+	case errors.Is(err, storage.ErrEventNotFound), errors.Is(err, storage.ErrCalendarNotFound),
+		errors.Is(err, storage.ErrResourceNotFound):
+		return &Error{Code: CodeNotFound, Message: err.Error(), cause: err}
+	case errors.Is(err, app.ErrNotResourceOwner):
+		return &Error{Code: CodePermissionDenied, Message: err.Error(), cause: err}
+	case errors.Is(err, storage.ErrCalendarNotEmpty), errors.Is(err, storage.ErrDefaultCalendar),
+		errors.Is(err, storage.ErrResourceInUse):
+		return &Error{Code: CodeFailedPrecondition, Message: err.Error(), cause: err}
+	// сообщение об отсутствии пользователя передаётся клиенту в тексте ошибки
+	case errors.Is(err, app.ErrOutsideWorkingHours), errors.Is(err, app.ErrOutOfOffice):
+		return &Error{Code: CodeFailedPrecondition, Message: err.Error(), cause: err}
+	case errors.As(err, &validationErrs):
+		prefix, message := "event.", app.ErrNotValidEvent.Error()
+		switch {
+		case errors.Is(err, app.ErrNotValidCalendar):
+			prefix, message = "calendar.", app.ErrNotValidCalendar.Error()
+		case errors.Is(err, app.ErrNotValidResource):
+			prefix, message = "resource.", app.ErrNotValidResource.Error()
+		case errors.Is(err, app.ErrNotValidSettings):
+			prefix, message = "settings.", app.ErrNotValidSettings.Error()
+		}
+		violations := make([]FieldViolation, len(validationErrs))
+		for i, validationErr := range validationErrs {
+			violations[i] = FieldViolation{Field: prefix + validationErr.Field, Message: validationErr.Err.Error()}
+		}
+		return &Error{Code: CodeInvalidArgument, Message: message, FieldViolations: violations, cause: err}
+	case errors.Is(err, app.ErrQuotaExceeded):
+		return &Error{Code: CodeQuotaExceeded, Message: err.Error(), cause: err}
+	case errors.Is(err, app.ErrNotValidOverlapPolicy):
+		return InvalidField("overlapPolicy", err)
+	case errors.Is(err, app.ErrNotValidIdempotencyKey), errors.Is(err, storage.ErrIdempotencyKeyReused):
+		return InvalidField("idempotencyKey", err)
+	case errors.Is(err, app.ErrNotValidPeriod):
+		return InvalidField("end", err)
+	default:
+		return &Error{Code: CodeInternal, Message: internalMessage, cause: err}
+	}
+}
+
+// HTTPStatus возвращает HTTP-статус ответа для кода.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeInvalidArgument, CodeBusyTime:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeFailedPrecondition:
+		return http.StatusConflict
+	case CodeRateLimited, CodeQuotaExceeded:
+		return http.StatusTooManyRequests
+	case CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeInternal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode возвращает код gRPC-статуса для кода.
+func (c Code) GRPCCode() codes.Code {
+	switch c {
+	case CodeInvalidArgument, CodeBusyTime:
+		return codes.InvalidArgument
+	case CodeNotFound:
+		return codes.NotFound
+	case CodeFailedPrecondition:
+		return codes.FailedPrecondition
+	case CodeRateLimited, CodeQuotaExceeded:
+		return codes.ResourceExhausted
+	case CodeUnauthenticated:
+		return codes.Unauthenticated
+	case CodePermissionDenied:
+		return codes.PermissionDenied
+	case CodeInternal:
+		return codes.Internal
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,202 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/validator"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFrom(t *testing.T) {
+	t.Parallel()
+
+	conflicts := []*app.EventDto{{ID: 1, Title: "my event"}}
+
+	tests := []struct {
+		testName          string
+		err               error
+		expectedCode      Code
+		expectedMessage   string
+		expectedFields    []FieldViolation
+		expectedConflicts []*app.EventDto
+	}{
+		{
+			testName:        "not found",
+			err:             fmt.Errorf("get event: %w", storage.ErrEventNotFound),
+			expectedCode:    CodeNotFound,
+			expectedMessage: "get event: event not found",
+		},
+		{
+			testName:          "busy time with conflicts",
+			err:               &app.BusyTimeError{Conflicts: conflicts},
+			expectedCode:      CodeBusyTime,
+			expectedMessage:   storage.ErrBusyTime.Error(),
+			expectedConflicts: conflicts,
+		},
+		{
+			testName:        "not valid overlap policy",
+			err:             app.ErrNotValidOverlapPolicy,
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: app.ErrNotValidOverlapPolicy.Error(),
+			expectedFields:  []FieldViolation{{Field: "overlapPolicy", Message: app.ErrNotValidOverlapPolicy.Error()}},
+		},
+		{
+			testName: "not valid event",
+			err: fmt.Errorf("%w: %w", app.ErrNotValidEvent, validator.ValidationErrors{
+				{Field: "title", Err: validator.ErrStringTooShort},
+				{Field: "endDate", Err: validator.ErrTimeNotAfter},
+			}),
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: app.ErrNotValidEvent.Error(),
+			expectedFields: []FieldViolation{
+				{Field: "event.title", Message: validator.ErrStringTooShort.Error()},
+				{Field: "event.endDate", Message: validator.ErrTimeNotAfter.Error()},
+			},
+		},
+		{
+			testName: "not valid calendar",
+			err: fmt.Errorf("%w: %w", app.ErrNotValidCalendar, validator.ValidationErrors{
+				{Field: "color", Err: validator.ErrStringNotMatchRegexp},
+			}),
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: app.ErrNotValidCalendar.Error(),
+			expectedFields:  []FieldViolation{{Field: "calendar.color", Message: validator.ErrStringNotMatchRegexp.Error()}},
+		},
+		{
+			testName: "not valid resource",
+			err: fmt.Errorf("%w: %w", app.ErrNotValidResource, validator.ValidationErrors{
+				{Field: "capacity", Err: validator.ErrIntMin},
+			}),
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: app.ErrNotValidResource.Error(),
+			expectedFields:  []FieldViolation{{Field: "resource.capacity", Message: validator.ErrIntMin.Error()}},
+		},
+		{
+			testName:        "resource busy",
+			err:             &storage.ResourceBusyError{ResourceIDs: []uint64{3, 5}},
+			expectedCode:    CodeBusyTime,
+			expectedMessage: storage.ErrResourceBusy.Error(),
+			expectedFields: []FieldViolation{
+				{Field: "event.resourceIds", Message: "resource 3 is busy"},
+				{Field: "event.resourceIds", Message: "resource 5 is busy"},
+			},
+		},
+		{
+			testName:        "resource in use",
+			err:             storage.ErrResourceInUse,
+			expectedCode:    CodeFailedPrecondition,
+			expectedMessage: storage.ErrResourceInUse.Error(),
+		},
+		{
+			testName: "not valid settings",
+			err: fmt.Errorf("%w: %w", app.ErrNotValidSettings, validator.ValidationErrors{
+				{Field: "timeZone", Err: app.ErrNotValidTimeZone},
+			}),
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: app.ErrNotValidSettings.Error(),
+			expectedFields:  []FieldViolation{{Field: "settings.timeZone", Message: app.ErrNotValidTimeZone.Error()}},
+		},
+		{
+			testName:        "out of office",
+			err:             &app.OutOfOfficeError{Message: "vacation"},
+			expectedCode:    CodeFailedPrecondition,
+			expectedMessage: "user is out of office: vacation",
+		},
+		{
+			testName:        "outside working hours",
+			err:             app.ErrOutsideWorkingHours,
+			expectedCode:    CodeFailedPrecondition,
+			expectedMessage: app.ErrOutsideWorkingHours.Error(),
+		},
+		{
+			testName:        "not resource owner",
+			err:             app.ErrNotResourceOwner,
+			expectedCode:    CodePermissionDenied,
+			expectedMessage: app.ErrNotResourceOwner.Error(),
+		},
+		{
+			testName:        "calendar not empty",
+			err:             storage.ErrCalendarNotEmpty,
+			expectedCode:    CodeFailedPrecondition,
+			expectedMessage: storage.ErrCalendarNotEmpty.Error(),
+		},
+		{
+			testName:        "quota exceeded",
+			err:             app.ErrQuotaExceeded,
+			expectedCode:    CodeQuotaExceeded,
+			expectedMessage: app.ErrQuotaExceeded.Error(),
+		},
+		{
+			testName:        "internal error is hidden",
+			err:             errors.New("connection refused"),
+			expectedCode:    CodeInternal,
+			expectedMessage: internalMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			apiErr := From(tt.err)
+			require.Equal(t, tt.expectedCode, apiErr.Code)
+			require.Equal(t, tt.expectedMessage, apiErr.Message)
+			require.Equal(t, tt.expectedFields, apiErr.FieldViolations)
+			require.Equal(t, tt.expectedConflicts, apiErr.Conflicts)
+			require.ErrorIs(t, apiErr, tt.err)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		apiErr := InvalidArgument("request validation failed",
+			FieldViolation{Field: "event.title", Message: "field is required"},
+		)
+
+		st, ok := status.FromError(apiErr)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+
+		actual := FromStatus(st)
+		require.Equal(t, apiErr.Code, actual.Code)
+		require.Equal(t, apiErr.Message, actual.Message)
+		require.Equal(t, apiErr.FieldViolations, actual.FieldViolations)
+	})
+
+	t.Run("retry info", func(t *testing.T) {
+		st, ok := status.FromError(RateLimited(1500 * time.Millisecond))
+		require.True(t, ok)
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+
+		actual := FromStatus(st)
+		require.Equal(t, CodeRateLimited, actual.Code)
+		require.Equal(t, 1500*time.Millisecond, actual.RetryAfter)
+		require.Equal(t, http.StatusTooManyRequests, actual.Code.HTTPStatus())
+	})
+
+	t.Run("status without details", func(t *testing.T) {
+		actual := FromStatus(status.New(codes.NotFound, "not found"))
+		require.Equal(t, CodeNotFound, actual.Code)
+		require.Equal(t, http.StatusNotFound, actual.Code.HTTPStatus())
+
+		actual = FromStatus(status.New(codes.Unauthenticated, "unknown client"))
+		require.Equal(t, CodeUnauthenticated, actual.Code)
+		require.Equal(t, http.StatusUnauthorized, actual.Code.HTTPStatus())
+
+		actual = FromStatus(status.New(codes.PermissionDenied, "not an owner"))
+		require.Equal(t, CodePermissionDenied, actual.Code)
+		require.Equal(t, http.StatusForbidden, actual.Code.HTTPStatus())
+	})
+}
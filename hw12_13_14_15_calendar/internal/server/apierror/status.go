@@ -0,0 +1,81 @@
+package apierror
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// GRPCStatus возвращает gRPC-статус с деталями google.rpc.ErrorInfo, google.rpc.BadRequest и google.rpc.RetryInfo.
+// Пересекающиеся события в детали не попадают: их добавляет gRPC-сервер в своём формате.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.GRPCCode(), e.Message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(e.Code), Domain: Domain}}
+	if len(e.FieldViolations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range e.FieldViolations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+
+	if stWithDetails, err := st.WithDetails(details...); err == nil {
+		return stWithDetails
+	}
+	return st
+}
+
+// FromStatus восстанавливает ошибку API из gRPC-статуса.
+// Если в деталях нет google.rpc.ErrorInfo, код определяется по коду статуса.
+func FromStatus(st *status.Status) *Error {
+	apiErr := &Error{Code: codeFromGRPC(st.Code()), Message: st.Message(), cause: st.Err()}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetDomain() == Domain {
+				apiErr.Code = Code(detail.GetReason())
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				apiErr.FieldViolations = append(apiErr.FieldViolations, FieldViolation{
+					Field:   violation.GetField(),
+					Message: violation.GetDescription(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			apiErr.RetryAfter = detail.GetRetryDelay().AsDuration()
+		}
+	}
+	return apiErr
+}
+
+func codeFromGRPC(code codes.Code) Code {
+	if code == codes.InvalidArgument {
+		return CodeInvalidArgument
+	}
+	if code == codes.NotFound {
+		return CodeNotFound
+	}
+	if code == codes.ResourceExhausted {
+		return CodeRateLimited
+	}
+	if code == codes.FailedPrecondition {
+		return CodeFailedPrecondition
+	}
+	if code == codes.Unauthenticated {
+		return CodeUnauthenticated
+	}
+	if code == codes.PermissionDenied {
+		return CodePermissionDenied
+	}
+	return CodeInternal
+}
@@ -0,0 +1,124 @@
+package internalgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminServer - административный API планировщика. Без TLS он должен слушать только loopback-адрес;
+// при mTLS доступ получают клиенты с сертификатом, подписанным CA из конфигурации.
+type AdminServer struct {
+	logger    Logger
+	scheduler Scheduler
+	addr      string
+	tls       bool
+	inFlight  lifecycle.InFlight
+	srv       *grpc.Server
+	pb.UnimplementedAdminServiceServer
+}
+
+type Scheduler interface {
+	Notify(ctx context.Context) error
+	Clear(ctx context.Context) error
+	ListNotifyStatus(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error)
+}
+
+// NewAdminServer создаёт сервер; tlsConfig == nil - без TLS.
+// grpc.Server создаётся здесь, чтобы Stop, вызванный из другой горутины, не гонялся со Start.
+func NewAdminServer(logger Logger, scheduler Scheduler, host string, grpcPort string, tlsConfig *tls.Config) *AdminServer {
+	s := &AdminServer{
+		logger:    logger,
+		scheduler: scheduler,
+		addr:      net.JoinHostPort(host, grpcPort),
+		tls:       tlsConfig != nil,
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			InFlightInterceptor(&s.inFlight),
+			LoggerInterceptor(s.logger),
+		),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.srv = grpc.NewServer(opts...)
+	reflection.Register(s.srv)
+	pb.RegisterAdminServiceServer(s.srv, s)
+
+	return s
+}
+
+func (s *AdminServer) Start(ctx context.Context) error {
+	s.logger.Info(ctx, "starting admin grpc server", "addr", s.addr, "tls", s.tls)
+
+	lsn, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		s.logger.Error(ctx, err, "failed to create admin grpc server")
+		return err
+	}
+
+	return s.srv.Serve(lsn)
+}
+
+// Stop перестаёт принимать запросы и ждёт завершения выполняющихся до отмены ctx.
+func (s *AdminServer) Stop(ctx context.Context) error {
+	s.logger.Info(ctx, "stopping admin grpc server")
+	return gracefulStop(ctx, s.srv, &s.inFlight)
+}
+
+func (s *AdminServer) ListNotifyStatus(ctx context.Context, req *pb.NotifyStatusRequest) (*pb.NotifyStatusList, error) {
+	if req == nil || req.StartDate == nil || req.EndDate == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+	}
+	startDate := req.StartDate.AsTime()
+	endDate := req.EndDate.AsTime()
+	if endDate.Before(startDate) {
+		return nil, status.Error(codes.InvalidArgument, "end date is before start date")
+	}
+
+	events, err := s.scheduler.ListNotifyStatus(ctx, startDate, endDate)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	statuses := make([]*pb.NotifyStatus, 0, len(events))
+	for _, event := range events {
+		statuses = append(statuses, &pb.NotifyStatus{
+			EventId:    event.ID,
+			UserId:     event.UserID,
+			Title:      event.Title,
+			StartDate:  timestamppb.New(event.StartDate),
+			NotifyDate: timestamppb.New(event.StartDate.Add(-event.NotifyBefore)),
+			Status:     event.NotifyStatus.String(),
+		})
+	}
+	return &pb.NotifyStatusList{Statuses: statuses}, nil
+}
+
+func (s *AdminServer) RunNotify(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.scheduler.Notify(ctx); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *AdminServer) RunClear(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.scheduler.Clear(ctx); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,107 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAdminServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("list notify status", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedScheduler := mocks.NewScheduler(t)
+		server := NewAdminServer(mockedLogger, mockedScheduler, "localhost", "", nil)
+
+		ctx := context.Background()
+		startDate := getTime(t, "2024-07-06 00:00:00")
+		endDate := getTime(t, "2024-07-07 00:00:00")
+		event := &storage.Event{
+			ID:           1,
+			Title:        "my event",
+			StartDate:    getTime(t, "2024-07-06 10:00:00"),
+			EndDate:      getTime(t, "2024-07-06 12:00:00"),
+			UserID:       12345,
+			NotifyBefore: time.Hour,
+			NotifyStatus: storage.NotifyInProgress,
+		}
+
+		mockedScheduler.EXPECT().ListNotifyStatus(ctx, startDate, endDate).Return([]*storage.Event{event}, nil)
+
+		actual, err := server.ListNotifyStatus(ctx, &pb.NotifyStatusRequest{
+			StartDate: timestamppb.New(startDate),
+			EndDate:   timestamppb.New(endDate),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(actual.Statuses))
+		require.Equal(t, uint64(1), actual.Statuses[0].EventId)
+		require.Equal(t, uint64(12345), actual.Statuses[0].UserId)
+		require.Equal(t, "IN_PROGRESS", actual.Statuses[0].Status)
+		require.True(t, getTime(t, "2024-07-06 09:00:00").Equal(actual.Statuses[0].NotifyDate.AsTime()))
+	})
+
+	t.Run("list notify status with invalid period", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedScheduler := mocks.NewScheduler(t)
+		server := NewAdminServer(mockedLogger, mockedScheduler, "localhost", "", nil)
+
+		_, err := server.ListNotifyStatus(context.Background(), &pb.NotifyStatusRequest{
+			StartDate: timestamppb.New(getTime(t, "2024-07-07 00:00:00")),
+			EndDate:   timestamppb.New(getTime(t, "2024-07-06 00:00:00")),
+		})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+	})
+
+	t.Run("run notify", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedScheduler := mocks.NewScheduler(t)
+		server := NewAdminServer(mockedLogger, mockedScheduler, "localhost", "", nil)
+
+		ctx := context.Background()
+		mockedScheduler.EXPECT().Notify(ctx).Return(nil)
+
+		_, err := server.RunNotify(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+	})
+
+	t.Run("run clear with error", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedScheduler := mocks.NewScheduler(t)
+		server := NewAdminServer(mockedLogger, mockedScheduler, "localhost", "", nil)
+
+		ctx := context.Background()
+		mockedScheduler.EXPECT().Clear(ctx).Return(errors.New("db is unavailable"))
+
+		_, err := server.RunClear(ctx, &emptypb.Empty{})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.Internal, st.Code())
+	})
+
+	t.Run("stop before start", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedScheduler := mocks.NewScheduler(t)
+		server := NewAdminServer(mockedLogger, mockedScheduler, "localhost", "0", nil)
+
+		ctx := context.Background()
+		mockedLogger.EXPECT().Info(ctx, "stopping admin grpc server").Return().Once()
+		mockedLogger.EXPECT().Info(ctx, "starting admin grpc server", "addr", "localhost:0", "tls", false).Return().Once()
+
+		require.NoError(t, server.Stop(ctx))
+		require.ErrorIs(t, server.Start(ctx), grpc.ErrServerStopped)
+	})
+}
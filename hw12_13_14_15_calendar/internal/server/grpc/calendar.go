@@ -0,0 +1,123 @@
+package internalgrpc
+
+import (
+	"context"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *Server) CreateCalendar(ctx context.Context, req *pb.CreateCalendarRequest) (*pb.CreateCalendarResponse, error) {
+	if req == nil || req.Calendar == nil {
+		return nil, s.statusError(ctx, requiredField("calendar"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	calendarID, err := s.app.CreateCalendar(ctx, *repackCalendarToDto(req.Calendar, userID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &pb.CreateCalendarResponse{Id: calendarID}, nil
+}
+
+func (s *Server) GetCalendar(ctx context.Context, req *pb.GetCalendarRequest) (*pb.Calendar, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	calendar, err := s.app.GetCalendar(ctx, userID, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return repackCalendarToProto(calendar), nil
+}
+
+func (s *Server) UpdateCalendar(ctx context.Context, req *pb.UpdateCalendarRequest) (*emptypb.Empty, error) {
+	if req == nil || req.Calendar == nil || req.Calendar.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("calendar.id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.UpdateCalendar(ctx, *repackCalendarToDto(req.Calendar, userID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DeleteCalendar(ctx context.Context, req *pb.DeleteCalendarRequest) (*emptypb.Empty, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.DeleteCalendar(ctx, userID, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListCalendars(ctx context.Context, _ *emptypb.Empty) (*pb.CalendarList, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	calendars, err := s.app.ListCalendars(ctx, userID)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	result := make([]*pb.Calendar, len(calendars))
+	for i, calendar := range calendars {
+		result[i] = repackCalendarToProto(calendar)
+	}
+	return &pb.CalendarList{Calendars: result}, nil
+}
+
+func repackCalendarToDto(in *pb.Calendar, userID uint64) *app.CalendarDto {
+	return &app.CalendarDto{
+		ID:           in.Id,
+		UserID:       userID,
+		Name:         in.Name,
+		Color:        in.Color,
+		NotifyBefore: in.NotifyBefore.AsDuration(),
+		SharedWith:   in.SharedWith,
+	}
+}
+
+func repackCalendarToProto(in *app.CalendarDto) *pb.Calendar {
+	return &pb.Calendar{
+		Id:           in.ID,
+		Name:         in.Name,
+		Color:        in.Color,
+		NotifyBefore: durationpb.New(in.NotifyBefore),
+		IsDefault:    in.IsDefault,
+		UserId:       in.UserID,
+		SharedWith:   in.SharedWith,
+	}
+}
@@ -2,16 +2,29 @@ package internalgrpc
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	timeLayout   = "02/Jan/2006:15:04:05 -0700"
-	healthMethod = "/grpc.health.v1.Health/Check"
+	timeLayout          = "02/Jan/2006:15:04:05 -0700"
+	healthMethod        = "/grpc.health.v1.Health/Check"
+	forwardedForHeader  = "x-forwarded-for"
+	healthServicePrefix = "/grpc.health.v1."
 )
 
 func LoggerInterceptor(logger Logger) grpc.UnaryServerInterceptor {
@@ -40,3 +53,123 @@ func LoggerInterceptor(logger Logger) grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+func InFlightInterceptor(inFlight *lifecycle.InFlight) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		defer inFlight.Start()()
+		return handler(ctx, req)
+	}
+}
+
+// gracefulStop ждёт завершения выполняющихся запросов до отмены ctx, после чего закрывает соединения.
+func gracefulStop(ctx context.Context, srv *grpc.Server, inFlight *lifecycle.InFlight) error {
+	if srv == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		count := inFlight.Count()
+		srv.Stop()
+		return fmt.Errorf("%d grpc requests in flight: %w", count, ctx.Err())
+	}
+}
+
+func SourceInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(app.ContextWithSource(ctx, app.SourceGRPC), req)
+	}
+}
+
+type certClientCtxKey struct{}
+
+// certClient - клиент, подтверждённый сертификатом (mTLS): пользователь или доверенный gateway.
+type certClient struct {
+	userID  uint64
+	gateway bool
+}
+
+// ClientCertInterceptor определяет пользователя по сертификату клиента (mTLS): числовой CommonName заменяет
+// переданный в метаданных x-user-id. Передавать x-user-id самому может только сервис с CommonName trustedGateway
+// (grpc-gateway), остальные сертификаты отклоняются. Без mTLS метаданные не меняются.
+func ClientCertInterceptor(trustedGateway string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		p, exists := peer.FromContext(ctx)
+		if !exists {
+			return handler(ctx, req)
+		}
+		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(tlsInfo.State.VerifiedChains) == 0 {
+			return handler(ctx, req)
+		}
+		userID, ok := tlsconfig.UserID(tlsInfo.State.VerifiedChains)
+		if !ok {
+			if trustedGateway != "" && tlsconfig.CommonName(tlsInfo.State.VerifiedChains) == trustedGateway {
+				return handler(context.WithValue(ctx, certClientCtxKey{}, certClient{gateway: true}), req)
+			}
+			return nil, apierror.Unauthenticated(errNoCertUser.Error())
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		md = md.Copy()
+		md.Set(userIDHeader, strconv.FormatUint(userID, 10))
+		ctx = context.WithValue(ctx, certClientCtxKey{}, certClient{userID: userID})
+		return handler(metadata.NewIncomingContext(ctx, md), req)
+	}
+}
+
+// RateLimitInterceptor ограничивает частоту запросов клиента к методу; имя маршрута - имя метода.
+// Запросы через grpc-gateway ограничиваются здесь же: для них IP-адрес берётся из x-forwarded-for.
+func RateLimitInterceptor(logger Logger, limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		route := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		allowed, retryAfter := limiter.Allow(rateLimitKey(ctx), route)
+		if !allowed {
+			logger.Warn(ctx, "grpc request rate limited", "method", info.FullMethod)
+			return nil, apierror.RateLimited(retryAfter)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ключ клиента: пользователь из сертификата (mTLS), а без него - IP-адрес. x-user-id из метаданных
+// не используется: его может подставить любой клиент. x-forwarded-for учитывается, только если запрос
+// пришёл от grpc-gateway (доверенный сертификат или loopback-адрес), и из него берётся последний адрес -
+// его дописывает gateway, а предыдущие мог передать сам клиент.
+func rateLimitKey(ctx context.Context) string {
+	client, _ := ctx.Value(certClientCtxKey{}).(certClient)
+	if client.userID != 0 {
+		return ratelimit.UserKey(strconv.FormatUint(client.userID, 10))
+	}
+
+	p, exists := peer.FromContext(ctx)
+	if !exists {
+		return ratelimit.IPKey("")
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if ip := net.ParseIP(host); client.gateway || (ip != nil && ip.IsLoopback()) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(forwardedForHeader); len(values) > 0 {
+			forwarded := strings.Split(values[len(values)-1], ",")
+			if addr := strings.TrimSpace(forwarded[len(forwarded)-1]); addr != "" {
+				return ratelimit.IPKey(addr)
+			}
+		}
+	}
+	return ratelimit.IPKey(host)
+}
@@ -0,0 +1,123 @@
+package internalgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	t.Parallel()
+
+	mockedLogger := mocks.NewLogger(t)
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 1, Burst: 1}, nil)
+	interceptor := RateLimitInterceptor(mockedLogger, limiter)
+
+	handler := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	// запрос с адреса ip и метаданными kv
+	request := func(ip string, kv ...string) context.Context {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+	}
+	requireLimited := func(ctx context.Context) {
+		t.Helper()
+		mockedLogger.EXPECT().Warn(ctx, mock.Anything, mock.Anything, mock.Anything).Return().Once()
+		err := call(ctx, "/event.EventService/GetEvent")
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	}
+
+	// пользователь из сертификата ограничивается отдельно от других клиентов с того же адреса
+	ctx := context.WithValue(request("10.0.0.1"), certClientCtxKey{}, certClient{userID: 12345})
+	require.NoError(t, call(ctx, "/event.EventService/GetEvent"))
+
+	mockedLogger.EXPECT().Warn(ctx, mock.Anything, mock.Anything, mock.Anything).Return().Once()
+	err := call(ctx, "/event.EventService/GetEvent")
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	var retryInfo *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = info
+		}
+	}
+	require.NotNil(t, retryInfo)
+	require.InDelta(t, time.Second, retryInfo.GetRetryDelay().AsDuration(), float64(100*time.Millisecond))
+
+	// health-check не ограничивается
+	require.NoError(t, call(ctx, healthMethod))
+
+	// x-user-id из метаданных не подтверждён, поэтому запросы ограничиваются по IP клиента
+	require.NoError(t, call(request("10.0.0.1", userIDHeader, "1"), "/event.EventService/GetEvent"))
+	requireLimited(request("10.0.0.1", userIDHeader, "2"))
+
+	// x-forwarded-for от удалённого клиента не учитывается
+	requireLimited(request("10.0.0.1", forwardedForHeader, "10.0.0.2"))
+
+	// от gateway (loopback) учитывается последний адрес x-forwarded-for - его дописывает gateway
+	require.NoError(t, call(request("127.0.0.1", forwardedForHeader, "10.0.0.3"), "/event.EventService/GetEvent"))
+	requireLimited(request("127.0.0.1", forwardedForHeader, "10.0.0.4, 10.0.0.3"))
+	gateway := context.WithValue(request("10.0.0.10", forwardedForHeader, "10.0.0.5"), certClientCtxKey{}, certClient{gateway: true})
+	require.NoError(t, call(gateway, "/event.EventService/GetEvent"))
+}
+
+func TestClientCertInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor := ClientCertInterceptor("calendar-gateway")
+	call := func(commonName string) (string, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(userIDHeader, "54321"))
+		if commonName != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+			ctx = peer.NewContext(ctx, &peer.Peer{
+				Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)},
+				AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+			})
+		}
+		var userID string
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			userID = md.Get(userIDHeader)[0]
+			return nil, nil
+		})
+		return userID, err
+	}
+
+	// пользователь из сертификата заменяет метаданные
+	userID, err := call("12345")
+	require.NoError(t, err)
+	require.Equal(t, "12345", userID)
+	// доверенный gateway и запрос без сертификата не меняют метаданные
+	userID, err = call("calendar-gateway")
+	require.NoError(t, err)
+	require.Equal(t, "54321", userID)
+	userID, err = call("")
+	require.NoError(t, err)
+	require.Equal(t, "54321", userID)
+
+	// сертификат без пользователя не позволяет выдать себя за пользователя из метаданных
+	for _, commonName := range []string{"other-service", "0", "12345abc"} {
+		_, err = call(commonName)
+		require.Equal(t, codes.Unauthenticated, status.Code(err), commonName)
+	}
+}
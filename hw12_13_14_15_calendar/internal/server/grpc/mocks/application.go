@@ -26,7 +26,7 @@ func (_m *Application) EXPECT() *Application_Expecter {
 }
 
 // Create provides a mock function with given fields: ctx, eventDto
-func (_m *Application) Create(ctx context.Context, eventDto app.EventDto) (uint64, error) {
+func (_m *Application) Create(ctx context.Context, eventDto app.EventDto) (uint64, []*app.EventDto, []app.WarningDto, error) {
 	ret := _m.Called(ctx, eventDto)
 
 	if len(ret) == 0 {
@@ -34,8 +34,10 @@ func (_m *Application) Create(ctx context.Context, eventDto app.EventDto) (uint6
 	}
 
 	var r0 uint64
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) (uint64, error)); ok {
+	var r1 []*app.EventDto
+	var r2 []app.WarningDto
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) (uint64, []*app.EventDto, []app.WarningDto, error)); ok {
 		return rf(ctx, eventDto)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) uint64); ok {
@@ -44,13 +46,29 @@ func (_m *Application) Create(ctx context.Context, eventDto app.EventDto) (uint6
 		r0 = ret.Get(0).(uint64)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, app.EventDto) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, app.EventDto) []*app.EventDto); ok {
 		r1 = rf(ctx, eventDto)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*app.EventDto)
+		}
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, app.EventDto) []app.WarningDto); ok {
+		r2 = rf(ctx, eventDto)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).([]app.WarningDto)
+		}
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, app.EventDto) error); ok {
+		r3 = rf(ctx, eventDto)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
 }
 
 // Application_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
@@ -72,12 +90,126 @@ func (_c *Application_Create_Call) Run(run func(ctx context.Context, eventDto ap
 	return _c
 }
 
-func (_c *Application_Create_Call) Return(_a0 uint64, _a1 error) *Application_Create_Call {
+func (_c *Application_Create_Call) Return(_a0 uint64, _a1 []*app.EventDto, _a2 []app.WarningDto, _a3 error) *Application_Create_Call {
+	_c.Call.Return(_a0, _a1, _a2, _a3)
+	return _c
+}
+
+func (_c *Application_Create_Call) RunAndReturn(run func(context.Context, app.EventDto) (uint64, []*app.EventDto, []app.WarningDto, error)) *Application_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateCalendar provides a mock function with given fields: ctx, calendarDto
+func (_m *Application) CreateCalendar(ctx context.Context, calendarDto app.CalendarDto) (uint64, error) {
+	ret := _m.Called(ctx, calendarDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCalendar")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.CalendarDto) (uint64, error)); ok {
+		return rf(ctx, calendarDto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, app.CalendarDto) uint64); ok {
+		r0 = rf(ctx, calendarDto)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, app.CalendarDto) error); ok {
+		r1 = rf(ctx, calendarDto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_CreateCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateCalendar'
+type Application_CreateCalendar_Call struct {
+	*mock.Call
+}
+
+// CreateCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - calendarDto app.CalendarDto
+func (_e *Application_Expecter) CreateCalendar(ctx interface{}, calendarDto interface{}) *Application_CreateCalendar_Call {
+	return &Application_CreateCalendar_Call{Call: _e.mock.On("CreateCalendar", ctx, calendarDto)}
+}
+
+func (_c *Application_CreateCalendar_Call) Run(run func(ctx context.Context, calendarDto app.CalendarDto)) *Application_CreateCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.CalendarDto))
+	})
+	return _c
+}
+
+func (_c *Application_CreateCalendar_Call) Return(_a0 uint64, _a1 error) *Application_CreateCalendar_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_CreateCalendar_Call) RunAndReturn(run func(context.Context, app.CalendarDto) (uint64, error)) *Application_CreateCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateResource provides a mock function with given fields: ctx, resourceDto
+func (_m *Application) CreateResource(ctx context.Context, resourceDto app.ResourceDto) (uint64, error) {
+	ret := _m.Called(ctx, resourceDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateResource")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.ResourceDto) (uint64, error)); ok {
+		return rf(ctx, resourceDto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, app.ResourceDto) uint64); ok {
+		r0 = rf(ctx, resourceDto)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, app.ResourceDto) error); ok {
+		r1 = rf(ctx, resourceDto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_CreateResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateResource'
+type Application_CreateResource_Call struct {
+	*mock.Call
+}
+
+// CreateResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceDto app.ResourceDto
+func (_e *Application_Expecter) CreateResource(ctx interface{}, resourceDto interface{}) *Application_CreateResource_Call {
+	return &Application_CreateResource_Call{Call: _e.mock.On("CreateResource", ctx, resourceDto)}
+}
+
+func (_c *Application_CreateResource_Call) Run(run func(ctx context.Context, resourceDto app.ResourceDto)) *Application_CreateResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.ResourceDto))
+	})
+	return _c
+}
+
+func (_c *Application_CreateResource_Call) Return(_a0 uint64, _a1 error) *Application_CreateResource_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Application_Create_Call) RunAndReturn(run func(context.Context, app.EventDto) (uint64, error)) *Application_Create_Call {
+func (_c *Application_CreateResource_Call) RunAndReturn(run func(context.Context, app.ResourceDto) (uint64, error)) *Application_CreateResource_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -130,6 +262,102 @@ func (_c *Application_Delete_Call) RunAndReturn(run func(context.Context, uint64
 	return _c
 }
 
+// DeleteCalendar provides a mock function with given fields: ctx, userID, calendarID
+func (_m *Application) DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error {
+	ret := _m.Called(ctx, userID, calendarID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCalendar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, calendarID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_DeleteCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCalendar'
+type Application_DeleteCalendar_Call struct {
+	*mock.Call
+}
+
+// DeleteCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - calendarID uint64
+func (_e *Application_Expecter) DeleteCalendar(ctx interface{}, userID interface{}, calendarID interface{}) *Application_DeleteCalendar_Call {
+	return &Application_DeleteCalendar_Call{Call: _e.mock.On("DeleteCalendar", ctx, userID, calendarID)}
+}
+
+func (_c *Application_DeleteCalendar_Call) Run(run func(ctx context.Context, userID uint64, calendarID uint64)) *Application_DeleteCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_DeleteCalendar_Call) Return(_a0 error) *Application_DeleteCalendar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Application_DeleteCalendar_Call) RunAndReturn(run func(context.Context, uint64, uint64) error) *Application_DeleteCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteResource provides a mock function with given fields: ctx, userID, resourceID
+func (_m *Application) DeleteResource(ctx context.Context, userID uint64, resourceID uint64) error {
+	ret := _m.Called(ctx, userID, resourceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteResource")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, resourceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_DeleteResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteResource'
+type Application_DeleteResource_Call struct {
+	*mock.Call
+}
+
+// DeleteResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - resourceID uint64
+func (_e *Application_Expecter) DeleteResource(ctx interface{}, userID interface{}, resourceID interface{}) *Application_DeleteResource_Call {
+	return &Application_DeleteResource_Call{Call: _e.mock.On("DeleteResource", ctx, userID, resourceID)}
+}
+
+func (_c *Application_DeleteResource_Call) Run(run func(ctx context.Context, userID uint64, resourceID uint64)) *Application_DeleteResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_DeleteResource_Call) Return(_a0 error) *Application_DeleteResource_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Application_DeleteResource_Call) RunAndReturn(run func(context.Context, uint64, uint64) error) *Application_DeleteResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByID provides a mock function with given fields: ctx, userID, eventID
 func (_m *Application) GetByID(ctx context.Context, userID uint64, eventID uint64) (*app.EventDto, error) {
 	ret := _m.Called(ctx, userID, eventID)
@@ -190,29 +418,29 @@ func (_c *Application_GetByID_Call) RunAndReturn(run func(context.Context, uint6
 	return _c
 }
 
-// ListForDay provides a mock function with given fields: ctx, userID, date
-func (_m *Application) ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*app.EventDto, error) {
-	ret := _m.Called(ctx, userID, date)
+// GetCalendar provides a mock function with given fields: ctx, userID, calendarID
+func (_m *Application) GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*app.CalendarDto, error) {
+	ret := _m.Called(ctx, userID, calendarID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListForDay")
+		panic("no return value specified for GetCalendar")
 	}
 
-	var r0 []*app.EventDto
+	var r0 *app.CalendarDto
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) ([]*app.EventDto, error)); ok {
-		return rf(ctx, userID, date)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (*app.CalendarDto, error)); ok {
+		return rf(ctx, userID, calendarID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) []*app.EventDto); ok {
-		r0 = rf(ctx, userID, date)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) *app.CalendarDto); ok {
+		r0 = rf(ctx, userID, calendarID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*app.EventDto)
+			r0 = ret.Get(0).(*app.CalendarDto)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time) error); ok {
-		r1 = rf(ctx, userID, date)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, calendarID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,59 +448,59 @@ func (_m *Application) ListForDay(ctx context.Context, userID uint64, date time.
 	return r0, r1
 }
 
-// Application_ListForDay_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForDay'
-type Application_ListForDay_Call struct {
+// Application_GetCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCalendar'
+type Application_GetCalendar_Call struct {
 	*mock.Call
 }
 
-// ListForDay is a helper method to define mock.On call
+// GetCalendar is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID uint64
-//   - date time.Time
-func (_e *Application_Expecter) ListForDay(ctx interface{}, userID interface{}, date interface{}) *Application_ListForDay_Call {
-	return &Application_ListForDay_Call{Call: _e.mock.On("ListForDay", ctx, userID, date)}
+//   - calendarID uint64
+func (_e *Application_Expecter) GetCalendar(ctx interface{}, userID interface{}, calendarID interface{}) *Application_GetCalendar_Call {
+	return &Application_GetCalendar_Call{Call: _e.mock.On("GetCalendar", ctx, userID, calendarID)}
 }
 
-func (_c *Application_ListForDay_Call) Run(run func(ctx context.Context, userID uint64, date time.Time)) *Application_ListForDay_Call {
+func (_c *Application_GetCalendar_Call) Run(run func(ctx context.Context, userID uint64, calendarID uint64)) *Application_GetCalendar_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time))
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
 	})
 	return _c
 }
 
-func (_c *Application_ListForDay_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForDay_Call {
+func (_c *Application_GetCalendar_Call) Return(_a0 *app.CalendarDto, _a1 error) *Application_GetCalendar_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Application_ListForDay_Call) RunAndReturn(run func(context.Context, uint64, time.Time) ([]*app.EventDto, error)) *Application_ListForDay_Call {
+func (_c *Application_GetCalendar_Call) RunAndReturn(run func(context.Context, uint64, uint64) (*app.CalendarDto, error)) *Application_GetCalendar_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListForMonth provides a mock function with given fields: ctx, userID, startDate
-func (_m *Application) ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error) {
-	ret := _m.Called(ctx, userID, startDate)
+// GetFreeBusy provides a mock function with given fields: ctx, userID, startDate, endDateExclusive
+func (_m *Application) GetFreeBusy(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) (*app.FreeBusyDto, error) {
+	ret := _m.Called(ctx, userID, startDate, endDateExclusive)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListForMonth")
+		panic("no return value specified for GetFreeBusy")
 	}
 
-	var r0 []*app.EventDto
+	var r0 *app.FreeBusyDto
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) ([]*app.EventDto, error)); ok {
-		return rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) (*app.FreeBusyDto, error)); ok {
+		return rf(ctx, userID, startDate, endDateExclusive)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) []*app.EventDto); ok {
-		r0 = rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) *app.FreeBusyDto); ok {
+		r0 = rf(ctx, userID, startDate, endDateExclusive)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*app.EventDto)
+			r0 = ret.Get(0).(*app.FreeBusyDto)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time) error); ok {
-		r1 = rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, userID, startDate, endDateExclusive)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -280,59 +508,60 @@ func (_m *Application) ListForMonth(ctx context.Context, userID uint64, startDat
 	return r0, r1
 }
 
-// Application_ListForMonth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForMonth'
-type Application_ListForMonth_Call struct {
+// Application_GetFreeBusy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFreeBusy'
+type Application_GetFreeBusy_Call struct {
 	*mock.Call
 }
 
-// ListForMonth is a helper method to define mock.On call
+// GetFreeBusy is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID uint64
 //   - startDate time.Time
-func (_e *Application_Expecter) ListForMonth(ctx interface{}, userID interface{}, startDate interface{}) *Application_ListForMonth_Call {
-	return &Application_ListForMonth_Call{Call: _e.mock.On("ListForMonth", ctx, userID, startDate)}
+//   - endDateExclusive time.Time
+func (_e *Application_Expecter) GetFreeBusy(ctx interface{}, userID interface{}, startDate interface{}, endDateExclusive interface{}) *Application_GetFreeBusy_Call {
+	return &Application_GetFreeBusy_Call{Call: _e.mock.On("GetFreeBusy", ctx, userID, startDate, endDateExclusive)}
 }
 
-func (_c *Application_ListForMonth_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time)) *Application_ListForMonth_Call {
+func (_c *Application_GetFreeBusy_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time)) *Application_GetFreeBusy_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time))
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(time.Time))
 	})
 	return _c
 }
 
-func (_c *Application_ListForMonth_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForMonth_Call {
+func (_c *Application_GetFreeBusy_Call) Return(_a0 *app.FreeBusyDto, _a1 error) *Application_GetFreeBusy_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Application_ListForMonth_Call) RunAndReturn(run func(context.Context, uint64, time.Time) ([]*app.EventDto, error)) *Application_ListForMonth_Call {
+func (_c *Application_GetFreeBusy_Call) RunAndReturn(run func(context.Context, uint64, time.Time, time.Time) (*app.FreeBusyDto, error)) *Application_GetFreeBusy_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListForWeek provides a mock function with given fields: ctx, userID, startDate
-func (_m *Application) ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error) {
-	ret := _m.Called(ctx, userID, startDate)
+// GetHistory provides a mock function with given fields: ctx, userID, eventID
+func (_m *Application) GetHistory(ctx context.Context, userID uint64, eventID uint64) ([]*app.AuditRecordDto, error) {
+	ret := _m.Called(ctx, userID, eventID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListForWeek")
+		panic("no return value specified for GetHistory")
 	}
 
-	var r0 []*app.EventDto
+	var r0 []*app.AuditRecordDto
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) ([]*app.EventDto, error)); ok {
-		return rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) ([]*app.AuditRecordDto, error)); ok {
+		return rf(ctx, userID, eventID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) []*app.EventDto); ok {
-		r0 = rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) []*app.AuditRecordDto); ok {
+		r0 = rf(ctx, userID, eventID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*app.EventDto)
+			r0 = ret.Get(0).([]*app.AuditRecordDto)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time) error); ok {
-		r1 = rf(ctx, userID, startDate)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, eventID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -340,79 +569,828 @@ func (_m *Application) ListForWeek(ctx context.Context, userID uint64, startDate
 	return r0, r1
 }
 
-// Application_ListForWeek_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForWeek'
-type Application_ListForWeek_Call struct {
+// Application_GetHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetHistory'
+type Application_GetHistory_Call struct {
 	*mock.Call
 }
 
-// ListForWeek is a helper method to define mock.On call
+// GetHistory is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID uint64
-//   - startDate time.Time
-func (_e *Application_Expecter) ListForWeek(ctx interface{}, userID interface{}, startDate interface{}) *Application_ListForWeek_Call {
-	return &Application_ListForWeek_Call{Call: _e.mock.On("ListForWeek", ctx, userID, startDate)}
+//   - eventID uint64
+func (_e *Application_Expecter) GetHistory(ctx interface{}, userID interface{}, eventID interface{}) *Application_GetHistory_Call {
+	return &Application_GetHistory_Call{Call: _e.mock.On("GetHistory", ctx, userID, eventID)}
 }
 
-func (_c *Application_ListForWeek_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time)) *Application_ListForWeek_Call {
+func (_c *Application_GetHistory_Call) Run(run func(ctx context.Context, userID uint64, eventID uint64)) *Application_GetHistory_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time))
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
 	})
 	return _c
 }
 
-func (_c *Application_ListForWeek_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForWeek_Call {
+func (_c *Application_GetHistory_Call) Return(_a0 []*app.AuditRecordDto, _a1 error) *Application_GetHistory_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Application_ListForWeek_Call) RunAndReturn(run func(context.Context, uint64, time.Time) ([]*app.EventDto, error)) *Application_ListForWeek_Call {
+func (_c *Application_GetHistory_Call) RunAndReturn(run func(context.Context, uint64, uint64) ([]*app.AuditRecordDto, error)) *Application_GetHistory_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: ctx, eventDto
-func (_m *Application) Update(ctx context.Context, eventDto app.EventDto) error {
-	ret := _m.Called(ctx, eventDto)
+// GetResource provides a mock function with given fields: ctx, resourceID
+func (_m *Application) GetResource(ctx context.Context, resourceID uint64) (*app.ResourceDto, error) {
+	ret := _m.Called(ctx, resourceID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for GetResource")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) error); ok {
-		r0 = rf(ctx, eventDto)
+	var r0 *app.ResourceDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*app.ResourceDto, error)); ok {
+		return rf(ctx, resourceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *app.ResourceDto); ok {
+		r0 = rf(ctx, resourceID)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.ResourceDto)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, resourceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Application_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
-type Application_Update_Call struct {
+// Application_GetResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResource'
+type Application_GetResource_Call struct {
 	*mock.Call
 }
 
-// Update is a helper method to define mock.On call
+// GetResource is a helper method to define mock.On call
 //   - ctx context.Context
-//   - eventDto app.EventDto
-func (_e *Application_Expecter) Update(ctx interface{}, eventDto interface{}) *Application_Update_Call {
-	return &Application_Update_Call{Call: _e.mock.On("Update", ctx, eventDto)}
+//   - resourceID uint64
+func (_e *Application_Expecter) GetResource(ctx interface{}, resourceID interface{}) *Application_GetResource_Call {
+	return &Application_GetResource_Call{Call: _e.mock.On("GetResource", ctx, resourceID)}
 }
 
-func (_c *Application_Update_Call) Run(run func(ctx context.Context, eventDto app.EventDto)) *Application_Update_Call {
+func (_c *Application_GetResource_Call) Run(run func(ctx context.Context, resourceID uint64)) *Application_GetResource_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(app.EventDto))
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_GetResource_Call) Return(_a0 *app.ResourceDto, _a1 error) *Application_GetResource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_GetResource_Call) RunAndReturn(run func(context.Context, uint64) (*app.ResourceDto, error)) *Application_GetResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResourceAvailability provides a mock function with given fields: ctx, resourceID, startDate, endDateExclusive
+func (_m *Application) GetResourceAvailability(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*app.BusyIntervalDto, error) {
+	ret := _m.Called(ctx, resourceID, startDate, endDateExclusive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResourceAvailability")
+	}
+
+	var r0 []*app.BusyIntervalDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) ([]*app.BusyIntervalDto, error)); ok {
+		return rf(ctx, resourceID, startDate, endDateExclusive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, time.Time) []*app.BusyIntervalDto); ok {
+		r0 = rf(ctx, resourceID, startDate, endDateExclusive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.BusyIntervalDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, resourceID, startDate, endDateExclusive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_GetResourceAvailability_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResourceAvailability'
+type Application_GetResourceAvailability_Call struct {
+	*mock.Call
+}
+
+// GetResourceAvailability is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceID uint64
+//   - startDate time.Time
+//   - endDateExclusive time.Time
+func (_e *Application_Expecter) GetResourceAvailability(ctx interface{}, resourceID interface{}, startDate interface{}, endDateExclusive interface{}) *Application_GetResourceAvailability_Call {
+	return &Application_GetResourceAvailability_Call{Call: _e.mock.On("GetResourceAvailability", ctx, resourceID, startDate, endDateExclusive)}
+}
+
+func (_c *Application_GetResourceAvailability_Call) Run(run func(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time)) *Application_GetResourceAvailability_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Application_GetResourceAvailability_Call) Return(_a0 []*app.BusyIntervalDto, _a1 error) *Application_GetResourceAvailability_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_GetResourceAvailability_Call) RunAndReturn(run func(context.Context, uint64, time.Time, time.Time) ([]*app.BusyIntervalDto, error)) *Application_GetResourceAvailability_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserSettings provides a mock function with given fields: ctx, userID
+func (_m *Application) GetUserSettings(ctx context.Context, userID uint64) (*app.UserSettingsDto, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserSettings")
+	}
+
+	var r0 *app.UserSettingsDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (*app.UserSettingsDto, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) *app.UserSettingsDto); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.UserSettingsDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_GetUserSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserSettings'
+type Application_GetUserSettings_Call struct {
+	*mock.Call
+}
+
+// GetUserSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Application_Expecter) GetUserSettings(ctx interface{}, userID interface{}) *Application_GetUserSettings_Call {
+	return &Application_GetUserSettings_Call{Call: _e.mock.On("GetUserSettings", ctx, userID)}
+}
+
+func (_c *Application_GetUserSettings_Call) Run(run func(ctx context.Context, userID uint64)) *Application_GetUserSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_GetUserSettings_Call) Return(_a0 *app.UserSettingsDto, _a1 error) *Application_GetUserSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_GetUserSettings_Call) RunAndReturn(run func(context.Context, uint64) (*app.UserSettingsDto, error)) *Application_GetUserSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCalendars provides a mock function with given fields: ctx, userID
+func (_m *Application) ListCalendars(ctx context.Context, userID uint64) ([]*app.CalendarDto, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCalendars")
+	}
+
+	var r0 []*app.CalendarDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]*app.CalendarDto, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []*app.CalendarDto); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.CalendarDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListCalendars_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCalendars'
+type Application_ListCalendars_Call struct {
+	*mock.Call
+}
+
+// ListCalendars is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Application_Expecter) ListCalendars(ctx interface{}, userID interface{}) *Application_ListCalendars_Call {
+	return &Application_ListCalendars_Call{Call: _e.mock.On("ListCalendars", ctx, userID)}
+}
+
+func (_c *Application_ListCalendars_Call) Run(run func(ctx context.Context, userID uint64)) *Application_ListCalendars_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_ListCalendars_Call) Return(_a0 []*app.CalendarDto, _a1 error) *Application_ListCalendars_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListCalendars_Call) RunAndReturn(run func(context.Context, uint64) ([]*app.CalendarDto, error)) *Application_ListCalendars_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDeleted provides a mock function with given fields: ctx, userID
+func (_m *Application) ListDeleted(ctx context.Context, userID uint64) ([]*app.EventDto, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeleted")
+	}
+
+	var r0 []*app.EventDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]*app.EventDto, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []*app.EventDto); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.EventDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDeleted'
+type Application_ListDeleted_Call struct {
+	*mock.Call
+}
+
+// ListDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+func (_e *Application_Expecter) ListDeleted(ctx interface{}, userID interface{}) *Application_ListDeleted_Call {
+	return &Application_ListDeleted_Call{Call: _e.mock.On("ListDeleted", ctx, userID)}
+}
+
+func (_c *Application_ListDeleted_Call) Run(run func(ctx context.Context, userID uint64)) *Application_ListDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_ListDeleted_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListDeleted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListDeleted_Call) RunAndReturn(run func(context.Context, uint64) ([]*app.EventDto, error)) *Application_ListDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForDay provides a mock function with given fields: ctx, userID, date, filter
+func (_m *Application) ListForDay(ctx context.Context, userID uint64, date time.Time, filter app.EventFilter) ([]*app.EventDto, error) {
+	ret := _m.Called(ctx, userID, date, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForDay")
+	}
+
+	var r0 []*app.EventDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)); ok {
+		return rf(ctx, userID, date, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) []*app.EventDto); ok {
+		r0 = rf(ctx, userID, date, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.EventDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, app.EventFilter) error); ok {
+		r1 = rf(ctx, userID, date, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListForDay_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForDay'
+type Application_ListForDay_Call struct {
+	*mock.Call
+}
+
+// ListForDay is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - date time.Time
+//   - filter app.EventFilter
+func (_e *Application_Expecter) ListForDay(ctx interface{}, userID interface{}, date interface{}, filter interface{}) *Application_ListForDay_Call {
+	return &Application_ListForDay_Call{Call: _e.mock.On("ListForDay", ctx, userID, date, filter)}
+}
+
+func (_c *Application_ListForDay_Call) Run(run func(ctx context.Context, userID uint64, date time.Time, filter app.EventFilter)) *Application_ListForDay_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(app.EventFilter))
+	})
+	return _c
+}
+
+func (_c *Application_ListForDay_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForDay_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListForDay_Call) RunAndReturn(run func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)) *Application_ListForDay_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForMonth provides a mock function with given fields: ctx, userID, startDate, filter
+func (_m *Application) ListForMonth(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error) {
+	ret := _m.Called(ctx, userID, startDate, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForMonth")
+	}
+
+	var r0 []*app.EventDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)); ok {
+		return rf(ctx, userID, startDate, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) []*app.EventDto); ok {
+		r0 = rf(ctx, userID, startDate, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.EventDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, app.EventFilter) error); ok {
+		r1 = rf(ctx, userID, startDate, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListForMonth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForMonth'
+type Application_ListForMonth_Call struct {
+	*mock.Call
+}
+
+// ListForMonth is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - startDate time.Time
+//   - filter app.EventFilter
+func (_e *Application_Expecter) ListForMonth(ctx interface{}, userID interface{}, startDate interface{}, filter interface{}) *Application_ListForMonth_Call {
+	return &Application_ListForMonth_Call{Call: _e.mock.On("ListForMonth", ctx, userID, startDate, filter)}
+}
+
+func (_c *Application_ListForMonth_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter)) *Application_ListForMonth_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(app.EventFilter))
+	})
+	return _c
+}
+
+func (_c *Application_ListForMonth_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForMonth_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListForMonth_Call) RunAndReturn(run func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)) *Application_ListForMonth_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForWeek provides a mock function with given fields: ctx, userID, startDate, filter
+func (_m *Application) ListForWeek(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error) {
+	ret := _m.Called(ctx, userID, startDate, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForWeek")
+	}
+
+	var r0 []*app.EventDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)); ok {
+		return rf(ctx, userID, startDate, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time, app.EventFilter) []*app.EventDto); ok {
+		r0 = rf(ctx, userID, startDate, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.EventDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time, app.EventFilter) error); ok {
+		r1 = rf(ctx, userID, startDate, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListForWeek_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForWeek'
+type Application_ListForWeek_Call struct {
+	*mock.Call
+}
+
+// ListForWeek is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - startDate time.Time
+//   - filter app.EventFilter
+func (_e *Application_Expecter) ListForWeek(ctx interface{}, userID interface{}, startDate interface{}, filter interface{}) *Application_ListForWeek_Call {
+	return &Application_ListForWeek_Call{Call: _e.mock.On("ListForWeek", ctx, userID, startDate, filter)}
+}
+
+func (_c *Application_ListForWeek_Call) Run(run func(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter)) *Application_ListForWeek_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(time.Time), args[3].(app.EventFilter))
+	})
+	return _c
+}
+
+func (_c *Application_ListForWeek_Call) Return(_a0 []*app.EventDto, _a1 error) *Application_ListForWeek_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListForWeek_Call) RunAndReturn(run func(context.Context, uint64, time.Time, app.EventFilter) ([]*app.EventDto, error)) *Application_ListForWeek_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListResources provides a mock function with given fields: ctx, filter
+func (_m *Application) ListResources(ctx context.Context, filter app.ResourceFilter) ([]*app.ResourceDto, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListResources")
+	}
+
+	var r0 []*app.ResourceDto
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.ResourceFilter) ([]*app.ResourceDto, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, app.ResourceFilter) []*app.ResourceDto); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.ResourceDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, app.ResourceFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Application_ListResources_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListResources'
+type Application_ListResources_Call struct {
+	*mock.Call
+}
+
+// ListResources is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter app.ResourceFilter
+func (_e *Application_Expecter) ListResources(ctx interface{}, filter interface{}) *Application_ListResources_Call {
+	return &Application_ListResources_Call{Call: _e.mock.On("ListResources", ctx, filter)}
+}
+
+func (_c *Application_ListResources_Call) Run(run func(ctx context.Context, filter app.ResourceFilter)) *Application_ListResources_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.ResourceFilter))
+	})
+	return _c
+}
+
+func (_c *Application_ListResources_Call) Return(_a0 []*app.ResourceDto, _a1 error) *Application_ListResources_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Application_ListResources_Call) RunAndReturn(run func(context.Context, app.ResourceFilter) ([]*app.ResourceDto, error)) *Application_ListResources_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: ctx, userID, eventID
+func (_m *Application) Restore(ctx context.Context, userID uint64, eventID uint64) error {
+	ret := _m.Called(ctx, userID, eventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, eventID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type Application_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uint64
+//   - eventID uint64
+func (_e *Application_Expecter) Restore(ctx interface{}, userID interface{}, eventID interface{}) *Application_Restore_Call {
+	return &Application_Restore_Call{Call: _e.mock.On("Restore", ctx, userID, eventID)}
+}
+
+func (_c *Application_Restore_Call) Run(run func(ctx context.Context, userID uint64, eventID uint64)) *Application_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint64), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *Application_Restore_Call) Return(_a0 error) *Application_Restore_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Application_Restore_Call) RunAndReturn(run func(context.Context, uint64, uint64) error) *Application_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, eventDto
+func (_m *Application) Update(ctx context.Context, eventDto app.EventDto) ([]*app.EventDto, []app.WarningDto, error) {
+	ret := _m.Called(ctx, eventDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 []*app.EventDto
+	var r1 []app.WarningDto
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) ([]*app.EventDto, []app.WarningDto, error)); ok {
+		return rf(ctx, eventDto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, app.EventDto) []*app.EventDto); ok {
+		r0 = rf(ctx, eventDto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*app.EventDto)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, app.EventDto) []app.WarningDto); ok {
+		r1 = rf(ctx, eventDto)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]app.WarningDto)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, app.EventDto) error); ok {
+		r2 = rf(ctx, eventDto)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Application_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type Application_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - eventDto app.EventDto
+func (_e *Application_Expecter) Update(ctx interface{}, eventDto interface{}) *Application_Update_Call {
+	return &Application_Update_Call{Call: _e.mock.On("Update", ctx, eventDto)}
+}
+
+func (_c *Application_Update_Call) Run(run func(ctx context.Context, eventDto app.EventDto)) *Application_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.EventDto))
+	})
+	return _c
+}
+
+func (_c *Application_Update_Call) Return(_a0 []*app.EventDto, _a1 []app.WarningDto, _a2 error) *Application_Update_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Application_Update_Call) RunAndReturn(run func(context.Context, app.EventDto) ([]*app.EventDto, []app.WarningDto, error)) *Application_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateCalendar provides a mock function with given fields: ctx, calendarDto
+func (_m *Application) UpdateCalendar(ctx context.Context, calendarDto app.CalendarDto) error {
+	ret := _m.Called(ctx, calendarDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCalendar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.CalendarDto) error); ok {
+		r0 = rf(ctx, calendarDto)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_UpdateCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateCalendar'
+type Application_UpdateCalendar_Call struct {
+	*mock.Call
+}
+
+// UpdateCalendar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - calendarDto app.CalendarDto
+func (_e *Application_Expecter) UpdateCalendar(ctx interface{}, calendarDto interface{}) *Application_UpdateCalendar_Call {
+	return &Application_UpdateCalendar_Call{Call: _e.mock.On("UpdateCalendar", ctx, calendarDto)}
+}
+
+func (_c *Application_UpdateCalendar_Call) Run(run func(ctx context.Context, calendarDto app.CalendarDto)) *Application_UpdateCalendar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.CalendarDto))
+	})
+	return _c
+}
+
+func (_c *Application_UpdateCalendar_Call) Return(_a0 error) *Application_UpdateCalendar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Application_UpdateCalendar_Call) RunAndReturn(run func(context.Context, app.CalendarDto) error) *Application_UpdateCalendar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateResource provides a mock function with given fields: ctx, resourceDto
+func (_m *Application) UpdateResource(ctx context.Context, resourceDto app.ResourceDto) error {
+	ret := _m.Called(ctx, resourceDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateResource")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.ResourceDto) error); ok {
+		r0 = rf(ctx, resourceDto)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_UpdateResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateResource'
+type Application_UpdateResource_Call struct {
+	*mock.Call
+}
+
+// UpdateResource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - resourceDto app.ResourceDto
+func (_e *Application_Expecter) UpdateResource(ctx interface{}, resourceDto interface{}) *Application_UpdateResource_Call {
+	return &Application_UpdateResource_Call{Call: _e.mock.On("UpdateResource", ctx, resourceDto)}
+}
+
+func (_c *Application_UpdateResource_Call) Run(run func(ctx context.Context, resourceDto app.ResourceDto)) *Application_UpdateResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.ResourceDto))
+	})
+	return _c
+}
+
+func (_c *Application_UpdateResource_Call) Return(_a0 error) *Application_UpdateResource_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Application_UpdateResource_Call) RunAndReturn(run func(context.Context, app.ResourceDto) error) *Application_UpdateResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUserSettings provides a mock function with given fields: ctx, settingsDto
+func (_m *Application) UpdateUserSettings(ctx context.Context, settingsDto app.UserSettingsDto) error {
+	ret := _m.Called(ctx, settingsDto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, app.UserSettingsDto) error); ok {
+		r0 = rf(ctx, settingsDto)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Application_UpdateUserSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserSettings'
+type Application_UpdateUserSettings_Call struct {
+	*mock.Call
+}
+
+// UpdateUserSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - settingsDto app.UserSettingsDto
+func (_e *Application_Expecter) UpdateUserSettings(ctx interface{}, settingsDto interface{}) *Application_UpdateUserSettings_Call {
+	return &Application_UpdateUserSettings_Call{Call: _e.mock.On("UpdateUserSettings", ctx, settingsDto)}
+}
+
+func (_c *Application_UpdateUserSettings_Call) Run(run func(ctx context.Context, settingsDto app.UserSettingsDto)) *Application_UpdateUserSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(app.UserSettingsDto))
 	})
 	return _c
 }
 
-func (_c *Application_Update_Call) Return(_a0 error) *Application_Update_Call {
+func (_c *Application_UpdateUserSettings_Call) Return(_a0 error) *Application_UpdateUserSettings_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Application_Update_Call) RunAndReturn(run func(context.Context, app.EventDto) error) *Application_Update_Call {
+func (_c *Application_UpdateUserSettings_Call) RunAndReturn(run func(context.Context, app.UserSettingsDto) error) *Application_UpdateUserSettings_Call {
 	_c.Call.Return(run)
 	return _c
 }
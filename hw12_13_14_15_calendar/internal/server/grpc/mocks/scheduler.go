@@ -0,0 +1,192 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+
+	time "time"
+)
+
+// Scheduler is an autogenerated mock type for the Scheduler type
+type Scheduler struct {
+	mock.Mock
+}
+
+type Scheduler_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Scheduler) EXPECT() *Scheduler_Expecter {
+	return &Scheduler_Expecter{mock: &_m.Mock}
+}
+
+// Clear provides a mock function with given fields: ctx
+func (_m *Scheduler) Clear(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clear")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Scheduler_Clear_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Clear'
+type Scheduler_Clear_Call struct {
+	*mock.Call
+}
+
+// Clear is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Scheduler_Expecter) Clear(ctx interface{}) *Scheduler_Clear_Call {
+	return &Scheduler_Clear_Call{Call: _e.mock.On("Clear", ctx)}
+}
+
+func (_c *Scheduler_Clear_Call) Run(run func(ctx context.Context)) *Scheduler_Clear_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Scheduler_Clear_Call) Return(_a0 error) *Scheduler_Clear_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Scheduler_Clear_Call) RunAndReturn(run func(context.Context) error) *Scheduler_Clear_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListNotifyStatus provides a mock function with given fields: ctx, startNotifyDate, endNotifyDate
+func (_m *Scheduler) ListNotifyStatus(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	ret := _m.Called(ctx, startNotifyDate, endNotifyDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListNotifyStatus")
+	}
+
+	var r0 []*storage.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) ([]*storage.Event, error)); ok {
+		return rf(ctx, startNotifyDate, endNotifyDate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*storage.Event); ok {
+		r0 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*storage.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, startNotifyDate, endNotifyDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scheduler_ListNotifyStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListNotifyStatus'
+type Scheduler_ListNotifyStatus_Call struct {
+	*mock.Call
+}
+
+// ListNotifyStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - startNotifyDate time.Time
+//   - endNotifyDate time.Time
+func (_e *Scheduler_Expecter) ListNotifyStatus(ctx interface{}, startNotifyDate interface{}, endNotifyDate interface{}) *Scheduler_ListNotifyStatus_Call {
+	return &Scheduler_ListNotifyStatus_Call{Call: _e.mock.On("ListNotifyStatus", ctx, startNotifyDate, endNotifyDate)}
+}
+
+func (_c *Scheduler_ListNotifyStatus_Call) Run(run func(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time)) *Scheduler_ListNotifyStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Scheduler_ListNotifyStatus_Call) Return(_a0 []*storage.Event, _a1 error) *Scheduler_ListNotifyStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Scheduler_ListNotifyStatus_Call) RunAndReturn(run func(context.Context, time.Time, time.Time) ([]*storage.Event, error)) *Scheduler_ListNotifyStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Notify provides a mock function with given fields: ctx
+func (_m *Scheduler) Notify(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notify")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Scheduler_Notify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Notify'
+type Scheduler_Notify_Call struct {
+	*mock.Call
+}
+
+// Notify is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Scheduler_Expecter) Notify(ctx interface{}) *Scheduler_Notify_Call {
+	return &Scheduler_Notify_Call{Call: _e.mock.On("Notify", ctx)}
+}
+
+func (_c *Scheduler_Notify_Call) Run(run func(ctx context.Context)) *Scheduler_Notify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Scheduler_Notify_Call) Return(_a0 error) *Scheduler_Notify_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Scheduler_Notify_Call) RunAndReturn(run func(context.Context) error) *Scheduler_Notify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewScheduler creates a new instance of Scheduler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewScheduler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Scheduler {
+	mock := &Scheduler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
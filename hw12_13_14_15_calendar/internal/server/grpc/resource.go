@@ -0,0 +1,165 @@
+package internalgrpc
+
+import (
+	"context"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ресурсы общие для всех пользователей, но запрос всё равно должен быть от аутентифицированного пользователя:
+// менять и удалять ресурс могут только его создатель и администраторы ресурсов.
+
+func (s *Server) CreateResource(ctx context.Context, req *pb.CreateResourceRequest) (*pb.CreateResourceResponse, error) {
+	if req == nil || req.Resource == nil {
+		return nil, s.statusError(ctx, requiredField("resource"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	resourceID, err := s.app.CreateResource(ctx, *repackResourceToDto(req.Resource, userID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &pb.CreateResourceResponse{Id: resourceID}, nil
+}
+
+func (s *Server) GetResource(ctx context.Context, req *pb.GetResourceRequest) (*pb.Resource, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	if _, err := getUserID(ctx); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	resource, err := s.app.GetResource(ctx, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return repackResourceToProto(resource), nil
+}
+
+func (s *Server) UpdateResource(ctx context.Context, req *pb.UpdateResourceRequest) (*emptypb.Empty, error) {
+	if req == nil || req.Resource == nil || req.Resource.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("resource.id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.UpdateResource(ctx, *repackResourceToDto(req.Resource, userID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) DeleteResource(ctx context.Context, req *pb.DeleteResourceRequest) (*emptypb.Empty, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.DeleteResource(ctx, userID, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListResources(ctx context.Context, req *pb.ListResourcesRequest) (*pb.ResourceList, error) {
+	if _, err := getUserID(ctx); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	filter := app.ResourceFilter{
+		Kind:        req.GetKind(),
+		MinCapacity: int(req.GetMinCapacity()),
+	}
+	resources, err := s.app.ListResources(ctx, filter)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	result := make([]*pb.Resource, len(resources))
+	for i, resource := range resources {
+		result[i] = repackResourceToProto(resource)
+	}
+	return &pb.ResourceList{Resources: result}, nil
+}
+
+func (s *Server) GetResourceAvailability(
+	ctx context.Context,
+	req *pb.ResourceAvailabilityRequest,
+) (*pb.ResourceAvailability, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+	if req.Start == nil {
+		return nil, s.statusError(ctx, requiredField("start"))
+	}
+	if req.End == nil {
+		return nil, s.statusError(ctx, requiredField("end"))
+	}
+
+	if _, err := getUserID(ctx); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	busy, err := s.app.GetResourceAvailability(ctx, req.Id, req.Start.AsTime(), req.End.AsTime())
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &pb.ResourceAvailability{Busy: repackIntervalsToProto(busy)}, nil
+}
+
+func repackIntervalsToProto(in []*app.BusyIntervalDto) []*pb.BusyInterval {
+	intervals := make([]*pb.BusyInterval, len(in))
+	for i, interval := range in {
+		intervals[i] = &pb.BusyInterval{
+			StartDate: timestamppb.New(interval.StartDate),
+			EndDate:   timestamppb.New(interval.EndDate),
+		}
+	}
+	return intervals
+}
+
+// создателем ресурса или пользователем, который его меняет, считается пользователь запроса, а не user_id из запроса.
+func repackResourceToDto(in *pb.Resource, userID uint64) *app.ResourceDto {
+	return &app.ResourceDto{
+		ID:         in.Id,
+		UserID:     userID,
+		Name:       in.Name,
+		Kind:       in.Kind,
+		Capacity:   int(in.Capacity),
+		Attributes: in.Attributes,
+	}
+}
+
+func repackResourceToProto(in *app.ResourceDto) *pb.Resource {
+	return &pb.Resource{
+		Id:         in.ID,
+		UserId:     in.UserID,
+		Name:       in.Name,
+		Kind:       in.Kind,
+		Capacity:   int32(in.Capacity),
+		Attributes: in.Attributes,
+	}
+}
@@ -2,40 +2,52 @@ package internalgrpc
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
-	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	userIDHeader       = "X-USER-ID"
-	serviceNameDefault = "calendar-app"
+	userIDHeader         = "X-USER-ID"
+	idempotencyKeyHeader = "idempotency-key"
+	serviceNameDefault   = "calendar-app"
 )
 
-var errNotValidUserID = errors.New("userID is not valid")
+var (
+	errNotValidUserID = errors.New("userID is not valid")
+	errRequiredField  = errors.New("field is required")
+	errNoCertUser     = errors.New("client certificate does not identify a user")
+)
 
-//go:generate protoc -I ../../../api EventService.proto --go_out=. --go-grpc_out=.
+//go:generate protoc -I ../../../api EventService.proto AdminService.proto --go_out=. --go-grpc_out=. --grpc-gateway_out=.
 type Server struct {
 	logger   Logger
 	app      Application
 	grpcPort string
+	limiter  *ratelimit.Limiter
+	tls      *tls.Config
+	gateway  string
+	inFlight lifecycle.InFlight
 	srv      *grpc.Server
 	pb.UnimplementedEventServiceServer
 }
@@ -48,13 +60,30 @@ type Logger interface {
 }
 
 type Application interface {
-	Create(ctx context.Context, eventDto app.EventDto) (uint64, error)
+	Create(ctx context.Context, eventDto app.EventDto) (uint64, []*app.EventDto, []app.WarningDto, error)
 	GetByID(ctx context.Context, userID uint64, eventID uint64) (*app.EventDto, error)
-	Update(ctx context.Context, eventDto app.EventDto) error
+	Update(ctx context.Context, eventDto app.EventDto) ([]*app.EventDto, []app.WarningDto, error)
 	Delete(ctx context.Context, userID uint64, eventID uint64) error
-	ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*app.EventDto, error)
-	ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error)
-	ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error)
+	Restore(ctx context.Context, userID uint64, eventID uint64) error
+	ListDeleted(ctx context.Context, userID uint64) ([]*app.EventDto, error)
+	GetHistory(ctx context.Context, userID uint64, eventID uint64) ([]*app.AuditRecordDto, error)
+	ListForDay(ctx context.Context, userID uint64, date time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	ListForWeek(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	ListForMonth(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	GetUserSettings(ctx context.Context, userID uint64) (*app.UserSettingsDto, error)
+	UpdateUserSettings(ctx context.Context, settingsDto app.UserSettingsDto) error
+	GetFreeBusy(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) (*app.FreeBusyDto, error)
+	CreateCalendar(ctx context.Context, calendarDto app.CalendarDto) (uint64, error)
+	GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*app.CalendarDto, error)
+	UpdateCalendar(ctx context.Context, calendarDto app.CalendarDto) error
+	DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error
+	ListCalendars(ctx context.Context, userID uint64) ([]*app.CalendarDto, error)
+	CreateResource(ctx context.Context, resourceDto app.ResourceDto) (uint64, error)
+	GetResource(ctx context.Context, resourceID uint64) (*app.ResourceDto, error)
+	UpdateResource(ctx context.Context, resourceDto app.ResourceDto) error
+	DeleteResource(ctx context.Context, userID uint64, resourceID uint64) error
+	ListResources(ctx context.Context, filter app.ResourceFilter) ([]*app.ResourceDto, error)
+	GetResourceAvailability(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*app.BusyIntervalDto, error)
 }
 
 func NewServer(logger Logger, app Application, grpcPort string) *Server {
@@ -65,8 +94,28 @@ func NewServer(logger Logger, app Application, grpcPort string) *Server {
 	}
 }
 
+// WithRateLimiter включает ограничение частоты запросов.
+func (s *Server) WithRateLimiter(limiter *ratelimit.Limiter) *Server {
+	s.limiter = limiter
+	return s
+}
+
+// WithTLS включает TLS; если в конфигурации задан CA клиентов - mTLS,
+// и пользователем запроса становится клиент из сертификата (см. ClientCertInterceptor).
+func (s *Server) WithTLS(config *tls.Config) *Server {
+	s.tls = config
+	return s
+}
+
+// WithTrustedGateway разрешает клиенту с сертификатом commonName (grpc-gateway) передавать
+// пользователя в x-user-id; при mTLS остальные клиенты без пользователя в сертификате отклоняются.
+func (s *Server) WithTrustedGateway(commonName string) *Server {
+	s.gateway = commonName
+	return s
+}
+
 func (s *Server) Start(ctx context.Context) error {
-	s.logger.Info(ctx, "starting grpc server", "port", s.grpcPort)
+	s.logger.Info(ctx, "starting grpc server", "port", s.grpcPort, "tls", s.tls != nil)
 
 	lsn, err := net.Listen("tcp", fmt.Sprintf(":%s", s.grpcPort))
 	if err != nil {
@@ -74,11 +123,20 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
-	s.srv = grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			LoggerInterceptor(s.logger),
-		),
-	)
+	interceptors := []grpc.UnaryServerInterceptor{
+		InFlightInterceptor(&s.inFlight),
+		LoggerInterceptor(s.logger),
+		SourceInterceptor(),
+		ClientCertInterceptor(s.gateway),
+	}
+	if s.limiter != nil {
+		interceptors = append(interceptors, RateLimitInterceptor(s.logger, s.limiter))
+	}
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}
+	if s.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tls)))
+	}
+	s.srv = grpc.NewServer(opts...)
 	reflection.Register(s.srv)
 	pb.RegisterEventServiceServer(s.srv, s)
 
@@ -93,114 +151,159 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.srv.Serve(lsn)
 }
 
+// Stop перестаёт принимать запросы и ждёт завершения выполняющихся до отмены ctx.
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info(ctx, "stopping grpc server")
-	s.srv.GracefulStop()
-	return nil
+	return gracefulStop(ctx, s.srv, &s.inFlight)
 }
 
 func (s *Server) CreateEvent(ctx context.Context, req *pb.CreateEventRequest) (*pb.CreateEventResponse, error) {
 	if req == nil || req.Event == nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("event"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
+	}
+
+	if md, exists := metadata.FromIncomingContext(ctx); exists {
+		if values := md.Get(idempotencyKeyHeader); len(values) > 0 && values[0] != "" {
+			ctx = app.ContextWithIdempotencyKey(ctx, values[0])
+		}
 	}
 
 	event := repackEventToDto(req.Event, userID)
-	eventID, err := s.app.Create(ctx, *event)
+	eventID, conflicts, warnings, err := s.app.Create(ctx, *event)
 	if err != nil {
-		if errors.Is(err, storage.ErrBusyTime) {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
-	return &pb.CreateEventResponse{Id: eventID}, nil
+	return &pb.CreateEventResponse{
+		Id:        eventID,
+		Conflicts: repackEventsToProto(conflicts),
+		Warnings:  repackWarningsToProto(warnings),
+	}, nil
 }
 
 func (s *Server) GetEvent(ctx context.Context, req *pb.GetEventRequest) (*pb.Event, error) {
 	if req == nil || req.Id == 0 {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("id"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	event, err := s.app.GetByID(ctx, userID, req.Id)
 	if err != nil {
-		if errors.Is(err, storage.ErrEventNotFound) {
-			return nil, status.Error(codes.NotFound, err.Error())
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	return repackEventToProto(event), nil
 }
 
-func (s *Server) UpdateEvent(ctx context.Context, req *pb.UpdateEventRequest) (*emptypb.Empty, error) {
+func (s *Server) UpdateEvent(ctx context.Context, req *pb.UpdateEventRequest) (*pb.UpdateEventResponse, error) {
 	if req == nil || req.Event == nil || req.Event.Id == 0 {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("event.id"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	event := repackEventToDto(req.Event, userID)
-	err = s.app.Update(ctx, *event)
+	conflicts, warnings, err := s.app.Update(ctx, *event)
 	if err != nil {
-		if errors.Is(err, storage.ErrBusyTime) {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
-		if errors.Is(err, storage.ErrEventNotFound) {
-			return nil, status.Error(codes.NotFound, err.Error())
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
-	return &emptypb.Empty{}, nil
+	return &pb.UpdateEventResponse{Conflicts: repackEventsToProto(conflicts), Warnings: repackWarningsToProto(warnings)}, nil
 }
 
 func (s *Server) DeleteEvent(ctx context.Context, req *pb.DeleteEventRequest) (*emptypb.Empty, error) {
 	if req == nil || req.Id == 0 {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("id"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	err = s.app.Delete(ctx, userID, req.Id)
 	if err != nil {
-		if errors.Is(err, storage.ErrEventNotFound) {
-			return nil, status.Error(codes.NotFound, err.Error())
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	return &emptypb.Empty{}, nil
 }
 
+func (s *Server) RestoreEvent(ctx context.Context, req *pb.RestoreEventRequest) (*emptypb.Empty, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.Restore(ctx, userID, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) EventListDeleted(ctx context.Context, _ *emptypb.Empty) (*pb.EventList, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	events, err := s.app.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &pb.EventList{Events: repackEventsToProto(events)}, nil
+}
+
+func (s *Server) EventHistory(ctx context.Context, req *pb.EventHistoryRequest) (*pb.EventHistoryResponse, error) {
+	if req == nil || req.Id == 0 {
+		return nil, s.statusError(ctx, requiredField("id"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	history, err := s.app.GetHistory(ctx, userID, req.Id)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &pb.EventHistoryResponse{History: repackAuditRecordsToProto(history)}, nil
+}
+
 func (s *Server) EventListForDay(ctx context.Context, req *pb.EventListRequest) (*pb.EventList, error) {
 	if req == nil || req.StartDate == nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("start_date"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
-	events, err := s.app.ListForDay(ctx, userID, req.StartDate.AsTime())
+	events, err := s.app.ListForDay(ctx, userID, req.StartDate.AsTime(), repackEventFilterToDto(req))
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	return &pb.EventList{Events: repackEventsToProto(events)}, nil
@@ -208,17 +311,17 @@ func (s *Server) EventListForDay(ctx context.Context, req *pb.EventListRequest)
 
 func (s *Server) EventListForWeek(ctx context.Context, req *pb.EventListRequest) (*pb.EventList, error) {
 	if req == nil || req.StartDate == nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("start_date"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
-	events, err := s.app.ListForWeek(ctx, userID, req.StartDate.AsTime())
+	events, err := s.app.ListForWeek(ctx, userID, req.StartDate.AsTime(), repackEventFilterToDto(req))
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	return &pb.EventList{Events: repackEventsToProto(events)}, nil
@@ -226,22 +329,111 @@ func (s *Server) EventListForWeek(ctx context.Context, req *pb.EventListRequest)
 
 func (s *Server) EventListForMonth(ctx context.Context, req *pb.EventListRequest) (*pb.EventList, error) {
 	if req == nil || req.StartDate == nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid argument")
+		return nil, s.statusError(ctx, requiredField("start_date"))
 	}
 
 	userID, err := getUserID(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
-	events, err := s.app.ListForMonth(ctx, userID, req.StartDate.AsTime())
+	events, err := s.app.ListForMonth(ctx, userID, req.StartDate.AsTime(), repackEventFilterToDto(req))
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, s.statusError(ctx, err)
 	}
 
 	return &pb.EventList{Events: repackEventsToProto(events)}, nil
 }
 
+func (s *Server) GetUserSettings(ctx context.Context, _ *emptypb.Empty) (*pb.UserSettings, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	settings, err := s.app.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return repackUserSettingsToProto(settings), nil
+}
+
+func (s *Server) UpdateUserSettings(ctx context.Context, req *pb.UserSettings) (*emptypb.Empty, error) {
+	if req == nil {
+		return nil, s.statusError(ctx, requiredField("overlap_policy"))
+	}
+
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	err = s.app.UpdateUserSettings(ctx, *repackUserSettingsToDto(req, userID))
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// GetFreeBusy возвращает занятость любого пользователя без подробностей его событий.
+func (s *Server) GetFreeBusy(ctx context.Context, req *pb.FreeBusyRequest) (*pb.FreeBusy, error) {
+	if req == nil || req.UserId == 0 {
+		return nil, s.statusError(ctx, requiredField("user_id"))
+	}
+	if req.Start == nil {
+		return nil, s.statusError(ctx, requiredField("start"))
+	}
+	if req.End == nil {
+		return nil, s.statusError(ctx, requiredField("end"))
+	}
+
+	if _, err := getUserID(ctx); err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	freeBusy, err := s.app.GetFreeBusy(ctx, req.UserId, req.Start.AsTime(), req.End.AsTime())
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	result := &pb.FreeBusy{
+		Busy: repackIntervalsToProto(freeBusy.Busy),
+		Free: repackIntervalsToProto(freeBusy.Free),
+	}
+	for _, period := range freeBusy.OutOfOffice {
+		result.OutOfOffice = append(result.OutOfOffice, &pb.OutOfOffice{
+			StartDate: timestamppb.New(period.StartDate),
+			EndDate:   timestamppb.New(period.EndDate),
+			Message:   period.Message,
+		})
+	}
+	return result, nil
+}
+
+// переводит ошибку в gRPC-статус с деталями из каталога apierror;
+// пересекающиеся события передаются в деталях как EventList.
+func (s *Server) statusError(ctx context.Context, err error) error {
+	apiErr := apierror.From(err)
+	if apiErr.Code == apierror.CodeInternal {
+		s.logger.Error(ctx, err, "grpc request failed")
+	}
+
+	st := apiErr.GRPCStatus()
+	if len(apiErr.Conflicts) > 0 {
+		if stWithDetails, detailsErr := st.WithDetails(&pb.EventList{Events: repackEventsToProto(apiErr.Conflicts)}); detailsErr == nil {
+			st = stWithDetails
+		}
+	}
+
+	return st.Err()
+}
+
+func requiredField(field string) error {
+	return apierror.InvalidField(field, errRequiredField)
+}
+
 func getUserID(ctx context.Context) (uint64, error) {
 	var userID uint64
 	var err error
@@ -260,30 +452,97 @@ func getUserID(ctx context.Context) (uint64, error) {
 	if err == nil && userID == 0 {
 		err = errNotValidUserID
 	}
+	if err != nil {
+		return 0, apierror.InvalidField(strings.ToLower(userIDHeader), err)
+	}
 
-	return userID, err
+	return userID, nil
 }
 
 func repackEventToDto(in *pb.Event, userID uint64) *app.EventDto {
-	return &app.EventDto{
-		ID:           in.Id,
-		Title:        in.Title,
-		StartDate:    in.StartDate.AsTime(),
-		EndDate:      in.EndDate.AsTime(),
-		Description:  in.Description,
-		UserID:       userID,
-		NotifyBefore: in.NotifyBefore.AsDuration(),
+	event := &app.EventDto{
+		ID:          in.Id,
+		Title:       in.Title,
+		StartDate:   repackTimeToDto(in.StartDate),
+		EndDate:     repackTimeToDto(in.EndDate),
+		Description: in.Description,
+		UserID:      userID,
+		CalendarID:  in.CalendarId,
+		Transparent: in.Transparent,
+		Category:    in.Category,
+		Tags:        in.Tags,
+		ResourceIDs: in.ResourceIds,
 	}
+	if in.NotifyBefore != nil {
+		notifyBefore := in.NotifyBefore.AsDuration()
+		event.NotifyBefore = &notifyBefore
+	}
+	if in.Location != nil {
+		event.Location = &app.LocationDto{Text: in.Location.Text}
+		if in.Location.Geo != nil {
+			event.Location.Geo = &app.GeoPointDto{Lat: in.Location.Geo.Lat, Lon: in.Location.Geo.Lon}
+		}
+	}
+	for _, attachment := range in.Attachments {
+		event.Attachments = append(event.Attachments, app.AttachmentDto{
+			URL:      attachment.Url,
+			Name:     attachment.Name,
+			Size:     attachment.Size,
+			MimeType: attachment.MimeType,
+		})
+	}
+	return event
+}
+
+// незаполненная дата становится нулевым time.Time, а не началом эпохи, чтобы её отклонила валидация события.
+func repackTimeToDto(in *timestamppb.Timestamp) time.Time {
+	if in == nil {
+		return time.Time{}
+	}
+	return in.AsTime()
 }
 
 func repackEventToProto(in *app.EventDto) *pb.Event {
-	return &pb.Event{
-		Id:           in.ID,
-		Title:        in.Title,
-		StartDate:    timestamppb.New(in.StartDate),
-		EndDate:      timestamppb.New(in.EndDate),
-		Description:  in.Description,
-		NotifyBefore: durationpb.New(in.NotifyBefore),
+	event := &pb.Event{
+		Id:          in.ID,
+		Title:       in.Title,
+		StartDate:   timestamppb.New(in.StartDate),
+		EndDate:     timestamppb.New(in.EndDate),
+		Description: in.Description,
+		Transparent: in.Transparent,
+		CalendarId:  in.CalendarID,
+		Category:    in.Category,
+		Tags:        in.Tags,
+		ResourceIds: in.ResourceIDs,
+	}
+	if in.NotifyBefore != nil {
+		event.NotifyBefore = durationpb.New(*in.NotifyBefore)
+	}
+	if in.DeletedAt != nil {
+		event.DeletedAt = timestamppb.New(*in.DeletedAt)
+	}
+	if in.Location != nil {
+		event.Location = &pb.Location{Text: in.Location.Text}
+		if in.Location.Geo != nil {
+			event.Location.Geo = &pb.GeoPoint{Lat: in.Location.Geo.Lat, Lon: in.Location.Geo.Lon}
+		}
+	}
+	for _, attachment := range in.Attachments {
+		event.Attachments = append(event.Attachments, &pb.Attachment{
+			Url:      attachment.URL,
+			Name:     attachment.Name,
+			Size:     attachment.Size,
+			MimeType: attachment.MimeType,
+		})
+	}
+	return event
+}
+
+func repackEventFilterToDto(in *pb.EventListRequest) app.EventFilter {
+	return app.EventFilter{
+		CalendarIDs: in.CalendarIds,
+		Category:    in.Category,
+		Tags:        in.Tags,
 	}
 }
 
@@ -294,3 +553,84 @@ func repackEventsToProto(in []*app.EventDto) []*pb.Event {
 	}
 	return events
 }
+
+func repackAuditRecordsToProto(in []*app.AuditRecordDto) []*pb.AuditRecord {
+	records := make([]*pb.AuditRecord, len(in))
+	for i, record := range in {
+		changes := make([]*pb.FieldChange, len(record.Changes))
+		for j, change := range record.Changes {
+			changes[j] = &pb.FieldChange{
+				Field:  change.Field,
+				Before: change.Before,
+				After:  change.After,
+			}
+		}
+		records[i] = &pb.AuditRecord{
+			Id:        record.ID,
+			EventId:   record.EventID,
+			ActorId:   record.ActorID,
+			Action:    record.Action,
+			Changes:   changes,
+			Source:    record.Source,
+			CreatedAt: timestamppb.New(record.CreatedAt),
+		}
+	}
+	return records
+}
+
+func repackWarningsToProto(in []app.WarningDto) []*pb.Warning {
+	warnings := make([]*pb.Warning, len(in))
+	for i, warning := range in {
+		warnings[i] = &pb.Warning{Code: warning.Code, Message: warning.Message}
+	}
+	return warnings
+}
+
+func repackUserSettingsToDto(in *pb.UserSettings, userID uint64) *app.UserSettingsDto {
+	settings := &app.UserSettingsDto{
+		UserID:             userID,
+		OverlapPolicy:      in.OverlapPolicy,
+		TimeZone:           in.TimeZone,
+		AvailabilityPolicy: in.AvailabilityPolicy,
+		Digest:             in.Digest,
+	}
+	for _, interval := range in.WorkingHours {
+		settings.WorkingHours = append(settings.WorkingHours, app.WorkingIntervalDto{
+			Weekday: int(interval.Weekday),
+			Start:   interval.Start,
+			End:     interval.End,
+		})
+	}
+	for _, period := range in.OutOfOffice {
+		settings.OutOfOffice = append(settings.OutOfOffice, app.OutOfOfficeDto{
+			StartDate: repackTimeToDto(period.StartDate),
+			EndDate:   repackTimeToDto(period.EndDate),
+			Message:   period.Message,
+		})
+	}
+	return settings
+}
+
+func repackUserSettingsToProto(in *app.UserSettingsDto) *pb.UserSettings {
+	settings := &pb.UserSettings{
+		OverlapPolicy:      in.OverlapPolicy,
+		TimeZone:           in.TimeZone,
+		AvailabilityPolicy: in.AvailabilityPolicy,
+		Digest:             in.Digest,
+	}
+	for _, interval := range in.WorkingHours {
+		settings.WorkingHours = append(settings.WorkingHours, &pb.WorkingInterval{
+			Weekday: int32(interval.Weekday),
+			Start:   interval.Start,
+			End:     interval.End,
+		})
+	}
+	for _, period := range in.OutOfOffice {
+		settings.OutOfOffice = append(settings.OutOfOffice, &pb.OutOfOffice{
+			StartDate: timestamppb.New(period.StartDate),
+			EndDate:   timestamppb.New(period.EndDate),
+			Message:   period.Message,
+		})
+	}
+	return settings
+}
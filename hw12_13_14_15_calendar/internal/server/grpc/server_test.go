@@ -2,19 +2,23 @@ package internalgrpc
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/mocks"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -37,7 +41,7 @@ func TestServer(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 24,
+		NotifyBefore: ptr(time.Hour * 24),
 	}
 	eventPb := pb.Event{
 		Id:           1,
@@ -61,13 +65,42 @@ func TestServer(t *testing.T) {
 		eventPb := proto.Clone(&eventPb).(*pb.Event)
 		eventID := uint64(1000)
 
-		mockedApplication.EXPECT().Create(ctx, eventDto).Return(eventID, nil)
+		mockedApplication.EXPECT().Create(ctx, eventDto).Return(eventID, nil, nil, nil)
 
 		actualCreateResponse, err := server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
 		require.NoError(t, err)
 		require.Equal(t, eventID, actualCreateResponse.Id)
 	})
 
+	t.Run("create event with and without reminder", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		// без notify_before - напоминание календаря, с нулевым notify_before - без напоминания
+		withoutReminder := eventDto
+		withoutReminder.NotifyBefore = ptr(time.Duration(0))
+		withCalendarReminder := eventDto
+		withCalendarReminder.NotifyBefore = nil
+		mockedApplication.EXPECT().Create(ctx, withoutReminder).Return(1, nil, nil, nil)
+		mockedApplication.EXPECT().Create(ctx, withCalendarReminder).Return(2, nil, nil, nil)
+
+		eventPb := proto.Clone(&eventPb).(*pb.Event)
+		eventPb.NotifyBefore = durationpb.New(0)
+		actualCreateResponse, err := server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), actualCreateResponse.Id)
+
+		eventPb.NotifyBefore = nil
+		actualCreateResponse, err = server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), actualCreateResponse.Id)
+	})
+
 	t.Run("create event with busy time", func(t *testing.T) {
 		mockedLogger := mocks.NewLogger(t)
 		mockedApplication := mocks.NewApplication(t)
@@ -81,12 +114,64 @@ func TestServer(t *testing.T) {
 		eventPb := proto.Clone(&eventPb).(*pb.Event)
 		eventID := uint64(1000)
 
-		mockedApplication.EXPECT().Create(ctx, eventDto).Return(eventID, storage.ErrBusyTime)
+		mockedApplication.EXPECT().Create(ctx, eventDto).Return(eventID, nil, nil, storage.ErrBusyTime)
+
+		_, err := server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+	})
+
+	t.Run("create event with conflicts", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		eventDto := eventDto
+		eventPb := proto.Clone(&eventPb).(*pb.Event)
+		conflictingEventDto := eventDto
+		conflictingEventDto.ID = 2
+		conflictingEventPb := proto.Clone(eventPb).(*pb.Event)
+		conflictingEventPb.Id = 2
+
+		mockedApplication.EXPECT().Create(ctx, eventDto).Return(0, nil, nil, &app.BusyTimeError{Conflicts: []*app.EventDto{&conflictingEventDto}})
 
 		_, err := server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
 		st, ok := status.FromError(err)
 		require.True(t, ok)
 		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Equal(t, 2, len(st.Details()))
+		errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, string(apierror.CodeBusyTime), errorInfo.Reason)
+		conflicts, ok := st.Details()[1].(*pb.EventList)
+		require.True(t, ok)
+		require.Equal(t, 1, len(conflicts.Events))
+		require.True(t, proto.Equal(conflictingEventPb, conflicts.Events[0]))
+	})
+
+	t.Run("update user settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		mockedApplication.EXPECT().UpdateUserSettings(ctx, app.UserSettingsDto{UserID: userID, OverlapPolicy: "ALLOW"}).Return(nil)
+		mockedApplication.EXPECT().GetUserSettings(ctx, userID).Return(&app.UserSettingsDto{UserID: userID, OverlapPolicy: "ALLOW"}, nil)
+
+		_, err := server.UpdateUserSettings(ctx, &pb.UserSettings{OverlapPolicy: "ALLOW"})
+		require.NoError(t, err)
+
+		settings, err := server.GetUserSettings(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Equal(t, "ALLOW", settings.OverlapPolicy)
 	})
 
 	t.Run("create event without user", func(t *testing.T) {
@@ -101,6 +186,10 @@ func TestServer(t *testing.T) {
 		st, ok := status.FromError(err)
 		require.True(t, ok)
 		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Equal(t, 2, len(st.Details()))
+		badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Equal(t, "x-user-id", badRequest.FieldViolations[0].Field)
 	})
 
 	t.Run("update event", func(t *testing.T) {
@@ -115,7 +204,7 @@ func TestServer(t *testing.T) {
 		eventDto := eventDto
 		eventPb := proto.Clone(&eventPb).(*pb.Event)
 
-		mockedApplication.EXPECT().Update(ctx, eventDto).Return(nil)
+		mockedApplication.EXPECT().Update(ctx, eventDto).Return(nil, nil, nil)
 
 		_, err := server.UpdateEvent(ctx, &pb.UpdateEventRequest{Event: eventPb})
 		require.NoError(t, err)
@@ -138,6 +227,104 @@ func TestServer(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("restore event", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		eventID := uint64(1000)
+
+		mockedApplication.EXPECT().Restore(ctx, userID, eventID).Return(nil)
+
+		_, err := server.RestoreEvent(ctx, &pb.RestoreEventRequest{Id: eventID})
+		require.NoError(t, err)
+	})
+
+	t.Run("restore event with busy time", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		eventID := uint64(1000)
+
+		mockedApplication.EXPECT().Restore(ctx, userID, eventID).Return(storage.ErrBusyTime)
+
+		_, err := server.RestoreEvent(ctx, &pb.RestoreEventRequest{Id: eventID})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+	})
+
+	t.Run("list deleted events", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		deletedAt := getTime(t, "2024-07-11 00:00:00")
+
+		eventDto := eventDto
+		eventDto.DeletedAt = &deletedAt
+		eventPb := proto.Clone(&eventPb).(*pb.Event)
+		eventPb.DeletedAt = timestamppb.New(deletedAt)
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		mockedApplication.EXPECT().ListDeleted(ctx, userID).Return([]*app.EventDto{&eventDto}, nil)
+
+		actualEvents, err := server.EventListDeleted(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(actualEvents.Events))
+		require.True(t, proto.Equal(eventPb, actualEvents.Events[0]))
+	})
+
+	t.Run("get event history", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		eventID := uint64(1000)
+		createdAt := getTime(t, "2024-07-11 00:00:00")
+		record := app.AuditRecordDto{
+			ID:        1,
+			EventID:   eventID,
+			ActorID:   userID,
+			Action:    "UPDATE",
+			Changes:   []app.FieldChange{{Field: "title", Before: "my old event", After: "my event"}},
+			Source:    app.SourceGRPC,
+			CreatedAt: createdAt,
+		}
+		expectedRecord := &pb.AuditRecord{
+			Id:        1,
+			EventId:   eventID,
+			ActorId:   userID,
+			Action:    "UPDATE",
+			Changes:   []*pb.FieldChange{{Field: "title", Before: "my old event", After: "my event"}},
+			Source:    app.SourceGRPC,
+			CreatedAt: timestamppb.New(createdAt),
+		}
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		mockedApplication.EXPECT().GetHistory(ctx, userID, eventID).Return([]*app.AuditRecordDto{&record}, nil)
+
+		actualHistory, err := server.EventHistory(ctx, &pb.EventHistoryRequest{Id: eventID})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(actualHistory.History))
+		require.True(t, proto.Equal(expectedRecord, actualHistory.History[0]))
+	})
+
 	t.Run("get event", func(t *testing.T) {
 		mockedLogger := mocks.NewLogger(t)
 		mockedApplication := mocks.NewApplication(t)
@@ -176,6 +363,197 @@ func TestServer(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, codes.NotFound, st.Code())
 	})
+
+	t.Run("internal error is hidden", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mockedLogger, mockedApplication, "")
+
+		eventID := uint64(1000)
+		internalErr := errors.New("connection refused")
+
+		md := make(metadata.MD)
+		md[userIDHeader] = []string{userIDStr}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		mockedApplication.EXPECT().GetByID(ctx, userID, eventID).Return(nil, internalErr)
+		mockedLogger.EXPECT().Error(ctx, internalErr, "grpc request failed").Return()
+
+		_, err := server.GetEvent(ctx, &pb.GetEventRequest{Id: eventID})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.Internal, st.Code())
+		require.Equal(t, "internal error", st.Message())
+	})
+}
+
+func TestServerCalendars(t *testing.T) {
+	t.Parallel()
+
+	userID := uint64(12345)
+	userIDStr := "12345"
+	calendarDto := app.CalendarDto{ID: 7, UserID: userID, Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour}
+	calendarPb := pb.Calendar{Id: 7, Name: "Work", Color: "#00ff00", NotifyBefore: durationpb.New(time.Hour)}
+
+	md := make(metadata.MD)
+	md[userIDHeader] = []string{userIDStr}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	t.Run("create calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		newCalendarDto := calendarDto
+		newCalendarDto.ID = 0
+		newCalendarPb := proto.Clone(&calendarPb).(*pb.Calendar)
+		newCalendarPb.Id = 0
+
+		mockedApplication.EXPECT().CreateCalendar(ctx, newCalendarDto).Return(calendarDto.ID, nil)
+
+		resp, err := server.CreateCalendar(ctx, &pb.CreateCalendarRequest{Calendar: newCalendarPb})
+		require.NoError(t, err)
+		require.Equal(t, calendarDto.ID, resp.Id)
+	})
+
+	t.Run("get and list calendars", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		calendarDto := calendarDto
+		calendarDto.SharedWith = []uint64{userID + 1}
+		expectedCalendarPb := proto.Clone(&calendarPb).(*pb.Calendar)
+		expectedCalendarPb.UserId = userID
+		expectedCalendarPb.SharedWith = []uint64{userID + 1}
+		mockedApplication.EXPECT().GetCalendar(ctx, userID, calendarDto.ID).Return(&calendarDto, nil)
+		mockedApplication.EXPECT().ListCalendars(ctx, userID).Return([]*app.CalendarDto{&calendarDto}, nil)
+
+		actualCalendar, err := server.GetCalendar(ctx, &pb.GetCalendarRequest{Id: calendarDto.ID})
+		require.NoError(t, err)
+		require.True(t, proto.Equal(expectedCalendarPb, actualCalendar))
+
+		calendars, err := server.ListCalendars(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(calendars.Calendars))
+		require.True(t, proto.Equal(expectedCalendarPb, calendars.Calendars[0]))
+	})
+
+	t.Run("update calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		mockedApplication.EXPECT().UpdateCalendar(ctx, calendarDto).Return(nil)
+
+		_, err := server.UpdateCalendar(ctx, &pb.UpdateCalendarRequest{Calendar: proto.Clone(&calendarPb).(*pb.Calendar)})
+		require.NoError(t, err)
+
+		_, err = server.UpdateCalendar(ctx, &pb.UpdateCalendarRequest{Calendar: &pb.Calendar{Name: "Work"}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("delete default calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		mockedApplication.EXPECT().DeleteCalendar(ctx, userID, uint64(1)).Return(storage.ErrDefaultCalendar)
+
+		_, err := server.DeleteCalendar(ctx, &pb.DeleteCalendarRequest{Id: 1})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.FailedPrecondition, st.Code())
+		require.Equal(t, apierror.CodeFailedPrecondition, apierror.FromStatus(st).Code)
+	})
+}
+
+func TestServerResources(t *testing.T) {
+	t.Parallel()
+
+	resourceDto := app.ResourceDto{ID: 3, UserID: 12345, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: map[string]string{"floor": "2"}}
+	resourcePb := pb.Resource{Id: 3, UserId: 12345, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: map[string]string{"floor": "2"}}
+
+	md := make(metadata.MD)
+	md[userIDHeader] = []string{"12345"}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	t.Run("create and list resources", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		newResourceDto := resourceDto
+		newResourceDto.ID = 0
+		// создатель берётся из запроса, а не из user_id ресурса
+		newResourcePb := proto.Clone(&resourcePb).(*pb.Resource)
+		newResourcePb.Id = 0
+		newResourcePb.UserId = 54321
+
+		resourceDto := resourceDto
+		mockedApplication.EXPECT().CreateResource(ctx, newResourceDto).Return(resourceDto.ID, nil)
+		mockedApplication.EXPECT().ListResources(ctx, app.ResourceFilter{Kind: "room", MinCapacity: 5}).
+			Return([]*app.ResourceDto{&resourceDto}, nil)
+
+		resp, err := server.CreateResource(ctx, &pb.CreateResourceRequest{Resource: newResourcePb})
+		require.NoError(t, err)
+		require.Equal(t, resourceDto.ID, resp.Id)
+
+		resources, err := server.ListResources(ctx, &pb.ListResourcesRequest{Kind: "room", MinCapacity: 5})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resources.Resources))
+		require.True(t, proto.Equal(&resourcePb, resources.Resources[0]))
+	})
+
+	t.Run("update and delete resource of another user", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		mockedApplication.EXPECT().UpdateResource(ctx, resourceDto).Return(app.ErrNotResourceOwner)
+		mockedApplication.EXPECT().DeleteResource(ctx, uint64(12345), resourceDto.ID).Return(app.ErrNotResourceOwner)
+
+		_, err := server.UpdateResource(ctx, &pb.UpdateResourceRequest{Resource: &resourcePb})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = server.DeleteResource(ctx, &pb.DeleteResourceRequest{Id: resourceDto.ID})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("resource availability", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		start := time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC)
+		end := start.Add(24 * time.Hour)
+		busy := []*app.BusyIntervalDto{{StartDate: start.Add(10 * time.Hour), EndDate: start.Add(12 * time.Hour)}}
+		mockedApplication.EXPECT().GetResourceAvailability(ctx, resourceDto.ID, start, end).Return(busy, nil)
+
+		resp, err := server.GetResourceAvailability(ctx, &pb.ResourceAvailabilityRequest{
+			Id: resourceDto.ID, Start: timestamppb.New(start), End: timestamppb.New(end),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(resp.Busy))
+		require.True(t, busy[0].StartDate.Equal(resp.Busy[0].StartDate.AsTime()))
+		require.True(t, busy[0].EndDate.Equal(resp.Busy[0].EndDate.AsTime()))
+
+		_, err = server.GetResourceAvailability(ctx, &pb.ResourceAvailabilityRequest{Id: resourceDto.ID, Start: timestamppb.New(start)})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("create event with busy resource", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(mocks.NewLogger(t), mockedApplication, "")
+
+		start := time.Date(2024, 7, 6, 10, 0, 0, 0, time.UTC)
+		eventDto := app.EventDto{Title: "meeting", StartDate: start, EndDate: start.Add(time.Hour), UserID: 12345, ResourceIDs: []uint64{3}}
+		eventPb := &pb.Event{
+			Title: "meeting", StartDate: timestamppb.New(start), EndDate: timestamppb.New(start.Add(time.Hour)),
+			ResourceIds: []uint64{3},
+		}
+		mockedApplication.EXPECT().Create(ctx, eventDto).Return(0, nil, nil, &storage.ResourceBusyError{ResourceIDs: []uint64{3}})
+
+		_, err := server.CreateEvent(ctx, &pb.CreateEventRequest{Event: eventPb})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		apiErr := apierror.FromStatus(st)
+		require.Equal(t, apierror.CodeBusyTime, apiErr.Code)
+		require.Equal(t, []apierror.FieldViolation{{Field: "event.resourceIds", Message: "resource 3 is busy"}}, apiErr.FieldViolations)
+	})
 }
 
 func TestServerListEvents(t *testing.T) {
@@ -191,7 +569,7 @@ func TestServerListEvents(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 24,
+		NotifyBefore: ptr(time.Hour * 24),
 	}
 	eventDto1 := app.EventDto{
 		ID:           2,
@@ -200,7 +578,7 @@ func TestServerListEvents(t *testing.T) {
 		EndDate:      getTime(t, "2024-07-06 09:59:59"),
 		Description:  "my event description2",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 12,
+		NotifyBefore: ptr(time.Hour * 12),
 	}
 
 	eventPb0 := pb.Event{
@@ -236,7 +614,7 @@ func TestServerListEvents(t *testing.T) {
 		md[userIDHeader] = []string{userIDStr}
 		ctx := metadata.NewIncomingContext(context.Background(), md)
 
-		mockedApplication.EXPECT().ListForDay(ctx, userID, startDate).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
+		mockedApplication.EXPECT().ListForDay(ctx, userID, startDate, app.EventFilter{}).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
 
 		actualEvents, err := server.EventListForDay(ctx, &pb.EventListRequest{StartDate: timestamppb.New(startDate)})
 		require.NoError(t, err)
@@ -261,9 +639,15 @@ func TestServerListEvents(t *testing.T) {
 		md[userIDHeader] = []string{userIDStr}
 		ctx := metadata.NewIncomingContext(context.Background(), md)
 
-		mockedApplication.EXPECT().ListForWeek(ctx, userID, startDate).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
+		filter := app.EventFilter{CalendarIDs: []uint64{3, 5}, Category: "work", Tags: []string{"urgent", "team"}}
+		mockedApplication.EXPECT().ListForWeek(ctx, userID, startDate, filter).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
 
-		actualEvents, err := server.EventListForWeek(ctx, &pb.EventListRequest{StartDate: timestamppb.New(startDate)})
+		actualEvents, err := server.EventListForWeek(ctx, &pb.EventListRequest{
+			StartDate:   timestamppb.New(startDate),
+			CalendarIds: filter.CalendarIDs,
+			Category:    filter.Category,
+			Tags:        filter.Tags,
+		})
 		require.NoError(t, err)
 		require.Equal(t, 2, len(actualEvents.Events))
 		require.True(t, proto.Equal(eventPb0, actualEvents.Events[0]))
@@ -286,7 +670,7 @@ func TestServerListEvents(t *testing.T) {
 		md[userIDHeader] = []string{userIDStr}
 		ctx := metadata.NewIncomingContext(context.Background(), md)
 
-		mockedApplication.EXPECT().ListForMonth(ctx, userID, startDate).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
+		mockedApplication.EXPECT().ListForMonth(ctx, userID, startDate, app.EventFilter{}).Return([]*app.EventDto{&eventDto0, &eventDto1}, nil)
 
 		actualEvents, err := server.EventListForMonth(ctx, &pb.EventListRequest{StartDate: timestamppb.New(startDate)})
 		require.NoError(t, err)
@@ -302,3 +686,7 @@ func getTime(t *testing.T, value string) time.Time {
 	require.NoError(t, err)
 	return time
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,182 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
+
+const calendarIDPath = "calendarID"
+
+var (
+	errNotValidCalendarID = errors.New("calendarID is not valid")
+	errCalendarRequired   = errors.New("calendar is required")
+)
+
+type CalendarHandler struct {
+	logger Logger
+	app    Application
+}
+
+func NewCalendarHandler(logger Logger, app Application) *CalendarHandler {
+	return &CalendarHandler{
+		logger: logger,
+		app:    app,
+	}
+}
+
+func (s *CalendarHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendar, err := readCalendar(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	calendar.UserID = userID
+
+	calendarID, err := s.app.CreateCalendar(ctx, *calendar)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, CreateCalendarResponse{CalendarID: calendarID})
+}
+
+func (s *CalendarHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendarID, err := getCalendarID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendar, err := s.app.GetCalendar(ctx, userID, calendarID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, CalendarResponse{Calendar: calendar})
+}
+
+func (s *CalendarHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendarID, err := getCalendarID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendar, err := readCalendar(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	if calendar.ID != calendarID {
+		writeError(ctx, s.logger, w, apierror.InvalidField("body.calendar.id", errNotValidCalendarID))
+		return
+	}
+	calendar.UserID = userID
+
+	err = s.app.UpdateCalendar(ctx, *calendar)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+func (s *CalendarHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendarID, err := getCalendarID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	err = s.app.DeleteCalendar(ctx, userID, calendarID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+func (s *CalendarHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	calendars, err := s.app.ListCalendars(ctx, userID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, CalendarsResponse{Calendars: calendars})
+}
+
+func readCalendar(r *http.Request) (*app.CalendarDto, error) {
+	reqData, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apierror.InvalidField("body", fmt.Errorf("failed reading request: %w", err))
+	}
+
+	var calendarReq CalendarRequest
+	if err := json.Unmarshal(reqData, &calendarReq); err != nil {
+		return nil, apierror.InvalidField("body", fmt.Errorf("failed parsing request: %w", err))
+	}
+	if calendarReq.Calendar == nil {
+		return nil, apierror.InvalidField("body.calendar", errCalendarRequired)
+	}
+	return calendarReq.Calendar, nil
+}
+
+func getCalendarID(r *http.Request) (uint64, error) {
+	calendarID, err := strconv.ParseUint(mux.Vars(r)[calendarIDPath], 10, 64)
+	if err == nil && calendarID == 0 {
+		err = errNotValidCalendarID
+	}
+	if err != nil {
+		return 0, apierror.InvalidField("path."+calendarIDPath, err)
+	}
+	return calendarID, nil
+}
@@ -0,0 +1,150 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarHandler(t *testing.T) {
+	t.Parallel()
+
+	calendar := &app.CalendarDto{ID: 7, UserID: userID2, Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour}
+
+	// отправляет запрос через маршрутизатор, чтобы в запросе был ID календаря из пути
+	serve := func(handler *CalendarHandler, method string, url string, body any) *httptest.ResponseRecorder {
+		requestBody, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewBuffer(requestBody))
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/calendars", handler.create).Methods("POST")
+		router.HandleFunc("/calendars", handler.list).Methods("GET")
+		router.HandleFunc("/calendars/{calendarID}", handler.get).Methods("GET")
+		router.HandleFunc("/calendars/{calendarID}", handler.update).Methods("PUT")
+		router.HandleFunc("/calendars/{calendarID}", handler.delete).Methods("DELETE")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("create calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		newCalendar := *calendar
+		newCalendar.ID = 0
+		mockedApplication.EXPECT().CreateCalendar(mock.Anything, newCalendar).Return(calendar.ID, nil)
+
+		response := serve(handler, "POST", "/calendars", CalendarRequest{Calendar: &app.CalendarDto{
+			Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour,
+		}})
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(CreateCalendarResponse{CalendarID: calendar.ID})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("create calendar without body", func(t *testing.T) {
+		handler := NewCalendarHandler(mocks.NewLogger(t), mocks.NewApplication(t))
+
+		response := serve(handler, "POST", "/calendars", map[string]any{})
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("get calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().GetCalendar(mock.Anything, userID2, calendar.ID).Return(calendar, nil)
+
+		response := serve(handler, "GET", "/calendars/7", nil)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(CalendarResponse{Calendar: calendar})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("get not existing calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().GetCalendar(mock.Anything, userID2, uint64(8)).Return(nil, storage.ErrCalendarNotFound)
+
+		response := serve(handler, "GET", "/calendars/8", nil)
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("update calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().UpdateCalendar(mock.Anything, *calendar).Return(nil)
+
+		response := serve(handler, "PUT", "/calendars/7", CalendarRequest{Calendar: &app.CalendarDto{
+			ID: 7, Name: "Work", Color: "#00ff00", NotifyBefore: time.Hour,
+		}})
+
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("update calendar with another id", func(t *testing.T) {
+		handler := NewCalendarHandler(mocks.NewLogger(t), mocks.NewApplication(t))
+
+		response := serve(handler, "PUT", "/calendars/8", CalendarRequest{Calendar: calendar})
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("delete not empty calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().DeleteCalendar(mock.Anything, userID2, calendar.ID).Return(storage.ErrCalendarNotEmpty)
+
+		response := serve(handler, "DELETE", "/calendars/7", nil)
+
+		require.Equal(t, http.StatusConflict, response.Code)
+	})
+
+	t.Run("list calendars", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewCalendarHandler(mocks.NewLogger(t), mockedApplication)
+
+		calendars := []*app.CalendarDto{{ID: 1, UserID: userID2, Name: storage.DefaultCalendarName, IsDefault: true}, calendar}
+		mockedApplication.EXPECT().ListCalendars(mock.Anything, userID2).Return(calendars, nil)
+
+		response := serve(handler, "GET", "/calendars", nil)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(CalendarsResponse{Calendars: calendars})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+}
@@ -0,0 +1,55 @@
+package internalhttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypeBlank   = "about:blank"
+)
+
+// writeError отвечает ошибкой из каталога apierror в формате application/problem+json.
+func writeError(ctx context.Context, logger Logger, w http.ResponseWriter, err error) {
+	apiErr := apierror.From(err)
+	if apiErr.Code == apierror.CodeInternal {
+		logger.Error(ctx, err, "http request failed")
+	}
+
+	problem := newProblem(apiErr.Code, apiErr.Message, apiErr.FieldViolations)
+	if len(apiErr.Conflicts) > 0 {
+		problem.Conflicts = apiErr.Conflicts
+	}
+	setRetryAfter(w, apiErr.RetryAfter)
+	writeProblem(ctx, logger, w, problem)
+}
+
+// setRetryAfter выставляет заголовок Retry-After в целых секундах с округлением вверх.
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		return
+	}
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+func newProblem(code apierror.Code, message string, fields []apierror.FieldViolation) ProblemResponse {
+	statusCode := code.HTTPStatus()
+	return ProblemResponse{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: message,
+		Code:   code,
+		Fields: fields,
+	}
+}
+
+func writeProblem(ctx context.Context, logger Logger, w http.ResponseWriter, problem ProblemResponse) {
+	writeContent(ctx, logger, w, problemContentType, problem.Status, problem)
+}
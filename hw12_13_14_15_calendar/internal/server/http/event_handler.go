@@ -11,17 +11,22 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
 )
 
 const (
-	userIDHeader = "X-USER-ID"
-	eventIDPath  = "eventID"
+	userIDHeader         = "X-USER-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	eventIDPath          = "eventID"
 )
 
 const (
 	startDateQueryKey   = "startDate"
 	startDateQueryValue = `{startDate:\d{4}-\d{2}-\d{2}}`
+	calendarIDQueryKey  = "calendarId"
+	categoryQueryKey    = "category"
+	tagQueryKey         = "tag"
 )
 
 const (
@@ -35,6 +40,8 @@ var (
 	errNotValidUserID    = errors.New("userID is not valid")
 	errNotValidEventID   = errors.New("eventID is not valid")
 	errNotValidStartDate = errors.New("startDate is not valid")
+	errNotValidCalendar  = errors.New("calendarId is not valid")
+	errNoCertUser        = errors.New("client certificate does not identify a user")
 )
 
 type EventHandler struct {
@@ -54,35 +61,34 @@ func (s *EventHandler) create(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	reqData, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, fmt.Errorf("failed reading request: %w", err).Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed reading request: %w", err)))
 		return
 	}
 
 	var createEventReq CreateEventRequest
 	if err := json.Unmarshal(reqData, &createEventReq); err != nil {
-		http.Error(w, fmt.Errorf("failed parsing request: %w", err).Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed parsing request: %w", err)))
 		return
 	}
 
 	createEventReq.Event.UserID = userID
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+		ctx = app.ContextWithIdempotencyKey(ctx, key)
+	}
 
-	eventID, err := s.app.Create(ctx, *createEventReq.Event)
+	eventID, conflicts, warnings, err := s.app.Create(ctx, *createEventReq.Event)
 	if err != nil {
-		if errors.Is(err, storage.ErrBusyTime) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
-	response := CreateEventResponse{EventID: eventID}
+	response := CreateEventResponse{EventID: eventID, Conflicts: conflicts, Warnings: warnings}
 	s.writeResponse(ctx, w, response)
 }
 
@@ -91,23 +97,19 @@ func (s *EventHandler) getByID(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	eventID, err := getEventID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	event, err := s.app.GetByID(ctx, userID, eventID)
 	if err != nil {
-		if errors.Is(err, storage.ErrEventNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
@@ -120,48 +122,44 @@ func (s *EventHandler) update(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	eventID, err := getEventID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	reqData, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, fmt.Errorf("failed reading request: %w", err).Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed reading request: %w", err)))
 		return
 	}
 
 	var updateEventReq UpdateEventRequest
 	if err := json.Unmarshal(reqData, &updateEventReq); err != nil {
-		http.Error(w, fmt.Errorf("failed parsing request: %w", err).Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed parsing request: %w", err)))
 		return
 	}
 
 	if updateEventReq.Event.ID == 0 || updateEventReq.Event.ID != eventID {
-		http.Error(w, errNotValidEventID.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, apierror.InvalidField("body.event.id", errNotValidEventID))
 		return
 	}
 
 	updateEventReq.Event.UserID = userID
 
-	err = s.app.Update(ctx, *updateEventReq.Event)
+	conflicts, warnings, err := s.app.Update(ctx, *updateEventReq.Event)
 	if err != nil {
-		if errors.Is(err, storage.ErrBusyTime) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		if errors.Is(err, storage.ErrEventNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
+
+	if len(conflicts) > 0 || len(warnings) > 0 {
+		s.writeResponse(ctx, w, UpdateEventResponse{Conflicts: conflicts, Warnings: warnings})
+	}
 }
 
 func (s *EventHandler) delete(w http.ResponseWriter, r *http.Request) {
@@ -169,45 +167,113 @@ func (s *EventHandler) delete(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	eventID, err := getEventID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	err = s.app.Delete(ctx, userID, eventID)
 	if err != nil {
-		if errors.Is(err, storage.ErrEventNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 }
 
+func (s *EventHandler) restore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	eventID, err := getEventID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	err = s.app.Restore(ctx, userID, eventID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+func (s *EventHandler) listDeleted(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	events, err := s.app.ListDeleted(ctx, userID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	response := EventsResponse{Events: events}
+	s.writeResponse(ctx, w, response)
+}
+
+func (s *EventHandler) getHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	eventID, err := getEventID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	history, err := s.app.GetHistory(ctx, userID, eventID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	response := EventHistoryResponse{History: history}
+	s.writeResponse(ctx, w, response)
+}
+
 func (s *EventHandler) listForDay(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	startDate, err := getStartDate(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
-	events, err := s.app.ListForDay(ctx, userID, startDate)
+	filter, err := getEventFilter(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	events, err := s.app.ListForDay(ctx, userID, startDate, filter)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
@@ -220,19 +286,25 @@ func (s *EventHandler) listForWeek(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	startDate, err := getStartDate(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	filter, err := getEventFilter(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
-	events, err := s.app.ListForWeek(ctx, userID, startDate)
+	events, err := s.app.ListForWeek(ctx, userID, startDate, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
@@ -245,19 +317,25 @@ func (s *EventHandler) listForMonth(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getUserID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
 	startDate, err := getStartDate(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
-	events, err := s.app.ListForMonth(ctx, userID, startDate)
+	filter, err := getEventFilter(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	events, err := s.app.ListForMonth(ctx, userID, startDate, filter)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
 		return
 	}
 
@@ -279,8 +357,11 @@ func getUserID(r *http.Request) (uint64, error) {
 	if err == nil && userID == 0 {
 		err = errNotValidUserID
 	}
+	if err != nil {
+		return 0, apierror.InvalidField("header."+userIDHeader, err)
+	}
 
-	return userID, err
+	return userID, nil
 }
 
 func getEventID(r *http.Request) (uint64, error) {
@@ -298,34 +379,80 @@ func getEventID(r *http.Request) (uint64, error) {
 	if err == nil && eventID == 0 {
 		err = errNotValidEventID
 	}
+	if err != nil {
+		return 0, apierror.InvalidField("path."+eventIDPath, err)
+	}
 
-	return eventID, err
+	return eventID, nil
 }
 
 func getStartDate(r *http.Request) (time.Time, error) {
 	startDateStr := r.URL.Query().Get(startDateQueryKey)
 	if len(startDateStr) == 0 {
-		return time.Time{}, errNotValidStartDate
+		return time.Time{}, apierror.InvalidField("query."+startDateQueryKey, errNotValidStartDate)
 	}
 
 	startDate, err := time.Parse(time.DateOnly, startDateStr)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, apierror.InvalidField("query."+startDateQueryKey, err)
 	}
 
 	return startDate, nil
 }
 
+// getEventFilter возвращает фильтр списка событий из параметров calendarId, category и повторяющегося tag.
+func getEventFilter(r *http.Request) (app.EventFilter, error) {
+	calendarIDs, err := getCalendarIDs(r)
+	if err != nil {
+		return app.EventFilter{}, err
+	}
+	query := r.URL.Query()
+	return app.EventFilter{
+		CalendarIDs: calendarIDs,
+		Category:    query.Get(categoryQueryKey),
+		Tags:        query[tagQueryKey],
+	}, nil
+}
+
+// getCalendarIDs возвращает календари из повторяющегося параметра calendarId; без параметра - nil (все календари).
+func getCalendarIDs(r *http.Request) ([]uint64, error) {
+	values := r.URL.Query()[calendarIDQueryKey]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	calendarIDs := make([]uint64, len(values))
+	for i, value := range values {
+		calendarID, err := strconv.ParseUint(value, 10, 64)
+		if err == nil && calendarID == 0 {
+			err = errNotValidCalendar
+		}
+		if err != nil {
+			return nil, apierror.InvalidField("query."+calendarIDQueryKey, err)
+		}
+		calendarIDs[i] = calendarID
+	}
+	return calendarIDs, nil
+}
+
 func (s *EventHandler) writeResponse(ctx context.Context, w http.ResponseWriter, resp any) {
+	writeJSON(ctx, s.logger, w, http.StatusOK, resp)
+}
+
+func writeJSON(ctx context.Context, logger Logger, w http.ResponseWriter, statusCode int, resp any) {
+	writeContent(ctx, logger, w, "application/json", statusCode, resp)
+}
+
+func writeContent(ctx context.Context, logger Logger, w http.ResponseWriter, contentType string, statusCode int, resp any) {
 	data, err := json.Marshal(resp)
 	if err != nil {
 		http.Error(w, fmt.Errorf("failed encoding response: %w", err).Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
 	if _, err := w.Write(data); err != nil {
-		s.logger.Error(ctx, err, "error writing response")
+		logger.Error(ctx, err, "error writing response")
 	}
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +36,7 @@ type eventHandlerTest struct {
 	url                  string
 	route                func(mux *mux.Router, handler *EventHandler)
 	appCall              func(app *mocks.Application)
+	loggerCall           func(logger *mocks.Logger)
 	expectedResponseBody interface{}
 	expectedResponseCode int
 	testName             string
@@ -52,6 +54,9 @@ func TestEventHandler(t *testing.T) {
 	tests = append(tests, initCreateHandlerTests(t)...)
 	tests = append(tests, initUpdateHandlerTests(t)...)
 	tests = append(tests, initDeleteHandlerTests(t)...)
+	tests = append(tests, initRestoreHandlerTests(t)...)
+	tests = append(tests, initListDeletedHandlerTests(t)...)
+	tests = append(tests, initGetHistoryHandlerTests(t)...)
 	tests = append(tests, initGetByIDHandlerTests(t)...)
 	tests = append(tests, initListForDayHandlerTests(t)...)
 	tests = append(tests, initListForWeekHandlerTests(t)...)
@@ -67,6 +72,9 @@ func TestEventHandler(t *testing.T) {
 			handler := NewEventHandler(mockedLogger, mockedApplication)
 
 			tt.appCall(mockedApplication)
+			if tt.loggerCall != nil {
+				tt.loggerCall(mockedLogger)
+			}
 
 			requestBody, err := json.Marshal(tt.requestBody)
 			require.NoError(t, err)
@@ -98,6 +106,38 @@ func initCreateHandlerTests(t *testing.T) []eventHandlerTest {
 	t.Helper()
 	createEventResponse, err := json.Marshal(CreateEventResponse{EventID: eventID})
 	require.NoError(t, err)
+	busyTimeErr := &app.BusyTimeError{Conflicts: []*app.EventDto{eventDto2(t, userID2)}}
+	busyTimeResponse, err := json.Marshal(ProblemResponse{
+		Type:      problemTypeBlank,
+		Title:     http.StatusText(http.StatusBadRequest),
+		Status:    http.StatusBadRequest,
+		Detail:    busyTimeErr.Error(),
+		Code:      apierror.CodeBusyTime,
+		Conflicts: busyTimeErr.Conflicts,
+	})
+	require.NoError(t, err)
+	internalErrorResponse, err := json.Marshal(ProblemResponse{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "internal error",
+		Code:   apierror.CodeInternal,
+	})
+	require.NoError(t, err)
+	createEventWithConflictsResponse, err := json.Marshal(CreateEventResponse{EventID: eventID, Conflicts: busyTimeErr.Conflicts})
+	require.NoError(t, err)
+	warnings := []app.WarningDto{{Code: app.WarningOutsideWorkingHours, Message: app.ErrOutsideWorkingHours.Error()}}
+	createEventWithWarningsResponse, err := json.Marshal(CreateEventResponse{EventID: eventID, Warnings: warnings})
+	require.NoError(t, err)
+	outOfOfficeErr := &app.OutOfOfficeError{Message: "vacation"}
+	outOfOfficeResponse, err := json.Marshal(ProblemResponse{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(http.StatusConflict),
+		Status: http.StatusConflict,
+		Detail: outOfOfficeErr.Error(),
+		Code:   apierror.CodeFailedPrecondition,
+	})
+	require.NoError(t, err)
 
 	return []eventHandlerTest{
 		{
@@ -124,7 +164,7 @@ func initCreateHandlerTests(t *testing.T) []eventHandlerTest {
 				mux.HandleFunc("/events", handler.create).Methods("POST")
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, storage.ErrBusyTime)
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil, nil, storage.ErrBusyTime)
 			},
 			expectedResponseBody: nil,
 			expectedResponseCode: http.StatusBadRequest,
@@ -141,9 +181,80 @@ func initCreateHandlerTests(t *testing.T) []eventHandlerTest {
 				mux.HandleFunc("/events", handler.create).Methods("POST")
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, errors.New("error"))
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(0, nil, nil, busyTimeErr)
 			},
-			expectedResponseBody: nil,
+			expectedResponseBody: busyTimeResponse,
+			expectedResponseCode: http.StatusBadRequest,
+			testName:             "create event with conflicts",
+		},
+		{
+			requestBody: CreateEventRequest{Event: eventDto(t, userID)},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, busyTimeErr.Conflicts, nil, nil)
+			},
+			expectedResponseBody: createEventWithConflictsResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "create event with overlap warning",
+		},
+		{
+			requestBody: CreateEventRequest{Event: eventDto(t, userID)},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil, warnings, nil)
+			},
+			expectedResponseBody: createEventWithWarningsResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "create event with availability warning",
+		},
+		{
+			requestBody: CreateEventRequest{Event: eventDto(t, userID)},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(0, nil, nil, outOfOfficeErr)
+			},
+			expectedResponseBody: outOfOfficeResponse,
+			expectedResponseCode: http.StatusConflict,
+			testName:             "create event out of office",
+		},
+		{
+			requestBody: CreateEventRequest{Event: eventDto(t, userID)},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil, nil, errors.New("error"))
+			},
+			loggerCall: func(logger *mocks.Logger) {
+				logger.EXPECT().Error(mock.Anything, mock.Anything, "http request failed").Return()
+			},
+			expectedResponseBody: internalErrorResponse,
 			expectedResponseCode: http.StatusInternalServerError,
 			testName:             "create event with Internal Error",
 		},
@@ -158,12 +269,55 @@ func initCreateHandlerTests(t *testing.T) []eventHandlerTest {
 				mux.HandleFunc("/events", handler.create).Methods("POST")
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil)
+				app.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil, nil, nil)
 			},
 			expectedResponseBody: createEventResponse,
 			expectedResponseCode: http.StatusOK,
 			testName:             "create event",
 		},
+		{
+			requestBody: map[string]any{"event": map[string]any{
+				"title": "my event", "startDate": "2024-07-06T10:00:00Z", "endDate": "2024-07-10T00:00:00Z",
+			}},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(application *mocks.Application) {
+				application.EXPECT().Create(mock.Anything, mock.MatchedBy(func(event app.EventDto) bool {
+					return event.NotifyBefore == nil
+				})).Return(eventID, nil, nil, nil)
+			},
+			expectedResponseBody: createEventResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "create event with calendar reminder",
+		},
+		{
+			requestBody: map[string]any{"event": map[string]any{
+				"title": "my event", "startDate": "2024-07-06T10:00:00Z", "endDate": "2024-07-10T00:00:00Z",
+				"notifyBefore": 0,
+			}},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    "/events",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.create).Methods("POST")
+			},
+			appCall: func(application *mocks.Application) {
+				application.EXPECT().Create(mock.Anything, mock.MatchedBy(func(event app.EventDto) bool {
+					return event.NotifyBefore != nil && *event.NotifyBefore == 0
+				})).Return(eventID, nil, nil, nil)
+			},
+			expectedResponseBody: createEventResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "create event without reminder",
+		},
 	}
 }
 
@@ -181,7 +335,7 @@ func initUpdateHandlerTests(t *testing.T) []eventHandlerTest {
 				mux.HandleFunc(fmt.Sprintf("/events/{%s}", eventIDPath), handler.update).Methods("PUT")
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().Update(mock.Anything, *eventDto(t, userID2)).Return(nil)
+				app.EXPECT().Update(mock.Anything, *eventDto(t, userID2)).Return(nil, nil, nil)
 			},
 			expectedResponseBody: nil,
 			expectedResponseCode: http.StatusOK,
@@ -213,6 +367,149 @@ func initDeleteHandlerTests(t *testing.T) []eventHandlerTest {
 	}
 }
 
+func initRestoreHandlerTests(t *testing.T) []eventHandlerTest {
+	t.Helper()
+	return []eventHandlerTest{
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    fmt.Sprintf("/events/%s/restore", eventIDStr),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc(fmt.Sprintf("/events/{%s}/restore", eventIDPath), handler.restore).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Restore(mock.Anything, userID2, eventID).Return(nil)
+			},
+			expectedResponseBody: nil,
+			expectedResponseCode: http.StatusOK,
+			testName:             "restore event",
+		},
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    fmt.Sprintf("/events/%s/restore", eventIDStr),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc(fmt.Sprintf("/events/{%s}/restore", eventIDPath), handler.restore).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Restore(mock.Anything, userID2, eventID).Return(storage.ErrBusyTime)
+			},
+			expectedResponseBody: nil,
+			expectedResponseCode: http.StatusBadRequest,
+			testName:             "restore event with ErrBusyTime",
+		},
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "POST",
+			url:    fmt.Sprintf("/events/%s/restore", eventIDStr),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc(fmt.Sprintf("/events/{%s}/restore", eventIDPath), handler.restore).Methods("POST")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().Restore(mock.Anything, userID2, eventID).Return(storage.ErrEventNotFound)
+			},
+			expectedResponseBody: nil,
+			expectedResponseCode: http.StatusNotFound,
+			testName:             "restore not existing event",
+		},
+	}
+}
+
+func initListDeletedHandlerTests(t *testing.T) []eventHandlerTest {
+	t.Helper()
+	deletedAt := getTime(t, "2024-08-01 00:00:00")
+	event := eventDto(t, userID2)
+	event.DeletedAt = &deletedAt
+	events := []*app.EventDto{event}
+	getEventsResponse, err := json.Marshal(EventsResponse{
+		Events: events,
+	})
+	require.NoError(t, err)
+
+	return []eventHandlerTest{
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "GET",
+			url:    "/events/trash",
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events/trash", handler.listDeleted).Methods("GET")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().ListDeleted(mock.Anything, userID2).Return(events, nil)
+			},
+			expectedResponseBody: getEventsResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "list deleted events",
+		},
+	}
+}
+
+func initGetHistoryHandlerTests(t *testing.T) []eventHandlerTest {
+	t.Helper()
+	history := []*app.AuditRecordDto{
+		{
+			ID:        1,
+			EventID:   eventID,
+			ActorID:   userID2,
+			Action:    "CREATE",
+			Changes:   []app.FieldChange{{Field: "title", After: "my event"}},
+			Source:    app.SourceHTTP,
+			CreatedAt: getTime(t, "2024-08-01 00:00:00"),
+		},
+	}
+	getHistoryResponse, err := json.Marshal(EventHistoryResponse{History: history})
+	require.NoError(t, err)
+
+	return []eventHandlerTest{
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "GET",
+			url:    fmt.Sprintf("/events/%s/history", eventIDStr),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc(fmt.Sprintf("/events/{%s}/history", eventIDPath), handler.getHistory).Methods("GET")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().GetHistory(mock.Anything, userID2, eventID).Return(history, nil)
+			},
+			expectedResponseBody: getHistoryResponse,
+			expectedResponseCode: http.StatusOK,
+			testName:             "get event history",
+		},
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "GET",
+			url:    fmt.Sprintf("/events/%s/history", eventIDStr),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc(fmt.Sprintf("/events/{%s}/history", eventIDPath), handler.getHistory).Methods("GET")
+			},
+			appCall: func(app *mocks.Application) {
+				app.EXPECT().GetHistory(mock.Anything, userID2, eventID).Return(nil, storage.ErrEventNotFound)
+			},
+			expectedResponseBody: nil,
+			expectedResponseCode: http.StatusNotFound,
+			testName:             "get history of not existing event",
+		},
+	}
+}
+
 func initGetByIDHandlerTests(t *testing.T) []eventHandlerTest {
 	t.Helper()
 	getEventResponse, err := json.Marshal(EventResponse{Event: eventDto(t, userID2)})
@@ -259,6 +556,7 @@ func initGetByIDHandlerTests(t *testing.T) []eventHandlerTest {
 func initListForDayHandlerTests(t *testing.T) []eventHandlerTest {
 	t.Helper()
 	events := []*app.EventDto{eventDto(t, userID2), eventDto2(t, userID2)}
+	var filter app.EventFilter
 	getEventsResponse, err := json.Marshal(EventsResponse{
 		Events: events,
 	})
@@ -279,7 +577,7 @@ func initListForDayHandlerTests(t *testing.T) []eventHandlerTest {
 				)
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().ListForDay(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00")).Return(events, nil)
+				app.EXPECT().ListForDay(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00"), filter).Return(events, nil)
 			},
 			expectedResponseBody: getEventsResponse,
 			expectedResponseCode: http.StatusOK,
@@ -291,6 +589,7 @@ func initListForDayHandlerTests(t *testing.T) []eventHandlerTest {
 func initListForWeekHandlerTests(t *testing.T) []eventHandlerTest {
 	t.Helper()
 	events := []*app.EventDto{eventDto(t, userID2), eventDto2(t, userID2)}
+	filter := app.EventFilter{CalendarIDs: []uint64{3, 5}, Category: "work", Tags: []string{"urgent", "team"}}
 	getEventsResponse, err := json.Marshal(EventsResponse{
 		Events: events,
 	})
@@ -303,7 +602,8 @@ func initListForWeekHandlerTests(t *testing.T) []eventHandlerTest {
 				userIDHeader: userID2Str,
 			},
 			method: "GET",
-			url:    fmt.Sprintf("/events?%s=%s&%s=%s", startDateQueryKey, "2024-08-01", periodTypeQueryKey, periodWeekQueryValue),
+			url: fmt.Sprintf("/events?%s=%s&%s=%s&%s=3&%s=5&%s=work&%s=urgent&%s=team", startDateQueryKey, "2024-08-01",
+				periodTypeQueryKey, periodWeekQueryValue, calendarIDQueryKey, calendarIDQueryKey, categoryQueryKey, tagQueryKey, tagQueryKey),
 			route: func(mux *mux.Router, handler *EventHandler) {
 				mux.HandleFunc("/events", handler.listForWeek).Methods("GET").Queries(
 					startDateQueryKey, startDateQueryValue,
@@ -311,7 +611,7 @@ func initListForWeekHandlerTests(t *testing.T) []eventHandlerTest {
 				)
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().ListForWeek(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00")).Return(events, nil)
+				app.EXPECT().ListForWeek(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00"), filter).Return(events, nil)
 			},
 			expectedResponseBody: getEventsResponse,
 			expectedResponseCode: http.StatusOK,
@@ -323,6 +623,7 @@ func initListForWeekHandlerTests(t *testing.T) []eventHandlerTest {
 func initListForMonthHandlerTests(t *testing.T) []eventHandlerTest {
 	t.Helper()
 	events := []*app.EventDto{eventDto(t, userID2), eventDto2(t, userID2)}
+	var filter app.EventFilter
 	getEventsResponse, err := json.Marshal(EventsResponse{
 		Events: events,
 	})
@@ -343,12 +644,31 @@ func initListForMonthHandlerTests(t *testing.T) []eventHandlerTest {
 				)
 			},
 			appCall: func(app *mocks.Application) {
-				app.EXPECT().ListForMonth(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00")).Return(events, nil)
+				app.EXPECT().ListForMonth(mock.Anything, userID2, getTime(t, "2024-08-01 00:00:00"), filter).Return(events, nil)
 			},
 			expectedResponseBody: getEventsResponse,
 			expectedResponseCode: http.StatusOK,
 			testName:             "list events for month",
 		},
+		{
+			requestBody: []byte{},
+			headers: map[string]string{
+				userIDHeader: userID2Str,
+			},
+			method: "GET",
+			url: fmt.Sprintf("/events?%s=%s&%s=%s&%s=abc", startDateQueryKey, "2024-08-01", periodTypeQueryKey, periodMonthQueryValue,
+				calendarIDQueryKey),
+			route: func(mux *mux.Router, handler *EventHandler) {
+				mux.HandleFunc("/events", handler.listForMonth).Methods("GET").Queries(
+					startDateQueryKey, startDateQueryValue,
+					periodTypeQueryKey, periodMonthQueryValue,
+				)
+			},
+			appCall:              func(_ *mocks.Application) {},
+			expectedResponseBody: nil,
+			expectedResponseCode: http.StatusBadRequest,
+			testName:             "list events for month with not valid calendar",
+		},
 	}
 }
 
@@ -361,7 +681,7 @@ func eventDto(t *testing.T, userID uint64) *app.EventDto {
 		EndDate:      getTime(t, "2024-07-10 00:00:00"),
 		Description:  "my event description",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 24,
+		NotifyBefore: ptr(time.Hour * 24),
 	}
 }
 
@@ -374,7 +694,7 @@ func eventDto2(t *testing.T, userID uint64) *app.EventDto {
 		EndDate:      getTime(t, "2024-08-10 00:00:00"),
 		Description:  "my event description2",
 		UserID:       userID,
-		NotifyBefore: time.Hour * 12,
+		NotifyBefore: ptr(time.Hour * 12),
 	}
 }
 
@@ -384,3 +704,7 @@ func getTime(t *testing.T, value string) time.Time {
 	require.NoError(t, err)
 	return time
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,94 @@
+package internalhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Режимы HTTP API.
+const (
+	// ModeLegacy - только написанные вручную ручки (event_handler.go).
+	ModeLegacy = "legacy"
+	// ModeGateway - только ручки grpc-gateway, сгенерированные по EventService.proto.
+	ModeGateway = "gateway"
+	// ModeCompat - ручки grpc-gateway и прежние ручки для совместимости со старыми клиентами.
+	ModeCompat = "compat"
+)
+
+const gatewayPathPrefix = "/v1/"
+
+// newGateway создаёт reverse proxy, который переводит REST-запросы в вызовы gRPC-сервера;
+// tlsConfig - настройки TLS подключения к gRPC-серверу (nil - без шифрования).
+func newGateway(ctx context.Context, logger Logger, grpcEndpoint string, tlsConfig *tls.Config) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithErrorHandler(gatewayErrorHandler(logger)),
+	)
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if err := pb.RegisterEventServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+		return nil, fmt.Errorf("cannot register grpc gateway: %w", err)
+	}
+	return mux, nil
+}
+
+// передаёт в gRPC-метаданные заголовки пользователя и ключа идемпотентности, остальные заголовки - по правилам gateway.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, userIDHeader) || strings.EqualFold(key, idempotencyKeyHeader) {
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// отвечает на ошибки gRPC в том же формате application/problem+json, что и прежние ручки;
+// пересекающиеся события кодируются так же, как остальные ответы gateway.
+func gatewayErrorHandler(logger Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, _ *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+		st := status.Convert(err)
+		apiErr := apierror.FromStatus(st)
+
+		problem := newProblem(apiErr.Code, apiErr.Message, apiErr.FieldViolations)
+		// статус - как у прежних ручек (FAILED_PRECONDITION - 409, а не 400 по правилам gateway);
+		// для ошибок без кода API (недоступность gRPC-сервера, таймаут) - по коду gRPC
+		problem.Status = apiErr.Code.HTTPStatus()
+		if apiErr.Code == apierror.CodeInternal {
+			problem.Status = runtime.HTTPStatusFromCode(st.Code())
+		}
+		problem.Title = http.StatusText(problem.Status)
+		setRetryAfter(w, apiErr.RetryAfter)
+
+		for _, detail := range st.Details() {
+			events, ok := detail.(*pb.EventList)
+			if !ok {
+				continue
+			}
+			conflicts := make([]json.RawMessage, 0, len(events.Events))
+			for _, event := range events.Events {
+				data, err := marshaler.Marshal(event)
+				if err != nil {
+					logger.Error(ctx, err, "failed encoding conflicting event")
+					continue
+				}
+				conflicts = append(conflicts, data)
+			}
+			problem.Conflicts = conflicts
+		}
+
+		writeProblem(ctx, logger, w, problem)
+	}
+}
@@ -0,0 +1,204 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	internalgrpc "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway(t *testing.T) {
+	t.Parallel()
+
+	t.Run("create event", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		mockedApplication.EXPECT().Create(mock.Anything, mock.MatchedBy(func(event app.EventDto) bool {
+			return event.UserID == userID2 && event.Title == "my event"
+		})).Return(eventID, nil, nil, nil)
+
+		body := `{"event":{"title":"my event","startDate":"2024-07-06T10:00:00Z","endDate":"2024-07-10T00:00:00Z"}}`
+		response := serveGateway(handler, http.MethodPost, "/v1/events", body, true)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		var createResponse struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &createResponse))
+		require.Equal(t, eventIDStr, createResponse.ID)
+	})
+
+	t.Run("list for day", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		date := time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC)
+		mockedApplication.EXPECT().ListForDay(mock.Anything, userID2, mock.MatchedBy(date.Equal), app.EventFilter{}).
+			Return([]*app.EventDto{eventDto(t, userID2)}, nil)
+
+		response := serveGateway(handler, http.MethodGet, "/v1/events/day?startDate=2024-07-06T00:00:00Z", "", true)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		var listResponse struct {
+			Events []map[string]any `json:"events"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &listResponse))
+		require.Len(t, listResponse.Events, 1)
+	})
+
+	t.Run("literal path wins over event id", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		mockedApplication.EXPECT().ListDeleted(mock.Anything, userID2).Return(nil, nil)
+
+		response := serveGateway(handler, http.MethodGet, "/v1/events/trash", "", true)
+
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("event not found", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		mockedApplication.EXPECT().GetByID(mock.Anything, userID2, eventID).Return(nil, storage.ErrEventNotFound)
+
+		response := serveGateway(handler, http.MethodGet, "/v1/events/"+eventIDStr, "", true)
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+		require.Equal(t, problemContentType, response.Header().Get("Content-Type"))
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		require.Equal(t, apierror.CodeNotFound, problem.Code)
+		require.Equal(t, storage.ErrEventNotFound.Error(), problem.Detail)
+	})
+
+	t.Run("busy time", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		busyTimeErr := &app.BusyTimeError{Conflicts: []*app.EventDto{eventDto2(t, userID2)}}
+		mockedApplication.EXPECT().Create(mock.Anything, mock.Anything).Return(0, nil, nil, busyTimeErr)
+
+		body := `{"event":{"title":"my event","startDate":"2024-08-06T10:00:00Z","endDate":"2024-08-07T00:00:00Z"}}`
+		response := serveGateway(handler, http.MethodPost, "/v1/events", body, true)
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		var problem struct {
+			Code      apierror.Code    `json:"code"`
+			Conflicts []map[string]any `json:"conflicts"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		require.Equal(t, apierror.CodeBusyTime, problem.Code)
+		require.Len(t, problem.Conflicts, 1)
+		require.Equal(t, "my event2", problem.Conflicts[0]["title"])
+	})
+
+	t.Run("delete not empty calendar", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeGateway)
+
+		mockedApplication.EXPECT().DeleteCalendar(mock.Anything, userID2, uint64(7)).Return(storage.ErrCalendarNotEmpty)
+
+		response := serveGateway(handler, http.MethodDelete, "/v1/calendars/7", "", true)
+
+		require.Equal(t, http.StatusConflict, response.Code)
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		require.Equal(t, apierror.CodeFailedPrecondition, problem.Code)
+		require.Equal(t, http.StatusConflict, problem.Status)
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		handler := gatewayHandler(t, mocks.NewApplication(t), ModeGateway)
+
+		response := serveGateway(handler, http.MethodGet, "/v1/events/trash", "", false)
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		require.Equal(t, apierror.CodeInvalidArgument, problem.Code)
+		require.Equal(t, []apierror.FieldViolation{{Field: "x-user-id", Message: "userID is not valid"}}, problem.Fields)
+	})
+
+	t.Run("legacy routes disabled in gateway mode", func(t *testing.T) {
+		handler := gatewayHandler(t, mocks.NewApplication(t), ModeGateway)
+
+		response := serveGateway(handler, http.MethodGet, "/events/trash", "", true)
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("legacy routes enabled in compat mode", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := gatewayHandler(t, mockedApplication, ModeCompat)
+
+		mockedApplication.EXPECT().ListDeleted(mock.Anything, userID2).Return(nil, nil).Twice()
+
+		response := serveGateway(handler, http.MethodGet, "/events/trash", "", true)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		response = serveGateway(handler, http.MethodGet, "/v1/events/trash", "", true)
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+// запускает gRPC-сервер и возвращает HTTP-обработчик в заданном режиме, проксирующий запросы в него.
+func gatewayHandler(t *testing.T, application Application, mode string) http.Handler {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcPort := strconv.Itoa(lsn.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, lsn.Close())
+
+	grpcServer := internalgrpc.NewServer(&testLogger{}, application, grpcPort)
+	go grpcServer.Start(ctx)
+	t.Cleanup(func() { grpcServer.Stop(ctx) })
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", "127.0.0.1:"+grpcPort)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	server := NewServer(&testLogger{}, application, "", 0).WithGateway(mode, "127.0.0.1:"+grpcPort)
+	gateway, err := newGateway(ctx, &testLogger{}, server.grpcEndpoint, nil)
+	require.NoError(t, err)
+
+	var openAPI *OpenAPI
+	if mode == ModeCompat {
+		openAPI, err = NewOpenAPI(ctx)
+		require.NoError(t, err)
+	}
+	return server.routes(ctx, openAPI, gateway)
+}
+
+func serveGateway(handler http.Handler, method string, url string, body string, withUser bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if withUser {
+		req.Header.Set(userIDHeader, userID2Str)
+	}
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	return response
+}
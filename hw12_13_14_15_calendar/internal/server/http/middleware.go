@@ -2,8 +2,16 @@ package internalhttp
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
 const timeLayout = "02/Jan/2006:15:04:05 -0700"
@@ -37,3 +45,62 @@ func loggingMiddleware(ctx context.Context, logger Logger, next http.Handler) ht
 		)
 	})
 }
+
+func sourceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(app.ContextWithSource(r.Context(), app.SourceHTTP)))
+	})
+}
+
+func inFlightMiddleware(inFlight *lifecycle.InFlight, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer inFlight.Start()()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientCertMiddleware подставляет в заголовок X-USER-ID пользователя из сертификата клиента (mTLS),
+// заменяя переданное клиентом значение; дальше его используют ручки, ограничение частоты и grpc-gateway.
+// Передавать X-USER-ID самому может только сервис с CommonName trustedGateway (например, прокси),
+// остальные сертификаты без пользователя отклоняются.
+func clientCertMiddleware(logger Logger, trustedGateway string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if userID, ok := tlsconfig.UserID(r.TLS.VerifiedChains); ok {
+			r.Header.Set(userIDHeader, strconv.FormatUint(userID, 10))
+		} else if trustedGateway == "" || tlsconfig.CommonName(r.TLS.VerifiedChains) != trustedGateway {
+			writeError(r.Context(), logger, w, apierror.Unauthenticated(errNoCertUser.Error()))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitMiddleware(ctx context.Context, logger Logger, limiter *ratelimit.Limiter, route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := limiter.Allow(rateLimitKey(r), route)
+		if !allowed {
+			logger.Warn(ctx, "http request rate limited", "path", r.URL.Path)
+			writeError(r.Context(), logger, w, apierror.RateLimited(retryAfter))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ключ клиента: пользователь из сертификата (mTLS), а без него - IP-адрес.
+// Заголовок X-USER-ID не используется: его может подставить любой клиент.
+func rateLimitKey(r *http.Request) string {
+	if r.TLS != nil {
+		if userID, ok := tlsconfig.UserID(r.TLS.VerifiedChains); ok {
+			return ratelimit.UserKey(strconv.FormatUint(userID, 10))
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return ratelimit.IPKey(host)
+	}
+	return ratelimit.IPKey(r.RemoteAddr)
+}
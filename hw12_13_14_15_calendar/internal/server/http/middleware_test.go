@@ -0,0 +1,100 @@
+package internalhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	openAPI, err := NewOpenAPI(context.Background())
+	require.NoError(t, err)
+
+	mockedApplication := mocks.NewApplication(t)
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 1, Burst: 1}, nil)
+	server := NewServer(&testLogger{}, mockedApplication, "", 0).WithRateLimiter(limiter)
+	handler := server.routes(context.Background(), openAPI, nil)
+
+	// запрос с адреса remoteAddr; certUser - пользователь из сертификата клиента
+	serve := func(remoteAddr string, userID string, certUser string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/trash", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(userIDHeader, userID)
+		if certUser != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: certUser}}
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+		return response
+	}
+
+	mockedApplication.EXPECT().ListDeleted(mock.Anything, userID2).Return(nil, nil).Once()
+	require.Equal(t, http.StatusOK, serve("10.0.0.1:50000", userID2Str, "").Code)
+
+	// X-USER-ID не подтверждён, поэтому другой пользователь с того же адреса ограничивается вместе с первым
+	response := serve("10.0.0.1:50001", "12345", "")
+	require.Equal(t, http.StatusTooManyRequests, response.Code)
+	require.Equal(t, "1", response.Header().Get("Retry-After"))
+	var problem ProblemResponse
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+	require.Equal(t, apierror.CodeRateLimited, problem.Code)
+
+	// другой адрес и пользователь из сертификата ограничиваются отдельно
+	mockedApplication.EXPECT().ListDeleted(mock.Anything, userID).Return(nil, nil).Twice()
+	require.Equal(t, http.StatusOK, serve("10.0.0.2:50000", "12345", "").Code)
+	require.Equal(t, http.StatusOK, serve("10.0.0.1:50002", "12345", "12345").Code)
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var actualUserID string
+	handler := clientCertMiddleware(&testLogger{}, "calendar-proxy", http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		actualUserID = r.Header.Get(userIDHeader)
+	}))
+	serve := func(commonName string) (*httptest.ResponseRecorder, string) {
+		actualUserID = ""
+		req := httptest.NewRequest(http.MethodGet, "/events/trash", nil)
+		req.Header.Set(userIDHeader, userID2Str)
+		if commonName != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+		return response, actualUserID
+	}
+
+	// пользователь из сертификата заменяет заголовок
+	_, userID := serve("12345")
+	require.Equal(t, "12345", userID)
+	// доверенный прокси и запрос без сертификата не меняют заголовок
+	_, userID = serve("calendar-proxy")
+	require.Equal(t, userID2Str, userID)
+	_, userID = serve("")
+	require.Equal(t, userID2Str, userID)
+
+	// сертификат без пользователя не позволяет выдать себя за пользователя из заголовка
+	for _, commonName := range []string{"calendar-gateway", "0"} {
+		response, userID := serve(commonName)
+		require.Equal(t, http.StatusUnauthorized, response.Code, commonName)
+		require.Empty(t, userID)
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		require.Equal(t, apierror.CodeUnauthenticated, problem.Code)
+	}
+}
@@ -0,0 +1,228 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/api"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
+
+const (
+	openAPIPath           = "/openapi.json"
+	swaggerUIPath         = "/swagger"
+	requestValidationFail = "request validation failed"
+)
+
+// OpenAPI - спецификация HTTP API и маршрутизатор для проверки запросов по ней.
+type OpenAPI struct {
+	router            routers.Router
+	spec              []byte
+	validateResponses bool
+}
+
+func NewOpenAPI(ctx context.Context) (*OpenAPI, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(api.OpenAPI())
+	if err != nil {
+		return nil, fmt.Errorf("cannot load openapi spec: %w", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("openapi spec is not valid: %w", err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create openapi router: %w", err)
+	}
+
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode openapi spec: %w", err)
+	}
+
+	return &OpenAPI{
+		router: router,
+		spec:   spec,
+	}, nil
+}
+
+// WithResponseValidation включает проверку ответов по спецификации. Для этого ответы буферизуются целиком,
+// поэтому проверка нужна в тестах и при отладке, а не в обычной работе.
+func (o *OpenAPI) WithResponseValidation() *OpenAPI {
+	o.validateResponses = true
+	return o
+}
+
+func (o *OpenAPI) specHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(o.spec)
+}
+
+func (o *OpenAPI) swaggerUIHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, swaggerUIPage)
+}
+
+// swagger-ui подключается с CDN, чтобы не хранить его статику в репозитории.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Calendar API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "` + openAPIPath + `", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *bodyRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *bodyRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
+
+// validationMiddleware проверяет запросы по спецификации и отвечает 400 со списком ошибок полей.
+// Ответы проверяются, только если это включено в OpenAPI.WithResponseValidation; расхождения только логируются -
+// это ошибки сервера, а не клиента.
+// Запросы к путям, которых нет в спецификации, пропускаются без проверки.
+func validationMiddleware(ctx context.Context, logger Logger, openAPI *OpenAPI, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := openAPI.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		options := &openapi3filter.Options{MultiError: true}
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), requestInput); err != nil {
+			logger.Info(ctx, "http request rejected by openapi validation", "method", r.Method, "path", r.URL.Path)
+			writeError(ctx, logger, w, apierror.InvalidArgument(requestValidationFail, fieldErrors(err)...))
+			return
+		}
+
+		if !openAPI.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// пустой ответ означает успешную операцию без результата
+		if rec.body.Len() == 0 {
+			return
+		}
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 rec.status,
+			Header:                 rec.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Options:                options,
+		}
+		if err := openapi3filter.ValidateResponse(r.Context(), responseInput); err != nil {
+			logger.Error(ctx, err, "response does not match openapi spec",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"statusCode", rec.status,
+			)
+		}
+	})
+}
+
+// раскладывает ошибку валидации на ошибки отдельных полей.
+func fieldErrors(err error) []apierror.FieldViolation {
+	switch e := err.(type) { //nolint:errorlint
+	case openapi3.MultiError:
+		fields := make([]apierror.FieldViolation, 0, len(e))
+		for _, err := range e {
+			fields = append(fields, fieldErrors(err)...)
+		}
+		return fields
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			return []apierror.FieldViolation{{Field: e.Parameter.In + "." + e.Parameter.Name, Message: errorReason(e)}}
+		case e.RequestBody != nil:
+			return bodyFieldErrors(e)
+		default:
+			return []apierror.FieldViolation{{Message: errorReason(e)}}
+		}
+	default:
+		return []apierror.FieldViolation{{Message: err.Error()}}
+	}
+}
+
+func bodyFieldErrors(requestErr *openapi3filter.RequestError) []apierror.FieldViolation {
+	var schemaErrs []*openapi3.SchemaError
+	var multiErr openapi3.MultiError
+	if errors.As(requestErr.Err, &multiErr) {
+		for _, e := range multiErr {
+			var schemaErr *openapi3.SchemaError
+			if errors.As(e, &schemaErr) {
+				schemaErrs = append(schemaErrs, schemaErr)
+			}
+		}
+	} else {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(requestErr.Err, &schemaErr) {
+			schemaErrs = append(schemaErrs, schemaErr)
+		}
+	}
+
+	if len(schemaErrs) == 0 {
+		return []apierror.FieldViolation{{Field: "body", Message: errorReason(requestErr)}}
+	}
+
+	fields := make([]apierror.FieldViolation, 0, len(schemaErrs))
+	for _, schemaErr := range schemaErrs {
+		field := strings.Join(append([]string{"body"}, schemaErr.JSONPointer()...), ".")
+		fields = append(fields, apierror.FieldViolation{Field: field, Message: schemaErr.Reason})
+	}
+	return fields
+}
+
+func errorReason(requestErr *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+	if requestErr.Err != nil {
+		return requestErr.Err.Error()
+	}
+	return requestErr.Reason
+}
@@ -0,0 +1,268 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIValidation(t *testing.T) {
+	t.Parallel()
+
+	openAPI, err := NewOpenAPI(context.Background())
+	require.NoError(t, err)
+	openAPI.WithResponseValidation()
+
+	tests := []struct {
+		testName       string
+		method         string
+		url            string
+		headers        map[string]string
+		body           string
+		expectedFields []string
+	}{
+		{
+			testName:       "invalid event fields",
+			method:         http.MethodPost,
+			url:            "/events",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{"event":{"title":"","startDate":"tomorrow","endDate":"2024-07-06T10:00:00Z","notifyBefore":-1}}`,
+			expectedFields: []string{"body.event.title", "body.event.startDate", "body.event.notifyBefore"},
+		},
+		{
+			testName: "invalid event details",
+			method:   http.MethodPost,
+			url:      "/events",
+			headers:  map[string]string{userIDHeader: userID2Str},
+			body: `{"event":{"title":"t","startDate":"2024-07-06T10:00:00Z","endDate":"2024-07-06T11:00:00Z",` +
+				`"location":{"text":"office","geo":{"lat":91,"lon":0}},"attachments":[{"name":"a.pdf"}]}}`,
+			expectedFields: []string{"body.event.location.geo.lat", "body.event.attachments.0.url"},
+		},
+		{
+			testName:       "missing event",
+			method:         http.MethodPost,
+			url:            "/events",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{}`,
+			expectedFields: []string{"body.event"},
+		},
+		{
+			testName:       "malformed json",
+			method:         http.MethodPost,
+			url:            "/events",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{"event":`,
+			expectedFields: []string{"body"},
+		},
+		{
+			testName:       "missing user",
+			method:         http.MethodGet,
+			url:            "/events/trash",
+			expectedFields: []string{"header.X-USER-ID"},
+		},
+		{
+			testName:       "invalid period",
+			method:         http.MethodGet,
+			url:            "/events?startDate=2024-07-06&period=year",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			expectedFields: []string{"query.period"},
+		},
+		{
+			testName:       "invalid event id",
+			method:         http.MethodGet,
+			url:            "/events/abc",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			expectedFields: []string{"path.eventID"},
+		},
+		{
+			testName:       "invalid overlap policy",
+			method:         http.MethodPut,
+			url:            "/settings",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{"settings":{"overlapPolicy":"SOMETIMES"}}`,
+			expectedFields: []string{"body.settings.overlapPolicy"},
+		},
+		{
+			testName: "invalid working hours",
+			method:   http.MethodPut,
+			url:      "/settings",
+			headers:  map[string]string{userIDHeader: userID2Str},
+			body: `{"settings":{"overlapPolicy":"REJECT","availabilityPolicy":"NEVER",` +
+				`"workingHours":[{"weekday":7,"start":"9am","end":"18:00"}],"outOfOffice":[{}]}}`,
+			expectedFields: []string{
+				"body.settings.availabilityPolicy", "body.settings.workingHours.0.weekday", "body.settings.workingHours.0.start",
+				"body.settings.outOfOffice.0.startDate", "body.settings.outOfOffice.0.endDate", "body.settings.outOfOffice.0.message",
+			},
+		},
+		{
+			testName:       "invalid calendar fields",
+			method:         http.MethodPost,
+			url:            "/calendars",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{"calendar":{"name":"","color":"green"}}`,
+			expectedFields: []string{"body.calendar.name", "body.calendar.color"},
+		},
+		{
+			testName:       "invalid calendar filter",
+			method:         http.MethodGet,
+			url:            "/events?startDate=2024-07-06&period=day&calendarId=0",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			expectedFields: []string{"query.calendarId"},
+		},
+		{
+			testName:       "invalid resource fields",
+			method:         http.MethodPost,
+			url:            "/resources",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			body:           `{"resource":{"name":"","capacity":-1}}`,
+			expectedFields: []string{"body.resource.name", "body.resource.capacity"},
+		},
+		{
+			testName:       "missing availability period",
+			method:         http.MethodGet,
+			url:            "/resources/3/availability?start=2024-07-06T00:00:00Z",
+			headers:        map[string]string{userIDHeader: userID2Str},
+			expectedFields: []string{"query.end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			server := NewServer(&testLogger{}, mocks.NewApplication(t), "", 0)
+			handler := server.routes(context.Background(), openAPI, nil)
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, req)
+
+			require.Equal(t, http.StatusBadRequest, response.Code)
+			require.Equal(t, problemContentType, response.Header().Get("Content-Type"))
+			var errorResponse ProblemResponse
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &errorResponse))
+			require.Equal(t, requestValidationFail, errorResponse.Detail)
+			require.Equal(t, apierror.CodeInvalidArgument, errorResponse.Code)
+
+			fields := make([]string, 0, len(errorResponse.Fields))
+			for _, field := range errorResponse.Fields {
+				require.NotEmpty(t, field.Message)
+				fields = append(fields, field.Field)
+			}
+			require.ElementsMatch(t, tt.expectedFields, fields)
+		})
+	}
+
+	t.Run("valid request", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		server := NewServer(&testLogger{}, mockedApplication, "", 0)
+		handler := server.routes(context.Background(), openAPI, nil)
+
+		mockedApplication.EXPECT().Create(mock.Anything, *eventDto(t, userID2)).Return(eventID, nil, nil, nil)
+
+		body, err := json.Marshal(CreateEventRequest{Event: eventDto(t, 0)})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(userIDHeader, userID2Str)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("response not matching spec is logged", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		logger := &testLogger{}
+		server := NewServer(logger, mockedApplication, "", 0)
+		handler := server.routes(context.Background(), openAPI, nil)
+
+		event := eventDto(t, userID2)
+		event.Title = ""
+		mockedApplication.EXPECT().GetByID(mock.Anything, userID2, eventID).Return(event, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/"+eventIDStr, nil)
+		req.Header.Set(userIDHeader, userID2Str)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		require.Equal(t, []string{"response does not match openapi spec"}, logger.errors())
+	})
+
+	t.Run("problem response matches spec", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		logger := &testLogger{}
+		server := NewServer(logger, mockedApplication, "", 0)
+		handler := server.routes(context.Background(), openAPI, nil)
+
+		mockedApplication.EXPECT().GetByID(mock.Anything, userID2, eventID).Return(nil, storage.ErrEventNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/"+eventIDStr, nil)
+		req.Header.Set(userIDHeader, userID2Str)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, req)
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+		require.Empty(t, logger.errors())
+	})
+
+	t.Run("serve spec", func(t *testing.T) {
+		server := NewServer(&testLogger{}, mocks.NewApplication(t), "", 0)
+		handler := server.routes(context.Background(), openAPI, nil)
+
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+		require.Equal(t, http.StatusOK, response.Code)
+		var spec struct {
+			OpenAPI string                    `json:"openapi"`
+			Paths   map[string]map[string]any `json:"paths"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &spec))
+		require.Equal(t, "3.0.3", spec.OpenAPI)
+		require.Contains(t, spec.Paths, "/events/{eventID}")
+	})
+}
+
+// testLogger запоминает сообщения об ошибках.
+type testLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *testLogger) Debug(_ context.Context, _ string, _ ...any) {}
+
+func (l *testLogger) Info(_ context.Context, _ string, _ ...any) {}
+
+func (l *testLogger) Warn(_ context.Context, _ string, _ ...any) {}
+
+func (l *testLogger) Error(_ context.Context, _ error, msg string, _ ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msgs = append(l.msgs, msg)
+}
+
+func (l *testLogger) errors() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.msgs
+}
+
+var _ app.Logger = (*testLogger)(nil)
@@ -0,0 +1,251 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
+
+const (
+	resourceIDPath      = "resourceID"
+	kindQueryKey        = "kind"
+	minCapacityQueryKey = "minCapacity"
+	periodStartQueryKey = "start"
+	periodEndQueryKey   = "end"
+)
+
+var (
+	errNotValidResourceID = errors.New("resourceID is not valid")
+	errResourceRequired   = errors.New("resource is required")
+)
+
+// ResourceHandler обрабатывает запросы к ресурсам; ресурсы общие для всех пользователей,
+// но запрос всё равно должен быть от аутентифицированного пользователя.
+type ResourceHandler struct {
+	logger Logger
+	app    Application
+}
+
+func NewResourceHandler(logger Logger, app Application) *ResourceHandler {
+	return &ResourceHandler{
+		logger: logger,
+		app:    app,
+	}
+}
+
+func (s *ResourceHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resource, err := readResource(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	resource.UserID = userID
+
+	resourceID, err := s.app.CreateResource(ctx, *resource)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, CreateResourceResponse{ResourceID: resourceID})
+}
+
+func (s *ResourceHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := getUserID(r); err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resourceID, err := getResourceID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resource, err := s.app.GetResource(ctx, resourceID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, ResourceResponse{Resource: resource})
+}
+
+func (s *ResourceHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resourceID, err := getResourceID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resource, err := readResource(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	if resource.ID != resourceID {
+		writeError(ctx, s.logger, w, apierror.InvalidField("body.resource.id", errNotValidResourceID))
+		return
+	}
+	resource.UserID = userID
+
+	err = s.app.UpdateResource(ctx, *resource)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+func (s *ResourceHandler) delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resourceID, err := getResourceID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	err = s.app.DeleteResource(ctx, userID, resourceID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+func (s *ResourceHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := getUserID(r); err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	filter, err := getResourceFilter(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resources, err := s.app.ListResources(ctx, filter)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, ResourcesResponse{Resources: resources})
+}
+
+func (s *ResourceHandler) availability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := getUserID(r); err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	resourceID, err := getResourceID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	start, err := getDateTime(r, periodStartQueryKey)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	end, err := getDateTime(r, periodEndQueryKey)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	busy, err := s.app.GetResourceAvailability(ctx, resourceID, start, end)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, ResourceAvailabilityResponse{Busy: busy})
+}
+
+func readResource(r *http.Request) (*app.ResourceDto, error) {
+	reqData, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apierror.InvalidField("body", fmt.Errorf("failed reading request: %w", err))
+	}
+
+	var resourceReq ResourceRequest
+	if err := json.Unmarshal(reqData, &resourceReq); err != nil {
+		return nil, apierror.InvalidField("body", fmt.Errorf("failed parsing request: %w", err))
+	}
+	if resourceReq.Resource == nil {
+		return nil, apierror.InvalidField("body.resource", errResourceRequired)
+	}
+	return resourceReq.Resource, nil
+}
+
+func getResourceID(r *http.Request) (uint64, error) {
+	resourceID, err := strconv.ParseUint(mux.Vars(r)[resourceIDPath], 10, 64)
+	if err == nil && resourceID == 0 {
+		err = errNotValidResourceID
+	}
+	if err != nil {
+		return 0, apierror.InvalidField("path."+resourceIDPath, err)
+	}
+	return resourceID, nil
+}
+
+func getResourceFilter(r *http.Request) (app.ResourceFilter, error) {
+	query := r.URL.Query()
+	filter := app.ResourceFilter{Kind: query.Get(kindQueryKey)}
+	if value := query.Get(minCapacityQueryKey); value != "" {
+		minCapacity, err := strconv.Atoi(value)
+		if err != nil {
+			return app.ResourceFilter{}, apierror.InvalidField("query."+minCapacityQueryKey, err)
+		}
+		filter.MinCapacity = minCapacity
+	}
+	return filter, nil
+}
+
+// getDateTime читает из запроса дату со временем в формате RFC 3339.
+func getDateTime(r *http.Request, key string) (time.Time, error) {
+	value, err := time.Parse(time.RFC3339, r.URL.Query().Get(key))
+	if err != nil {
+		return time.Time{}, apierror.InvalidField("query."+key, err)
+	}
+	return value, nil
+}
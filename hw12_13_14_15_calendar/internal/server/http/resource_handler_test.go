@@ -0,0 +1,153 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHandler(t *testing.T) {
+	t.Parallel()
+
+	resource := &app.ResourceDto{ID: 3, UserID: userID2, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: map[string]string{"floor": "2"}}
+
+	// отправляет запрос через маршрутизатор, чтобы в запросе был ID ресурса из пути
+	serve := func(handler *ResourceHandler, method string, url string, body any) *httptest.ResponseRecorder {
+		requestBody, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewBuffer(requestBody))
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/resources", handler.create).Methods("POST")
+		router.HandleFunc("/resources", handler.list).Methods("GET")
+		router.HandleFunc("/resources/{resourceID}/availability", handler.availability).Methods("GET")
+		router.HandleFunc("/resources/{resourceID}", handler.get).Methods("GET")
+		router.HandleFunc("/resources/{resourceID}", handler.update).Methods("PUT")
+		router.HandleFunc("/resources/{resourceID}", handler.delete).Methods("DELETE")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("create resource", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		// создатель берётся из заголовка, а не из тела запроса
+		newResource := *resource
+		newResource.ID = 0
+		mockedApplication.EXPECT().CreateResource(mock.Anything, newResource).Return(resource.ID, nil)
+
+		requestResource := newResource
+		requestResource.UserID = userID
+		response := serve(handler, "POST", "/resources", ResourceRequest{Resource: &requestResource})
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(CreateResourceResponse{ResourceID: resource.ID})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("get not existing resource", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().GetResource(mock.Anything, uint64(8)).Return(nil, storage.ErrResourceNotFound)
+
+		response := serve(handler, "GET", "/resources/8", nil)
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("update resource with another id", func(t *testing.T) {
+		handler := NewResourceHandler(mocks.NewLogger(t), mocks.NewApplication(t))
+
+		response := serve(handler, "PUT", "/resources/8", ResourceRequest{Resource: resource})
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("delete reserved resource", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().DeleteResource(mock.Anything, userID2, resource.ID).Return(storage.ErrResourceInUse)
+
+		response := serve(handler, "DELETE", "/resources/3", nil)
+
+		require.Equal(t, http.StatusConflict, response.Code)
+	})
+
+	t.Run("update resource of another user", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().UpdateResource(mock.Anything, *resource).Return(app.ErrNotResourceOwner)
+
+		response := serve(handler, "PUT", "/resources/3", ResourceRequest{Resource: resource})
+
+		require.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("list resources by filter", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		mockedApplication.EXPECT().ListResources(mock.Anything, app.ResourceFilter{Kind: "room", MinCapacity: 8}).
+			Return([]*app.ResourceDto{resource}, nil)
+
+		response := serve(handler, "GET", "/resources?kind=room&minCapacity=8", nil)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(ResourcesResponse{Resources: []*app.ResourceDto{resource}})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("resource availability", func(t *testing.T) {
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewResourceHandler(mocks.NewLogger(t), mockedApplication)
+
+		start := time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC)
+		end := start.Add(24 * time.Hour)
+		busy := []*app.BusyIntervalDto{{StartDate: start.Add(10 * time.Hour), EndDate: start.Add(12 * time.Hour)}}
+		mockedApplication.EXPECT().GetResourceAvailability(mock.Anything, resource.ID, start, end).Return(busy, nil)
+
+		response := serve(handler, "GET", "/resources/3/availability?start=2024-07-06T00:00:00Z&end=2024-07-07T00:00:00Z", nil)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(ResourceAvailabilityResponse{Busy: busy})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("resource availability without period", func(t *testing.T) {
+		handler := NewResourceHandler(mocks.NewLogger(t), mocks.NewApplication(t))
+
+		response := serve(handler, "GET", "/resources/3/availability?start=2024-07-06", nil)
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
@@ -2,20 +2,34 @@ package internalhttp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/lifecycle"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/ratelimit"
 )
 
 type Server struct {
-	logger      Logger
-	addr        string
-	readTimeout time.Duration
-	handler     *EventHandler
-	srv         *http.Server
+	logger            Logger
+	addr              string
+	readTimeout       time.Duration
+	mode              string
+	grpcEndpoint      string
+	limiter           *ratelimit.Limiter
+	tls               *tls.Config
+	trustedGateway    string
+	gatewayTLS        *tls.Config
+	validateResponses bool
+	inFlight          lifecycle.InFlight
+	handler           *EventHandler
+	settings          *SettingsHandler
+	calendars         *CalendarHandler
+	resources         *ResourceHandler
+	srv               *http.Server
 }
 
 type Logger interface {
@@ -26,13 +40,30 @@ type Logger interface {
 }
 
 type Application interface {
-	Create(ctx context.Context, eventDto app.EventDto) (uint64, error)
+	Create(ctx context.Context, eventDto app.EventDto) (uint64, []*app.EventDto, []app.WarningDto, error)
 	GetByID(ctx context.Context, userID uint64, eventID uint64) (*app.EventDto, error)
-	Update(ctx context.Context, eventDto app.EventDto) error
+	Update(ctx context.Context, eventDto app.EventDto) ([]*app.EventDto, []app.WarningDto, error)
 	Delete(ctx context.Context, userID uint64, eventID uint64) error
-	ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*app.EventDto, error)
-	ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error)
-	ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*app.EventDto, error)
+	Restore(ctx context.Context, userID uint64, eventID uint64) error
+	ListDeleted(ctx context.Context, userID uint64) ([]*app.EventDto, error)
+	GetHistory(ctx context.Context, userID uint64, eventID uint64) ([]*app.AuditRecordDto, error)
+	ListForDay(ctx context.Context, userID uint64, date time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	ListForWeek(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	ListForMonth(ctx context.Context, userID uint64, startDate time.Time, filter app.EventFilter) ([]*app.EventDto, error)
+	GetUserSettings(ctx context.Context, userID uint64) (*app.UserSettingsDto, error)
+	UpdateUserSettings(ctx context.Context, settingsDto app.UserSettingsDto) error
+	GetFreeBusy(ctx context.Context, userID uint64, startDate time.Time, endDateExclusive time.Time) (*app.FreeBusyDto, error)
+	CreateCalendar(ctx context.Context, calendarDto app.CalendarDto) (uint64, error)
+	GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*app.CalendarDto, error)
+	UpdateCalendar(ctx context.Context, calendarDto app.CalendarDto) error
+	DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error
+	ListCalendars(ctx context.Context, userID uint64) ([]*app.CalendarDto, error)
+	CreateResource(ctx context.Context, resourceDto app.ResourceDto) (uint64, error)
+	GetResource(ctx context.Context, resourceID uint64) (*app.ResourceDto, error)
+	UpdateResource(ctx context.Context, resourceDto app.ResourceDto) error
+	DeleteResource(ctx context.Context, userID uint64, resourceID uint64) error
+	ListResources(ctx context.Context, filter app.ResourceFilter) ([]*app.ResourceDto, error)
+	GetResourceAvailability(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*app.BusyIntervalDto, error)
 }
 
 func NewServer(logger Logger, app Application, addr string, readTimeout time.Duration) *Server {
@@ -40,46 +71,183 @@ func NewServer(logger Logger, app Application, addr string, readTimeout time.Dur
 		logger:      logger,
 		addr:        addr,
 		readTimeout: readTimeout,
+		mode:        ModeLegacy,
 		handler:     NewEventHandler(logger, app),
+		settings:    NewSettingsHandler(logger, app),
+		calendars:   NewCalendarHandler(logger, app),
+		resources:   NewResourceHandler(logger, app),
 	}
 }
 
+// WithGateway включает режим работы HTTP API (ModeGateway или ModeCompat),
+// в котором REST-запросы проксируются в gRPC-сервер по адресу grpcEndpoint.
+func (s *Server) WithGateway(mode string, grpcEndpoint string) *Server {
+	s.mode = mode
+	s.grpcEndpoint = grpcEndpoint
+	return s
+}
+
+// WithRateLimiter включает ограничение частоты запросов к прежним ручкам;
+// запросы через grpc-gateway ограничивает gRPC-сервер.
+func (s *Server) WithRateLimiter(limiter *ratelimit.Limiter) *Server {
+	s.limiter = limiter
+	return s
+}
+
+// WithTLS включает HTTPS; если в конфигурации задан CA клиентов - mTLS,
+// и пользователь из сертификата клиента заменяет заголовок X-USER-ID.
+func (s *Server) WithTLS(config *tls.Config) *Server {
+	s.tls = config
+	return s
+}
+
+// WithTrustedGateway разрешает клиенту с сертификатом commonName (например, прокси) передавать
+// пользователя в X-USER-ID; при mTLS остальные клиенты без пользователя в сертификате отклоняются.
+func (s *Server) WithTrustedGateway(commonName string) *Server {
+	s.trustedGateway = commonName
+	return s
+}
+
+// WithGatewayTLS задаёт настройки TLS подключения grpc-gateway к gRPC-серверу;
+// без них gateway подключается без шифрования.
+func (s *Server) WithGatewayTLS(config *tls.Config) *Server {
+	s.gatewayTLS = config
+	return s
+}
+
+// WithResponseValidation включает проверку ответов прежних ручек по спецификации OpenAPI (см. OpenAPI.WithResponseValidation).
+func (s *Server) WithResponseValidation() *Server {
+	s.validateResponses = true
+	return s
+}
+
 func (s *Server) Start(ctx context.Context) error {
-	s.logger.Info(ctx, "starting http server")
+	s.logger.Info(ctx, "starting http server", "mode", s.mode, "tls", s.tls != nil)
+
+	if s.mode != ModeLegacy && s.mode != ModeGateway && s.mode != ModeCompat {
+		err := fmt.Errorf("unknown http server mode %q", s.mode)
+		s.logger.Error(ctx, err, "failed to start http server")
+		return err
+	}
 
+	var openAPI *OpenAPI
+	if s.mode != ModeGateway {
+		var err error
+		if openAPI, err = NewOpenAPI(ctx); err != nil {
+			s.logger.Error(ctx, err, "failed to load openapi spec")
+			return err
+		}
+		if s.validateResponses {
+			openAPI.WithResponseValidation()
+		}
+	}
+
+	var gateway http.Handler
+	if s.mode != ModeLegacy {
+		var err error
+		if gateway, err = newGateway(ctx, s.logger, s.grpcEndpoint, s.gatewayTLS); err != nil {
+			s.logger.Error(ctx, err, "failed to create grpc gateway")
+			return err
+		}
+	}
+
+	s.srv = &http.Server{
+		Addr:        s.addr,
+		Handler:     inFlightMiddleware(&s.inFlight, clientCertMiddleware(s.logger, s.trustedGateway, s.routes(ctx, openAPI, gateway))),
+		ReadTimeout: s.readTimeout,
+		TLSConfig:   s.tls,
+	}
+
+	if s.tls != nil {
+		// сертификат берётся из TLSConfig
+		return s.srv.ListenAndServeTLS("", "")
+	}
+	return s.srv.ListenAndServe()
+}
+
+// routes собирает маршруты. openAPI нужен для прежних ручек, gateway - для ручек grpc-gateway;
+// nil означает, что соответствующие ручки в текущем режиме не обслуживаются.
+func (s *Server) routes(ctx context.Context, openAPI *OpenAPI, gateway http.Handler) http.Handler {
 	mux := mux.NewRouter()
 
 	mux.Handle("/hello", loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.helloHandler))).Methods("GET")
 
-	mux.Handle("/events", loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.create))).Methods("POST")
-	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.update))).Methods("PUT")
-	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.getByID))).Methods("GET")
-	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.delete))).Methods("DELETE")
-	mux.Handle("/events", loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.listForDay))).Methods("GET").Queries(
+	if gateway != nil {
+		mux.PathPrefix(gatewayPathPrefix).Handler(loggingMiddleware(ctx, s.logger, gateway))
+	}
+	if openAPI == nil {
+		return sourceMiddleware(mux)
+	}
+
+	mux.Handle(openAPIPath, http.HandlerFunc(openAPI.specHandler)).Methods("GET")
+	mux.Handle(swaggerUIPath, http.HandlerFunc(openAPI.swaggerUIHandler)).Methods("GET")
+
+	mux.Handle("/events", s.handle(ctx, "CreateEvent", s.handler.create)).Methods("POST")
+	mux.Handle("/events/trash", s.handle(ctx, "EventListDeleted", s.handler.listDeleted)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/events/{%s}/restore", eventIDPath), s.handle(ctx, "RestoreEvent", s.handler.restore)).Methods("POST")
+	mux.Handle(fmt.Sprintf("/events/{%s}/history", eventIDPath), s.handle(ctx, "EventHistory", s.handler.getHistory)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), s.handle(ctx, "UpdateEvent", s.handler.update)).Methods("PUT")
+	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), s.handle(ctx, "GetEvent", s.handler.getByID)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/events/{%s}", eventIDPath), s.handle(ctx, "DeleteEvent", s.handler.delete)).Methods("DELETE")
+	mux.Handle("/events", s.handle(ctx, "EventListForDay", s.handler.listForDay)).Methods("GET").Queries(
 		startDateQueryKey, startDateQueryValue,
 		periodTypeQueryKey, periodDayQueryValue,
 	)
-	mux.Handle("/events", loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.listForWeek))).Methods("GET").Queries(
+	mux.Handle("/events", s.handle(ctx, "EventListForWeek", s.handler.listForWeek)).Methods("GET").Queries(
 		startDateQueryKey, startDateQueryValue,
 		periodTypeQueryKey, periodWeekQueryValue,
 	)
-	mux.Handle("/events", loggingMiddleware(ctx, s.logger, http.HandlerFunc(s.handler.listForMonth))).Methods("GET").Queries(
+	mux.Handle("/events", s.handle(ctx, "EventListForMonth", s.handler.listForMonth)).Methods("GET").Queries(
 		startDateQueryKey, startDateQueryValue,
 		periodTypeQueryKey, periodMonthQueryValue,
 	)
 
-	s.srv = &http.Server{
-		Addr:        s.addr,
-		Handler:     mux,
-		ReadTimeout: s.readTimeout,
-	}
+	mux.Handle("/settings", s.handle(ctx, "GetUserSettings", s.settings.get)).Methods("GET")
+	mux.Handle("/settings", s.handle(ctx, "UpdateUserSettings", s.settings.update)).Methods("PUT")
+	mux.Handle(fmt.Sprintf("/users/{%s}/freebusy", userIDPath), s.handle(ctx, "GetFreeBusy", s.settings.freeBusy)).Methods("GET")
 
-	return s.srv.ListenAndServe()
+	mux.Handle("/calendars", s.handle(ctx, "CreateCalendar", s.calendars.create)).Methods("POST")
+	mux.Handle("/calendars", s.handle(ctx, "ListCalendars", s.calendars.list)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/calendars/{%s}", calendarIDPath), s.handle(ctx, "GetCalendar", s.calendars.get)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/calendars/{%s}", calendarIDPath), s.handle(ctx, "UpdateCalendar", s.calendars.update)).Methods("PUT")
+	mux.Handle(fmt.Sprintf("/calendars/{%s}", calendarIDPath), s.handle(ctx, "DeleteCalendar", s.calendars.delete)).Methods("DELETE")
+
+	mux.Handle("/resources", s.handle(ctx, "CreateResource", s.resources.create)).Methods("POST")
+	mux.Handle("/resources", s.handle(ctx, "ListResources", s.resources.list)).Methods("GET")
+	mux.Handle(
+		fmt.Sprintf("/resources/{%s}/availability", resourceIDPath),
+		s.handle(ctx, "GetResourceAvailability", s.resources.availability),
+	).Methods("GET")
+	mux.Handle(fmt.Sprintf("/resources/{%s}", resourceIDPath), s.handle(ctx, "GetResource", s.resources.get)).Methods("GET")
+	mux.Handle(fmt.Sprintf("/resources/{%s}", resourceIDPath), s.handle(ctx, "UpdateResource", s.resources.update)).Methods("PUT")
+	mux.Handle(fmt.Sprintf("/resources/{%s}", resourceIDPath), s.handle(ctx, "DeleteResource", s.resources.delete)).Methods("DELETE")
+
+	return sourceMiddleware(validationMiddleware(ctx, s.logger, openAPI, mux))
 }
 
+// handle оборачивает прежнюю ручку логированием и ограничением частоты запросов.
+// Имя маршрута route совпадает с именем метода EventService, чтобы лимиты в конфиге были общими для HTTP и gRPC.
+func (s *Server) handle(ctx context.Context, route string, handler http.HandlerFunc) http.Handler {
+	var next http.Handler = handler
+	if s.limiter != nil {
+		next = rateLimitMiddleware(ctx, s.logger, s.limiter, route, next)
+	}
+	return loggingMiddleware(ctx, s.logger, next)
+}
+
+// Stop перестаёт принимать соединения и ждёт завершения выполняющихся запросов до отмены ctx;
+// после этого оставшиеся соединения закрываются.
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info(ctx, "stopping http server")
-	return s.srv.Shutdown(ctx)
+	if s.srv == nil {
+		return nil
+	}
+	if err := s.srv.Shutdown(ctx); err != nil {
+		inFlight := s.inFlight.Count()
+		_ = s.srv.Close()
+		return fmt.Errorf("%d http requests in flight: %w", inFlight, err)
+	}
+	return nil
 }
 
 func (s *Server) helloHandler(w http.ResponseWriter, _ *http.Request) {
@@ -0,0 +1,120 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
+
+const userIDPath = "userID"
+
+var errSettingsRequired = errors.New("settings are required")
+
+type SettingsHandler struct {
+	logger Logger
+	app    Application
+}
+
+func NewSettingsHandler(logger Logger, app Application) *SettingsHandler {
+	return &SettingsHandler{
+		logger: logger,
+		app:    app,
+	}
+}
+
+func (s *SettingsHandler) get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	settings, err := s.app.GetUserSettings(ctx, userID)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	response := UserSettingsResponse{Settings: settings}
+	writeJSON(ctx, s.logger, w, http.StatusOK, response)
+}
+
+func (s *SettingsHandler) update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := getUserID(r)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	reqData, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed reading request: %w", err)))
+		return
+	}
+
+	var settingsReq UserSettingsRequest
+	if err := json.Unmarshal(reqData, &settingsReq); err != nil {
+		writeError(ctx, s.logger, w, apierror.InvalidField("body", fmt.Errorf("failed parsing request: %w", err)))
+		return
+	}
+	if settingsReq.Settings == nil {
+		writeError(ctx, s.logger, w, apierror.InvalidField("body.settings", errSettingsRequired))
+		return
+	}
+
+	settingsReq.Settings.UserID = userID
+
+	err = s.app.UpdateUserSettings(ctx, *settingsReq.Settings)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+}
+
+// freeBusy возвращает занятость любого пользователя из пути без подробностей его событий.
+func (s *SettingsHandler) freeBusy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := getUserID(r); err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(mux.Vars(r)[userIDPath], 10, 64)
+	if err == nil && userID == 0 {
+		err = errNotValidUserID
+	}
+	if err != nil {
+		writeError(ctx, s.logger, w, apierror.InvalidField("path."+userIDPath, err))
+		return
+	}
+
+	start, err := getDateTime(r, periodStartQueryKey)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+	end, err := getDateTime(r, periodEndQueryKey)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	freeBusy, err := s.app.GetFreeBusy(ctx, userID, start, end)
+	if err != nil {
+		writeError(ctx, s.logger, w, err)
+		return
+	}
+
+	writeJSON(ctx, s.logger, w, http.StatusOK, FreeBusyResponse{FreeBusy: freeBusy})
+}
@@ -0,0 +1,120 @@
+package internalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsHandler(t *testing.T) {
+	t.Parallel()
+
+	settings := &app.UserSettingsDto{UserID: userID2, OverlapPolicy: "WARN"}
+
+	t.Run("get user settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewSettingsHandler(mockedLogger, mockedApplication)
+
+		mockedApplication.EXPECT().GetUserSettings(mock.Anything, userID2).Return(settings, nil)
+
+		req, err := http.NewRequestWithContext(context.Background(), "GET", "/settings", nil)
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		response := httptest.NewRecorder()
+		handler.get(response, req)
+
+		expectedResponseBody, err := json.Marshal(UserSettingsResponse{Settings: settings})
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+
+	t.Run("update user settings", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewSettingsHandler(mockedLogger, mockedApplication)
+
+		mockedApplication.EXPECT().UpdateUserSettings(mock.Anything, *settings).Return(nil)
+
+		requestBody, err := json.Marshal(UserSettingsRequest{Settings: &app.UserSettingsDto{OverlapPolicy: "WARN"}})
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), "PUT", "/settings", bytes.NewBuffer(requestBody))
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		response := httptest.NewRecorder()
+		handler.update(response, req)
+
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("update user settings with not valid policy", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewSettingsHandler(mockedLogger, mockedApplication)
+
+		invalidSettings := app.UserSettingsDto{UserID: userID2, OverlapPolicy: "SOMETIMES"}
+		mockedApplication.EXPECT().UpdateUserSettings(mock.Anything, invalidSettings).Return(app.ErrNotValidOverlapPolicy)
+
+		requestBody, err := json.Marshal(UserSettingsRequest{Settings: &invalidSettings})
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), "PUT", "/settings", bytes.NewBuffer(requestBody))
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		response := httptest.NewRecorder()
+		handler.update(response, req)
+
+		require.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("free busy of another user", func(t *testing.T) {
+		mockedLogger := mocks.NewLogger(t)
+		mockedApplication := mocks.NewApplication(t)
+		handler := NewSettingsHandler(mockedLogger, mockedApplication)
+
+		start := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+		end := start.Add(24 * time.Hour)
+		freeBusy := &app.FreeBusyDto{
+			Busy:        []*app.BusyIntervalDto{{StartDate: start.Add(10 * time.Hour), EndDate: start.Add(11 * time.Hour)}},
+			OutOfOffice: []app.OutOfOfficeDto{{StartDate: start.Add(16 * time.Hour), EndDate: end, Message: "On vacation"}},
+			Free:        []*app.BusyIntervalDto{{StartDate: start.Add(9 * time.Hour), EndDate: start.Add(10 * time.Hour)}},
+		}
+		mockedApplication.EXPECT().GetFreeBusy(mock.Anything, userID, start, end).Return(freeBusy, nil)
+
+		url := "/users/12345/freebusy?start=2024-07-08T00:00:00Z&end=2024-07-09T00:00:00Z"
+		req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+		require.NoError(t, err)
+		req.Header.Add(userIDHeader, userID2Str)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/users/{userID}/freebusy", handler.freeBusy).Methods("GET")
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		expectedResponseBody, err := json.Marshal(FreeBusyResponse{FreeBusy: freeBusy})
+		require.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, expectedResponseBody, responseBody)
+	})
+}
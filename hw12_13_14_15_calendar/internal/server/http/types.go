@@ -1,19 +1,29 @@
 package internalhttp
 
-import "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+import (
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/apierror"
+)
 
 type CreateEventRequest struct {
 	Event *app.EventDto `json:"event"`
 }
 
 type CreateEventResponse struct {
-	EventID uint64 `json:"eventId"`
+	EventID   uint64           `json:"eventId"`
+	Conflicts []*app.EventDto  `json:"conflicts,omitempty"`
+	Warnings  []app.WarningDto `json:"warnings,omitempty"`
 }
 
 type UpdateEventRequest struct {
 	Event *app.EventDto `json:"event"`
 }
 
+type UpdateEventResponse struct {
+	Conflicts []*app.EventDto  `json:"conflicts,omitempty"`
+	Warnings  []app.WarningDto `json:"warnings,omitempty"`
+}
+
 type EventResponse struct {
 	Event *app.EventDto `json:"event"`
 }
@@ -21,3 +31,67 @@ type EventResponse struct {
 type EventsResponse struct {
 	Events []*app.EventDto `json:"events"`
 }
+
+type EventHistoryResponse struct {
+	History []*app.AuditRecordDto `json:"history"`
+}
+
+// ProblemResponse - ошибка API в формате application/problem+json (RFC 7807).
+// Conflicts заполняется для ошибки пересечения с другими событиями.
+type ProblemResponse struct {
+	Type      string                    `json:"type"`
+	Title     string                    `json:"title"`
+	Status    int                       `json:"status"`
+	Detail    string                    `json:"detail"`
+	Code      apierror.Code             `json:"code"`
+	Fields    []apierror.FieldViolation `json:"fields,omitempty"`
+	Conflicts any                       `json:"conflicts,omitempty"`
+}
+
+type UserSettingsRequest struct {
+	Settings *app.UserSettingsDto `json:"settings"`
+}
+
+type UserSettingsResponse struct {
+	Settings *app.UserSettingsDto `json:"settings"`
+}
+
+type CalendarRequest struct {
+	Calendar *app.CalendarDto `json:"calendar"`
+}
+
+type CreateCalendarResponse struct {
+	CalendarID uint64 `json:"calendarId"`
+}
+
+type CalendarResponse struct {
+	Calendar *app.CalendarDto `json:"calendar"`
+}
+
+type CalendarsResponse struct {
+	Calendars []*app.CalendarDto `json:"calendars"`
+}
+
+type ResourceRequest struct {
+	Resource *app.ResourceDto `json:"resource"`
+}
+
+type CreateResourceResponse struct {
+	ResourceID uint64 `json:"resourceId"`
+}
+
+type ResourceResponse struct {
+	Resource *app.ResourceDto `json:"resource"`
+}
+
+type ResourcesResponse struct {
+	Resources []*app.ResourceDto `json:"resources"`
+}
+
+type ResourceAvailabilityResponse struct {
+	Busy []*app.BusyIntervalDto `json:"busy"`
+}
+
+type FreeBusyResponse struct {
+	FreeBusy *app.FreeBusyDto `json:"freeBusy"`
+}
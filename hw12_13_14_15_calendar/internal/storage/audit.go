@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type AuditRecord struct {
+	ID        uint64       `db:"audit_id"`
+	EventID   uint64       `db:"event_id"`
+	UserID    uint64       `db:"user_id"`
+	ActorID   uint64       `db:"actor_id"`
+	Action    AuditAction  `db:"action"`
+	Changes   AuditChanges `db:"changes"`
+	Source    string       `db:"source"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "CREATE"
+	AuditUpdate  AuditAction = "UPDATE"
+	AuditDelete  AuditAction = "DELETE"
+	AuditRestore AuditAction = "RESTORE"
+)
+
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// AuditChanges хранится в БД в виде json.
+type AuditChanges []FieldChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *AuditChanges) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for audit changes")
+	}
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// DefaultCalendarName - имя календаря, который создаётся для пользователя автоматически.
+const DefaultCalendarName = "Default"
+
+// Calendar - календарь пользователя («Работа», «Личное», календарь команды); каждое событие принадлежит календарю.
+// NotifyBefore - напоминание по умолчанию для событий, создаваемых без своего напоминания.
+// Календарь по умолчанию (IsDefault) создаётся автоматически, в него попадают события без календаря.
+// SharedWith - пользователи, которым календарь открыт на чтение: они видят календарь и его события,
+// но менять их может только владелец.
+type Calendar struct {
+	ID           uint64         `db:"calendar_id"`
+	UserID       uint64         `db:"user_id"`
+	Name         string         `db:"name"`
+	Color        string         `db:"color"`
+	NotifyBefore time.Duration  `db:"notify_before"`
+	IsDefault    bool           `db:"is_default"`
+	SharedWith   CalendarShares `db:"shared_with"`
+}
+
+func NewDefaultCalendar(userID uint64) *Calendar {
+	return &Calendar{
+		UserID:    userID,
+		Name:      DefaultCalendarName,
+		IsDefault: true,
+	}
+}
+
+// CalendarShares - ID пользователей, которым открыт календарь, по возрастанию. В БД хранится в таблице
+// calendar_shares, а в запросах собирается в json так же, как ResourceIDs.
+type CalendarShares []uint64
+
+func (c CalendarShares) Value() (driver.Value, error) {
+	return jsonValue(c, c == nil)
+}
+
+func (c *CalendarShares) Scan(src any) error {
+	if err := jsonScan(src, c); err != nil {
+		return err
+	}
+	if len(*c) == 0 {
+		*c = nil
+	}
+	return nil
+}
@@ -3,6 +3,42 @@ package storage
 import "errors"
 
 var (
-	ErrEventNotFound = errors.New("event not found")
-	ErrBusyTime      = errors.New("time is busy by another event")
+	ErrEventNotFound    = errors.New("event not found")
+	ErrBusyTime         = errors.New("time is busy by another event")
+	ErrCalendarNotFound = errors.New("calendar not found")
+	ErrCalendarNotEmpty = errors.New("calendar has events")
+	ErrDefaultCalendar  = errors.New("default calendar cannot be deleted")
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrResourceBusy     = errors.New("resource is busy by another event")
+	ErrResourceInUse    = errors.New("resource is reserved by events")
+	// ErrIdempotencyKeyNotFound - ключ идемпотентности не использовался или истёк.
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	// ErrIdempotencyKeyReused - ключ идемпотентности уже использован для другого запроса.
+	ErrIdempotencyKeyReused = errors.New("idempotency key is already used for another request")
 )
+
+// BusyTimeError содержит события, с которыми пересекается добавляемое событие.
+type BusyTimeError struct {
+	Conflicts []*Event
+}
+
+func (e *BusyTimeError) Error() string {
+	return ErrBusyTime.Error()
+}
+
+func (e *BusyTimeError) Unwrap() error {
+	return ErrBusyTime
+}
+
+// ResourceBusyError содержит ресурсы, которые в это время уже забронированы другими событиями.
+type ResourceBusyError struct {
+	ResourceIDs []uint64
+}
+
+func (e *ResourceBusyError) Error() string {
+	return ErrResourceBusy.Error()
+}
+
+func (e *ResourceBusyError) Unwrap() error {
+	return ErrResourceBusy
+}
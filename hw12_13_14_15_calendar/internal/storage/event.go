@@ -1,6 +1,12 @@
 package storage
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
 
 type Event struct {
 	ID           uint64        `db:"event_id"`
@@ -9,8 +15,18 @@ type Event struct {
 	EndDate      time.Time     `db:"end_date"`
 	Description  string        `db:"description"`
 	UserID       uint64        `db:"user_id"`
+	CalendarID   uint64        `db:"calendar_id"`
 	NotifyBefore time.Duration `db:"notify_before"`
 	NotifyStatus NotifyStatus  `db:"notify_status"`
+	Transparent  bool          `db:"transparent"`
+	DeletedAt    *time.Time    `db:"deleted_at"`
+	Location     string        `db:"location"`
+	Latitude     *float64      `db:"latitude"`
+	Longitude    *float64      `db:"longitude"`
+	Category     string        `db:"category"`
+	Tags         Tags          `db:"tags"`
+	Attachments  Attachments   `db:"attachments"`
+	ResourceIDs  ResourceIDs   `db:"resource_ids"`
 }
 
 type NotifyStatus int
@@ -20,3 +36,104 @@ const (
 	NotifyInProgress
 	Notified
 )
+
+func (s NotifyStatus) String() string {
+	switch s {
+	case NotNotified:
+		return "NOT_NOTIFIED"
+	case NotifyInProgress:
+		return "IN_PROGRESS"
+	case Notified:
+		return "NOTIFIED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Attachment - ссылка на вложение события; сам файл хранится вне календаря.
+type Attachment struct {
+	URL      string `json:"url"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// Tags хранится в БД в виде json; пустой список читается как nil, так же как nil записывается пустым списком.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	return jsonValue(t, t == nil)
+}
+
+func (t *Tags) Scan(src any) error {
+	if err := jsonScan(src, t); err != nil {
+		return err
+	}
+	if len(*t) == 0 {
+		*t = nil
+	}
+	return nil
+}
+
+// Attachments хранится в БД в виде json так же, как Tags.
+type Attachments []Attachment
+
+func (a Attachments) Value() (driver.Value, error) {
+	return jsonValue(a, a == nil)
+}
+
+func (a *Attachments) Scan(src any) error {
+	if err := jsonScan(src, a); err != nil {
+		return err
+	}
+	if len(*a) == 0 {
+		*a = nil
+	}
+	return nil
+}
+
+// EventFilter - условия отбора событий при получении списка; пустые условия не ограничивают выборку.
+// Событие должно содержать все теги из Tags.
+type EventFilter struct {
+	CalendarIDs []uint64
+	Category    string
+	Tags        []string
+}
+
+// Match проверяет, удовлетворяет ли событие фильтру.
+func (f EventFilter) Match(event *Event) bool {
+	if len(f.CalendarIDs) > 0 && !slices.Contains(f.CalendarIDs, event.CalendarID) {
+		return false
+	}
+	if f.Category != "" && f.Category != event.Category {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(event.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonValue(v any, isNil bool) (driver.Value, error) {
+	if isNil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v)
+}
+
+func jsonScan(src any, dst any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for json column")
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,13 @@
+package storage
+
+// IdempotencyKey - событие, созданное по ключу идемпотентности, и хэш запроса, с которым ключ использован впервые.
+// Пустой хэш у ключей, сохранённых до появления проверки: с ними запрос не сверяется.
+type IdempotencyKey struct {
+	EventID     uint64 `db:"event_id"`
+	RequestHash string `db:"request_hash"`
+}
+
+// Matches сообщает, что ключ использован для того же запроса.
+func (k *IdempotencyKey) Matches(requestHash string) bool {
+	return k.RequestHash == "" || k.RequestHash == requestHash
+}
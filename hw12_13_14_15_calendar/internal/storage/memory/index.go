@@ -0,0 +1,235 @@
+package memorystorage
+
+import (
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// indexKey - ключ упорядочивания событий в индексе.
+// Ключ сохраняется при добавлении, поэтому изменение полей события
+// не нарушает структуру дерева до его переиндексации.
+type indexKey struct {
+	t1, t2, t3 time.Time
+	id         uint64
+}
+
+func (k indexKey) compare(other indexKey) int {
+	if c := k.t1.Compare(other.t1); c != 0 {
+		return c
+	}
+	if c := k.t2.Compare(other.t2); c != 0 {
+		return c
+	}
+	if c := k.t3.Compare(other.t3); c != 0 {
+		return c
+	}
+	switch {
+	case k.id < other.id:
+		return -1
+	case k.id > other.id:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// интервальный индекс: по дате начала, дате окончания и идентификатору.
+func intervalKey(event *storage.Event) indexKey {
+	return indexKey{t1: event.StartDate, t2: event.EndDate, id: event.ID}
+}
+
+// индекс по дате уведомления, затем по датам начала и окончания.
+func notifyKey(event *storage.Event) indexKey {
+	return indexKey{t1: event.StartDate.Add(-event.NotifyBefore), t2: event.StartDate, t3: event.EndDate, id: event.ID}
+}
+
+// индекс по дате окончания.
+func endDateKey(event *storage.Event) indexKey {
+	return indexKey{t1: event.EndDate, id: event.ID}
+}
+
+// eventIndex - AVL-дерево событий, упорядоченных по ключу.
+// Каждый узел хранит максимальное значение t2 в поддереве, что для интервального
+// ключа позволяет находить пересекающиеся интервалы за O(log n + k).
+type eventIndex struct {
+	root *indexNode
+	keys map[uint64]indexKey
+	key  func(event *storage.Event) indexKey
+}
+
+type indexNode struct {
+	key         indexKey
+	event       *storage.Event
+	left, right *indexNode
+	height      int
+	maxT2       time.Time
+}
+
+func newEventIndex(key func(event *storage.Event) indexKey) *eventIndex {
+	return &eventIndex{
+		keys: make(map[uint64]indexKey),
+		key:  key,
+	}
+}
+
+func (idx *eventIndex) len() int {
+	return len(idx.keys)
+}
+
+// добавляет событие, заменяя ранее добавленное с тем же идентификатором.
+func (idx *eventIndex) insert(event *storage.Event) {
+	idx.delete(event.ID)
+	key := idx.key(event)
+	idx.keys[event.ID] = key
+	idx.root = insertNode(idx.root, key, event)
+}
+
+func (idx *eventIndex) delete(eventID uint64) {
+	key, exists := idx.keys[eventID]
+	if !exists {
+		return
+	}
+	delete(idx.keys, eventID)
+	idx.root = deleteNode(idx.root, key)
+}
+
+// обходит события по возрастанию ключа, пока fn возвращает true.
+func (idx *eventIndex) ascend(fn func(event *storage.Event) bool) {
+	ascendNode(idx.root, func(indexKey) bool { return true }, fn)
+}
+
+// обходит по возрастанию ключа события, у которых t1 >= from, пока fn возвращает true.
+func (idx *eventIndex) ascendFrom(from time.Time, fn func(event *storage.Event) bool) {
+	ascendNode(idx.root, func(key indexKey) bool { return key.t1.Compare(from) >= 0 }, fn)
+}
+
+// обходит по возрастанию ключа события с t1 < to и t2 >= from, т.е. интервалы [t1, t2],
+// пересекающиеся с полуинтервалом [from, to).
+func (idx *eventIndex) overlapping(from time.Time, to time.Time, fn func(event *storage.Event)) {
+	overlappingNode(idx.root, from, to, fn)
+}
+
+func ascendNode(n *indexNode, fromHere func(key indexKey) bool, fn func(event *storage.Event) bool) bool {
+	if n == nil {
+		return true
+	}
+	if fromHere(n.key) {
+		if !ascendNode(n.left, fromHere, fn) {
+			return false
+		}
+		if !fn(n.event) {
+			return false
+		}
+	}
+	return ascendNode(n.right, fromHere, fn)
+}
+
+func overlappingNode(n *indexNode, from time.Time, to time.Time, fn func(event *storage.Event)) {
+	if n == nil || n.maxT2.Compare(from) < 0 {
+		return
+	}
+	overlappingNode(n.left, from, to, fn)
+	if n.key.t1.Compare(to) >= 0 {
+		// у правого поддерева t1 ещё больше
+		return
+	}
+	if n.key.t2.Compare(from) >= 0 {
+		fn(n.event)
+	}
+	overlappingNode(n.right, from, to, fn)
+}
+
+func height(n *indexNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *indexNode) update() {
+	n.height = max(height(n.left), height(n.right)) + 1
+	n.maxT2 = n.key.t2
+	if n.left != nil && n.left.maxT2.After(n.maxT2) {
+		n.maxT2 = n.left.maxT2
+	}
+	if n.right != nil && n.right.maxT2.After(n.maxT2) {
+		n.maxT2 = n.right.maxT2
+	}
+}
+
+func rotateRight(n *indexNode) *indexNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *indexNode) *indexNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+func balance(n *indexNode) *indexNode {
+	n.update()
+	switch diff := height(n.left) - height(n.right); {
+	case diff > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case diff < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	default:
+		return n
+	}
+}
+
+func insertNode(n *indexNode, key indexKey, event *storage.Event) *indexNode {
+	if n == nil {
+		node := &indexNode{key: key, event: event}
+		node.update()
+		return node
+	}
+	if key.compare(n.key) < 0 {
+		n.left = insertNode(n.left, key, event)
+	} else {
+		n.right = insertNode(n.right, key, event)
+	}
+	return balance(n)
+}
+
+func deleteNode(n *indexNode, key indexKey) *indexNode {
+	if n == nil {
+		return nil
+	}
+	switch c := key.compare(n.key); {
+	case c < 0:
+		n.left = deleteNode(n.left, key)
+	case c > 0:
+		n.right = deleteNode(n.right, key)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		n.key, n.event = successor.key, successor.event
+		n.right = deleteNode(n.right, successor.key)
+	}
+	return balance(n)
+}
@@ -0,0 +1,132 @@
+package memorystorage
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventIndex(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	base := getTime(t, "2024-07-01 00:00:00")
+	events := randomEvents(rnd, base, 1000)
+
+	idx := newEventIndex(intervalKey)
+	for _, event := range events {
+		idx.insert(event)
+	}
+
+	// удаляем часть событий и переиндексируем другую часть с новыми датами
+	alive := make(map[uint64]*storage.Event)
+	for i, event := range events {
+		switch i % 3 {
+		case 0:
+			idx.delete(event.ID)
+		case 1:
+			event.StartDate = event.StartDate.Add(time.Hour)
+			event.EndDate = event.EndDate.Add(2 * time.Hour)
+			idx.insert(event)
+			alive[event.ID] = event
+		default:
+			alive[event.ID] = event
+		}
+	}
+	require.Equal(t, len(alive), idx.len())
+	requireBalanced(t, idx.root)
+
+	t.Run("ascend", func(t *testing.T) {
+		expected := make([]*storage.Event, 0, len(alive))
+		for _, event := range alive {
+			expected = append(expected, event)
+		}
+		sortByInterval(expected)
+
+		actual := make([]*storage.Event, 0, len(alive))
+		idx.ascend(func(event *storage.Event) bool {
+			actual = append(actual, event)
+			return true
+		})
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("overlapping", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			from := base.Add(time.Duration(rnd.Intn(30*24)) * time.Hour)
+			to := from.Add(time.Duration(rnd.Intn(72)) * time.Hour)
+
+			expected := make([]*storage.Event, 0)
+			for _, event := range alive {
+				if event.StartDate.Before(to) && !event.EndDate.Before(from) {
+					expected = append(expected, event)
+				}
+			}
+			sortByInterval(expected)
+
+			actual := make([]*storage.Event, 0)
+			idx.overlapping(from, to, func(event *storage.Event) {
+				actual = append(actual, event)
+			})
+			require.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("ascend from", func(t *testing.T) {
+		from := base.Add(10 * 24 * time.Hour)
+
+		expected := make([]*storage.Event, 0)
+		for _, event := range alive {
+			if !event.StartDate.Before(from) {
+				expected = append(expected, event)
+			}
+		}
+		sortByInterval(expected)
+		expected = expected[:10]
+
+		actual := make([]*storage.Event, 0)
+		idx.ascendFrom(from, func(event *storage.Event) bool {
+			actual = append(actual, event)
+			return len(actual) < 10
+		})
+		require.Equal(t, expected, actual)
+	})
+}
+
+func randomEvents(rnd *rand.Rand, base time.Time, count int) []*storage.Event {
+	events := make([]*storage.Event, 0, count)
+	for i := 0; i < count; i++ {
+		startDate := base.Add(time.Duration(rnd.Intn(30*24*60)) * time.Minute)
+		events = append(events, &storage.Event{
+			ID:           uint64(i + 1),
+			Title:        "my event",
+			StartDate:    startDate,
+			EndDate:      startDate.Add(time.Duration(rnd.Intn(48*60)) * time.Minute),
+			UserID:       uint64(rnd.Intn(10)),
+			NotifyBefore: time.Duration(rnd.Intn(24)) * time.Hour,
+		})
+	}
+	return events
+}
+
+func sortByInterval(events []*storage.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		return intervalKey(events[i]).compare(intervalKey(events[j])) < 0
+	})
+}
+
+func requireBalanced(t *testing.T, n *indexNode) {
+	t.Helper()
+	if n == nil {
+		return
+	}
+	diff := height(n.left) - height(n.right)
+	require.True(t, diff >= -1 && diff <= 1)
+	require.Equal(t, max(height(n.left), height(n.right))+1, n.height)
+	requireBalanced(t, n.left)
+	requireBalanced(t, n.right)
+}
@@ -0,0 +1,430 @@
+package memorystorage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	walFileName      = "wal.log"
+)
+
+type Logger interface {
+	Info(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, err error, msg string, args ...any)
+}
+
+// persistence сохраняет изменения хранилища в каталог:
+// каждая операция дописывается в журнал (WAL), периодически всё состояние
+// записывается в снапшот, после чего журнал очищается.
+type persistence struct {
+	logger         Logger
+	dir            string
+	snapshotPeriod time.Duration
+	wal            walFile
+	walSize        int64 // размер журнала после последней успешной записи
+	done           chan struct{}
+	wg             sync.WaitGroup
+}
+
+// walFile - открытый файл журнала; выделен для подмены в тестах.
+type walFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+type walOp string
+
+const (
+	opCreate          walOp = "CREATE"
+	opUpdate          walOp = "UPDATE"
+	opDelete          walOp = "DELETE"
+	opRestore         walOp = "RESTORE"
+	opSetNotifyStatus walOp = "SET_NOTIFY_STATUS"
+	opPurge           walOp = "PURGE"
+	opCreateAudit     walOp = "CREATE_AUDIT"
+	opSaveSettings    walOp = "SAVE_SETTINGS"
+	opSaveCalendar    walOp = "SAVE_CALENDAR"
+	opDeleteCalendar  walOp = "DELETE_CALENDAR"
+	opSaveResource    walOp = "SAVE_RESOURCE"
+	opDeleteResource  walOp = "DELETE_RESOURCE"
+)
+
+// запись журнала; заполняются только поля, нужные для операции.
+type walRecord struct {
+	Op           walOp                 `json:"op"`
+	Event        *storage.Event        `json:"event,omitempty"`
+	EventID      uint64                `json:"eventId,omitempty"`
+	EventIDs     []uint64              `json:"eventIds,omitempty"`
+	DeletedAt    *time.Time            `json:"deletedAt,omitempty"`
+	NotifyStatus storage.NotifyStatus  `json:"notifyStatus,omitempty"`
+	Audit        *storage.AuditRecord  `json:"audit,omitempty"`
+	Settings     *storage.UserSettings `json:"settings,omitempty"`
+	Calendar     *storage.Calendar     `json:"calendar,omitempty"`
+	CalendarID   uint64                `json:"calendarId,omitempty"`
+	Resource     *storage.Resource     `json:"resource,omitempty"`
+	ResourceID   uint64                `json:"resourceId,omitempty"`
+}
+
+type snapshot struct {
+	Events      []*storage.Event        `json:"events"`
+	Audit       []*storage.AuditRecord  `json:"audit"`
+	LastAuditID uint64                  `json:"lastAuditId"`
+	Settings    []*storage.UserSettings `json:"settings"`
+	Calendars   []*storage.Calendar     `json:"calendars"`
+	// ID последнего календаря, чтобы ID удалённых календарей не выдавались повторно
+	LastCalendarID uint64              `json:"lastCalendarId"`
+	Resources      []*storage.Resource `json:"resources"`
+	LastResourceID uint64              `json:"lastResourceId"`
+}
+
+// NewPersistent создаёт хранилище, которое сохраняет данные в каталог dir
+// и восстанавливает их при подключении.
+func NewPersistent(logger Logger, dir string, snapshotPeriod time.Duration) *Storage {
+	s := New()
+	s.persistence = &persistence{
+		logger:         logger,
+		dir:            dir,
+		snapshotPeriod: snapshotPeriod,
+		done:           make(chan struct{}),
+	}
+	return s
+}
+
+// Connect восстанавливает состояние из снапшота и журнала и запускает периодическое создание снапшотов.
+func (s *Storage) Connect(ctx context.Context) error {
+	p := s.persistence
+	if p == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create storage dir: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadSnapshot(); err != nil {
+		return err
+	}
+	records, err := s.replayWAL()
+	if err != nil {
+		return err
+	}
+	s.assignDefaultCalendars()
+	p.logger.Info(ctx, "memory storage recovered", "events", len(s.events), "walRecords", records)
+
+	wal, err := os.OpenFile(filepath.Join(p.dir, walFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open wal: %w", err)
+	}
+	p.wal = wal
+
+	// сразу уплотняем журнал, чтобы не воспроизводить его при следующем запуске
+	if err := s.compact(); err != nil {
+		return err
+	}
+
+	if p.snapshotPeriod > 0 {
+		p.wg.Add(1)
+		go s.snapshotLoop(ctx)
+	}
+	return nil
+}
+
+// Close сохраняет снапшот и закрывает журнал.
+func (s *Storage) Close(_ context.Context) error {
+	p := s.persistence
+	if p == nil || p.wal == nil {
+		return nil
+	}
+
+	close(p.done)
+	p.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.compact()
+	if closeErr := p.wal.Close(); err == nil {
+		err = closeErr
+	}
+	p.wal = nil
+	return err
+}
+
+func (s *Storage) snapshotLoop(ctx context.Context) {
+	p := s.persistence
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.snapshotPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			err := s.compact()
+			s.mu.Unlock()
+			if err != nil {
+				p.logger.Error(ctx, err, "failed to save memory storage snapshot")
+			}
+		}
+	}
+}
+
+// записывает операцию в журнал и применяет её к хранилищу; вызывается под блокировкой.
+func (s *Storage) commit(record *walRecord) error {
+	if p := s.persistence; p != nil && p.wal != nil {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("cannot encode wal record: %w", err)
+		}
+		data = append(data, '\n')
+		// при ошибке обрезаем журнал до последней успешной записи, чтобы в нём не осталось
+		// недописанной или неприменённой операции
+		if _, err := p.wal.Write(data); err != nil {
+			return p.rollbackWAL(fmt.Errorf("cannot write wal record: %w", err))
+		}
+		if err := p.wal.Sync(); err != nil {
+			return p.rollbackWAL(fmt.Errorf("cannot sync wal: %w", err))
+		}
+		p.walSize += int64(len(data))
+	}
+
+	s.apply(record)
+	return nil
+}
+
+func (p *persistence) rollbackWAL(err error) error {
+	if truncErr := p.wal.Truncate(p.walSize); truncErr != nil {
+		return errors.Join(err, fmt.Errorf("cannot truncate wal: %w", truncErr))
+	}
+	return err
+}
+
+func (s *Storage) apply(record *walRecord) {
+	switch record.Op {
+	case opCreate, opUpdate:
+		s.indexEvent(record.Event)
+	case opDelete:
+		if event := s.events[record.EventID]; event != nil {
+			event.DeletedAt = record.DeletedAt
+		}
+	case opRestore:
+		if event := s.events[record.EventID]; event != nil {
+			event.DeletedAt = nil
+		}
+	case opSetNotifyStatus:
+		for _, eventID := range record.EventIDs {
+			if event := s.events[eventID]; event != nil {
+				event.NotifyStatus = record.NotifyStatus
+			}
+		}
+	case opPurge:
+		for _, eventID := range record.EventIDs {
+			if event := s.events[eventID]; event != nil {
+				s.deleteEvent(event)
+			}
+		}
+	case opCreateAudit:
+		// запись уже попала в снапшот
+		if record.Audit.ID <= s.lastAuditID {
+			return
+		}
+		s.lastAuditID = record.Audit.ID
+		s.audit[record.Audit.EventID] = append(s.audit[record.Audit.EventID], record.Audit)
+	case opSaveSettings:
+		// в записях, сделанных до появления настроек доступности, политики нет
+		if record.Settings.AvailabilityPolicy == "" {
+			record.Settings.AvailabilityPolicy = storage.OverlapAllow
+		}
+		if record.Settings.Digest == "" {
+			record.Settings.Digest = storage.DigestNone
+		}
+		s.settings[record.Settings.UserID] = record.Settings
+	case opSaveCalendar:
+		s.calendars[record.Calendar.ID] = record.Calendar
+		if record.Calendar.ID > s.lastCalendarID {
+			s.lastCalendarID = record.Calendar.ID
+		}
+		if record.Calendar.IsDefault {
+			s.defaultCalendars[record.Calendar.UserID] = record.Calendar.ID
+		}
+	case opDeleteCalendar:
+		delete(s.calendars, record.CalendarID)
+	case opSaveResource:
+		s.resources[record.Resource.ID] = record.Resource
+		s.lastResourceID = max(s.lastResourceID, record.Resource.ID)
+	case opDeleteResource:
+		delete(s.resources, record.ResourceID)
+	}
+}
+
+// сохраняет снапшот и очищает журнал; вызывается под блокировкой.
+func (s *Storage) compact() error {
+	p := s.persistence
+
+	snap := snapshot{
+		Events:      make([]*storage.Event, 0, len(s.events)),
+		Audit:       make([]*storage.AuditRecord, 0),
+		LastAuditID: s.lastAuditID,
+		Settings:    make([]*storage.UserSettings, 0, len(s.settings)),
+		Calendars:   make([]*storage.Calendar, 0, len(s.calendars)),
+
+		LastCalendarID: s.lastCalendarID,
+		Resources:      make([]*storage.Resource, 0, len(s.resources)),
+		LastResourceID: s.lastResourceID,
+	}
+	for _, event := range s.events {
+		snap.Events = append(snap.Events, event)
+	}
+	for _, records := range s.audit {
+		snap.Audit = append(snap.Audit, records...)
+	}
+	for _, settings := range s.settings {
+		snap.Settings = append(snap.Settings, settings)
+	}
+	for _, calendar := range s.calendars {
+		snap.Calendars = append(snap.Calendars, calendar)
+	}
+	for _, resource := range s.resources {
+		snap.Resources = append(snap.Resources, resource)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("cannot encode snapshot: %w", err)
+	}
+
+	// снапшот подменяется атомарно, чтобы при сбое остался предыдущий
+	tmpPath := filepath.Join(p.dir, snapshotFileName+".tmp")
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return fmt.Errorf("cannot write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(p.dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("cannot replace snapshot: %w", err)
+	}
+	// переименование становится надёжным только после синхронизации каталога
+	if err := syncDir(p.dir); err != nil {
+		return fmt.Errorf("cannot sync storage dir: %w", err)
+	}
+
+	if err := p.wal.Truncate(0); err != nil {
+		return fmt.Errorf("cannot truncate wal: %w", err)
+	}
+	p.walSize = 0
+	return nil
+}
+
+func (s *Storage) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.persistence.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("cannot decode snapshot: %w", err)
+	}
+
+	for _, event := range snap.Events {
+		s.apply(&walRecord{Op: opCreate, Event: event})
+	}
+	for _, record := range snap.Audit {
+		s.audit[record.EventID] = append(s.audit[record.EventID], record)
+	}
+	for _, settings := range snap.Settings {
+		s.apply(&walRecord{Op: opSaveSettings, Settings: settings})
+	}
+	for _, calendar := range snap.Calendars {
+		s.apply(&walRecord{Op: opSaveCalendar, Calendar: calendar})
+	}
+	s.lastCalendarID = max(s.lastCalendarID, snap.LastCalendarID)
+	for _, resource := range snap.Resources {
+		s.apply(&walRecord{Op: opSaveResource, Resource: resource})
+	}
+	s.lastResourceID = max(s.lastResourceID, snap.LastResourceID)
+	s.lastAuditID = snap.LastAuditID
+	return nil
+}
+
+// воспроизводит журнал поверх снапшота. Недописанная при сбое последняя запись отбрасывается.
+func (s *Storage) replayWAL() (int, error) {
+	file, err := os.Open(filepath.Join(s.persistence.dir, walFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot open wal: %w", err)
+	}
+	defer file.Close()
+
+	records := 0
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// запись без перевода строки не была дописана до конца
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("cannot read wal: %w", err)
+		}
+
+		var record walRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &record); err != nil {
+			return records, fmt.Errorf("cannot decode wal record %d: %w", records+1, err)
+		}
+		s.apply(&record)
+		records++
+	}
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
@@ -0,0 +1,205 @@
+package memorystorage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct{}
+
+func (testLogger) Info(context.Context, string, ...any) {}
+
+func (testLogger) Error(context.Context, error, string, ...any) {}
+
+// failingWAL дописывает в журнал только половину записи и возвращает ошибку.
+type failingWAL struct {
+	walFile
+}
+
+var errWALWrite = errors.New("disk full")
+
+func (w failingWAL) Write(data []byte) (int, error) {
+	n, _ := w.walFile.Write(data[:len(data)/2])
+	return n, errWALWrite
+}
+
+func TestStoragePersistence(t *testing.T) {
+	t.Parallel()
+
+	userID := uint64(12345)
+	event := storage.Event{
+		Title:        "my event",
+		StartDate:    getTime(t, "2024-07-06 10:00:00"),
+		EndDate:      getTime(t, "2024-07-10 00:00:00"),
+		Description:  "my event description",
+		UserID:       userID,
+		NotifyBefore: time.Hour * 24,
+	}
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-11 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-12 00:00:00")
+
+	ctx := context.Background()
+
+	// выполняет операции и возвращает ожидаемое состояние событий пользователя
+	fill := func(t *testing.T, s *Storage) []*storage.Event {
+		t.Helper()
+		event := event
+		event1 := event1
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+
+		event.Title = "my new event"
+		require.NoError(t, s.Update(ctx, &event))
+		require.NoError(t, s.SetNotifyStatus(ctx, []uint64{eventID}, storage.Notified))
+		require.NoError(t, s.Delete(ctx, userID, event1ID))
+		require.NoError(t, s.CreateAuditRecord(ctx, &storage.AuditRecord{EventID: eventID, UserID: userID, Action: storage.AuditCreate}))
+		require.NoError(t, s.SaveUserSettings(ctx, &storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapWarn}))
+		_, err = s.CreateCalendar(ctx, &storage.Calendar{UserID: userID, Name: "Work"})
+		require.NoError(t, err)
+
+		events, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, event.StartDate, event1.EndDate)
+		require.NoError(t, err)
+		return events
+	}
+
+	check := func(t *testing.T, s *Storage, expected []*storage.Event) {
+		t.Helper()
+		actual, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, event.StartDate, event1.EndDate)
+		require.NoError(t, err)
+		require.Equal(t, len(expected), len(actual))
+		for i := range expected {
+			require.Equal(t, expected[i].ID, actual[i].ID)
+			require.Equal(t, expected[i].Title, actual[i].Title)
+			require.Equal(t, expected[i].NotifyStatus, actual[i].NotifyStatus)
+			require.True(t, expected[i].StartDate.Equal(actual[i].StartDate))
+		}
+
+		deleted, err := s.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deleted))
+
+		records, err := s.ListAuditRecords(ctx, userID, expected[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(records))
+
+		settings, err := s.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.OverlapWarn, settings.OverlapPolicy)
+
+		// события без календаря при восстановлении переносятся в календарь по умолчанию
+		calendars, err := s.ListCalendars(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(calendars))
+		require.Equal(t, "Work", calendars[0].Name)
+		require.True(t, calendars[1].IsDefault)
+		for _, event := range actual {
+			require.Equal(t, calendars[1].ID, event.CalendarID)
+		}
+	}
+
+	t.Run("restore from snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		expected := fill(t, s)
+		require.NoError(t, s.Close(ctx))
+
+		wal, err := os.ReadFile(filepath.Join(dir, walFileName))
+		require.NoError(t, err)
+		require.Empty(t, wal)
+
+		s = NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		defer s.Close(ctx)
+		check(t, s, expected)
+	})
+
+	t.Run("restore from wal after crash", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		expected := fill(t, s)
+
+		// имитируем сбой во время записи в журнал
+		wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = wal.WriteString(`{"op":"DELETE","eventId":`)
+		require.NoError(t, err)
+		require.NoError(t, wal.Close())
+
+		s = NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		defer s.Close(ctx)
+		check(t, s, expected)
+
+		// журнал уплотнён в снапшот
+		data, err := os.ReadFile(filepath.Join(dir, walFileName))
+		require.NoError(t, err)
+		require.Empty(t, data)
+	})
+
+	t.Run("rollback partial wal write", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		expected := fill(t, s)
+
+		wal := s.persistence.wal
+		s.persistence.wal = failingWAL{wal}
+		event := event
+		event.StartDate = getTime(t, "2024-07-10 10:00:00")
+		event.EndDate = getTime(t, "2024-07-10 11:00:00")
+		_, err := s.Create(ctx, &event)
+		require.ErrorIs(t, err, errWALWrite)
+		s.persistence.wal = wal
+
+		// неудачная операция не применена, а следующие пишутся в журнал без мусора
+		events, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.NoError(t, s.SetNotifyStatus(ctx, []uint64{expected[0].ID}, storage.NotNotified))
+		expected[0].NotifyStatus = storage.NotNotified
+
+		s = NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		defer s.Close(ctx)
+		check(t, s, expected)
+	})
+
+	t.Run("replay wal over snapshot", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		expected := fill(t, s)
+
+		// снапшот сохранён, а журнал не успел очиститься
+		data, err := os.ReadFile(filepath.Join(dir, walFileName))
+		require.NoError(t, err)
+		s.mu.Lock()
+		require.NoError(t, s.compact())
+		s.mu.Unlock()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, walFileName), data, 0o644))
+
+		s = NewPersistent(testLogger{}, dir, 0)
+		require.NoError(t, s.Connect(ctx))
+		defer s.Close(ctx)
+		check(t, s, expected)
+	})
+}
@@ -2,6 +2,7 @@ package memorystorage
 
 import (
 	"context"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -12,14 +13,46 @@ import (
 
 type Storage struct {
 	events      map[uint64]*storage.Event
-	usersEvents map[uint64]map[uint64]struct{}
-	mu          sync.RWMutex
+	usersEvents map[uint64]*eventIndex // интервальные индексы событий пользователей
+	notifyIndex *eventIndex            // индекс событий с уведомлением по дате уведомления
+	endIndex    *eventIndex            // индекс событий по дате окончания
+	audit       map[uint64][]*storage.AuditRecord
+	lastAuditID uint64
+	settings    map[uint64]*storage.UserSettings
+	idempotency map[uint64]map[string]idempotencyKey // ключи идемпотентности пользователей
+	digestLog   map[uint64]time.Time                 // начало периода последней сводки пользователя
+	calendars   map[uint64]*storage.Calendar
+	// ID календарей по умолчанию по пользователям
+	defaultCalendars map[uint64]uint64
+	lastCalendarID   uint64
+	resources        map[uint64]*storage.Resource
+	lastResourceID   uint64
+	// интервальные индексы событий, забронировавших ресурс
+	resourceEvents map[uint64]*eventIndex
+	persistence    *persistence
+	mu             sync.RWMutex
+}
+
+type idempotencyKey struct {
+	storage.IdempotencyKey
+	createdAt time.Time
 }
 
 func New() *Storage {
 	return &Storage{
 		events:      make(map[uint64]*storage.Event),
-		usersEvents: make(map[uint64]map[uint64]struct{}),
+		usersEvents: make(map[uint64]*eventIndex),
+		notifyIndex: newEventIndex(notifyKey),
+		endIndex:    newEventIndex(endDateKey),
+		audit:       make(map[uint64][]*storage.AuditRecord),
+		settings:    make(map[uint64]*storage.UserSettings),
+		idempotency: make(map[uint64]map[string]idempotencyKey),
+		digestLog:   make(map[uint64]time.Time),
+		calendars:   make(map[uint64]*storage.Calendar),
+
+		defaultCalendars: make(map[uint64]uint64),
+		resources:        make(map[uint64]*storage.Resource),
+		resourceEvents:   make(map[uint64]*eventIndex),
 	}
 }
 
@@ -27,19 +60,74 @@ func (s *Storage) Create(_ context.Context, event *storage.Event) (uint64, error
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.createEvent(event)
+}
+
+func (s *Storage) GetIdempotencyKey(_ context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, exists := s.idempotency[userID][key]
+	if !exists || v.createdAt.Before(notBefore) {
+		return nil, storage.ErrIdempotencyKeyNotFound
+	}
+	idempotencyKey := v.IdempotencyKey
+	return &idempotencyKey, nil
+}
+
+// CreateIdempotent создаёт событие или возвращает событие, ранее созданное с тем же ключом.
+// Ключи не попадают в журнал изменений и теряются при перезапуске.
+func (s *Storage) CreateIdempotent(
+	_ context.Context,
+	event *storage.Event,
+	key string,
+	requestHash string,
+	notBefore time.Time,
+) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, exists := s.idempotency[event.UserID]
+	if !exists {
+		keys = make(map[string]idempotencyKey)
+		s.idempotency[event.UserID] = keys
+	}
+	for k, v := range keys {
+		if v.createdAt.Before(notBefore) {
+			delete(keys, k)
+		}
+	}
+	if v, exists := keys[key]; exists {
+		if !v.Matches(requestHash) {
+			return 0, false, storage.ErrIdempotencyKeyReused
+		}
+		return v.EventID, true, nil
+	}
+
+	eventID, err := s.createEvent(event)
+	if err != nil {
+		return 0, false, err
+	}
+	keys[key] = idempotencyKey{
+		IdempotencyKey: storage.IdempotencyKey{EventID: eventID, RequestHash: requestHash},
+		createdAt:      time.Now(),
+	}
+	return eventID, false, nil
+}
+
+func (s *Storage) createEvent(event *storage.Event) (uint64, error) {
 	event.ID = s.generateUniqueID()
 
 	if err := s.checkBusyTime(event); err != nil {
 		return 0, err
 	}
+	if err := s.checkResources(event); err != nil {
+		return 0, err
+	}
 
-	s.events[event.ID] = event
-	eventsByUser, exists := s.usersEvents[event.UserID]
-	if !exists {
-		eventsByUser = make(map[uint64]struct{})
-		s.usersEvents[event.UserID] = eventsByUser
+	if err := s.commit(&walRecord{Op: opCreate, Event: event}); err != nil {
+		return 0, err
 	}
-	eventsByUser[event.ID] = struct{}{}
 
 	return event.ID, nil
 }
@@ -49,7 +137,7 @@ func (s *Storage) GetByID(_ context.Context, userID uint64, eventID uint64) (*st
 	defer s.mu.RUnlock()
 
 	event, exists := s.events[eventID]
-	if !exists || event.UserID != userID {
+	if !exists || event.UserID != userID || event.DeletedAt != nil {
 		return nil, storage.ErrEventNotFound
 	}
 
@@ -61,17 +149,18 @@ func (s *Storage) Update(_ context.Context, event *storage.Event) error {
 	defer s.mu.Unlock()
 
 	existingEvent, exists := s.events[event.ID]
-	if !exists || event.UserID != existingEvent.UserID {
+	if !exists || event.UserID != existingEvent.UserID || existingEvent.DeletedAt != nil {
 		return storage.ErrEventNotFound
 	}
 
 	if err := s.checkBusyTime(event); err != nil {
 		return err
 	}
+	if err := s.checkResources(event); err != nil {
+		return err
+	}
 
-	s.events[event.ID] = event
-
-	return nil
+	return s.commit(&walRecord{Op: opUpdate, Event: event})
 }
 
 func (s *Storage) Delete(_ context.Context, userID uint64, eventID uint64) error {
@@ -79,22 +168,76 @@ func (s *Storage) Delete(_ context.Context, userID uint64, eventID uint64) error
 	defer s.mu.Unlock()
 
 	existingEvent, exists := s.events[eventID]
-	if !exists || existingEvent.UserID != userID {
+	if !exists || existingEvent.UserID != userID || existingEvent.DeletedAt != nil {
 		return storage.ErrEventNotFound
 	}
 
-	delete(s.events, eventID)
-	delete(s.usersEvents[userID], eventID)
-	if len(s.usersEvents[userID]) == 0 {
-		delete(s.usersEvents, userID)
+	deletedAt := time.Now()
+	return s.commit(&walRecord{Op: opDelete, EventID: eventID, DeletedAt: &deletedAt})
+}
+
+func (s *Storage) Restore(_ context.Context, userID uint64, eventID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existingEvent, exists := s.events[eventID]
+	if !exists || existingEvent.UserID != userID || existingEvent.DeletedAt == nil {
+		return storage.ErrEventNotFound
 	}
 
-	return nil
+	if err := s.checkBusyTime(existingEvent); err != nil {
+		return err
+	}
+	if err := s.checkResources(existingEvent); err != nil {
+		return err
+	}
+
+	return s.commit(&walRecord{Op: opRestore, EventID: eventID})
+}
+
+func (s *Storage) ListDeleted(_ context.Context, userID uint64) ([]*storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]*storage.Event, 0)
+	eventsByUser, exists := s.usersEvents[userID]
+	if !exists {
+		return events, nil
+	}
+
+	eventsByUser.ascend(func(event *storage.Event) bool {
+		if event.DeletedAt != nil {
+			events = append(events, event)
+		}
+		return true
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].DeletedAt.Equal(*events[j].DeletedAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].DeletedAt.After(*events[j].DeletedAt)
+	})
+
+	return events, nil
 }
 
+func (s *Storage) CountByUser(_ context.Context, userID uint64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	eventsByUser, exists := s.usersEvents[userID]
+	if !exists {
+		return 0, nil
+	}
+	return eventsByUser.len(), nil
+}
+
+// ListForPeriod возвращает события пользователя за период, удовлетворяющие фильтру.
 func (s *Storage) ListForPeriod(
 	_ context.Context,
 	userID uint64,
+	filter storage.EventFilter,
 	startDate time.Time,
 	endDateExclusive time.Time,
 ) ([]*storage.Event, error) {
@@ -107,80 +250,165 @@ func (s *Storage) ListForPeriod(
 		return events, nil
 	}
 
-	for eventID := range eventsByUser {
-		event := s.events[eventID]
-		if event.StartDate.Compare(endDateExclusive) < 0 && event.EndDate.Compare(startDate) >= 0 {
+	eventsByUser.overlapping(startDate, endDateExclusive, func(event *storage.Event) {
+		if event.DeletedAt == nil && filter.Match(event) {
 			events = append(events, event)
 		}
-	}
-
-	sort.Slice(events, func(i, j int) bool {
-		if events[i].StartDate.Equal(events[j].StartDate) {
-			return events[i].EndDate.Before(events[j].EndDate)
-		}
-		return events[i].StartDate.Before(events[j].StartDate)
 	})
 
 	return events, nil
 }
 
 func (s *Storage) ListForNotify(_ context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	return s.listByNotifyDate(startNotifyDate, endNotifyDate, func(event *storage.Event) bool {
+		return event.NotifyStatus == storage.NotNotified
+	}), nil
+}
+
+func (s *Storage) ListByNotifyDate(_ context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	return s.listByNotifyDate(startNotifyDate, endNotifyDate, func(*storage.Event) bool {
+		return true
+	}), nil
+}
+
+func (s *Storage) listByNotifyDate(startNotifyDate time.Time, endNotifyDate time.Time, match func(event *storage.Event) bool) []*storage.Event {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	events := make([]*storage.Event, 0)
 
-	for _, event := range s.events {
-		if event.NotifyBefore != 0 {
-			notifyDate := event.StartDate.Add(-event.NotifyBefore)
-			if notifyDate.Compare(startNotifyDate) >= 0 && notifyDate.Compare(endNotifyDate) <= 0 {
-				events = append(events, event)
-			}
+	s.notifyIndex.ascendFrom(startNotifyDate, func(event *storage.Event) bool {
+		if event.StartDate.Add(-event.NotifyBefore).After(endNotifyDate) {
+			return false
 		}
-	}
-
-	sort.Slice(events, func(i, j int) bool {
-		notifyDate1 := events[i].StartDate.Add(events[i].NotifyBefore)
-		notifyDate2 := events[j].StartDate.Add(events[j].NotifyBefore)
-		if notifyDate1.Equal(notifyDate2) {
-			if events[i].StartDate.Equal(events[j].StartDate) {
-				return events[i].EndDate.Before(events[j].EndDate)
-			}
-			return events[i].StartDate.Before(events[j].StartDate)
+		if event.DeletedAt == nil && match(event) {
+			events = append(events, event)
 		}
-		return notifyDate1.Before(notifyDate2)
+		return true
 	})
 
-	return events, nil
+	return events
 }
 
 func (s *Storage) SetNotifyStatus(_ context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, eventID := range eventIDs {
-		if event := s.events[eventID]; event != nil {
-			event.NotifyStatus = notifyStatus
-		}
-	}
-	return nil
+	return s.commit(&walRecord{Op: opSetNotifyStatus, EventIDs: eventIDs, NotifyStatus: notifyStatus})
 }
 
 func (s *Storage) DeleteByEndDate(_ context.Context, maxEndDate time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	eventIDs := make([]uint64, 0)
+	s.endIndex.ascend(func(event *storage.Event) bool {
+		if event.EndDate.After(maxEndDate) {
+			return false
+		}
+		eventIDs = append(eventIDs, event.ID)
+		return true
+	})
+
+	return s.purge(eventIDs)
+}
+
+func (s *Storage) DeleteByDeletedDate(_ context.Context, maxDeletedDate time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eventIDs := make([]uint64, 0)
 	for _, event := range s.events {
-		if event.EndDate.Compare(maxEndDate) <= 0 {
-			delete(s.events, event.ID)
-			delete(s.usersEvents[event.UserID], event.ID)
-			if len(s.usersEvents[event.UserID]) == 0 {
-				delete(s.usersEvents, event.UserID)
+		if event.DeletedAt != nil && event.DeletedAt.Compare(maxDeletedDate) <= 0 {
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+
+	return s.purge(eventIDs)
+}
+
+func (s *Storage) purge(eventIDs []uint64) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+	return s.commit(&walRecord{Op: opPurge, EventIDs: eventIDs})
+}
+
+// добавляет событие в индексы, заменяя его предыдущую версию.
+func (s *Storage) indexEvent(event *storage.Event) {
+	if existingEvent, exists := s.events[event.ID]; exists {
+		s.unindexResources(existingEvent)
+	}
+	s.events[event.ID] = event
+
+	eventsByUser, exists := s.usersEvents[event.UserID]
+	if !exists {
+		eventsByUser = newEventIndex(intervalKey)
+		s.usersEvents[event.UserID] = eventsByUser
+	}
+	eventsByUser.insert(event)
+
+	if event.NotifyBefore != 0 {
+		s.notifyIndex.insert(event)
+	} else {
+		s.notifyIndex.delete(event.ID)
+	}
+	s.endIndex.insert(event)
+
+	for _, resourceID := range event.ResourceIDs {
+		eventsByResource, exists := s.resourceEvents[resourceID]
+		if !exists {
+			eventsByResource = newEventIndex(intervalKey)
+			s.resourceEvents[resourceID] = eventsByResource
+		}
+		eventsByResource.insert(event)
+	}
+}
+
+func (s *Storage) unindexResources(event *storage.Event) {
+	for _, resourceID := range event.ResourceIDs {
+		if eventsByResource, exists := s.resourceEvents[resourceID]; exists {
+			eventsByResource.delete(event.ID)
+			if eventsByResource.len() == 0 {
+				delete(s.resourceEvents, resourceID)
 			}
 		}
 	}
+}
 
-	return nil
+func (s *Storage) deleteEvent(event *storage.Event) {
+	delete(s.events, event.ID)
+	if eventsByUser, exists := s.usersEvents[event.UserID]; exists {
+		eventsByUser.delete(event.ID)
+		if eventsByUser.len() == 0 {
+			delete(s.usersEvents, event.UserID)
+		}
+	}
+	s.notifyIndex.delete(event.ID)
+	s.endIndex.delete(event.ID)
+	s.unindexResources(event)
+}
+
+func (s *Storage) CreateAuditRecord(_ context.Context, record *storage.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.ID = s.lastAuditID + 1
+	return s.commit(&walRecord{Op: opCreateAudit, Audit: record})
+}
+
+func (s *Storage) ListAuditRecords(_ context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*storage.AuditRecord, 0)
+	for _, record := range s.audit[eventID] {
+		if record.UserID == userID {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
 }
 
 func (s *Storage) generateUniqueID() uint64 {
@@ -195,15 +423,463 @@ func (s *Storage) generateUniqueID() uint64 {
 	return eventID
 }
 
+func (s *Storage) ListConflicts(_ context.Context, event *storage.Event) ([]*storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.findConflicts(event), nil
+}
+
+func (s *Storage) GetUserSettings(_ context.Context, userID uint64) (*storage.UserSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.userSettings(userID), nil
+}
+
+// SaveUserSettings сохраняет настройки. Запретить пересечения нельзя, пока у пользователя
+// есть пересекающиеся события: возвращается storage.ErrBusyTime.
+func (s *Storage) SaveUserSettings(_ context.Context, settings *storage.UserSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if settings.OverlapPolicy == storage.OverlapReject && s.hasOverlaps(settings.UserID) {
+		return storage.ErrBusyTime
+	}
+
+	settingsCopy := *settings
+	return s.commit(&walRecord{Op: opSaveSettings, Settings: &settingsCopy})
+}
+
+// ListDigestSettings возвращает настройки пользователей, подписанных на сводку событий.
+func (s *Storage) ListDigestSettings(_ context.Context) ([]*storage.UserSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*storage.UserSettings, 0)
+	for _, settings := range s.settings {
+		if settings.Digest != storage.DigestNone {
+			settingsCopy := *settings
+			result = append(result, &settingsCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UserID < result[j].UserID
+	})
+	return result, nil
+}
+
+// ClaimDigest отмечает, что сводка пользователя за период, начинающийся в startDate, отправляется.
+// Возвращает false, если сводка за этот период уже отмечена. Отметки, как и ключи идемпотентности, не сохраняются на диск.
+func (s *Storage) ClaimDigest(_ context.Context, userID uint64, startDate time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.digestLog[userID].Before(startDate) {
+		return false, nil
+	}
+	s.digestLog[userID] = startDate
+	return true, nil
+}
+
+// ReleaseDigest снимает отметку ClaimDigest, если сводку не удалось отправить, чтобы её отправил следующий запуск.
+func (s *Storage) ReleaseDigest(_ context.Context, userID uint64, startDate time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.digestLog[userID].Equal(startDate) {
+		delete(s.digestLog, userID)
+	}
+	return nil
+}
+
+func (s *Storage) userSettings(userID uint64) *storage.UserSettings {
+	if settings, exists := s.settings[userID]; exists {
+		settingsCopy := *settings
+		return &settingsCopy
+	}
+	return storage.DefaultUserSettings(userID)
+}
+
 func (s *Storage) checkBusyTime(event *storage.Event) error {
-	eventsByUser := s.usersEvents[event.UserID]
+	if s.userSettings(event.UserID).OverlapPolicy != storage.OverlapReject {
+		return nil
+	}
+
+	if conflicts := s.findConflicts(event); len(conflicts) > 0 {
+		return &storage.BusyTimeError{Conflicts: conflicts}
+	}
+
+	return nil
+}
+
+// проверяет, пересекаются ли между собой непрозрачные события пользователя (границы включаются).
+func (s *Storage) hasOverlaps(userID uint64) bool {
+	eventsByUser, exists := s.usersEvents[userID]
+	if !exists {
+		return false
+	}
+
+	var (
+		overlaps bool
+		maxEnd   *time.Time
+	)
+	// события упорядочены по дате начала
+	eventsByUser.ascend(func(event *storage.Event) bool {
+		if event.DeletedAt != nil || event.Transparent {
+			return true
+		}
+		if maxEnd != nil && !event.StartDate.After(*maxEnd) {
+			overlaps = true
+			return false
+		}
+		if maxEnd == nil || event.EndDate.After(*maxEnd) {
+			maxEnd = &event.EndDate
+		}
+		return true
+	})
+	return overlaps
+}
+
+// возвращает непрозрачные события пользователя, пересекающиеся с данным.
+func (s *Storage) findConflicts(event *storage.Event) []*storage.Event {
+	conflicts := make([]*storage.Event, 0)
+	if event.Transparent {
+		return conflicts
+	}
+
+	eventsByUser, exists := s.usersEvents[event.UserID]
+	if !exists {
+		return conflicts
+	}
+
+	// границы интервалов включаются
+	eventsByUser.overlapping(event.StartDate, event.EndDate.Add(time.Nanosecond), func(existingEvent *storage.Event) {
+		if event.ID != existingEvent.ID && existingEvent.DeletedAt == nil && !existingEvent.Transparent {
+			conflicts = append(conflicts, existingEvent)
+		}
+	})
+
+	return conflicts
+}
+
+func (s *Storage) CreateCalendar(_ context.Context, calendar *storage.Calendar) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createCalendar(calendar)
+}
+
+func (s *Storage) createCalendar(calendar *storage.Calendar) (uint64, error) {
+	calendarCopy := *calendar
+	calendarCopy.ID = s.lastCalendarID + 1
+	calendarCopy.SharedWith = slices.Clone(calendar.SharedWith)
+	if err := s.commit(&walRecord{Op: opSaveCalendar, Calendar: &calendarCopy}); err != nil {
+		return 0, err
+	}
+	return calendarCopy.ID, nil
+}
+
+func (s *Storage) GetCalendar(_ context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calendar, exists := s.calendars[calendarID]
+	if !exists || calendar.UserID != userID {
+		return nil, storage.ErrCalendarNotFound
+	}
+	calendarCopy := *calendar
+	return &calendarCopy, nil
+}
+
+// GetDefaultCalendar возвращает календарь пользователя по умолчанию, создавая его при первом обращении.
+func (s *Storage) GetDefaultCalendar(_ context.Context, userID uint64) (*storage.Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if calendarID, exists := s.defaultCalendars[userID]; exists {
+		calendarCopy := *s.calendars[calendarID]
+		return &calendarCopy, nil
+	}
+
+	calendar := storage.NewDefaultCalendar(userID)
+	calendarID, err := s.createCalendar(calendar)
+	if err != nil {
+		return nil, err
+	}
+	calendar.ID = calendarID
+	return calendar, nil
+}
+
+// UpdateCalendar меняет название, цвет и напоминание по умолчанию; признак календаря по умолчанию не меняется.
+func (s *Storage) UpdateCalendar(_ context.Context, calendar *storage.Calendar) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existingCalendar, exists := s.calendars[calendar.ID]
+	if !exists || existingCalendar.UserID != calendar.UserID {
+		return storage.ErrCalendarNotFound
+	}
+
+	calendarCopy := *calendar
+	calendarCopy.IsDefault = existingCalendar.IsDefault
+	calendarCopy.SharedWith = slices.Clone(calendar.SharedWith)
+	return s.commit(&walRecord{Op: opSaveCalendar, Calendar: &calendarCopy})
+}
+
+// DeleteCalendar удаляет пустой календарь; календарь с событиями (в том числе в корзине)
+// и календарь по умолчанию удалить нельзя.
+func (s *Storage) DeleteCalendar(_ context.Context, userID uint64, calendarID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calendar, exists := s.calendars[calendarID]
+	if !exists || calendar.UserID != userID {
+		return storage.ErrCalendarNotFound
+	}
+	if calendar.IsDefault {
+		return storage.ErrDefaultCalendar
+	}
+
+	if eventsByUser, exists := s.usersEvents[userID]; exists {
+		empty := true
+		eventsByUser.ascend(func(event *storage.Event) bool {
+			empty = event.CalendarID != calendarID
+			return empty
+		})
+		if !empty {
+			return storage.ErrCalendarNotEmpty
+		}
+	}
+
+	return s.commit(&walRecord{Op: opDeleteCalendar, CalendarID: calendarID})
+}
+
+func (s *Storage) ListCalendars(_ context.Context, userID uint64) ([]*storage.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calendars := make([]*storage.Calendar, 0)
+	for _, calendar := range s.calendars {
+		if calendar.UserID == userID {
+			calendarCopy := *calendar
+			calendars = append(calendars, &calendarCopy)
+		}
+	}
+	sort.Slice(calendars, func(i, j int) bool { return calendars[i].ID < calendars[j].ID })
+
+	return calendars, nil
+}
+
+// ListSharedCalendars возвращает календари других пользователей, открытые пользователю.
+func (s *Storage) ListSharedCalendars(_ context.Context, userID uint64) ([]*storage.Calendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calendars := make([]*storage.Calendar, 0)
+	for _, calendar := range s.sharedCalendars(userID) {
+		calendarCopy := *calendar
+		calendars = append(calendars, &calendarCopy)
+	}
+	return calendars, nil
+}
+
+// GetSharedByID возвращает событие из календаря, открытого пользователю.
+func (s *Storage) GetSharedByID(_ context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	event, exists := s.events[eventID]
+	if !exists || event.DeletedAt != nil {
+		return nil, storage.ErrEventNotFound
+	}
+	calendar, exists := s.calendars[event.CalendarID]
+	if !exists || !slices.Contains(calendar.SharedWith, userID) {
+		return nil, storage.ErrEventNotFound
+	}
+
+	return event, nil
+}
 
-	for existingEventID := range eventsByUser {
-		existingEvent := s.events[existingEventID]
-		if event.ID != existingEvent.ID && event.StartDate.Compare(existingEvent.EndDate) <= 0 && event.EndDate.Compare(existingEvent.StartDate) >= 0 {
-			return storage.ErrBusyTime
+// ListSharedForPeriod возвращает события из календарей, открытых пользователю, пересекающиеся с периодом.
+func (s *Storage) ListSharedForPeriod(
+	_ context.Context,
+	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]*storage.Event, 0)
+	for _, calendar := range s.sharedCalendars(userID) {
+		eventsByUser, exists := s.usersEvents[calendar.UserID]
+		if !exists {
+			continue
 		}
+		eventsByUser.overlapping(startDate, endDateExclusive, func(event *storage.Event) {
+			if event.CalendarID == calendar.ID && event.DeletedAt == nil && filter.Match(event) {
+				events = append(events, event)
+			}
+		})
 	}
 
+	return events, nil
+}
+
+// возвращает календари, открытые пользователю, по возрастанию ID; вызывается под блокировкой.
+func (s *Storage) sharedCalendars(userID uint64) []*storage.Calendar {
+	calendars := make([]*storage.Calendar, 0)
+	for _, calendar := range s.calendars {
+		if slices.Contains(calendar.SharedWith, userID) {
+			calendars = append(calendars, calendar)
+		}
+	}
+	sort.Slice(calendars, func(i, j int) bool { return calendars[i].ID < calendars[j].ID })
+	return calendars
+}
+
+// переносит события без календаря (сохранённые до появления календарей) в календари по умолчанию;
+// вызывается под блокировкой при восстановлении, результат попадает в следующий снапшот.
+func (s *Storage) assignDefaultCalendars() {
+	for _, event := range s.events {
+		if event.CalendarID != 0 {
+			continue
+		}
+		calendarID, exists := s.defaultCalendars[event.UserID]
+		if !exists {
+			calendar := storage.NewDefaultCalendar(event.UserID)
+			calendar.ID = s.lastCalendarID + 1
+			s.apply(&walRecord{Op: opSaveCalendar, Calendar: calendar})
+			calendarID = calendar.ID
+		}
+		event.CalendarID = calendarID
+	}
+}
+
+// проверяет, что ресурсы события существуют и не забронированы другими событиями на это время;
+// ресурсы бронируются эксклюзивно независимо от прозрачности события и политики пользователя.
+func (s *Storage) checkResources(event *storage.Event) error {
+	busy := make([]uint64, 0)
+	for _, resourceID := range event.ResourceIDs {
+		if _, exists := s.resources[resourceID]; !exists {
+			return storage.ErrResourceNotFound
+		}
+
+		eventsByResource, exists := s.resourceEvents[resourceID]
+		if !exists {
+			continue
+		}
+		isBusy := false
+		// границы интервалов включаются
+		eventsByResource.overlapping(event.StartDate, event.EndDate.Add(time.Nanosecond), func(existingEvent *storage.Event) {
+			if event.ID != existingEvent.ID && existingEvent.DeletedAt == nil {
+				isBusy = true
+			}
+		})
+		if isBusy {
+			busy = append(busy, resourceID)
+		}
+	}
+
+	if len(busy) > 0 {
+		return &storage.ResourceBusyError{ResourceIDs: busy}
+	}
 	return nil
 }
+
+func (s *Storage) CreateResource(_ context.Context, resource *storage.Resource) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resourceCopy := *resource
+	resourceCopy.ID = s.lastResourceID + 1
+	if err := s.commit(&walRecord{Op: opSaveResource, Resource: &resourceCopy}); err != nil {
+		return 0, err
+	}
+	return resourceCopy.ID, nil
+}
+
+func (s *Storage) GetResource(_ context.Context, resourceID uint64) (*storage.Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resource, exists := s.resources[resourceID]
+	if !exists {
+		return nil, storage.ErrResourceNotFound
+	}
+	resourceCopy := *resource
+	return &resourceCopy, nil
+}
+
+func (s *Storage) UpdateResource(_ context.Context, resource *storage.Resource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existingResource, exists := s.resources[resource.ID]
+	if !exists {
+		return storage.ErrResourceNotFound
+	}
+
+	// создатель ресурса не меняется
+	resourceCopy := *resource
+	resourceCopy.UserID = existingResource.UserID
+	return s.commit(&walRecord{Op: opSaveResource, Resource: &resourceCopy})
+}
+
+// DeleteResource удаляет ресурс без броней; ресурс, забронированный событиями (в том числе в корзине), удалить нельзя.
+func (s *Storage) DeleteResource(_ context.Context, resourceID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.resources[resourceID]; !exists {
+		return storage.ErrResourceNotFound
+	}
+	if _, exists := s.resourceEvents[resourceID]; exists {
+		return storage.ErrResourceInUse
+	}
+
+	return s.commit(&walRecord{Op: opDeleteResource, ResourceID: resourceID})
+}
+
+func (s *Storage) ListResources(_ context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resources := make([]*storage.Resource, 0)
+	for _, resource := range s.resources {
+		if (filter.Kind == "" || resource.Kind == filter.Kind) && resource.Capacity >= filter.MinCapacity {
+			resourceCopy := *resource
+			resources = append(resources, &resourceCopy)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+
+	return resources, nil
+}
+
+// ListResourceBusy возвращает брони ресурса, пересекающиеся с периодом.
+func (s *Storage) ListResourceBusy(
+	_ context.Context,
+	resourceID uint64,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.BusyInterval, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	intervals := make([]*storage.BusyInterval, 0)
+	eventsByResource, exists := s.resourceEvents[resourceID]
+	if !exists {
+		return intervals, nil
+	}
+
+	eventsByResource.overlapping(startDate, endDateExclusive, func(event *storage.Event) {
+		if event.DeletedAt == nil {
+			intervals = append(intervals, &storage.BusyInterval{StartDate: event.StartDate, EndDate: event.EndDate})
+		}
+	})
+
+	return intervals, nil
+}
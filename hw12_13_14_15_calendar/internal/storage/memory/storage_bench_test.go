@@ -0,0 +1,128 @@
+//go:build bench
+// +build bench
+
+package memorystorage
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Сравнение индексов с полным перебором событий, как было до их появления.
+// go test -count=10 -tags bench -run '^$' -bench . -benchmem ./internal/storage/memory/ | tee new.txt
+// benchstat old.txt new.txt | tee benchstat.txt.
+
+const (
+	benchEvents = 100_000
+	benchUserID = 1
+)
+
+func newBenchStorage(b *testing.B) (*Storage, time.Time) {
+	b.Helper()
+
+	rnd := rand.New(rand.NewSource(1))
+	base := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	s := New()
+	err := s.SaveUserSettings(context.Background(), &storage.UserSettings{UserID: benchUserID, OverlapPolicy: storage.OverlapAllow})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, event := range randomEvents(rnd, base, benchEvents) {
+		event.UserID = benchUserID
+		if _, err := s.Create(context.Background(), event); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return s, base.Add(15 * 24 * time.Hour)
+}
+
+func BenchmarkListForPeriod(b *testing.B) {
+	s, from := newBenchStorage(b)
+	to := from.Add(24 * time.Hour)
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = s.ListForPeriod(context.Background(), benchUserID, storage.EventFilter{}, from, to)
+		}
+	})
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			events := make([]*storage.Event, 0)
+			for _, event := range s.events {
+				if event.UserID == benchUserID && event.DeletedAt == nil &&
+					event.StartDate.Compare(to) < 0 && event.EndDate.Compare(from) >= 0 {
+					events = append(events, event)
+				}
+			}
+			sortByStart(events)
+		}
+	})
+}
+
+func BenchmarkListConflicts(b *testing.B) {
+	s, from := newBenchStorage(b)
+	event := &storage.Event{UserID: benchUserID, StartDate: from, EndDate: from.Add(time.Hour)}
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = s.ListConflicts(context.Background(), event)
+		}
+	})
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			conflicts := make([]*storage.Event, 0)
+			for _, existingEvent := range s.events {
+				if existingEvent.UserID == event.UserID && existingEvent.DeletedAt == nil && !existingEvent.Transparent &&
+					event.StartDate.Compare(existingEvent.EndDate) <= 0 && event.EndDate.Compare(existingEvent.StartDate) >= 0 {
+					conflicts = append(conflicts, existingEvent)
+				}
+			}
+			sortByStart(conflicts)
+		}
+	})
+}
+
+func BenchmarkListForNotify(b *testing.B) {
+	s, from := newBenchStorage(b)
+	to := from.Add(time.Hour)
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = s.ListForNotify(context.Background(), from, to)
+		}
+	})
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			events := make([]*storage.Event, 0)
+			for _, event := range s.events {
+				if event.NotifyBefore != 0 && event.DeletedAt == nil {
+					notifyDate := event.StartDate.Add(-event.NotifyBefore)
+					if notifyDate.Compare(from) >= 0 && notifyDate.Compare(to) <= 0 {
+						events = append(events, event)
+					}
+				}
+			}
+			sort.Slice(events, func(i, j int) bool {
+				return notifyKey(events[i]).compare(notifyKey(events[j])) < 0
+			})
+		}
+	})
+}
+
+func sortByStart(events []*storage.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].StartDate.Equal(events[j].StartDate) {
+			return events[i].EndDate.Before(events[j].EndDate)
+		}
+		return events[i].StartDate.Before(events[j].StartDate)
+	})
+}
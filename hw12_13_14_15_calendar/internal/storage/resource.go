@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Resource - бронируемый ресурс (переговорная, проектор); ресурсы общие для всех пользователей.
+// Событие бронирует ресурсы на всё своё время, одно время ресурса не могут занимать два события.
+// UserID - создатель ресурса; 0 у ресурсов, созданных до учёта создателей.
+type Resource struct {
+	ID         uint64             `db:"resource_id"`
+	UserID     uint64             `db:"user_id"`
+	Name       string             `db:"name"`
+	Kind       string             `db:"kind"`
+	Capacity   int                `db:"capacity"`
+	Attributes ResourceAttributes `db:"attributes"`
+}
+
+// ResourceAttributes - произвольные свойства ресурса (этаж, наличие видеосвязи); хранится в БД в виде json.
+type ResourceAttributes map[string]string
+
+func (a ResourceAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *ResourceAttributes) Scan(src any) error {
+	if err := jsonScan(src, a); err != nil {
+		return err
+	}
+	if len(*a) == 0 {
+		*a = nil
+	}
+	return nil
+}
+
+// ResourceIDs - ресурсы, забронированные событием; читается из БД в виде json.
+type ResourceIDs []uint64
+
+func (r ResourceIDs) Value() (driver.Value, error) {
+	return jsonValue(r, r == nil)
+}
+
+func (r *ResourceIDs) Scan(src any) error {
+	if err := jsonScan(src, r); err != nil {
+		return err
+	}
+	if len(*r) == 0 {
+		*r = nil
+	}
+	return nil
+}
+
+// ResourceFilter - условия отбора ресурсов; пустые условия не ограничивают выборку.
+type ResourceFilter struct {
+	Kind        string
+	MinCapacity int
+}
+
+// BusyInterval - время, на которое ресурс забронирован событием. Подробности события
+// не раскрываются, т.к. событие может принадлежать другому пользователю.
+type BusyInterval struct {
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+}
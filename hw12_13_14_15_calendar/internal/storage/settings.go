@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	// база часовых поясов встраивается в бинарник, т.к. в образе её может не быть
+	_ "time/tzdata"
+)
+
+type UserSettings struct {
+	UserID        uint64        `db:"user_id"`
+	OverlapPolicy OverlapPolicy `db:"overlap_policy"`
+	// TimeZone - часовой пояс пользователя в формате IANA (для рабочих часов и сводок); пустая строка - UTC.
+	TimeZone     string       `db:"time_zone"`
+	WorkingHours WorkingHours `db:"working_hours"`
+	OutOfOffice  OutOfOffice  `db:"out_of_office"`
+	// AvailabilityPolicy определяет, что делать с событием вне рабочих часов или в период отсутствия;
+	// значения те же, что у OverlapPolicy.
+	AvailabilityPolicy OverlapPolicy `db:"availability_policy"`
+	// Digest - периодичность сводки событий; сводки рассылает планировщик.
+	Digest DigestPeriod `db:"digest"`
+}
+
+// OverlapPolicy определяет, что делать при пересечении событий пользователя.
+type OverlapPolicy string
+
+const (
+	OverlapReject OverlapPolicy = "REJECT"
+	OverlapWarn   OverlapPolicy = "WARN"
+	OverlapAllow  OverlapPolicy = "ALLOW"
+)
+
+func (p OverlapPolicy) Valid() bool {
+	switch p {
+	case OverlapReject, OverlapWarn, OverlapAllow:
+		return true
+	}
+	return false
+}
+
+// DigestPeriod определяет, получает ли пользователь сводку событий на день или на неделю.
+type DigestPeriod string
+
+const (
+	DigestNone   DigestPeriod = "NONE"
+	DigestDaily  DigestPeriod = "DAILY"
+	DigestWeekly DigestPeriod = "WEEKLY"
+)
+
+func DefaultUserSettings(userID uint64) *UserSettings {
+	return &UserSettings{
+		UserID:             userID,
+		OverlapPolicy:      OverlapReject,
+		AvailabilityPolicy: OverlapAllow,
+		Digest:             DigestNone,
+	}
+}
+
+// WorkingInterval - рабочее время в день недели; Start и End в формате ЧЧ:ММ, End может быть 24:00.
+type WorkingInterval struct {
+	Weekday time.Weekday `json:"weekday"`
+	Start   string       `json:"start"`
+	End     string       `json:"end"`
+}
+
+// WorkingHours - рабочие часы по дням недели, в день может быть несколько интервалов;
+// пустой список - рабочие часы не ограничены. Хранится в БД в виде json так же, как Tags.
+type WorkingHours []WorkingInterval
+
+func (w WorkingHours) Value() (driver.Value, error) {
+	return jsonValue(w, w == nil)
+}
+
+func (w *WorkingHours) Scan(src any) error {
+	if err := jsonScan(src, w); err != nil {
+		return err
+	}
+	if len(*w) == 0 {
+		*w = nil
+	}
+	return nil
+}
+
+// OutOfOfficePeriod - период отсутствия пользователя с сообщением, которое получает создающий событие в этот период.
+type OutOfOfficePeriod struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Message   string    `json:"message"`
+}
+
+// OutOfOffice - периоды отсутствия; хранится в БД в виде json так же, как Tags.
+type OutOfOffice []OutOfOfficePeriod
+
+func (o OutOfOffice) Value() (driver.Value, error) {
+	return jsonValue(o, o == nil)
+}
+
+func (o *OutOfOffice) Scan(src any) error {
+	if err := jsonScan(src, o); err != nil {
+		return err
+	}
+	if len(*o) == 0 {
+		*o = nil
+	}
+	return nil
+}
+
+// Location возвращает часовой пояс пользователя; неизвестный пояс считается UTC.
+func (s *UserSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WithinWorkingHours проверяет, что время лежит в рабочих часах: в каждом захваченном дне - целиком
+// в одном рабочем интервале. Событие через полночь допустимо, если интервал одного дня заканчивается
+// в 24:00, а интервал следующего начинается в 00:00.
+func (s *UserSettings) WithinWorkingHours(startDate time.Time, endDate time.Time) bool {
+	if len(s.WorkingHours) == 0 {
+		return true
+	}
+	loc := s.Location()
+	start := startDate.In(loc)
+	end := endDate.In(loc)
+	for {
+		nextDay := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc)
+		// минуты считаются по часам пользователя, чтобы день перехода на летнее время не сдвигал интервалы
+		startMinute := start.Hour()*60 + start.Minute()
+		endMinute := 24 * 60
+		if end.Before(nextDay) {
+			endMinute = end.Hour()*60 + end.Minute()
+			if end.Second() > 0 || end.Nanosecond() > 0 {
+				endMinute++
+			}
+		}
+		if !s.withinWorkingInterval(start.Weekday(), startMinute, endMinute) {
+			return false
+		}
+		if !end.After(nextDay) {
+			return true
+		}
+		start = nextDay
+	}
+}
+
+func (s *UserSettings) withinWorkingInterval(weekday time.Weekday, startMinute int, endMinute int) bool {
+	for _, interval := range s.WorkingHours {
+		if interval.Weekday == weekday && ClockMinutes(interval.Start) <= startMinute && endMinute <= ClockMinutes(interval.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkingPeriod - непрерывный отрезок рабочего времени пользователя.
+type WorkingPeriod struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// WorkingPeriods возвращает рабочее время в периоде [startDate, endDateExclusive), отсортированное по началу;
+// соседние интервалы склеиваются, в т.ч. через полночь. Без рабочих часов рабочим считается весь период.
+func (s *UserSettings) WorkingPeriods(startDate time.Time, endDateExclusive time.Time) []WorkingPeriod {
+	if !endDateExclusive.After(startDate) {
+		return nil
+	}
+	if len(s.WorkingHours) == 0 {
+		return []WorkingPeriod{{StartDate: startDate, EndDate: endDateExclusive}}
+	}
+	loc := s.Location()
+	start := startDate.In(loc)
+	var periods []WorkingPeriod
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(endDateExclusive); {
+		var dayPeriods []WorkingPeriod
+		for _, interval := range s.WorkingHours {
+			startMinute, endMinute := ClockMinutes(interval.Start), ClockMinutes(interval.End)
+			if interval.Weekday != day.Weekday() || startMinute < 0 || endMinute <= startMinute {
+				continue
+			}
+			// время собирается по часам пользователя, как в WithinWorkingHours
+			periodStart := time.Date(day.Year(), day.Month(), day.Day(), 0, startMinute, 0, 0, loc)
+			periodEnd := time.Date(day.Year(), day.Month(), day.Day(), 0, endMinute, 0, 0, loc)
+			if periodStart.Before(startDate) {
+				periodStart = startDate
+			}
+			if periodEnd.After(endDateExclusive) {
+				periodEnd = endDateExclusive
+			}
+			if periodEnd.After(periodStart) {
+				dayPeriods = append(dayPeriods, WorkingPeriod{StartDate: periodStart, EndDate: periodEnd})
+			}
+		}
+		slices.SortFunc(dayPeriods, func(a, b WorkingPeriod) int { return a.StartDate.Compare(b.StartDate) })
+		for _, period := range dayPeriods {
+			last := len(periods) - 1
+			if last >= 0 && !period.StartDate.After(periods[last].EndDate) {
+				if period.EndDate.After(periods[last].EndDate) {
+					periods[last].EndDate = period.EndDate
+				}
+				continue
+			}
+			periods = append(periods, period)
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return periods
+}
+
+// OutOfOfficeAt возвращает период отсутствия, пересекающийся со временем, или nil.
+func (s *UserSettings) OutOfOfficeAt(startDate time.Time, endDate time.Time) *OutOfOfficePeriod {
+	for i := range s.OutOfOffice {
+		period := &s.OutOfOffice[i]
+		if period.StartDate.Before(endDate) && period.EndDate.After(startDate) {
+			return period
+		}
+	}
+	return nil
+}
+
+// ClockMinutes переводит время ЧЧ:ММ в минуты от начала дня; для некорректного значения возвращает -1.
+func ClockMinutes(clock string) int {
+	hours, minutes, ok := strings.Cut(clock, ":")
+	if !ok || len(hours) != 2 || len(minutes) != 2 {
+		return -1
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 24 {
+		return -1
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return -1
+	}
+	return h*60 + m
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithinWorkingHours(t *testing.T) {
+	t.Parallel()
+
+	// 2024-07-08 - понедельник; в Москве UTC+3
+	office := &UserSettings{
+		TimeZone: "Europe/Moscow",
+		WorkingHours: WorkingHours{
+			{Weekday: time.Monday, Start: "09:00", End: "13:00"},
+			{Weekday: time.Monday, Start: "14:00", End: "18:00"},
+		},
+	}
+	nightShift := &UserSettings{
+		WorkingHours: WorkingHours{
+			{Weekday: time.Monday, Start: "20:00", End: "24:00"},
+			{Weekday: time.Tuesday, Start: "00:00", End: "04:00"},
+		},
+	}
+	allWeek := &UserSettings{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		allWeek.WorkingHours = append(allWeek.WorkingHours, WorkingInterval{Weekday: weekday, Start: "00:00", End: "24:00"})
+	}
+	// 2024-03-31 в Берлине переход на летнее время: после 02:00 сразу 03:00
+	berlin := &UserSettings{
+		TimeZone:     "Europe/Berlin",
+		WorkingHours: WorkingHours{{Weekday: time.Sunday, Start: "09:00", End: "18:00"}},
+	}
+
+	tests := []struct {
+		name      string
+		settings  *UserSettings
+		startDate string
+		endDate   string
+		expected  bool
+	}{
+		{name: "no working hours", settings: &UserSettings{}, startDate: "2024-07-09T23:00:00Z", endDate: "2024-07-10T03:00:00Z", expected: true},
+		{name: "within interval", settings: office, startDate: "2024-07-08T06:00:00Z", endDate: "2024-07-08T10:00:00Z", expected: true},
+		{name: "across break", settings: office, startDate: "2024-07-08T09:30:00Z", endDate: "2024-07-08T11:30:00Z"},
+		{name: "ends one second late", settings: office, startDate: "2024-07-08T14:00:00Z", endDate: "2024-07-08T15:00:01Z"},
+		{name: "day off", settings: office, startDate: "2024-07-09T06:00:00Z", endDate: "2024-07-09T07:00:00Z"},
+		{name: "across midnight", settings: nightShift, startDate: "2024-07-08T22:00:00Z", endDate: "2024-07-09T02:00:00Z", expected: true},
+		{name: "until midnight", settings: nightShift, startDate: "2024-07-08T22:00:00Z", endDate: "2024-07-09T00:00:00Z", expected: true},
+		{name: "across midnight after working hours", settings: nightShift, startDate: "2024-07-08T22:00:00Z", endDate: "2024-07-09T05:00:00Z"},
+		{name: "across midnight into day off", settings: nightShift, startDate: "2024-07-09T22:00:00Z", endDate: "2024-07-10T01:00:00Z"},
+		{name: "several days", settings: allWeek, startDate: "2024-07-08T12:00:00Z", endDate: "2024-07-11T12:00:00Z", expected: true},
+		{name: "dst day", settings: berlin, startDate: "2024-03-31T07:00:00Z", endDate: "2024-03-31T16:00:00Z", expected: true},
+		{name: "dst day after working hours", settings: berlin, startDate: "2024-03-31T07:00:00Z", endDate: "2024-03-31T16:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startDate, err := time.Parse(time.RFC3339, tt.startDate)
+			require.NoError(t, err)
+			endDate, err := time.Parse(time.RFC3339, tt.endDate)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.settings.WithinWorkingHours(startDate, endDate))
+		})
+	}
+}
+
+func TestWorkingPeriods(t *testing.T) {
+	t.Parallel()
+
+	office := &UserSettings{
+		TimeZone: "Europe/Moscow",
+		WorkingHours: WorkingHours{
+			{Weekday: time.Monday, Start: "14:00", End: "18:00"},
+			{Weekday: time.Monday, Start: "09:00", End: "13:00"},
+		},
+	}
+	nightShift := &UserSettings{
+		WorkingHours: WorkingHours{
+			{Weekday: time.Monday, Start: "20:00", End: "24:00"},
+			{Weekday: time.Tuesday, Start: "00:00", End: "04:00"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		settings  *UserSettings
+		startDate string
+		endDate   string
+		expected  [][2]string
+	}{
+		{
+			name:      "no working hours",
+			settings:  &UserSettings{},
+			startDate: "2024-07-09T23:00:00Z",
+			endDate:   "2024-07-10T03:00:00Z",
+			expected:  [][2]string{{"2024-07-09T23:00:00Z", "2024-07-10T03:00:00Z"}},
+		},
+		{
+			name:      "week",
+			settings:  office,
+			startDate: "2024-07-07T21:00:00Z",
+			endDate:   "2024-07-14T21:00:00Z",
+			expected:  [][2]string{{"2024-07-08T06:00:00Z", "2024-07-08T10:00:00Z"}, {"2024-07-08T11:00:00Z", "2024-07-08T15:00:00Z"}},
+		},
+		{
+			name:      "clipped by period",
+			settings:  office,
+			startDate: "2024-07-08T07:00:00Z",
+			endDate:   "2024-07-08T12:00:00Z",
+			expected:  [][2]string{{"2024-07-08T07:00:00Z", "2024-07-08T10:00:00Z"}, {"2024-07-08T11:00:00Z", "2024-07-08T12:00:00Z"}},
+		},
+		{
+			name:      "merged across midnight",
+			settings:  nightShift,
+			startDate: "2024-07-08T00:00:00Z",
+			endDate:   "2024-07-10T00:00:00Z",
+			expected:  [][2]string{{"2024-07-08T20:00:00Z", "2024-07-09T04:00:00Z"}},
+		},
+		{
+			name:      "day off",
+			settings:  office,
+			startDate: "2024-07-09T00:00:00Z",
+			endDate:   "2024-07-10T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startDate, err := time.Parse(time.RFC3339, tt.startDate)
+			require.NoError(t, err)
+			endDate, err := time.Parse(time.RFC3339, tt.endDate)
+			require.NoError(t, err)
+			var actual [][2]string
+			for _, period := range tt.settings.WorkingPeriods(startDate, endDate) {
+				actual = append(actual, [2]string{
+					period.StartDate.UTC().Format(time.RFC3339),
+					period.EndDate.UTC().Format(time.RFC3339),
+				})
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -2,10 +2,12 @@ package sqlstorage
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgconn"
 	_ "github.com/jackc/pgx/v4/stdlib" // for postgres
 	"github.com/jmoiron/sqlx"
 	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
@@ -34,17 +36,54 @@ func (s *Storage) Close(_ context.Context) error {
 }
 
 const eventFields = `
-event_id, title, start_date, end_date, description, user_id, 
-CAST(EXTRACT(EPOCH FROM notify_before) * 1000000000 as BIGINT) AS notify_before, notify_status
+event_id, title, start_date, end_date, description, user_id, COALESCE(calendar_id, 0) AS calendar_id,
+CAST(EXTRACT(EPOCH FROM notify_before) * 1000000000 as BIGINT) AS notify_before, notify_status, transparent, deleted_at,
+location, latitude, longitude, category, tags, attachments,
+COALESCE((
+	SELECT json_agg(r.resource_id ORDER BY r.resource_id) FROM event_resources r WHERE r.event_id = events.event_id
+), '[]') AS resource_ids
 `
 
-const checkExistingEventsSQL = `
-SELECT count(*) FROM events WHERE start_date <= ? AND end_date >= ? AND user_id = ? AND event_id <> ?
+const selectConflictingEventsSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date <= ? AND end_date >= ? AND user_id = ? AND event_id <> ? AND deleted_at IS NULL AND NOT transparent
+ORDER BY start_date, end_date
+`
+
+// событие занимает время эксклюзивно (участвует в ограничении events_busy_time_excl),
+// если оно непрозрачное и пользователь запрещает пересечения.
+const exclusiveEventSQL = `
+NOT :transparent AND COALESCE((SELECT overlap_policy FROM user_settings WHERE user_id = :user_id), 'REJECT') = 'REJECT'
+`
+
+// то же для уже сохранённого события: пересчитывается при восстановлении события из корзины
+// и при изменении политики пользователя.
+const storedEventExclusiveSQL = `
+NOT transparent AND COALESCE((SELECT s.overlap_policy FROM user_settings s WHERE s.user_id = events.user_id), 'REJECT') = 'REJECT'
 `
 
+// ограничение, запрещающее пересечение эксклюзивных событий пользователя.
+const busyTimeConstraint = "events_busy_time_excl"
+
+// ограничение, запрещающее пересечение броней одного ресурса.
+const resourceBusyConstraint = "event_resources_busy_time_excl"
+
+// коды ошибок postgres exclusion_violation и foreign_key_violation.
+const (
+	exclusionViolationCode  = "23P01"
+	foreignKeyViolationCode = "23503"
+)
+
 const createEventSQL = `
-INSERT INTO events (title, start_date, end_date, description, user_id, notify_before)
-VALUES (:title, :start_date, :end_date, :description, :user_id, :notify_before)
+INSERT INTO events (
+	title, start_date, end_date, description, user_id, calendar_id, notify_before, transparent,
+	location, latitude, longitude, category, tags, attachments, exclusive
+)
+VALUES (
+	:title, :start_date, :end_date, :description, :user_id, NULLIF(CAST(:calendar_id AS bigint), 0), :notify_before, :transparent,
+	:location, :latitude, :longitude, :category, :tags, :attachments, ` + exclusiveEventSQL + `
+)
 RETURNING event_id
 `
 
@@ -55,13 +94,19 @@ func (s *Storage) Create(ctx context.Context, event *storage.Event) (uint64, err
 	}
 	defer tx.Rollback()
 
-	var countExistingEvents int
-	err = tx.GetContext(ctx, &countExistingEvents, tx.Rebind(checkExistingEventsSQL), event.EndDate, event.StartDate, event.UserID, 0)
+	eventID, err := s.createEvent(ctx, tx, event)
 	if err != nil {
-		return 0, fmt.Errorf("cannot check events: %w", err)
+		return 0, err
+	}
+	return eventID, s.convertBusyTimeError(ctx, event, tx.Commit())
+}
+
+func (s *Storage) createEvent(ctx context.Context, tx *sqlx.Tx, event *storage.Event) (uint64, error) {
+	if err := s.checkBusyTime(ctx, tx, event); err != nil {
+		return 0, err
 	}
-	if countExistingEvents > 0 {
-		return 0, storage.ErrBusyTime
+	if err := s.checkResources(ctx, tx, event); err != nil {
+		return 0, err
 	}
 
 	stmt, err := tx.PrepareNamedContext(ctx, createEventSQL)
@@ -69,31 +114,126 @@ func (s *Storage) Create(ctx context.Context, event *storage.Event) (uint64, err
 		return 0, fmt.Errorf("cannot prepare context for creating event: %w", err)
 	}
 
-	rows, err := stmt.QueryxContext(ctx, event)
+	var eventID uint64
+	err = stmt.GetContext(ctx, &eventID, event)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.New("event not created")
+	}
 	if err != nil {
-		return 0, fmt.Errorf("cannot query context for creating event: %w", err)
+		return 0, s.convertBusyTimeError(ctx, event, fmt.Errorf("cannot query context for creating event: %w", err))
 	}
 
-	if !rows.Next() {
-		return 0, errors.New("event not created")
+	if err := s.saveEventResources(ctx, tx, eventID, event); err != nil {
+		return 0, err
 	}
+	return eventID, nil
+}
 
-	var eventID uint64
-	err = rows.Scan(&eventID)
-	if err == nil {
-		err = tx.Commit()
+const deleteExpiredIdempotencyKeysSQL = `
+DELETE FROM idempotency_keys WHERE user_id = ? AND created_at < ?
+`
+
+// параллельная транзакция с тем же ключом ждёт здесь, пока первая не завершится.
+const lockIdempotencyKeySQL = `
+INSERT INTO idempotency_keys (user_id, idempotency_key, event_id, request_hash) VALUES (?, ?, 0, ?)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+`
+
+const getIdempotencyKeySQL = `
+SELECT event_id, request_hash FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND created_at >= ?
+`
+
+const saveIdempotencyKeySQL = `
+UPDATE idempotency_keys SET event_id = ? WHERE user_id = ? AND idempotency_key = ?
+`
+
+func (s *Storage) GetIdempotencyKey(ctx context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error) {
+	var idempotencyKey storage.IdempotencyKey
+	err := s.db.GetContext(ctx, &idempotencyKey, s.db.Rebind(getIdempotencyKeySQL), userID, key, notBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting idempotency key: %w", err)
+	}
+	return &idempotencyKey, nil
+}
+
+func (s *Storage) CreateIdempotent(
+	ctx context.Context,
+	event *storage.Event,
+	key string,
+	requestHash string,
+	notBefore time.Time,
+) (uint64, bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(deleteExpiredIdempotencyKeysSQL), event.UserID, notBefore)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot query context for deleting expired idempotency keys: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(lockIdempotencyKeySQL), event.UserID, key, requestHash)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot query context for locking idempotency key: %w", err)
+	}
+	locked, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot get result for locking idempotency key: %w", err)
+	}
+
+	if locked == 0 {
+		var idempotencyKey storage.IdempotencyKey
+		err = tx.GetContext(ctx, &idempotencyKey, tx.Rebind(getIdempotencyKeySQL), event.UserID, key, notBefore)
+		if err != nil {
+			return 0, false, fmt.Errorf("cannot query context for getting idempotency key: %w", err)
+		}
+		if !idempotencyKey.Matches(requestHash) {
+			return 0, false, storage.ErrIdempotencyKeyReused
+		}
+		return idempotencyKey.EventID, true, nil
+	}
+
+	eventID, err := s.createEvent(ctx, tx, event)
+	if err != nil {
+		return 0, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(saveIdempotencyKeySQL), eventID, event.UserID, key)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot query context for saving idempotency key: %w", err)
 	}
-	return eventID, err
+	return eventID, false, s.convertBusyTimeError(ctx, event, tx.Commit())
 }
 
 const getEventByIDSQL = `
 SELECT ` + eventFields + `
 FROM events
-WHERE event_id = :event_id AND user_id = :user_id
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NULL
 `
 
 func (s *Storage) GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
-	stmt, err := s.db.PrepareNamedContext(ctx, getEventByIDSQL)
+	return s.getEvent(ctx, getEventByIDSQL, userID, eventID)
+}
+
+const getSharedEventByIDSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE event_id = :event_id AND deleted_at IS NULL
+	AND calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = :user_id)
+`
+
+// GetSharedByID возвращает событие из календаря, открытого пользователю.
+func (s *Storage) GetSharedByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	return s.getEvent(ctx, getSharedEventByIDSQL, userID, eventID)
+}
+
+func (s *Storage) getEvent(ctx context.Context, query string, userID uint64, eventID uint64) (*storage.Event, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("cannot prepare context for getting event by id: %w", err)
 	}
@@ -122,8 +262,17 @@ SET title = :title,
 	end_date = :end_date,
 	description = :description,
 	user_id = :user_id,
-	notify_before = :notify_before
-WHERE event_id = :event_id AND user_id = :user_id
+	calendar_id = NULLIF(CAST(:calendar_id AS bigint), 0),
+	notify_before = :notify_before,
+	transparent = :transparent,
+	location = :location,
+	latitude = :latitude,
+	longitude = :longitude,
+	category = :category,
+	tags = :tags,
+	attachments = :attachments,
+	exclusive = ` + exclusiveEventSQL + `
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NULL
 `
 
 func (s *Storage) Update(ctx context.Context, event *storage.Event) error {
@@ -133,13 +282,11 @@ func (s *Storage) Update(ctx context.Context, event *storage.Event) error {
 	}
 	defer tx.Rollback()
 
-	var countExistingEvents int
-	err = tx.GetContext(ctx, &countExistingEvents, tx.Rebind(checkExistingEventsSQL), event.EndDate, event.StartDate, event.UserID, event.ID)
-	if err != nil {
-		return fmt.Errorf("cannot check events: %w", err)
+	if err := s.checkBusyTime(ctx, tx, event); err != nil {
+		return err
 	}
-	if countExistingEvents > 0 {
-		return storage.ErrBusyTime
+	if err := s.checkResources(ctx, tx, event); err != nil {
+		return err
 	}
 
 	stmt, err := tx.PrepareNamedContext(ctx, updateEventSQL)
@@ -149,7 +296,7 @@ func (s *Storage) Update(ctx context.Context, event *storage.Event) error {
 
 	result, err := stmt.ExecContext(ctx, event)
 	if err != nil {
-		return fmt.Errorf("cannot query context for updating event: %w", err)
+		return s.convertBusyTimeError(ctx, event, fmt.Errorf("cannot query context for updating event: %w", err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -159,16 +306,28 @@ func (s *Storage) Update(ctx context.Context, event *storage.Event) error {
 	if rowsAffected == 0 {
 		return storage.ErrEventNotFound
 	}
-	return tx.Commit()
+
+	if err := s.saveEventResources(ctx, tx, event.ID, event); err != nil {
+		return err
+	}
+	return s.convertBusyTimeError(ctx, event, tx.Commit())
 }
 
 const deleteEventSQL = `
-DELETE FROM events
-WHERE event_id = :event_id AND user_id = :user_id
+UPDATE events
+SET deleted_at = now()
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NULL
 `
 
+// Delete переносит событие в корзину и снимает брони его ресурсов; при восстановлении брони возвращаются.
 func (s *Storage) Delete(ctx context.Context, userID uint64, eventID uint64) error {
-	stmt, err := s.db.PrepareNamedContext(ctx, deleteEventSQL)
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareNamedContext(ctx, deleteEventSQL)
 	if err != nil {
 		return fmt.Errorf("cannot prepare context for deleting event: %w", err)
 	}
@@ -188,31 +347,187 @@ func (s *Storage) Delete(ctx context.Context, userID uint64, eventID uint64) err
 	if rowsAffected == 0 {
 		return storage.ErrEventNotFound
 	}
-	return nil
+
+	if _, err := tx.ExecContext(ctx, s.db.Rebind(setEventResourcesActiveSQL), false, eventID); err != nil {
+		return fmt.Errorf("cannot query context for releasing event resources: %w", err)
+	}
+	return tx.Commit()
+}
+
+const getDeletedEventByIDSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NOT NULL
+FOR UPDATE
+`
+
+const restoreEventSQL = `
+UPDATE events
+SET deleted_at = NULL, exclusive = ` + storedEventExclusiveSQL + `
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NOT NULL
+`
+
+func (s *Storage) Restore(ctx context.Context, userID uint64, eventID uint64) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	params := map[string]interface{}{
+		"event_id": eventID,
+		"user_id":  userID,
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, getDeletedEventByIDSQL)
+	if err != nil {
+		return fmt.Errorf("cannot prepare context for getting deleted event by id: %w", err)
+	}
+
+	var event storage.Event
+	err = stmt.GetContext(ctx, &event, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrEventNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query context for getting deleted event by id: %w", err)
+	}
+
+	if err := s.checkBusyTime(ctx, tx, &event); err != nil {
+		return err
+	}
+	if err := s.checkResources(ctx, tx, &event); err != nil {
+		return err
+	}
+
+	stmt, err = tx.PrepareNamedContext(ctx, restoreEventSQL)
+	if err != nil {
+		return fmt.Errorf("cannot prepare context for restoring event: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, params)
+	if err != nil {
+		return s.convertBusyTimeError(ctx, &event, fmt.Errorf("cannot query context for restoring event: %w", err))
+	}
+
+	_, err = tx.ExecContext(ctx, s.db.Rebind(setEventResourcesActiveSQL), true, eventID)
+	if err != nil {
+		return s.convertBusyTimeError(ctx, &event, fmt.Errorf("cannot query context for reserving event resources: %w", err))
+	}
+	return s.convertBusyTimeError(ctx, &event, tx.Commit())
+}
+
+const selectDeletedEventsSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE user_id = :user_id AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, event_id
+`
+
+func (s *Storage) ListDeleted(ctx context.Context, userID uint64) ([]*storage.Event, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, selectDeletedEventsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare context for listing deleted events: %w", err)
+	}
+
+	rows, err := stmt.QueryxContext(ctx, map[string]interface{}{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing deleted events: %w", err)
+	}
+
+	events := make([]*storage.Event, 0)
+	for rows.Next() {
+		var event storage.Event
+		err = rows.StructScan(&event)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get result for listing deleted events: %w", err)
+		}
+		events = append(events, &event)
+	}
+	return events, nil
 }
 
+const countEventsByUserSQL = `
+SELECT COUNT(*)
+FROM events
+WHERE user_id = ?
+`
+
+func (s *Storage) CountByUser(ctx context.Context, userID uint64) (int, error) {
+	var count int
+	err := s.db.GetContext(ctx, &count, s.db.Rebind(countEventsByUserSQL), userID)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for counting events: %w", err)
+	}
+	return count, nil
+}
+
+// условия фильтра, не заданные в запросе, не ограничивают выборку.
 const selectEventsByDatesSQL = `
 SELECT ` + eventFields + `
 FROM events
-WHERE start_date < :end_date AND end_date >= :start_date AND user_id = :user_id
+WHERE start_date < :end_date AND end_date >= :start_date AND user_id = :user_id AND deleted_at IS NULL
+	AND (COALESCE(cardinality(CAST(:calendar_ids AS bigint[])), 0) = 0 OR calendar_id = ANY(:calendar_ids))
+	AND (:category = '' OR category = :category)
+	AND tags @> CAST(:tags AS jsonb)
+ORDER BY start_date, end_date
+`
+
+const selectSharedEventsByDatesSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date < :end_date AND end_date >= :start_date AND deleted_at IS NULL
+	AND calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = :user_id)
+	AND (COALESCE(cardinality(CAST(:calendar_ids AS bigint[])), 0) = 0 OR calendar_id = ANY(:calendar_ids))
+	AND (:category = '' OR category = :category)
+	AND tags @> CAST(:tags AS jsonb)
 ORDER BY start_date, end_date
 `
 
+// ListForPeriod возвращает события пользователя за период, удовлетворяющие фильтру.
 func (s *Storage) ListForPeriod(
 	ctx context.Context,
 	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	return s.listForPeriod(ctx, selectEventsByDatesSQL, userID, filter, startDate, endDateExclusive)
+}
+
+// ListSharedForPeriod возвращает события из календарей, открытых пользователю, за период.
+func (s *Storage) ListSharedForPeriod(
+	ctx context.Context,
+	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	return s.listForPeriod(ctx, selectSharedEventsByDatesSQL, userID, filter, startDate, endDateExclusive)
+}
+
+func (s *Storage) listForPeriod(
+	ctx context.Context,
+	query string,
+	userID uint64,
+	filter storage.EventFilter,
 	startDate time.Time,
 	endDateExclusive time.Time,
 ) ([]*storage.Event, error) {
-	stmt, err := s.db.PrepareNamedContext(ctx, selectEventsByDatesSQL)
+	stmt, err := s.db.PrepareNamedContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("cannot prepare context for listing events: %w", err)
 	}
 
 	rows, err := stmt.QueryxContext(ctx, map[string]interface{}{
-		"start_date": startDate,
-		"end_date":   endDateExclusive,
-		"user_id":    userID,
+		"start_date":   startDate,
+		"end_date":     endDateExclusive,
+		"user_id":      userID,
+		"calendar_ids": filter.CalendarIDs,
+		"category":     filter.Category,
+		"tags":         storage.Tags(filter.Tags),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot query context for listing events: %w", err)
@@ -234,6 +549,7 @@ const selectEventsForNotifySQL = `
 SELECT ` + eventFields + `
 FROM events
 WHERE start_date - notify_before BETWEEN :start_notify_date AND :end_notify_date AND notify_status = :notify_status
+	AND notify_before > interval '0' AND deleted_at IS NULL
 ORDER BY start_date - notify_before, start_date, end_date
 `
 
@@ -264,6 +580,31 @@ func (s *Storage) ListForNotify(ctx context.Context, startNotifyDate time.Time,
 	return events, nil
 }
 
+const selectEventsByNotifyDateSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date - notify_before BETWEEN :start_notify_date AND :end_notify_date
+	AND notify_before > interval '0' AND deleted_at IS NULL
+ORDER BY start_date - notify_before, start_date, end_date
+`
+
+func (s *Storage) ListByNotifyDate(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, selectEventsByNotifyDateSQL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare context for listing events by notify date: %w", err)
+	}
+
+	events := make([]*storage.Event, 0)
+	err = stmt.SelectContext(ctx, &events, map[string]interface{}{
+		"start_notify_date": startNotifyDate,
+		"end_notify_date":   endNotifyDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing events by notify date: %w", err)
+	}
+	return events, nil
+}
+
 const setNotifyStatusSQL = `
 UPDATE events
 SET notify_status = :notify_status
@@ -300,3 +641,681 @@ func (s *Storage) DeleteByEndDate(ctx context.Context, maxEndDate time.Time) err
 
 	return err
 }
+
+const deleteEventsByDeletedDateSQL = `
+DELETE FROM events
+WHERE deleted_at <= :max_deleted_date
+`
+
+func (s *Storage) DeleteByDeletedDate(ctx context.Context, maxDeletedDate time.Time) error {
+	stmt, err := s.db.PrepareNamedContext(ctx, deleteEventsByDeletedDateSQL)
+	if err != nil {
+		return fmt.Errorf("cannot prepare context for deleting events by deleted date: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, map[string]interface{}{
+		"max_deleted_date": maxDeletedDate,
+	})
+
+	return err
+}
+
+const createAuditRecordSQL = `
+INSERT INTO event_audit (event_id, user_id, actor_id, action, changes, source, created_at)
+VALUES (:event_id, :user_id, :actor_id, :action, :changes, :source, :created_at)
+RETURNING audit_id
+`
+
+func (s *Storage) CreateAuditRecord(ctx context.Context, record *storage.AuditRecord) error {
+	stmt, err := s.db.PrepareNamedContext(ctx, createAuditRecordSQL)
+	if err != nil {
+		return fmt.Errorf("cannot prepare context for creating audit record: %w", err)
+	}
+
+	err = stmt.GetContext(ctx, &record.ID, record)
+	if err != nil {
+		return fmt.Errorf("cannot query context for creating audit record: %w", err)
+	}
+	return nil
+}
+
+const selectAuditRecordsSQL = `
+SELECT audit_id, event_id, user_id, actor_id, action, changes, source, created_at
+FROM event_audit
+WHERE event_id = :event_id AND user_id = :user_id
+ORDER BY audit_id
+`
+
+func (s *Storage) ListAuditRecords(ctx context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, selectAuditRecordsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare context for listing audit records: %w", err)
+	}
+
+	rows, err := stmt.QueryxContext(ctx, map[string]interface{}{
+		"event_id": eventID,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing audit records: %w", err)
+	}
+
+	records := make([]*storage.AuditRecord, 0)
+	for rows.Next() {
+		var record storage.AuditRecord
+		err = rows.StructScan(&record)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get result for listing audit records: %w", err)
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+func (s *Storage) ListConflicts(ctx context.Context, event *storage.Event) ([]*storage.Event, error) {
+	return s.listConflicts(ctx, s.db, event)
+}
+
+// проверяет пересечение события с другими с учётом политики пользователя.
+func (s *Storage) checkBusyTime(ctx context.Context, tx *sqlx.Tx, event *storage.Event) error {
+	settings, err := s.getUserSettings(ctx, tx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if settings.OverlapPolicy != storage.OverlapReject {
+		return nil
+	}
+
+	conflicts, err := s.listConflicts(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &storage.BusyTimeError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// преобразует нарушение ограничения на пересечение событий в storage.BusyTimeError,
+// а ограничения на пересечение броней ресурса - в storage.ResourceBusyError.
+// Проверки в checkBusyTime и checkResources не защищают от параллельных вставок, поэтому
+// окончательно пересечения отсекаются ограничениями в БД.
+func (s *Storage) convertBusyTimeError(ctx context.Context, event *storage.Event, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != exclusionViolationCode {
+		return err
+	}
+
+	switch pgErr.ConstraintName {
+	case busyTimeConstraint:
+		conflicts, listErr := s.listConflicts(ctx, s.db, event)
+		if listErr != nil {
+			return storage.ErrBusyTime
+		}
+		return &storage.BusyTimeError{Conflicts: conflicts}
+	case resourceBusyConstraint:
+		// конкурирующая бронь может быть уже отменена, тогда заняты все ресурсы события
+		resourceIDs, listErr := s.listBusyResources(ctx, s.db, event)
+		if listErr != nil || len(resourceIDs) == 0 {
+			resourceIDs = event.ResourceIDs
+		}
+		return &storage.ResourceBusyError{ResourceIDs: resourceIDs}
+	default:
+		return err
+	}
+}
+
+func (s *Storage) listConflicts(ctx context.Context, q sqlx.QueryerContext, event *storage.Event) ([]*storage.Event, error) {
+	conflicts := make([]*storage.Event, 0)
+	if event.Transparent {
+		return conflicts, nil
+	}
+
+	err := sqlx.SelectContext(
+		ctx, q, &conflicts, s.db.Rebind(selectConflictingEventsSQL),
+		event.EndDate, event.StartDate, event.UserID, event.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check events: %w", err)
+	}
+	return conflicts, nil
+}
+
+func (s *Storage) GetUserSettings(ctx context.Context, userID uint64) (*storage.UserSettings, error) {
+	return s.getUserSettings(ctx, s.db, userID)
+}
+
+func (s *Storage) getUserSettings(ctx context.Context, q sqlx.QueryerContext, userID uint64) (*storage.UserSettings, error) {
+	settings := storage.DefaultUserSettings(userID)
+	err := sqlx.GetContext(ctx, q, settings, s.db.Rebind(getUserSettingsSQL), userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cannot get user settings: %w", err)
+	}
+	return settings, nil
+}
+
+const userSettingsFields = `
+user_id, overlap_policy, time_zone, working_hours, out_of_office, availability_policy, digest
+`
+
+const getUserSettingsSQL = `
+SELECT ` + userSettingsFields + ` FROM user_settings WHERE user_id = ?
+`
+
+const listDigestSettingsSQL = `
+SELECT ` + userSettingsFields + ` FROM user_settings WHERE digest <> 'NONE' ORDER BY user_id
+`
+
+const saveUserSettingsSQL = `
+INSERT INTO user_settings (user_id, overlap_policy, time_zone, working_hours, out_of_office, availability_policy, digest)
+VALUES (:user_id, :overlap_policy, :time_zone, :working_hours, :out_of_office, :availability_policy, :digest)
+ON CONFLICT (user_id) DO UPDATE SET overlap_policy = EXCLUDED.overlap_policy, time_zone = EXCLUDED.time_zone,
+	working_hours = EXCLUDED.working_hours, out_of_office = EXCLUDED.out_of_office,
+	availability_policy = EXCLUDED.availability_policy, digest = EXCLUDED.digest
+`
+
+const updateEventsExclusiveSQL = `
+UPDATE events
+SET exclusive = ` + storedEventExclusiveSQL + `
+WHERE user_id = ? AND exclusive <> (` + storedEventExclusiveSQL + `)
+`
+
+// SaveUserSettings сохраняет настройки и в той же транзакции пересчитывает эксклюзивность событий
+// пользователя. Запретить пересечения нельзя, пока у пользователя есть пересекающиеся события:
+// возвращается storage.ErrBusyTime.
+func (s *Storage) SaveUserSettings(ctx context.Context, settings *storage.UserSettings) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExecContext(ctx, saveUserSettingsSQL, settings)
+	if err != nil {
+		return fmt.Errorf("cannot save user settings: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, s.db.Rebind(updateEventsExclusiveSQL), settings.UserID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolationCode && pgErr.ConstraintName == busyTimeConstraint {
+		return storage.ErrBusyTime
+	}
+	if err != nil {
+		return fmt.Errorf("cannot update exclusive events: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListDigestSettings возвращает настройки пользователей, подписанных на сводку событий.
+func (s *Storage) ListDigestSettings(ctx context.Context) ([]*storage.UserSettings, error) {
+	settings := make([]*storage.UserSettings, 0)
+	err := s.db.SelectContext(ctx, &settings, s.db.Rebind(listDigestSettingsSQL))
+	if err != nil {
+		return nil, fmt.Errorf("cannot list digest settings: %w", err)
+	}
+	return settings, nil
+}
+
+// отметка ставится, только если прежняя относится к более раннему периоду.
+const claimDigestSQL = `
+INSERT INTO digest_log (user_id, start_date) VALUES (?, ?)
+ON CONFLICT (user_id) DO UPDATE SET start_date = EXCLUDED.start_date WHERE digest_log.start_date < EXCLUDED.start_date
+`
+
+const releaseDigestSQL = `
+DELETE FROM digest_log WHERE user_id = ? AND start_date = ?
+`
+
+// ClaimDigest отмечает, что сводка пользователя за период, начинающийся в startDate, отправляется.
+// Возвращает false, если сводка за этот период уже отмечена, в том числе другим экземпляром планировщика.
+func (s *Storage) ClaimDigest(ctx context.Context, userID uint64, startDate time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, s.db.Rebind(claimDigestSQL), userID, startDate)
+	if err != nil {
+		return false, fmt.Errorf("cannot query context for claiming digest: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get result for claiming digest: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+// ReleaseDigest снимает отметку ClaimDigest, если сводку не удалось отправить, чтобы её отправил следующий запуск.
+func (s *Storage) ReleaseDigest(ctx context.Context, userID uint64, startDate time.Time) error {
+	_, err := s.db.ExecContext(ctx, s.db.Rebind(releaseDigestSQL), userID, startDate)
+	if err != nil {
+		return fmt.Errorf("cannot query context for releasing digest: %w", err)
+	}
+	return nil
+}
+
+const calendarFields = `
+calendar_id, user_id, name, color,
+CAST(EXTRACT(EPOCH FROM notify_before) * 1000000000 as BIGINT) AS notify_before, is_default,
+COALESCE((
+	SELECT json_agg(s.user_id ORDER BY s.user_id) FROM calendar_shares s WHERE s.calendar_id = calendars.calendar_id
+), '[]') AS shared_with
+`
+
+const createCalendarSQL = `
+INSERT INTO calendars (user_id, name, color, notify_before, is_default)
+VALUES (:user_id, :name, :color, :notify_before, :is_default)
+RETURNING calendar_id
+`
+
+func (s *Storage) CreateCalendar(ctx context.Context, calendar *storage.Calendar) (uint64, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareNamedContext(ctx, createCalendarSQL)
+	if err != nil {
+		return 0, fmt.Errorf("cannot prepare context for creating calendar: %w", err)
+	}
+
+	var calendarID uint64
+	err = stmt.GetContext(ctx, &calendarID, calendar)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for creating calendar: %w", err)
+	}
+
+	if err := s.saveCalendarShares(ctx, tx, calendarID, calendar.SharedWith); err != nil {
+		return 0, err
+	}
+	return calendarID, tx.Commit()
+}
+
+const getCalendarSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE calendar_id = ? AND user_id = ?
+`
+
+func (s *Storage) GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error) {
+	var calendar storage.Calendar
+	err := s.db.GetContext(ctx, &calendar, s.db.Rebind(getCalendarSQL), calendarID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrCalendarNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+// параллельные запросы не создадут второй календарь по умолчанию благодаря уникальному индексу.
+const createDefaultCalendarSQL = `
+INSERT INTO calendars (user_id, name, notify_before, is_default)
+VALUES (:user_id, :name, :notify_before, true)
+ON CONFLICT (user_id) WHERE is_default DO NOTHING
+`
+
+const getDefaultCalendarSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE user_id = ? AND is_default
+`
+
+// GetDefaultCalendar возвращает календарь пользователя по умолчанию, создавая его при первом обращении.
+func (s *Storage) GetDefaultCalendar(ctx context.Context, userID uint64) (*storage.Calendar, error) {
+	var calendar storage.Calendar
+	err := s.db.GetContext(ctx, &calendar, s.db.Rebind(getDefaultCalendarSQL), userID)
+	if err == nil {
+		return &calendar, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cannot query context for getting default calendar: %w", err)
+	}
+
+	_, err = s.db.NamedExecContext(ctx, createDefaultCalendarSQL, storage.NewDefaultCalendar(userID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for creating default calendar: %w", err)
+	}
+
+	err = s.db.GetContext(ctx, &calendar, s.db.Rebind(getDefaultCalendarSQL), userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting default calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+const updateCalendarSQL = `
+UPDATE calendars
+SET name = :name,
+	color = :color,
+	notify_before = :notify_before
+WHERE calendar_id = :calendar_id AND user_id = :user_id
+`
+
+// UpdateCalendar меняет название, цвет, напоминание по умолчанию и пользователей, которым открыт календарь;
+// признак календаря по умолчанию не меняется.
+func (s *Storage) UpdateCalendar(ctx context.Context, calendar *storage.Calendar) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.NamedExecContext(ctx, updateCalendarSQL, calendar)
+	if err != nil {
+		return fmt.Errorf("cannot query context for updating calendar: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrCalendarNotFound
+	}
+
+	if err := s.saveCalendarShares(ctx, tx, calendar.ID, calendar.SharedWith); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const deleteCalendarSQL = `
+DELETE FROM calendars
+WHERE calendar_id = ? AND user_id = ? AND NOT is_default
+`
+
+// DeleteCalendar удаляет пустой календарь; календарь с событиями (в том числе в корзине)
+// не даёт удалить внешний ключ events_calendar_fk, календарь по умолчанию удалить нельзя.
+func (s *Storage) DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error {
+	calendar, err := s.GetCalendar(ctx, userID, calendarID)
+	if err != nil {
+		return err
+	}
+	if calendar.IsDefault {
+		return storage.ErrDefaultCalendar
+	}
+
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(deleteCalendarSQL), calendarID, userID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return storage.ErrCalendarNotEmpty
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting calendar: %w", err)
+	}
+	return nil
+}
+
+const selectCalendarsSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE user_id = ?
+ORDER BY calendar_id
+`
+
+func (s *Storage) ListCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	calendars := make([]*storage.Calendar, 0)
+	err := s.db.SelectContext(ctx, &calendars, s.db.Rebind(selectCalendarsSQL), userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+const selectSharedCalendarsSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = ?)
+ORDER BY calendar_id
+`
+
+// ListSharedCalendars возвращает календари других пользователей, открытые пользователю.
+func (s *Storage) ListSharedCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	calendars := make([]*storage.Calendar, 0)
+	err := s.db.SelectContext(ctx, &calendars, s.db.Rebind(selectSharedCalendarsSQL), userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing shared calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+const deleteCalendarSharesSQL = `
+DELETE FROM calendar_shares WHERE calendar_id = ?
+`
+
+const createCalendarShareSQL = `
+INSERT INTO calendar_shares (calendar_id, user_id) VALUES (?, ?)
+`
+
+// заменяет пользователей, которым открыт календарь.
+func (s *Storage) saveCalendarShares(ctx context.Context, tx *sqlx.Tx, calendarID uint64, shares storage.CalendarShares) error {
+	_, err := tx.ExecContext(ctx, s.db.Rebind(deleteCalendarSharesSQL), calendarID)
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting calendar shares: %w", err)
+	}
+
+	for _, userID := range shares {
+		_, err = tx.ExecContext(ctx, s.db.Rebind(createCalendarShareSQL), calendarID, userID)
+		if err != nil {
+			return fmt.Errorf("cannot query context for creating calendar share: %w", err)
+		}
+	}
+	return nil
+}
+
+const selectBusyResourcesSQL = `
+SELECT DISTINCT resource_id
+FROM event_resources
+WHERE resource_id IN (?) AND event_id <> ? AND active AND start_date <= ? AND end_date >= ?
+ORDER BY resource_id
+`
+
+const deleteEventResourcesSQL = `
+DELETE FROM event_resources WHERE event_id = ?
+`
+
+const createEventResourceSQL = `
+INSERT INTO event_resources (event_id, resource_id, start_date, end_date) VALUES (?, ?, ?, ?)
+`
+
+const setEventResourcesActiveSQL = `
+UPDATE event_resources SET active = ? WHERE event_id = ?
+`
+
+// проверяет, что ресурсы события не забронированы другими событиями на это время;
+// ресурсы бронируются эксклюзивно независимо от прозрачности события и политики пользователя.
+func (s *Storage) checkResources(ctx context.Context, tx *sqlx.Tx, event *storage.Event) error {
+	resourceIDs, err := s.listBusyResources(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+	if len(resourceIDs) > 0 {
+		return &storage.ResourceBusyError{ResourceIDs: resourceIDs}
+	}
+	return nil
+}
+
+func (s *Storage) listBusyResources(ctx context.Context, q sqlx.QueryerContext, event *storage.Event) ([]uint64, error) {
+	resourceIDs := make([]uint64, 0)
+	if len(event.ResourceIDs) == 0 {
+		return resourceIDs, nil
+	}
+
+	query, args, err := sqlx.In(
+		selectBusyResourcesSQL,
+		[]uint64(event.ResourceIDs), event.ID, event.EndDate, event.StartDate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare query for checking resources: %w", err)
+	}
+
+	err = sqlx.SelectContext(ctx, q, &resourceIDs, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check resources: %w", err)
+	}
+	return resourceIDs, nil
+}
+
+// заменяет брони события; время броней совпадает со временем события.
+func (s *Storage) saveEventResources(ctx context.Context, tx *sqlx.Tx, eventID uint64, event *storage.Event) error {
+	_, err := tx.ExecContext(ctx, s.db.Rebind(deleteEventResourcesSQL), eventID)
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting event resources: %w", err)
+	}
+
+	for _, resourceID := range event.ResourceIDs {
+		_, err = tx.ExecContext(
+			ctx, s.db.Rebind(createEventResourceSQL),
+			eventID, resourceID, event.StartDate, event.EndDate,
+		)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return storage.ErrResourceNotFound
+		}
+		if err != nil {
+			return s.convertBusyTimeError(ctx, event, fmt.Errorf("cannot query context for creating event resource: %w", err))
+		}
+	}
+	return nil
+}
+
+const resourceFields = `
+resource_id, user_id, name, kind, capacity, attributes
+`
+
+const createResourceSQL = `
+INSERT INTO resources (user_id, name, kind, capacity, attributes)
+VALUES (:user_id, :name, :kind, :capacity, :attributes)
+RETURNING resource_id
+`
+
+func (s *Storage) CreateResource(ctx context.Context, resource *storage.Resource) (uint64, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, createResourceSQL)
+	if err != nil {
+		return 0, fmt.Errorf("cannot prepare context for creating resource: %w", err)
+	}
+
+	var resourceID uint64
+	err = stmt.GetContext(ctx, &resourceID, resource)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for creating resource: %w", err)
+	}
+	return resourceID, nil
+}
+
+const getResourceSQL = `
+SELECT ` + resourceFields + `
+FROM resources
+WHERE resource_id = ?
+`
+
+func (s *Storage) GetResource(ctx context.Context, resourceID uint64) (*storage.Resource, error) {
+	var resource storage.Resource
+	err := s.db.GetContext(ctx, &resource, s.db.Rebind(getResourceSQL), resourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting resource: %w", err)
+	}
+	return &resource, nil
+}
+
+const updateResourceSQL = `
+UPDATE resources
+SET name = :name,
+	kind = :kind,
+	capacity = :capacity,
+	attributes = :attributes
+WHERE resource_id = :resource_id
+`
+
+func (s *Storage) UpdateResource(ctx context.Context, resource *storage.Resource) error {
+	result, err := s.db.NamedExecContext(ctx, updateResourceSQL, resource)
+	if err != nil {
+		return fmt.Errorf("cannot query context for updating resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrResourceNotFound
+	}
+	return nil
+}
+
+const deleteResourceSQL = `
+DELETE FROM resources
+WHERE resource_id = ?
+`
+
+// DeleteResource удаляет ресурс без броней; ресурс, забронированный событиями (в том числе в корзине),
+// не даёт удалить внешний ключ event_resources_resource_fk.
+func (s *Storage) DeleteResource(ctx context.Context, resourceID uint64) error {
+	result, err := s.db.ExecContext(ctx, s.db.Rebind(deleteResourceSQL), resourceID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return storage.ErrResourceInUse
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrResourceNotFound
+	}
+	return nil
+}
+
+const selectResourcesSQL = `
+SELECT ` + resourceFields + `
+FROM resources
+WHERE (:kind = '' OR kind = :kind) AND capacity >= :min_capacity
+ORDER BY resource_id
+`
+
+func (s *Storage) ListResources(ctx context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error) {
+	stmt, err := s.db.PrepareNamedContext(ctx, selectResourcesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare context for listing resources: %w", err)
+	}
+
+	resources := make([]*storage.Resource, 0)
+	err = stmt.SelectContext(ctx, &resources, map[string]interface{}{
+		"kind":         filter.Kind,
+		"min_capacity": filter.MinCapacity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing resources: %w", err)
+	}
+	return resources, nil
+}
+
+const selectResourceBusySQL = `
+SELECT start_date, end_date
+FROM event_resources
+WHERE resource_id = ? AND active AND start_date < ? AND end_date >= ?
+ORDER BY start_date, end_date
+`
+
+// ListResourceBusy возвращает брони ресурса, пересекающиеся с периодом.
+func (s *Storage) ListResourceBusy(
+	ctx context.Context,
+	resourceID uint64,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.BusyInterval, error) {
+	intervals := make([]*storage.BusyInterval, 0)
+	err := s.db.SelectContext(
+		ctx, &intervals, s.db.Rebind(selectResourceBusySQL),
+		resourceID, endDateExclusive, startDate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing resource busy time: %w", err)
+	}
+	return intervals, nil
+}
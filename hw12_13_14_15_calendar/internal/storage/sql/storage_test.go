@@ -0,0 +1,41 @@
+//go:build integration
+// +build integration
+
+package sqlstorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/migrator"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newStorage(t)
+	})
+}
+
+// создаёт хранилище в отдельной схеме БД и применяет к ней миграции.
+func newStorage(t *testing.T) *Storage {
+	t.Helper()
+	ctx := context.Background()
+	dsn := storagetest.PostgresDSN(t)
+
+	m := migrator.New("pgx", dsn)
+	require.NoError(t, m.Connect(ctx))
+	_, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.Close(ctx))
+
+	s := New("pgx", dsn)
+	require.NoError(t, s.Connect(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, s.Close(ctx))
+	})
+	return s
+}
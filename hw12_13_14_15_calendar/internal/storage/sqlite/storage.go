@@ -0,0 +1,1330 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	_ "modernc.org/sqlite" // for sqlite
+)
+
+// DriverName - имя драйвера sqlite.
+const DriverName = "sqlite"
+
+// Storage - хранилище событий в файле SQLite.
+// Даты хранятся в виде unix-времени в наносекундах, т.к. в SQLite нет типа для даты со временем.
+type Storage struct {
+	db  *sqlx.DB
+	dsn string
+}
+
+func New(dsn string) *Storage {
+	return &Storage{
+		dsn: dsn,
+	}
+}
+
+// параметры соединения: ожидание блокировки вместо немедленной ошибки SQLITE_BUSY,
+// журнал WAL для чтения во время записи и проверка внешних ключей (по умолчанию выключена)
+var pragmas = []string{
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+	"_pragma=foreign_keys(1)",
+}
+
+// withPragmas добавляет параметры соединения к DSN.
+func withPragmas(dsn string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + strings.Join(pragmas, "&")
+}
+
+func (s *Storage) Connect(ctx context.Context) (err error) {
+	s.db, err = sqlx.ConnectContext(ctx, DriverName, withPragmas(s.dsn))
+	if err != nil {
+		return
+	}
+	// SQLite не поддерживает параллельную запись, поэтому все запросы процесса выполняются
+	// через одно соединение; это же исключает гонку при проверке пересечения событий
+	s.db.SetMaxOpenConns(1)
+	return
+}
+
+func (s *Storage) Close(_ context.Context) error {
+	return s.db.Close()
+}
+
+type eventRow struct {
+	ID           uint64               `db:"event_id"`
+	Title        string               `db:"title"`
+	StartDate    int64                `db:"start_date"`
+	EndDate      int64                `db:"end_date"`
+	Description  string               `db:"description"`
+	UserID       uint64               `db:"user_id"`
+	CalendarID   uint64               `db:"calendar_id"`
+	NotifyBefore time.Duration        `db:"notify_before"`
+	NotifyStatus storage.NotifyStatus `db:"notify_status"`
+	Transparent  bool                 `db:"transparent"`
+	DeletedAt    sql.NullInt64        `db:"deleted_at"`
+	Location     string               `db:"location"`
+	Latitude     *float64             `db:"latitude"`
+	Longitude    *float64             `db:"longitude"`
+	Category     string               `db:"category"`
+	Tags         storage.Tags         `db:"tags"`
+	Attachments  storage.Attachments  `db:"attachments"`
+	ResourceIDs  storage.ResourceIDs  `db:"resource_ids"`
+}
+
+func newEventRow(event *storage.Event) *eventRow {
+	row := &eventRow{
+		ID:           event.ID,
+		Title:        event.Title,
+		StartDate:    toUnix(event.StartDate),
+		EndDate:      toUnix(event.EndDate),
+		Description:  event.Description,
+		UserID:       event.UserID,
+		CalendarID:   event.CalendarID,
+		NotifyBefore: event.NotifyBefore,
+		NotifyStatus: event.NotifyStatus,
+		Transparent:  event.Transparent,
+		Location:     event.Location,
+		Latitude:     event.Latitude,
+		Longitude:    event.Longitude,
+		Category:     event.Category,
+		Tags:         event.Tags,
+		Attachments:  event.Attachments,
+		ResourceIDs:  event.ResourceIDs,
+	}
+	if event.DeletedAt != nil {
+		row.DeletedAt = sql.NullInt64{Int64: toUnix(*event.DeletedAt), Valid: true}
+	}
+	return row
+}
+
+func (r *eventRow) toEvent() *storage.Event {
+	event := &storage.Event{
+		ID:           r.ID,
+		Title:        r.Title,
+		StartDate:    fromUnix(r.StartDate),
+		EndDate:      fromUnix(r.EndDate),
+		Description:  r.Description,
+		UserID:       r.UserID,
+		CalendarID:   r.CalendarID,
+		NotifyBefore: r.NotifyBefore,
+		NotifyStatus: r.NotifyStatus,
+		Transparent:  r.Transparent,
+		Location:     r.Location,
+		Latitude:     r.Latitude,
+		Longitude:    r.Longitude,
+		Category:     r.Category,
+		Tags:         r.Tags,
+		Attachments:  r.Attachments,
+		ResourceIDs:  r.ResourceIDs,
+	}
+	if r.DeletedAt.Valid {
+		deletedAt := fromUnix(r.DeletedAt.Int64)
+		event.DeletedAt = &deletedAt
+	}
+	return event
+}
+
+func toUnix(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+func fromUnix(t int64) time.Time {
+	return time.Unix(0, t)
+}
+
+func toEvents(rows []*eventRow) []*storage.Event {
+	events := make([]*storage.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, row.toEvent())
+	}
+	return events
+}
+
+const eventFields = `
+event_id, title, start_date, end_date, COALESCE(description, '') AS description, user_id,
+COALESCE(calendar_id, 0) AS calendar_id, notify_before, notify_status, transparent, deleted_at,
+location, latitude, longitude, category, tags, attachments,
+(
+	SELECT json_group_array(resource_id) FROM (
+		SELECT resource_id FROM event_resources r WHERE r.event_id = events.event_id ORDER BY resource_id
+	)
+) AS resource_ids
+`
+
+const selectConflictingEventsSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date <= ? AND end_date >= ? AND user_id = ? AND event_id <> ? AND deleted_at IS NULL AND NOT transparent
+ORDER BY start_date, end_date
+`
+
+const createEventSQL = `
+INSERT INTO events (
+	title, start_date, end_date, description, user_id, calendar_id, notify_before, transparent,
+	location, latitude, longitude, category, tags, attachments
+)
+VALUES (
+	:title, :start_date, :end_date, :description, :user_id, :calendar_id, :notify_before, :transparent,
+	:location, :latitude, :longitude, :category, :tags, :attachments
+)
+`
+
+func (s *Storage) Create(ctx context.Context, event *storage.Event) (uint64, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	eventID, err := s.createEvent(ctx, tx, event)
+	if err != nil {
+		return 0, err
+	}
+	return eventID, tx.Commit()
+}
+
+func (s *Storage) createEvent(ctx context.Context, tx *sqlx.Tx, event *storage.Event) (uint64, error) {
+	if err := s.checkBusyTime(ctx, tx, event); err != nil {
+		return 0, err
+	}
+	if err := s.checkResources(ctx, tx, event); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.NamedExecContext(ctx, createEventSQL, newEventRow(event))
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for creating event: %w", err)
+	}
+
+	eventID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("event not created: %w", err)
+	}
+
+	if err := s.saveEventResources(ctx, tx, uint64(eventID), event); err != nil {
+		return 0, err
+	}
+	return uint64(eventID), nil
+}
+
+const deleteExpiredIdempotencyKeysSQL = `
+DELETE FROM idempotency_keys WHERE user_id = ? AND created_at < ?
+`
+
+const getIdempotencyKeySQL = `
+SELECT event_id, request_hash FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND created_at >= ?
+`
+
+const saveIdempotencyKeySQL = `
+INSERT INTO idempotency_keys (user_id, idempotency_key, event_id, request_hash, created_at) VALUES (?, ?, ?, ?, ?)
+`
+
+func (s *Storage) GetIdempotencyKey(ctx context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error) {
+	var idempotencyKey storage.IdempotencyKey
+	err := s.db.GetContext(ctx, &idempotencyKey, getIdempotencyKeySQL, userID, key, toUnix(notBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting idempotency key: %w", err)
+	}
+	return &idempotencyKey, nil
+}
+
+// CreateIdempotent не нуждается в блокировке ключа: запросы процесса выполняются через одно соединение.
+func (s *Storage) CreateIdempotent(
+	ctx context.Context,
+	event *storage.Event,
+	key string,
+	requestHash string,
+	notBefore time.Time,
+) (uint64, bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, deleteExpiredIdempotencyKeysSQL, event.UserID, toUnix(notBefore))
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot query context for deleting expired idempotency keys: %w", err)
+	}
+
+	var idempotencyKey storage.IdempotencyKey
+	err = tx.GetContext(ctx, &idempotencyKey, getIdempotencyKeySQL, event.UserID, key, toUnix(notBefore))
+	if err == nil {
+		if !idempotencyKey.Matches(requestHash) {
+			return 0, false, storage.ErrIdempotencyKeyReused
+		}
+		return idempotencyKey.EventID, true, tx.Commit()
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("cannot query context for getting idempotency key: %w", err)
+	}
+
+	eventID, err := s.createEvent(ctx, tx, event)
+	if err != nil {
+		return 0, false, err
+	}
+
+	_, err = tx.ExecContext(ctx, saveIdempotencyKeySQL, event.UserID, key, eventID, requestHash, toUnix(time.Now()))
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot query context for saving idempotency key: %w", err)
+	}
+	return eventID, false, tx.Commit()
+}
+
+const getEventByIDSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE event_id = ? AND user_id = ? AND deleted_at IS NULL
+`
+
+func (s *Storage) GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	return s.getEvent(ctx, getEventByIDSQL, userID, eventID)
+}
+
+const getSharedEventByIDSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE event_id = ? AND deleted_at IS NULL
+	AND calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = ?)
+`
+
+// GetSharedByID возвращает событие из календаря, открытого пользователю.
+func (s *Storage) GetSharedByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error) {
+	return s.getEvent(ctx, getSharedEventByIDSQL, userID, eventID)
+}
+
+func (s *Storage) getEvent(ctx context.Context, query string, userID uint64, eventID uint64) (*storage.Event, error) {
+	var row eventRow
+	err := s.db.GetContext(ctx, &row, query, eventID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting event by id: %w", err)
+	}
+	return row.toEvent(), nil
+}
+
+const updateEventSQL = `
+UPDATE events
+SET title = :title,
+	start_date = :start_date,
+	end_date = :end_date,
+	description = :description,
+	user_id = :user_id,
+	calendar_id = :calendar_id,
+	notify_before = :notify_before,
+	transparent = :transparent,
+	location = :location,
+	latitude = :latitude,
+	longitude = :longitude,
+	category = :category,
+	tags = :tags,
+	attachments = :attachments
+WHERE event_id = :event_id AND user_id = :user_id AND deleted_at IS NULL
+`
+
+func (s *Storage) Update(ctx context.Context, event *storage.Event) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.checkBusyTime(ctx, tx, event); err != nil {
+		return err
+	}
+	if err := s.checkResources(ctx, tx, event); err != nil {
+		return err
+	}
+
+	result, err := tx.NamedExecContext(ctx, updateEventSQL, newEventRow(event))
+	if err != nil {
+		return fmt.Errorf("cannot query context for updating event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrEventNotFound
+	}
+
+	if err := s.saveEventResources(ctx, tx, event.ID, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const deleteEventSQL = `
+UPDATE events
+SET deleted_at = ?
+WHERE event_id = ? AND user_id = ? AND deleted_at IS NULL
+`
+
+// Delete переносит событие в корзину и снимает брони его ресурсов; при восстановлении брони возвращаются.
+func (s *Storage) Delete(ctx context.Context, userID uint64, eventID uint64) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, deleteEventSQL, toUnix(time.Now()), eventID, userID)
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrEventNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, setEventResourcesActiveSQL, false, eventID); err != nil {
+		return fmt.Errorf("cannot query context for releasing event resources: %w", err)
+	}
+	return tx.Commit()
+}
+
+const getDeletedEventByIDSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE event_id = ? AND user_id = ? AND deleted_at IS NOT NULL
+`
+
+const restoreEventSQL = `
+UPDATE events
+SET deleted_at = NULL
+WHERE event_id = ? AND user_id = ? AND deleted_at IS NOT NULL
+`
+
+func (s *Storage) Restore(ctx context.Context, userID uint64, eventID uint64) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var row eventRow
+	err = tx.GetContext(ctx, &row, getDeletedEventByIDSQL, eventID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrEventNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query context for getting deleted event by id: %w", err)
+	}
+
+	event := row.toEvent()
+	if err := s.checkBusyTime(ctx, tx, event); err != nil {
+		return err
+	}
+	if err := s.checkResources(ctx, tx, event); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, restoreEventSQL, eventID, userID)
+	if err != nil {
+		return fmt.Errorf("cannot query context for restoring event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, setEventResourcesActiveSQL, true, eventID); err != nil {
+		return fmt.Errorf("cannot query context for reserving event resources: %w", err)
+	}
+	return tx.Commit()
+}
+
+const selectDeletedEventsSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE user_id = ? AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, event_id
+`
+
+func (s *Storage) ListDeleted(ctx context.Context, userID uint64) ([]*storage.Event, error) {
+	rows := make([]*eventRow, 0)
+	err := s.db.SelectContext(ctx, &rows, selectDeletedEventsSQL, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing deleted events: %w", err)
+	}
+	return toEvents(rows), nil
+}
+
+const countEventsByUserSQL = `
+SELECT COUNT(*)
+FROM events
+WHERE user_id = ?
+`
+
+func (s *Storage) CountByUser(ctx context.Context, userID uint64) (int, error) {
+	var count int
+	err := s.db.GetContext(ctx, &count, countEventsByUserSQL, userID)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for counting events: %w", err)
+	}
+	return count, nil
+}
+
+const selectEventsByDatesSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date < ? AND end_date >= ? AND user_id = ? AND deleted_at IS NULL
+`
+
+const selectSharedEventsByDatesSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date < ? AND end_date >= ? AND deleted_at IS NULL
+	AND calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = ?)
+`
+
+// ListForPeriod возвращает события пользователя за период, удовлетворяющие фильтру.
+// Условия фильтра добавляются к запросу только если заданы; событие должно содержать все теги фильтра.
+func (s *Storage) ListForPeriod(
+	ctx context.Context,
+	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	return s.listForPeriod(ctx, selectEventsByDatesSQL, userID, filter, startDate, endDateExclusive)
+}
+
+// ListSharedForPeriod возвращает события из календарей, открытых пользователю, за период.
+func (s *Storage) ListSharedForPeriod(
+	ctx context.Context,
+	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	return s.listForPeriod(ctx, selectSharedEventsByDatesSQL, userID, filter, startDate, endDateExclusive)
+}
+
+func (s *Storage) listForPeriod(
+	ctx context.Context,
+	query string,
+	userID uint64,
+	filter storage.EventFilter,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.Event, error) {
+	args := []any{toUnix(endDateExclusive), toUnix(startDate), userID}
+	if len(filter.CalendarIDs) > 0 {
+		query += " AND calendar_id IN (?)"
+		args = append(args, filter.CalendarIDs)
+	}
+	if filter.Category != "" {
+		query += " AND category = ?"
+		args = append(args, filter.Category)
+	}
+	if len(filter.Tags) > 0 {
+		query += " AND (SELECT COUNT(DISTINCT value) FROM json_each(CAST(events.tags AS TEXT)) WHERE value IN (?)) = ?"
+		args = append(args, filter.Tags, countDistinct(filter.Tags))
+	}
+	query += " ORDER BY start_date, end_date"
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare query for listing events: %w", err)
+	}
+
+	rows := make([]*eventRow, 0)
+	err = s.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing events: %w", err)
+	}
+	return toEvents(rows), nil
+}
+
+func countDistinct(values []string) int {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return len(set)
+}
+
+const selectEventsForNotifySQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date - notify_before BETWEEN ? AND ? AND notify_status = ?
+	AND notify_before > 0 AND deleted_at IS NULL
+ORDER BY start_date - notify_before, start_date, end_date
+`
+
+func (s *Storage) ListForNotify(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	rows := make([]*eventRow, 0)
+	err := s.db.SelectContext(
+		ctx, &rows, selectEventsForNotifySQL,
+		toUnix(startNotifyDate), toUnix(endNotifyDate), storage.NotNotified,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing events for notify: %w", err)
+	}
+	return toEvents(rows), nil
+}
+
+const selectEventsByNotifyDateSQL = `
+SELECT ` + eventFields + `
+FROM events
+WHERE start_date - notify_before BETWEEN ? AND ? AND notify_before > 0 AND deleted_at IS NULL
+ORDER BY start_date - notify_before, start_date, end_date
+`
+
+func (s *Storage) ListByNotifyDate(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error) {
+	rows := make([]*eventRow, 0)
+	err := s.db.SelectContext(ctx, &rows, selectEventsByNotifyDateSQL, toUnix(startNotifyDate), toUnix(endNotifyDate))
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing events by notify date: %w", err)
+	}
+	return toEvents(rows), nil
+}
+
+const setNotifyStatusSQL = `
+UPDATE events
+SET notify_status = ?
+WHERE event_id IN (?)
+`
+
+func (s *Storage) SetNotifyStatus(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(setNotifyStatusSQL, notifyStatus, eventIDs)
+	if err != nil {
+		return fmt.Errorf("cannot prepare query for setting notify status of events: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+const deleteEventsByEndDateSQL = `
+DELETE FROM events
+WHERE end_date <= ?
+`
+
+func (s *Storage) DeleteByEndDate(ctx context.Context, maxEndDate time.Time) error {
+	_, err := s.db.ExecContext(ctx, deleteEventsByEndDateSQL, toUnix(maxEndDate))
+	return err
+}
+
+const deleteEventsByDeletedDateSQL = `
+DELETE FROM events
+WHERE deleted_at <= ?
+`
+
+func (s *Storage) DeleteByDeletedDate(ctx context.Context, maxDeletedDate time.Time) error {
+	_, err := s.db.ExecContext(ctx, deleteEventsByDeletedDateSQL, toUnix(maxDeletedDate))
+	return err
+}
+
+type auditRecordRow struct {
+	ID        uint64               `db:"audit_id"`
+	EventID   uint64               `db:"event_id"`
+	UserID    uint64               `db:"user_id"`
+	ActorID   uint64               `db:"actor_id"`
+	Action    storage.AuditAction  `db:"action"`
+	Changes   storage.AuditChanges `db:"changes"`
+	Source    string               `db:"source"`
+	CreatedAt int64                `db:"created_at"`
+}
+
+const createAuditRecordSQL = `
+INSERT INTO event_audit (event_id, user_id, actor_id, action, changes, source, created_at)
+VALUES (:event_id, :user_id, :actor_id, :action, :changes, :source, :created_at)
+`
+
+func (s *Storage) CreateAuditRecord(ctx context.Context, record *storage.AuditRecord) error {
+	result, err := s.db.NamedExecContext(ctx, createAuditRecordSQL, &auditRecordRow{
+		EventID:   record.EventID,
+		UserID:    record.UserID,
+		ActorID:   record.ActorID,
+		Action:    record.Action,
+		Changes:   record.Changes,
+		Source:    record.Source,
+		CreatedAt: toUnix(record.CreatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot query context for creating audit record: %w", err)
+	}
+
+	auditID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("audit record not created: %w", err)
+	}
+	record.ID = uint64(auditID)
+	return nil
+}
+
+const selectAuditRecordsSQL = `
+SELECT audit_id, event_id, user_id, actor_id, action, changes, COALESCE(source, '') AS source, created_at
+FROM event_audit
+WHERE event_id = ? AND user_id = ?
+ORDER BY audit_id
+`
+
+func (s *Storage) ListAuditRecords(ctx context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error) {
+	rows := make([]*auditRecordRow, 0)
+	err := s.db.SelectContext(ctx, &rows, selectAuditRecordsSQL, eventID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing audit records: %w", err)
+	}
+
+	records := make([]*storage.AuditRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, &storage.AuditRecord{
+			ID:        row.ID,
+			EventID:   row.EventID,
+			UserID:    row.UserID,
+			ActorID:   row.ActorID,
+			Action:    row.Action,
+			Changes:   row.Changes,
+			Source:    row.Source,
+			CreatedAt: fromUnix(row.CreatedAt),
+		})
+	}
+	return records, nil
+}
+
+func (s *Storage) ListConflicts(ctx context.Context, event *storage.Event) ([]*storage.Event, error) {
+	return s.listConflicts(ctx, s.db, event)
+}
+
+// проверяет пересечение события с другими с учётом политики пользователя.
+func (s *Storage) checkBusyTime(ctx context.Context, tx *sqlx.Tx, event *storage.Event) error {
+	settings, err := s.getUserSettings(ctx, tx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if settings.OverlapPolicy != storage.OverlapReject {
+		return nil
+	}
+
+	conflicts, err := s.listConflicts(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &storage.BusyTimeError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+func (s *Storage) listConflicts(ctx context.Context, q sqlx.QueryerContext, event *storage.Event) ([]*storage.Event, error) {
+	if event.Transparent {
+		return make([]*storage.Event, 0), nil
+	}
+
+	rows := make([]*eventRow, 0)
+	err := sqlx.SelectContext(
+		ctx, q, &rows, selectConflictingEventsSQL,
+		toUnix(event.EndDate), toUnix(event.StartDate), event.UserID, event.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check events: %w", err)
+	}
+	return toEvents(rows), nil
+}
+
+func (s *Storage) GetUserSettings(ctx context.Context, userID uint64) (*storage.UserSettings, error) {
+	return s.getUserSettings(ctx, s.db, userID)
+}
+
+func (s *Storage) getUserSettings(ctx context.Context, q sqlx.QueryerContext, userID uint64) (*storage.UserSettings, error) {
+	settings := storage.DefaultUserSettings(userID)
+	err := sqlx.GetContext(ctx, q, settings, getUserSettingsSQL, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cannot get user settings: %w", err)
+	}
+	return settings, nil
+}
+
+const userSettingsFields = `
+user_id, overlap_policy, time_zone, working_hours, out_of_office, availability_policy, digest
+`
+
+const getUserSettingsSQL = `
+SELECT ` + userSettingsFields + ` FROM user_settings WHERE user_id = ?
+`
+
+const listDigestSettingsSQL = `
+SELECT ` + userSettingsFields + ` FROM user_settings WHERE digest <> 'NONE' ORDER BY user_id
+`
+
+const saveUserSettingsSQL = `
+INSERT INTO user_settings (user_id, overlap_policy, time_zone, working_hours, out_of_office, availability_policy, digest)
+VALUES (:user_id, :overlap_policy, :time_zone, :working_hours, :out_of_office, :availability_policy, :digest)
+ON CONFLICT (user_id) DO UPDATE SET overlap_policy = excluded.overlap_policy, time_zone = excluded.time_zone,
+	working_hours = excluded.working_hours, out_of_office = excluded.out_of_office,
+	availability_policy = excluded.availability_policy, digest = excluded.digest
+`
+
+const hasOverlappingEventsSQL = `
+SELECT EXISTS (
+	SELECT 1
+	FROM events a
+	JOIN events b ON b.user_id = a.user_id AND b.event_id > a.event_id
+	WHERE a.user_id = ? AND a.deleted_at IS NULL AND b.deleted_at IS NULL AND NOT a.transparent AND NOT b.transparent
+		AND a.start_date <= b.end_date AND a.end_date >= b.start_date
+)
+`
+
+// SaveUserSettings сохраняет настройки. Запретить пересечения нельзя, пока у пользователя
+// есть пересекающиеся события: возвращается storage.ErrBusyTime.
+func (s *Storage) SaveUserSettings(ctx context.Context, settings *storage.UserSettings) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if settings.OverlapPolicy == storage.OverlapReject {
+		var overlaps bool
+		if err := tx.GetContext(ctx, &overlaps, hasOverlappingEventsSQL, settings.UserID); err != nil {
+			return fmt.Errorf("cannot check overlapping events: %w", err)
+		}
+		if overlaps {
+			return storage.ErrBusyTime
+		}
+	}
+
+	_, err = tx.NamedExecContext(ctx, saveUserSettingsSQL, settings)
+	if err != nil {
+		return fmt.Errorf("cannot save user settings: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListDigestSettings возвращает настройки пользователей, подписанных на сводку событий.
+func (s *Storage) ListDigestSettings(ctx context.Context) ([]*storage.UserSettings, error) {
+	settings := make([]*storage.UserSettings, 0)
+	err := s.db.SelectContext(ctx, &settings, listDigestSettingsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list digest settings: %w", err)
+	}
+	return settings, nil
+}
+
+// отметка ставится, только если прежняя относится к более раннему периоду.
+const claimDigestSQL = `
+INSERT INTO digest_log (user_id, start_date) VALUES (?, ?)
+ON CONFLICT (user_id) DO UPDATE SET start_date = excluded.start_date WHERE digest_log.start_date < excluded.start_date
+`
+
+const releaseDigestSQL = `
+DELETE FROM digest_log WHERE user_id = ? AND start_date = ?
+`
+
+// ClaimDigest отмечает, что сводка пользователя за период, начинающийся в startDate, отправляется.
+// Возвращает false, если сводка за этот период уже отмечена, в том числе другим экземпляром планировщика.
+func (s *Storage) ClaimDigest(ctx context.Context, userID uint64, startDate time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, claimDigestSQL, userID, toUnix(startDate))
+	if err != nil {
+		return false, fmt.Errorf("cannot query context for claiming digest: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get result for claiming digest: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+// ReleaseDigest снимает отметку ClaimDigest, если сводку не удалось отправить, чтобы её отправил следующий запуск.
+func (s *Storage) ReleaseDigest(ctx context.Context, userID uint64, startDate time.Time) error {
+	_, err := s.db.ExecContext(ctx, releaseDigestSQL, userID, toUnix(startDate))
+	if err != nil {
+		return fmt.Errorf("cannot query context for releasing digest: %w", err)
+	}
+	return nil
+}
+
+const calendarFields = `
+calendar_id, user_id, name, color, notify_before, is_default,
+(
+	SELECT json_group_array(user_id) FROM (
+		SELECT user_id FROM calendar_shares s WHERE s.calendar_id = calendars.calendar_id ORDER BY user_id
+	)
+) AS shared_with
+`
+
+const createCalendarSQL = `
+INSERT INTO calendars (user_id, name, color, notify_before, is_default)
+VALUES (:user_id, :name, :color, :notify_before, :is_default)
+`
+
+func (s *Storage) CreateCalendar(ctx context.Context, calendar *storage.Calendar) (uint64, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.NamedExecContext(ctx, createCalendarSQL, calendar)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for creating calendar: %w", err)
+	}
+
+	calendarID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("calendar not created: %w", err)
+	}
+
+	if err := s.saveCalendarShares(ctx, tx, uint64(calendarID), calendar.SharedWith); err != nil {
+		return 0, err
+	}
+	return uint64(calendarID), tx.Commit()
+}
+
+const getCalendarSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE calendar_id = ? AND user_id = ?
+`
+
+func (s *Storage) GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error) {
+	var calendar storage.Calendar
+	err := s.db.GetContext(ctx, &calendar, getCalendarSQL, calendarID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrCalendarNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+const createDefaultCalendarSQL = `
+INSERT INTO calendars (user_id, name, notify_before, is_default)
+VALUES (:user_id, :name, :notify_before, true)
+ON CONFLICT (user_id) WHERE is_default DO NOTHING
+`
+
+const getDefaultCalendarSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE user_id = ? AND is_default
+`
+
+// GetDefaultCalendar возвращает календарь пользователя по умолчанию, создавая его при первом обращении.
+func (s *Storage) GetDefaultCalendar(ctx context.Context, userID uint64) (*storage.Calendar, error) {
+	var calendar storage.Calendar
+	err := s.db.GetContext(ctx, &calendar, getDefaultCalendarSQL, userID)
+	if err == nil {
+		return &calendar, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cannot query context for getting default calendar: %w", err)
+	}
+
+	_, err = s.db.NamedExecContext(ctx, createDefaultCalendarSQL, storage.NewDefaultCalendar(userID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for creating default calendar: %w", err)
+	}
+
+	err = s.db.GetContext(ctx, &calendar, getDefaultCalendarSQL, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting default calendar: %w", err)
+	}
+	return &calendar, nil
+}
+
+const updateCalendarSQL = `
+UPDATE calendars
+SET name = :name,
+	color = :color,
+	notify_before = :notify_before
+WHERE calendar_id = :calendar_id AND user_id = :user_id
+`
+
+// UpdateCalendar меняет название, цвет, напоминание по умолчанию и пользователей, которым открыт календарь;
+// признак календаря по умолчанию не меняется.
+func (s *Storage) UpdateCalendar(ctx context.Context, calendar *storage.Calendar) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.NamedExecContext(ctx, updateCalendarSQL, calendar)
+	if err != nil {
+		return fmt.Errorf("cannot query context for updating calendar: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrCalendarNotFound
+	}
+
+	if err := s.saveCalendarShares(ctx, tx, calendar.ID, calendar.SharedWith); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const countEventsByCalendarSQL = `
+SELECT COUNT(*)
+FROM events
+WHERE calendar_id = ?
+`
+
+const deleteCalendarSQL = `
+DELETE FROM calendars
+WHERE calendar_id = ? AND user_id = ?
+`
+
+// DeleteCalendar удаляет пустой календарь; календарь с событиями (в том числе в корзине)
+// и календарь по умолчанию удалить нельзя.
+func (s *Storage) DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var calendar storage.Calendar
+	err = tx.GetContext(ctx, &calendar, getCalendarSQL, calendarID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrCalendarNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("cannot query context for getting calendar: %w", err)
+	}
+	if calendar.IsDefault {
+		return storage.ErrDefaultCalendar
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, countEventsByCalendarSQL, calendarID); err != nil {
+		return fmt.Errorf("cannot query context for counting events of calendar: %w", err)
+	}
+	if count > 0 {
+		return storage.ErrCalendarNotEmpty
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteCalendarSQL, calendarID, userID); err != nil {
+		return fmt.Errorf("cannot query context for deleting calendar: %w", err)
+	}
+	return tx.Commit()
+}
+
+const selectCalendarsSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE user_id = ?
+ORDER BY calendar_id
+`
+
+func (s *Storage) ListCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	calendars := make([]*storage.Calendar, 0)
+	err := s.db.SelectContext(ctx, &calendars, selectCalendarsSQL, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+const selectSharedCalendarsSQL = `
+SELECT ` + calendarFields + `
+FROM calendars
+WHERE calendar_id IN (SELECT calendar_id FROM calendar_shares WHERE user_id = ?)
+ORDER BY calendar_id
+`
+
+// ListSharedCalendars возвращает календари других пользователей, открытые пользователю.
+func (s *Storage) ListSharedCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error) {
+	calendars := make([]*storage.Calendar, 0)
+	err := s.db.SelectContext(ctx, &calendars, selectSharedCalendarsSQL, userID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing shared calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+const deleteCalendarSharesSQL = `
+DELETE FROM calendar_shares WHERE calendar_id = ?
+`
+
+const createCalendarShareSQL = `
+INSERT INTO calendar_shares (calendar_id, user_id) VALUES (?, ?)
+`
+
+// заменяет пользователей, которым открыт календарь.
+func (s *Storage) saveCalendarShares(ctx context.Context, tx *sqlx.Tx, calendarID uint64, shares storage.CalendarShares) error {
+	if _, err := tx.ExecContext(ctx, deleteCalendarSharesSQL, calendarID); err != nil {
+		return fmt.Errorf("cannot query context for deleting calendar shares: %w", err)
+	}
+
+	for _, userID := range shares {
+		if _, err := tx.ExecContext(ctx, createCalendarShareSQL, calendarID, userID); err != nil {
+			return fmt.Errorf("cannot query context for creating calendar share: %w", err)
+		}
+	}
+	return nil
+}
+
+const selectBusyResourcesSQL = `
+SELECT DISTINCT resource_id
+FROM event_resources
+WHERE resource_id IN (?) AND event_id <> ? AND active AND start_date <= ? AND end_date >= ?
+ORDER BY resource_id
+`
+
+const deleteEventResourcesSQL = `
+DELETE FROM event_resources WHERE event_id = ?
+`
+
+const createEventResourceSQL = `
+INSERT INTO event_resources (event_id, resource_id, start_date, end_date) VALUES (?, ?, ?, ?)
+`
+
+const setEventResourcesActiveSQL = `
+UPDATE event_resources SET active = ? WHERE event_id = ?
+`
+
+// проверяет, что ресурсы события существуют и не забронированы другими событиями на это время;
+// ресурсы бронируются эксклюзивно независимо от прозрачности события и политики пользователя.
+func (s *Storage) checkResources(ctx context.Context, tx *sqlx.Tx, event *storage.Event) error {
+	if len(event.ResourceIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(countResourcesSQL, []uint64(event.ResourceIDs))
+	if err != nil {
+		return fmt.Errorf("cannot prepare query for checking resources: %w", err)
+	}
+	var count int
+	if err := tx.GetContext(ctx, &count, query, args...); err != nil {
+		return fmt.Errorf("cannot check resources: %w", err)
+	}
+	if count < len(event.ResourceIDs) {
+		return storage.ErrResourceNotFound
+	}
+
+	query, args, err = sqlx.In(
+		selectBusyResourcesSQL,
+		[]uint64(event.ResourceIDs), event.ID, toUnix(event.EndDate), toUnix(event.StartDate),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot prepare query for checking resources: %w", err)
+	}
+
+	resourceIDs := make([]uint64, 0)
+	if err := tx.SelectContext(ctx, &resourceIDs, query, args...); err != nil {
+		return fmt.Errorf("cannot check resources: %w", err)
+	}
+	if len(resourceIDs) > 0 {
+		return &storage.ResourceBusyError{ResourceIDs: resourceIDs}
+	}
+	return nil
+}
+
+// заменяет брони события; время броней совпадает со временем события.
+func (s *Storage) saveEventResources(ctx context.Context, tx *sqlx.Tx, eventID uint64, event *storage.Event) error {
+	if _, err := tx.ExecContext(ctx, deleteEventResourcesSQL, eventID); err != nil {
+		return fmt.Errorf("cannot query context for deleting event resources: %w", err)
+	}
+
+	for _, resourceID := range event.ResourceIDs {
+		_, err := tx.ExecContext(
+			ctx, createEventResourceSQL,
+			eventID, resourceID, toUnix(event.StartDate), toUnix(event.EndDate),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query context for creating event resource: %w", err)
+		}
+	}
+	return nil
+}
+
+const resourceFields = `
+resource_id, user_id, name, kind, capacity, attributes
+`
+
+const createResourceSQL = `
+INSERT INTO resources (user_id, name, kind, capacity, attributes)
+VALUES (:user_id, :name, :kind, :capacity, :attributes)
+`
+
+func (s *Storage) CreateResource(ctx context.Context, resource *storage.Resource) (uint64, error) {
+	result, err := s.db.NamedExecContext(ctx, createResourceSQL, resource)
+	if err != nil {
+		return 0, fmt.Errorf("cannot query context for creating resource: %w", err)
+	}
+
+	resourceID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("resource not created: %w", err)
+	}
+	return uint64(resourceID), nil
+}
+
+const getResourceSQL = `
+SELECT ` + resourceFields + `
+FROM resources
+WHERE resource_id = ?
+`
+
+func (s *Storage) GetResource(ctx context.Context, resourceID uint64) (*storage.Resource, error) {
+	var resource storage.Resource
+	err := s.db.GetContext(ctx, &resource, getResourceSQL, resourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for getting resource: %w", err)
+	}
+	return &resource, nil
+}
+
+const updateResourceSQL = `
+UPDATE resources
+SET name = :name,
+	kind = :kind,
+	capacity = :capacity,
+	attributes = :attributes
+WHERE resource_id = :resource_id
+`
+
+func (s *Storage) UpdateResource(ctx context.Context, resource *storage.Resource) error {
+	result, err := s.db.NamedExecContext(ctx, updateResourceSQL, resource)
+	if err != nil {
+		return fmt.Errorf("cannot query context for updating resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrResourceNotFound
+	}
+	return nil
+}
+
+const countResourcesSQL = `
+SELECT COUNT(*)
+FROM resources
+WHERE resource_id IN (?)
+`
+
+const countEventsByResourceSQL = `
+SELECT COUNT(*)
+FROM event_resources
+WHERE resource_id = ?
+`
+
+const deleteResourceSQL = `
+DELETE FROM resources
+WHERE resource_id = ?
+`
+
+// DeleteResource удаляет ресурс без броней; ресурс, забронированный событиями (в том числе в корзине), удалить нельзя.
+func (s *Storage) DeleteResource(ctx context.Context, resourceID uint64) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.GetContext(ctx, &count, countEventsByResourceSQL, resourceID); err != nil {
+		return fmt.Errorf("cannot query context for counting events of resource: %w", err)
+	}
+	if count > 0 {
+		return storage.ErrResourceInUse
+	}
+
+	result, err := tx.ExecContext(ctx, deleteResourceSQL, resourceID)
+	if err != nil {
+		return fmt.Errorf("cannot query context for deleting resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrResourceNotFound
+	}
+	return tx.Commit()
+}
+
+const selectResourcesSQL = `
+SELECT ` + resourceFields + `
+FROM resources
+WHERE (? = '' OR kind = ?) AND capacity >= ?
+ORDER BY resource_id
+`
+
+func (s *Storage) ListResources(ctx context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error) {
+	resources := make([]*storage.Resource, 0)
+	err := s.db.SelectContext(
+		ctx, &resources, selectResourcesSQL,
+		filter.Kind, filter.Kind, filter.MinCapacity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing resources: %w", err)
+	}
+	return resources, nil
+}
+
+type busyIntervalRow struct {
+	StartDate int64 `db:"start_date"`
+	EndDate   int64 `db:"end_date"`
+}
+
+const selectResourceBusySQL = `
+SELECT start_date, end_date
+FROM event_resources
+WHERE resource_id = ? AND active AND start_date < ? AND end_date >= ?
+ORDER BY start_date, end_date
+`
+
+// ListResourceBusy возвращает брони ресурса, пересекающиеся с периодом.
+func (s *Storage) ListResourceBusy(
+	ctx context.Context,
+	resourceID uint64,
+	startDate time.Time,
+	endDateExclusive time.Time,
+) ([]*storage.BusyInterval, error) {
+	rows := make([]*busyIntervalRow, 0)
+	err := s.db.SelectContext(
+		ctx, &rows, selectResourceBusySQL,
+		resourceID, toUnix(endDateExclusive), toUnix(startDate),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query context for listing resource busy time: %w", err)
+	}
+
+	intervals := make([]*storage.BusyInterval, 0, len(rows))
+	for _, row := range rows {
+		intervals = append(intervals, &storage.BusyInterval{
+			StartDate: fromUnix(row.StartDate),
+			EndDate:   fromUnix(row.EndDate),
+		})
+	}
+	return intervals, nil
+}
@@ -0,0 +1,56 @@
+package sqlitestorage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/migrator"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newStorage(t)
+	})
+}
+
+func TestStoragePragmas(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newStorage(t)
+
+	var foreignKeys, busyTimeout int
+	require.NoError(t, s.db.GetContext(ctx, &foreignKeys, "PRAGMA foreign_keys"))
+	require.Equal(t, 1, foreignKeys)
+	require.NoError(t, s.db.GetContext(ctx, &busyTimeout, "PRAGMA busy_timeout"))
+	require.Equal(t, 5000, busyTimeout)
+
+	var journalMode string
+	require.NoError(t, s.db.GetContext(ctx, &journalMode, "PRAGMA journal_mode"))
+	require.Equal(t, "wal", journalMode)
+}
+
+// создаёт хранилище в отдельном файле и применяет к нему миграции.
+func newStorage(t *testing.T) *Storage {
+	t.Helper()
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "calendar.db")
+
+	m := migrator.New(DriverName, dsn)
+	require.NoError(t, m.Connect(ctx))
+	_, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.Close(ctx))
+
+	s := New(dsn)
+	require.NoError(t, s.Connect(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, s.Close(ctx))
+	})
+	return s
+}
@@ -0,0 +1,204 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func testCalendars(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := storage.Event{
+		Title:     "my event",
+		StartDate: getTime(t, "2024-07-06 10:00:00"),
+		EndDate:   getTime(t, "2024-07-10 00:00:00"),
+		UserID:    userID,
+	}
+
+	ctx := context.Background()
+
+	t.Run("default calendar is created once", func(t *testing.T) {
+		s := newStorage(t)
+
+		calendar, err := s.GetDefaultCalendar(ctx, userID)
+		require.NoError(t, err)
+		require.True(t, calendar.IsDefault)
+		require.Equal(t, storage.DefaultCalendarName, calendar.Name)
+
+		sameCalendar, err := s.GetDefaultCalendar(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, calendar, sameCalendar)
+
+		otherCalendar, err := s.GetDefaultCalendar(ctx, otherUserID)
+		require.NoError(t, err)
+		require.NotEqual(t, calendar.ID, otherCalendar.ID)
+	})
+
+	t.Run("create, update and list calendars", func(t *testing.T) {
+		s := newStorage(t)
+
+		calendar := storage.Calendar{UserID: userID, Name: "Work", Color: "#ff0000", NotifyBefore: time.Hour}
+		calendarID, err := s.CreateCalendar(ctx, &calendar)
+		require.NoError(t, err)
+		calendar.ID = calendarID
+
+		// признак календаря по умолчанию при изменении не меняется
+		updatedCalendar := calendar
+		updatedCalendar.Name = "Job"
+		updatedCalendar.IsDefault = true
+		require.NoError(t, s.UpdateCalendar(ctx, &updatedCalendar))
+		updatedCalendar.IsDefault = false
+
+		actualCalendar, err := s.GetCalendar(ctx, userID, calendarID)
+		require.NoError(t, err)
+		require.Equal(t, &updatedCalendar, actualCalendar)
+
+		_, err = s.GetCalendar(ctx, otherUserID, calendarID)
+		require.ErrorIs(t, err, storage.ErrCalendarNotFound)
+
+		otherCalendar := updatedCalendar
+		otherCalendar.UserID = otherUserID
+		require.ErrorIs(t, s.UpdateCalendar(ctx, &otherCalendar), storage.ErrCalendarNotFound)
+
+		_, err = s.CreateCalendar(ctx, &storage.Calendar{UserID: otherUserID, Name: "Other"})
+		require.NoError(t, err)
+
+		calendars, err := s.ListCalendars(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, []*storage.Calendar{&updatedCalendar}, calendars)
+	})
+
+	t.Run("delete calendar", func(t *testing.T) {
+		s := newStorage(t)
+
+		defaultCalendar, err := s.GetDefaultCalendar(ctx, userID)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.DeleteCalendar(ctx, userID, defaultCalendar.ID), storage.ErrDefaultCalendar)
+
+		calendarID, err := s.CreateCalendar(ctx, &storage.Calendar{UserID: userID, Name: "Work"})
+		require.NoError(t, err)
+
+		event := event
+		event.CalendarID = calendarID
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		// событие в корзине тоже принадлежит календарю
+		require.NoError(t, s.Delete(ctx, userID, eventID))
+		require.ErrorIs(t, s.DeleteCalendar(ctx, userID, calendarID), storage.ErrCalendarNotEmpty)
+		require.ErrorIs(t, s.DeleteCalendar(ctx, otherUserID, calendarID), storage.ErrCalendarNotFound)
+
+		require.NoError(t, s.DeleteByDeletedDate(ctx, time.Now().Add(time.Minute)))
+		require.NoError(t, s.DeleteCalendar(ctx, userID, calendarID))
+		require.ErrorIs(t, s.DeleteCalendar(ctx, userID, calendarID), storage.ErrCalendarNotFound)
+
+		_, err = s.GetCalendar(ctx, userID, calendarID)
+		require.ErrorIs(t, err, storage.ErrCalendarNotFound)
+	})
+
+	t.Run("list events of calendars", func(t *testing.T) {
+		s := newStorage(t)
+
+		calendar1ID, err := s.CreateCalendar(ctx, &storage.Calendar{UserID: userID, Name: "Work"})
+		require.NoError(t, err)
+		calendar2ID, err := s.CreateCalendar(ctx, &storage.Calendar{UserID: userID, Name: "Home"})
+		require.NoError(t, err)
+
+		event1 := event
+		event1.CalendarID = calendar1ID
+		event2 := event
+		event2.CalendarID = calendar2ID
+		event2.Transparent = true
+
+		for _, ev := range []*storage.Event{&event1, &event2} {
+			eventID, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+			ev.ID = eventID
+		}
+
+		filter := storage.EventFilter{CalendarIDs: []uint64{calendar2ID, calendar2ID + 100}}
+		actualEvents, err := s.ListForPeriod(ctx, userID, filter, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&event2}, actualEvents)
+
+		actualEvents, err = s.ListForPeriod(ctx, userID, storage.EventFilter{}, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uint64{event1.ID, event2.ID}, eventIDs(actualEvents))
+	})
+
+	t.Run("share calendar", func(t *testing.T) {
+		s := newStorage(t)
+
+		const thirdUserID = otherUserID + 1
+
+		sharedCalendar := storage.Calendar{UserID: userID, Name: "Team", SharedWith: storage.CalendarShares{otherUserID, thirdUserID}}
+		sharedCalendarID, err := s.CreateCalendar(ctx, &sharedCalendar)
+		require.NoError(t, err)
+		sharedCalendar.ID = sharedCalendarID
+		privateCalendarID, err := s.CreateCalendar(ctx, &storage.Calendar{UserID: userID, Name: "Private"})
+		require.NoError(t, err)
+
+		sharedEvent := event
+		sharedEvent.CalendarID = sharedCalendarID
+		sharedEventID, err := s.Create(ctx, &sharedEvent)
+		require.NoError(t, err)
+		privateEvent := event
+		privateEvent.CalendarID = privateCalendarID
+		privateEvent.Transparent = true
+		privateEventID, err := s.Create(ctx, &privateEvent)
+		require.NoError(t, err)
+
+		actualCalendar, err := s.GetCalendar(ctx, userID, sharedCalendarID)
+		require.NoError(t, err)
+		require.Equal(t, &sharedCalendar, actualCalendar)
+
+		calendars, err := s.ListSharedCalendars(ctx, otherUserID)
+		require.NoError(t, err)
+		require.Equal(t, []*storage.Calendar{&sharedCalendar}, calendars)
+
+		actualEvent, err := s.GetSharedByID(ctx, otherUserID, sharedEventID)
+		require.NoError(t, err)
+		require.Equal(t, sharedEventID, actualEvent.ID)
+		_, err = s.GetSharedByID(ctx, otherUserID, privateEventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+		// свои события пользователь получает через GetByID
+		_, err = s.GetSharedByID(ctx, userID, sharedEventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+		actualEvents, err := s.ListSharedForPeriod(ctx, otherUserID, storage.EventFilter{}, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{sharedEventID}, eventIDs(actualEvents))
+		filter := storage.EventFilter{CalendarIDs: []uint64{privateCalendarID}}
+		actualEvents, err = s.ListSharedForPeriod(ctx, otherUserID, filter, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.Empty(t, actualEvents)
+
+		// доступ заменяется целиком
+		sharedCalendar.SharedWith = storage.CalendarShares{thirdUserID}
+		require.NoError(t, s.UpdateCalendar(ctx, &sharedCalendar))
+		calendars, err = s.ListSharedCalendars(ctx, otherUserID)
+		require.NoError(t, err)
+		require.Empty(t, calendars)
+		_, err = s.GetSharedByID(ctx, otherUserID, sharedEventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+		// событие в корзине не видно
+		require.NoError(t, s.Delete(ctx, userID, sharedEventID))
+		_, err = s.GetSharedByID(ctx, thirdUserID, sharedEventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+		actualEvents, err = s.ListSharedForPeriod(ctx, thirdUserID, storage.EventFilter{}, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.Empty(t, actualEvents)
+
+		// доступ удаляется вместе с календарём
+		require.NoError(t, s.DeleteByDeletedDate(ctx, time.Now().Add(time.Minute)))
+		require.NoError(t, s.DeleteCalendar(ctx, userID, sharedCalendarID))
+		calendars, err = s.ListSharedCalendars(ctx, thirdUserID)
+		require.NoError(t, err)
+		require.Empty(t, calendars)
+	})
+}
@@ -0,0 +1,709 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	userID      = uint64(12345)
+	otherUserID = uint64(54321)
+)
+
+func newEvent(t *testing.T) storage.Event {
+	t.Helper()
+	return storage.Event{
+		Title:        "my event",
+		StartDate:    getTime(t, "2024-07-06 10:00:00"),
+		EndDate:      getTime(t, "2024-07-10 00:00:00"),
+		Description:  "my event description",
+		UserID:       userID,
+		NotifyBefore: time.Hour * 24,
+	}
+}
+
+func testEvents(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-06 09:59:59")
+
+	event2 := event
+	event2.StartDate = getTime(t, "2024-07-10 00:00:01")
+	event2.EndDate = getTime(t, "2024-07-10 10:00:00")
+
+	event3 := event
+	event3.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event3.EndDate = getTime(t, "2024-07-10 09:59:59")
+
+	ctx := context.Background()
+
+	t.Run("create new event without intersections", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+		event2 := event2
+
+		for _, ev := range []*storage.Event{&event1, &event2, &event} {
+			eventID, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+			require.Greater(t, eventID, uint64(0))
+		}
+	})
+
+	t.Run("create new event in busy time", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event3 := event3
+
+		_, err := s.Create(ctx, &event3)
+		require.NoError(t, err)
+
+		_, err = s.Create(ctx, &event)
+		require.ErrorIs(t, err, storage.ErrBusyTime)
+	})
+
+	t.Run("get created event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		expectedEvent := event
+		expectedEvent.ID = eventID
+
+		actualEvent, err := s.GetByID(ctx, userID, eventID)
+		require.NoError(t, err)
+		require.Equal(t, expectedEvent, *actualEvent)
+	})
+
+	t.Run("get not existing event", func(t *testing.T) {
+		s := newStorage(t)
+		_, err := s.GetByID(ctx, userID, uint64(0))
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("update created event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		_, err = s.Create(ctx, &event1)
+		require.NoError(t, err)
+
+		// прозрачное событие может пересекаться с другими
+		updatedEvent := event
+		updatedEvent.ID = eventID
+		updatedEvent.Title = "my event 2"
+		updatedEvent.Description = "my event 2 description"
+		updatedEvent.StartDate = event1.StartDate
+		updatedEvent.NotifyBefore = time.Hour
+		updatedEvent.Transparent = true
+
+		err = s.Update(ctx, &updatedEvent)
+		require.NoError(t, err)
+
+		actualEvent, err := s.GetByID(ctx, userID, eventID)
+		require.NoError(t, err)
+		require.Equal(t, updatedEvent, *actualEvent)
+	})
+
+	t.Run("update not existing event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event.ID = 100
+
+		err := s.Update(ctx, &event)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("delete created event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		_, err = s.GetByID(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("delete not existing event", func(t *testing.T) {
+		s := newStorage(t)
+		err := s.Delete(ctx, userID, uint64(0))
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+}
+
+func testOtherUser(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-06 09:59:59")
+
+	ctx := context.Background()
+
+	t.Run("get created event by another user", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		_, err = s.GetByID(ctx, otherUserID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("update created event by another user", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		updatedEvent := storage.Event{
+			ID:           eventID,
+			Title:        "my event 2",
+			StartDate:    getTime(t, "2025-07-06 10:00:00"),
+			EndDate:      getTime(t, "2025-07-10 00:00:00"),
+			Description:  "my event 2 description",
+			UserID:       otherUserID,
+			NotifyBefore: time.Hour * 1,
+		}
+
+		err = s.Update(ctx, &updatedEvent)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("delete created event by another user", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, otherUserID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("count events by user", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+		_, err = s.Create(ctx, &event1)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, userID, eventID))
+
+		// события в корзине тоже учитываются
+		count, err := s.CountByUser(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		count, err = s.CountByUser(ctx, otherUserID)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("create event with idempotency key", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		otherEvent := event
+		otherEvent.UserID = otherUserID
+
+		_, err := s.GetIdempotencyKey(ctx, userID, "key", time.Now().Add(-time.Hour))
+		require.ErrorIs(t, err, storage.ErrIdempotencyKeyNotFound)
+
+		eventID, replayed, err := s.CreateIdempotent(ctx, &event, "key", "hash", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.False(t, replayed)
+
+		idempotencyKey, err := s.GetIdempotencyKey(ctx, userID, "key", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, storage.IdempotencyKey{EventID: eventID, RequestHash: "hash"}, *idempotencyKey)
+
+		replayedEvent := event
+		replayedID, replayed, err := s.CreateIdempotent(ctx, &replayedEvent, "key", "hash", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, eventID, replayedID)
+
+		// тот же ключ с другим запросом
+		changedEvent := event
+		_, _, err = s.CreateIdempotent(ctx, &changedEvent, "key", "other hash", time.Now().Add(-time.Hour))
+		require.ErrorIs(t, err, storage.ErrIdempotencyKeyReused)
+
+		otherEventID, replayed, err := s.CreateIdempotent(ctx, &otherEvent, "key", "other hash", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.NotEqual(t, eventID, otherEventID)
+
+		count, err := s.CountByUser(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("create event with expired idempotency key", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event2 := event
+		event2.StartDate = getTime(t, "2025-07-06 10:00:00")
+		event2.EndDate = getTime(t, "2025-07-10 00:00:00")
+
+		eventID, _, err := s.CreateIdempotent(ctx, &event, "key", "hash", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		_, err = s.GetIdempotencyKey(ctx, userID, "key", time.Now().Add(time.Hour))
+		require.ErrorIs(t, err, storage.ErrIdempotencyKeyNotFound)
+
+		event2ID, replayed, err := s.CreateIdempotent(ctx, &event2, "key", "other hash", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.NotEqual(t, eventID, event2ID)
+	})
+}
+
+func testOverlapPolicy(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-10 09:59:59")
+
+	ctx := context.Background()
+
+	t.Run("busy time error contains conflicts", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+		event1.ID = event1ID
+
+		_, err = s.Create(ctx, &event)
+		var busyTimeErr *storage.BusyTimeError
+		require.ErrorAs(t, err, &busyTimeErr)
+		require.EqualValues(t, []*storage.Event{&event1}, busyTimeErr.Conflicts)
+	})
+
+	t.Run("concurrent create of overlapping events", func(t *testing.T) {
+		s := newStorage(t)
+
+		const workers = 20
+		var wg sync.WaitGroup
+		var created, busy atomic.Int32
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				event := event
+				_, err := s.Create(ctx, &event)
+				switch {
+				case err == nil:
+					created.Add(1)
+				case errors.Is(err, storage.ErrBusyTime):
+					busy.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), created.Load())
+		require.Equal(t, int32(workers-1), busy.Load())
+	})
+
+	t.Run("transparent events do not conflict", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event.Transparent = true
+		event1 := event1
+
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+		event1.ID = event1ID
+
+		_, err = s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		conflicts, err := s.ListConflicts(ctx, &event1)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(conflicts))
+	})
+
+	t.Run("overlap allowed by user settings", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		settings, err := s.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.OverlapReject, settings.OverlapPolicy)
+
+		err = s.SaveUserSettings(ctx, &storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapWarn})
+		require.NoError(t, err)
+
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+		event1.ID = event1ID
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+		event.ID = eventID
+
+		conflicts, err := s.ListConflicts(ctx, &event)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&event1}, conflicts)
+	})
+
+	t.Run("policy change applies to existing events", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		// событие в корзине создано, пока пересечения были запрещены
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, userID, eventID))
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+
+		require.NoError(t, s.SaveUserSettings(ctx, &storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapAllow}))
+		require.NoError(t, s.Restore(ctx, userID, eventID))
+
+		// пока события пересекаются, запретить пересечения нельзя
+		err = s.SaveUserSettings(ctx, &storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapReject})
+		require.ErrorIs(t, err, storage.ErrBusyTime)
+		settings, err := s.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.OverlapAllow, settings.OverlapPolicy)
+
+		require.NoError(t, s.Delete(ctx, userID, event1ID))
+		require.NoError(t, s.SaveUserSettings(ctx, &storage.UserSettings{UserID: userID, OverlapPolicy: storage.OverlapReject}))
+		require.ErrorIs(t, s.Restore(ctx, userID, event1ID), storage.ErrBusyTime)
+	})
+}
+
+func testTrash(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-10 09:59:59")
+
+	ctx := context.Background()
+
+	t.Run("deleted event is moved to trash", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		_, err = s.GetByID(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+		actualEvents, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(actualEvents))
+
+		deletedEvents, err := s.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deletedEvents))
+		require.Equal(t, eventID, deletedEvents[0].ID)
+		require.NotNil(t, deletedEvents[0].DeletedAt)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+		updatedEvent := event
+		updatedEvent.ID = eventID
+		updatedEvent.DeletedAt = nil
+		err = s.Update(ctx, &updatedEvent)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("deleted event does not take busy time", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		_, err = s.Create(ctx, &event1)
+		require.NoError(t, err)
+	})
+
+	t.Run("restore deleted event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		err = s.Restore(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		actualEvent, err := s.GetByID(ctx, userID, eventID)
+		require.NoError(t, err)
+		require.Nil(t, actualEvent.DeletedAt)
+
+		deletedEvents, err := s.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(deletedEvents))
+	})
+
+	t.Run("restore deleted event in busy time", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		event1ID, err := s.Create(ctx, &event1)
+		require.NoError(t, err)
+
+		err = s.Restore(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrBusyTime)
+
+		// после удаления мешающего события восстановление проходит
+		require.NoError(t, s.Delete(ctx, userID, event1ID))
+		require.NoError(t, s.Restore(ctx, userID, eventID))
+	})
+
+	t.Run("restore not deleted event", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Restore(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+		err = s.Restore(ctx, userID, uint64(0))
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+
+	t.Run("clear trash", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+
+		eventID, err := s.Create(ctx, &event)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, userID, eventID)
+		require.NoError(t, err)
+
+		err = s.DeleteByDeletedDate(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		deletedEvents, err := s.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(deletedEvents))
+
+		err = s.DeleteByDeletedDate(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		deletedEvents, err = s.ListDeleted(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(deletedEvents))
+
+		err = s.Restore(ctx, userID, eventID)
+		require.ErrorIs(t, err, storage.ErrEventNotFound)
+	})
+}
+
+func testEventDetails(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	lat, lon := 55.75, 37.62
+	event := storage.Event{
+		Title:        "my event",
+		StartDate:    getTime(t, "2024-07-06 10:00:00"),
+		EndDate:      getTime(t, "2024-07-10 00:00:00"),
+		UserID:       userID,
+		NotifyBefore: time.Hour,
+		Transparent:  true,
+		Location:     "Office",
+		Latitude:     &lat,
+		Longitude:    &lon,
+		Category:     "work",
+		Tags:         storage.Tags{"urgent", "team"},
+		Attachments: storage.Attachments{
+			{URL: "https://example.com/a.pdf", Name: "a.pdf", Size: 1024, MimeType: "application/pdf"},
+		},
+	}
+
+	ctx := context.Background()
+	s := newStorage(t)
+
+	eventID, err := s.Create(ctx, &event)
+	require.NoError(t, err)
+	event.ID = eventID
+
+	actualEvent, err := s.GetByID(ctx, userID, eventID)
+	require.NoError(t, err)
+	require.Equal(t, &event, actualEvent)
+
+	other := event
+	other.Location, other.Latitude, other.Longitude = "", nil, nil
+	other.Category = "home"
+	other.Tags = storage.Tags{"team"}
+	other.Attachments = nil
+	otherID, err := s.Create(ctx, &other)
+	require.NoError(t, err)
+
+	tests := []struct {
+		filter   storage.EventFilter
+		expected []uint64
+	}{
+		{filter: storage.EventFilter{}, expected: []uint64{eventID, otherID}},
+		{filter: storage.EventFilter{Category: "work"}, expected: []uint64{eventID}},
+		{filter: storage.EventFilter{Tags: []string{"team"}}, expected: []uint64{eventID, otherID}},
+		{filter: storage.EventFilter{Tags: []string{"team", "urgent", "team"}}, expected: []uint64{eventID}},
+		{filter: storage.EventFilter{Category: "home", Tags: []string{"urgent"}}, expected: []uint64{}},
+	}
+	for _, tt := range tests {
+		actualEvents, err := s.ListForPeriod(ctx, userID, tt.filter, event.StartDate, event.EndDate)
+		require.NoError(t, err)
+		require.ElementsMatch(t, tt.expected, eventIDs(actualEvents), "filter %+v", tt.filter)
+	}
+}
+
+func testAudit(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	eventID := uint64(1000)
+	record := storage.AuditRecord{
+		EventID:   eventID,
+		UserID:    userID,
+		ActorID:   userID,
+		Action:    storage.AuditCreate,
+		Changes:   storage.AuditChanges{{Field: "title", After: "my event"}},
+		Source:    "HTTP",
+		CreatedAt: getTime(t, "2024-07-06 10:00:00"),
+	}
+
+	ctx := context.Background()
+
+	t.Run("list audit records of event", func(t *testing.T) {
+		s := newStorage(t)
+		record1 := record
+		record2 := record
+		record2.Action = storage.AuditDelete
+		recordOther := record
+		recordOther.EventID = eventID + 1
+
+		for _, r := range []*storage.AuditRecord{&record1, &recordOther, &record2} {
+			err := s.CreateAuditRecord(ctx, r)
+			require.NoError(t, err)
+			require.Greater(t, r.ID, uint64(0))
+		}
+
+		actualRecords, err := s.ListAuditRecords(ctx, userID, eventID)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.AuditRecord{&record1, &record2}, actualRecords)
+
+		actualRecords, err = s.ListAuditRecords(ctx, otherUserID, eventID)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(actualRecords))
+	})
+}
+
+func testList(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-06 09:59:59")
+
+	event2 := event
+	event2.StartDate = getTime(t, "2024-07-10 00:00:01")
+	event2.EndDate = getTime(t, "2024-07-10 10:00:00")
+
+	eventOther := event
+	eventOther.UserID = otherUserID
+	eventOther.EndDate = getTime(t, "2024-07-10 00:00:01")
+
+	ctx := context.Background()
+
+	t.Run("list events in period (all)", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+		event2 := event2
+		eventOther := eventOther
+
+		// события создаются не в порядке начала
+		events := []*storage.Event{&event2, &eventOther, &event1, &event}
+		for _, ev := range events {
+			eventID, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+			ev.ID = eventID
+		}
+
+		actualEvents, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, getTime(t, "2024-07-06 09:59:59"), getTime(t, "2024-07-10 00:00:02"))
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&event1, &event, &event2}, actualEvents)
+	})
+
+	t.Run("list events in period (zero)", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+		event2 := event2
+		eventOther := eventOther
+
+		for _, ev := range []*storage.Event{&event1, &event, &event2, &eventOther} {
+			_, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+		}
+
+		actualEvents, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, getTime(t, "2024-07-05 00:00:00"), getTime(t, "2024-07-05 09:59:59"))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(actualEvents))
+	})
+}
@@ -0,0 +1,56 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib" // for postgres
+	"github.com/stretchr/testify/require"
+)
+
+// PostgresEnv - переменная окружения с URI PostgreSQL для интеграционных тестов.
+const PostgresEnv = "DB_URI"
+
+var schemaSeq atomic.Int64
+
+// PostgresDSN создаёт для теста отдельную схему в БД из переменной окружения DB_URI
+// и возвращает DSN, в котором эта схема указана первой в search_path; после теста схема удаляется.
+// Если DB_URI не задана, тест пропускается.
+func PostgresDSN(t *testing.T) string {
+	t.Helper()
+
+	uri := os.Getenv(PostgresEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", PostgresEnv)
+	}
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Contains(t, []string{"postgres", "postgresql"}, u.Scheme, "%s must be an URL", PostgresEnv)
+
+	ctx := context.Background()
+	db, err := sql.Open("pgx", uri)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	schema := fmt.Sprintf("test_%d_%d", time.Now().UnixNano(), schemaSeq.Add(1))
+	_, err = db.ExecContext(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		require.NoError(t, err)
+	})
+
+	// расширения (btree_gist) остаются в схеме public
+	query := u.Query()
+	query.Set("search_path", schema+",public")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,150 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func testResources(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	t.Run("create, update, list and delete resource", func(t *testing.T) {
+		s := newStorage(t)
+
+		room := storage.Resource{
+			UserID: userID, Name: "Room 1", Kind: "room", Capacity: 10, Attributes: storage.ResourceAttributes{"floor": "2"},
+		}
+		roomID, err := s.CreateResource(ctx, &room)
+		require.NoError(t, err)
+		room.ID = roomID
+
+		projector := storage.Resource{UserID: otherUserID, Name: "Projector", Kind: "projector"}
+		projectorID, err := s.CreateResource(ctx, &projector)
+		require.NoError(t, err)
+		projector.ID = projectorID
+
+		// создатель ресурса при изменении не меняется
+		room.Capacity = 12
+		updatedRoom := room
+		updatedRoom.UserID = otherUserID
+		require.NoError(t, s.UpdateResource(ctx, &updatedRoom))
+
+		actualResource, err := s.GetResource(ctx, roomID)
+		require.NoError(t, err)
+		require.Equal(t, &room, actualResource)
+
+		resources, err := s.ListResources(ctx, storage.ResourceFilter{})
+		require.NoError(t, err)
+		require.Equal(t, []*storage.Resource{&room, &projector}, resources)
+
+		resources, err = s.ListResources(ctx, storage.ResourceFilter{Kind: "room", MinCapacity: 12})
+		require.NoError(t, err)
+		require.Equal(t, []*storage.Resource{&room}, resources)
+
+		resources, err = s.ListResources(ctx, storage.ResourceFilter{MinCapacity: 13})
+		require.NoError(t, err)
+		require.Empty(t, resources)
+
+		require.NoError(t, s.DeleteResource(ctx, projectorID))
+		_, err = s.GetResource(ctx, projectorID)
+		require.ErrorIs(t, err, storage.ErrResourceNotFound)
+		require.ErrorIs(t, s.DeleteResource(ctx, projectorID), storage.ErrResourceNotFound)
+		require.ErrorIs(t, s.UpdateResource(ctx, &projector), storage.ErrResourceNotFound)
+	})
+
+	t.Run("resource cannot be double-booked", func(t *testing.T) {
+		s := newStorage(t)
+
+		roomID, err := s.CreateResource(ctx, &storage.Resource{Name: "Room 1", Kind: "room"})
+		require.NoError(t, err)
+		projectorID, err := s.CreateResource(ctx, &storage.Resource{Name: "Projector", Kind: "projector"})
+		require.NoError(t, err)
+
+		meeting := storage.Event{
+			Title:       "meeting",
+			StartDate:   getTime(t, "2024-07-06 10:00:00"),
+			EndDate:     getTime(t, "2024-07-06 12:00:00"),
+			UserID:      userID,
+			ResourceIDs: storage.ResourceIDs{roomID, projectorID},
+		}
+		meetingID, err := s.Create(ctx, &meeting)
+		require.NoError(t, err)
+
+		actualEvent, err := s.GetByID(ctx, userID, meetingID)
+		require.NoError(t, err)
+		require.Equal(t, storage.ResourceIDs{roomID, projectorID}, actualEvent.ResourceIDs)
+
+		// ресурс занят независимо от пользователя и прозрачности события, границы включаются
+		other := storage.Event{
+			Title:       "other meeting",
+			StartDate:   getTime(t, "2024-07-06 12:00:00"),
+			EndDate:     getTime(t, "2024-07-06 13:00:00"),
+			UserID:      otherUserID,
+			Transparent: true,
+			ResourceIDs: storage.ResourceIDs{roomID},
+		}
+		_, err = s.Create(ctx, &other)
+		var resourceBusyErr *storage.ResourceBusyError
+		require.ErrorAs(t, err, &resourceBusyErr)
+		require.Equal(t, []uint64{roomID}, resourceBusyErr.ResourceIDs)
+		require.ErrorIs(t, err, storage.ErrResourceBusy)
+
+		other.ResourceIDs = storage.ResourceIDs{roomID + projectorID + 100}
+		_, err = s.Create(ctx, &other)
+		require.ErrorIs(t, err, storage.ErrResourceNotFound)
+
+		other.StartDate = getTime(t, "2024-07-06 12:00:01")
+		other.ResourceIDs = storage.ResourceIDs{roomID}
+		otherID, err := s.Create(ctx, &other)
+		require.NoError(t, err)
+
+		busy, err := s.ListResourceBusy(ctx, roomID, getTime(t, "2024-07-06 00:00:00"), getTime(t, "2024-07-07 00:00:00"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(busy))
+		require.True(t, meeting.StartDate.Equal(busy[0].StartDate))
+		require.True(t, meeting.EndDate.Equal(busy[0].EndDate))
+		require.True(t, other.StartDate.Equal(busy[1].StartDate))
+
+		// перенос события не конфликтует с его же бронью
+		moved := meeting
+		moved.ID = meetingID
+		moved.StartDate = getTime(t, "2024-07-06 09:00:00")
+		moved.EndDate = getTime(t, "2024-07-06 11:00:00")
+		moved.ResourceIDs = storage.ResourceIDs{roomID}
+		require.NoError(t, s.Update(ctx, &moved))
+
+		busy, err = s.ListResourceBusy(ctx, projectorID, getTime(t, "2024-07-06 00:00:00"), getTime(t, "2024-07-07 00:00:00"))
+		require.NoError(t, err)
+		require.Empty(t, busy)
+
+		require.ErrorIs(t, s.DeleteResource(ctx, roomID), storage.ErrResourceInUse)
+		require.NoError(t, s.DeleteResource(ctx, projectorID))
+
+		// событие в корзине не занимает ресурс, но восстановить его можно только пока ресурс свободен
+		require.NoError(t, s.Delete(ctx, userID, meetingID))
+		busy, err = s.ListResourceBusy(ctx, roomID, getTime(t, "2024-07-06 00:00:00"), getTime(t, "2024-07-06 12:00:00"))
+		require.NoError(t, err)
+		require.Empty(t, busy)
+
+		blocking := other
+		blocking.ID = 0
+		blocking.StartDate = getTime(t, "2024-07-06 10:00:00")
+		blocking.EndDate = getTime(t, "2024-07-06 10:30:00")
+		_, err = s.Create(ctx, &blocking)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Restore(ctx, userID, meetingID), storage.ErrResourceBusy)
+
+		// удалённые из корзины события освобождают ресурс
+		require.NoError(t, s.Delete(ctx, otherUserID, otherID))
+		require.NoError(t, s.DeleteByDeletedDate(ctx, time.Now().Add(time.Minute)))
+		busy, err = s.ListResourceBusy(ctx, roomID, getTime(t, "2024-07-06 00:00:00"), getTime(t, "2024-07-07 00:00:00"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(busy))
+	})
+}
@@ -0,0 +1,109 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func testScheduling(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	event := newEvent(t)
+	event.NotifyBefore = time.Hour * 25
+
+	event1 := event
+	event1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	event1.EndDate = getTime(t, "2024-07-06 09:59:59")
+	event1.NotifyBefore = time.Hour * 48
+
+	event2 := event
+	event2.StartDate = getTime(t, "2024-07-10 00:00:01")
+	event2.EndDate = getTime(t, "2024-07-10 10:00:00")
+	event2.NotifyBefore = time.Hour * 24 * 4
+
+	eventOther := event
+	eventOther.UserID = otherUserID
+	eventOther.EndDate = getTime(t, "2024-07-10 00:00:01")
+
+	eventOther1 := event
+	eventOther1.UserID = uint64(32154)
+	eventOther1.StartDate = getTime(t, "2024-07-05 10:00:00")
+	eventOther1.EndDate = getTime(t, "2024-07-10 09:59:59")
+	eventOther1.NotifyBefore = time.Hour
+
+	// событие без напоминания не попадает в уведомления
+	eventNoNotify := event
+	eventNoNotify.UserID = uint64(32155)
+	eventNoNotify.NotifyBefore = 0
+	eventNoNotify.StartDate = getTime(t, "2024-07-05 12:00:00")
+
+	ctx := context.Background()
+
+	t.Run("list and notify events", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+		event2 := event2
+		eventOther := eventOther
+		eventOther1 := eventOther1
+		eventNoNotify := eventNoNotify
+
+		for _, ev := range []*storage.Event{&event1, &eventOther, &event2, &event, &eventOther1, &eventNoNotify} {
+			eventID, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+			ev.ID = eventID
+		}
+
+		// события упорядочены по дате уведомления, затем по началу и окончанию;
+		// уведомление о event1 - до начала периода
+		from, to := getTime(t, "2024-07-05 09:00:00"), getTime(t, "2024-07-06 09:00:00")
+		actualEvents, err := s.ListForNotify(ctx, from, to)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&eventOther1, &event, &eventOther, &event2}, actualEvents)
+
+		err = s.SetNotifyStatus(ctx, []uint64{event.ID, eventOther1.ID}, storage.Notified)
+		require.NoError(t, err)
+		err = s.SetNotifyStatus(ctx, []uint64{eventOther.ID}, storage.NotifyInProgress)
+		require.NoError(t, err)
+		event.NotifyStatus = storage.Notified
+		eventOther1.NotifyStatus = storage.Notified
+		eventOther.NotifyStatus = storage.NotifyInProgress
+
+		actualEvents, err = s.ListForNotify(ctx, from, to)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&event2}, actualEvents)
+
+		actualEvent, err := s.GetByID(ctx, otherUserID, eventOther.ID)
+		require.NoError(t, err)
+		require.Equal(t, storage.NotifyInProgress, actualEvent.NotifyStatus)
+
+		// список для администрирования включает события в любом статусе
+		actualEvents, err = s.ListByNotifyDate(ctx, from, to)
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&eventOther1, &event, &eventOther, &event2}, actualEvents)
+	})
+
+	t.Run("delete old events", func(t *testing.T) {
+		s := newStorage(t)
+		event := event
+		event1 := event1
+		event2 := event2
+
+		for _, ev := range []*storage.Event{&event1, &event2, &event} {
+			eventID, err := s.Create(ctx, ev)
+			require.NoError(t, err)
+			ev.ID = eventID
+		}
+
+		err := s.DeleteByEndDate(ctx, getTime(t, "2024-07-10 00:00:00"))
+		require.NoError(t, err)
+
+		actualEvents, err := s.ListForPeriod(ctx, userID, storage.EventFilter{}, getTime(t, "2024-07-05 10:00:00"), getTime(t, "2024-07-10 10:00:00"))
+		require.NoError(t, err)
+		require.EqualValues(t, []*storage.Event{&event2}, actualEvents)
+	})
+}
@@ -0,0 +1,94 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func testUserSettings(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	t.Run("user availability settings", func(t *testing.T) {
+		s := newStorage(t)
+
+		settings, err := s.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, storage.DefaultUserSettings(userID), settings)
+
+		expected := &storage.UserSettings{
+			UserID:        userID,
+			OverlapPolicy: storage.OverlapReject,
+			TimeZone:      "Europe/Moscow",
+			WorkingHours:  storage.WorkingHours{{Weekday: time.Monday, Start: "09:00", End: "18:00"}},
+			OutOfOffice: storage.OutOfOffice{
+				{StartDate: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC), Message: "vacation"},
+			},
+			AvailabilityPolicy: storage.OverlapWarn,
+			Digest:             storage.DigestDaily,
+		}
+		require.NoError(t, s.SaveUserSettings(ctx, expected))
+
+		settings, err = s.GetUserSettings(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, expected, settings)
+	})
+
+	t.Run("digest settings", func(t *testing.T) {
+		s := newStorage(t)
+
+		weekly := storage.DefaultUserSettings(userID + 1)
+		weekly.Digest = storage.DigestWeekly
+		daily := storage.DefaultUserSettings(userID)
+		daily.Digest = storage.DigestDaily
+		for _, settings := range []*storage.UserSettings{weekly, daily, storage.DefaultUserSettings(userID + 2)} {
+			require.NoError(t, s.SaveUserSettings(ctx, settings))
+		}
+
+		settings, err := s.ListDigestSettings(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []*storage.UserSettings{daily, weekly}, settings)
+	})
+
+	t.Run("digest log", func(t *testing.T) {
+		s := newStorage(t)
+
+		today := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+		yesterday := today.AddDate(0, 0, -1)
+
+		// сводку за период отправляет только первый запуск
+		claimed, err := s.ClaimDigest(ctx, userID, today)
+		require.NoError(t, err)
+		require.True(t, claimed)
+		claimed, err = s.ClaimDigest(ctx, userID, today)
+		require.NoError(t, err)
+		require.False(t, claimed)
+		claimed, err = s.ClaimDigest(ctx, userID, yesterday)
+		require.NoError(t, err)
+		require.False(t, claimed)
+		claimed, err = s.ClaimDigest(ctx, userID+1, today)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		// отметка за другой период не снимается
+		require.NoError(t, s.ReleaseDigest(ctx, userID, yesterday))
+		claimed, err = s.ClaimDigest(ctx, userID, today)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		// неотправленную сводку отправляет следующий запуск
+		require.NoError(t, s.ReleaseDigest(ctx, userID, today))
+		claimed, err = s.ClaimDigest(ctx, userID, today)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = s.ClaimDigest(ctx, userID, today.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		require.True(t, claimed)
+	})
+}
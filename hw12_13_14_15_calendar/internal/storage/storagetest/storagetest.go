@@ -0,0 +1,113 @@
+// Package storagetest - общий набор тестов хранилищ событий: один и тот же сценарий
+// выполняется для хранилища в памяти, SQLite и PostgreSQL.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// Storage - методы хранилища, проверяемые набором тестов.
+type Storage interface {
+	Create(ctx context.Context, event *storage.Event) (uint64, error)
+	GetIdempotencyKey(ctx context.Context, userID uint64, key string, notBefore time.Time) (*storage.IdempotencyKey, error)
+	CreateIdempotent(ctx context.Context, event *storage.Event, key string, requestHash string, notBefore time.Time) (uint64, bool, error)
+	GetByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error)
+	Update(ctx context.Context, event *storage.Event) error
+	Delete(ctx context.Context, userID uint64, eventID uint64) error
+	Restore(ctx context.Context, userID uint64, eventID uint64) error
+	ListDeleted(ctx context.Context, userID uint64) ([]*storage.Event, error)
+	CountByUser(ctx context.Context, userID uint64) (int, error)
+	ListForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
+	ListForNotify(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error)
+	ListByNotifyDate(ctx context.Context, startNotifyDate time.Time, endNotifyDate time.Time) ([]*storage.Event, error)
+	SetNotifyStatus(ctx context.Context, eventIDs []uint64, notifyStatus storage.NotifyStatus) error
+	DeleteByEndDate(ctx context.Context, maxEndDate time.Time) error
+	DeleteByDeletedDate(ctx context.Context, maxDeletedDate time.Time) error
+	CreateAuditRecord(ctx context.Context, record *storage.AuditRecord) error
+	ListAuditRecords(ctx context.Context, userID uint64, eventID uint64) ([]*storage.AuditRecord, error)
+	ListConflicts(ctx context.Context, event *storage.Event) ([]*storage.Event, error)
+	GetUserSettings(ctx context.Context, userID uint64) (*storage.UserSettings, error)
+	SaveUserSettings(ctx context.Context, settings *storage.UserSettings) error
+	ListDigestSettings(ctx context.Context) ([]*storage.UserSettings, error)
+	ClaimDigest(ctx context.Context, userID uint64, startDate time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, userID uint64, startDate time.Time) error
+	CreateCalendar(ctx context.Context, calendar *storage.Calendar) (uint64, error)
+	GetCalendar(ctx context.Context, userID uint64, calendarID uint64) (*storage.Calendar, error)
+	GetDefaultCalendar(ctx context.Context, userID uint64) (*storage.Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar *storage.Calendar) error
+	DeleteCalendar(ctx context.Context, userID uint64, calendarID uint64) error
+	ListCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error)
+	ListSharedCalendars(ctx context.Context, userID uint64) ([]*storage.Calendar, error)
+	GetSharedByID(ctx context.Context, userID uint64, eventID uint64) (*storage.Event, error)
+	ListSharedForPeriod(
+		ctx context.Context,
+		userID uint64,
+		filter storage.EventFilter,
+		startDate time.Time,
+		endDateExclusive time.Time,
+	) ([]*storage.Event, error)
+	CreateResource(ctx context.Context, resource *storage.Resource) (uint64, error)
+	GetResource(ctx context.Context, resourceID uint64) (*storage.Resource, error)
+	UpdateResource(ctx context.Context, resource *storage.Resource) error
+	DeleteResource(ctx context.Context, resourceID uint64) error
+	ListResources(ctx context.Context, filter storage.ResourceFilter) ([]*storage.Resource, error)
+	ListResourceBusy(ctx context.Context, resourceID uint64, startDate time.Time, endDateExclusive time.Time) ([]*storage.BusyInterval, error)
+}
+
+// Run выполняет набор тестов; newStorage создаёт пустое хранилище для каждого подтеста.
+// Хранилище в памяти возвращает сохранённые события по указателю, поэтому тесты не изменяют
+// переданное в хранилище событие, а работают с его копией.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		test func(t *testing.T, newStorage func(t *testing.T) Storage)
+	}{
+		{name: "events", test: testEvents},
+		{name: "other user", test: testOtherUser},
+		{name: "overlap policy", test: testOverlapPolicy},
+		{name: "user settings", test: testUserSettings},
+		{name: "trash", test: testTrash},
+		{name: "event details", test: testEventDetails},
+		{name: "audit", test: testAudit},
+		{name: "list", test: testList},
+		{name: "calendars", test: testCalendars},
+		{name: "resources", test: testResources},
+		{name: "scheduling", test: testScheduling},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.test(t, newStorage)
+		})
+	}
+}
+
+// getTime возвращает время в локальной зоне: в ней время возвращают хранилища SQLite и PostgreSQL.
+func getTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	time, err := time.ParseInLocation(time.DateTime, value, time.Local)
+	require.NoError(t, err)
+	return time
+}
+
+// eventIDs возвращает ID событий в исходном порядке.
+func eventIDs(events []*storage.Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
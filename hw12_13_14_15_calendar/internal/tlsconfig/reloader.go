@@ -0,0 +1,247 @@
+// Package tlsconfig - настройки TLS серверов календаря и клиента grpc-gateway
+// с перечитыванием сертификатов при изменении файлов без перезапуска.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReloadPeriod - как часто проверяются изменения файлов сертификатов.
+const DefaultReloadPeriod = 10 * time.Second
+
+var (
+	ErrNoCertificate     = errors.New("certificate and key files are required")
+	ErrNoCACertificates  = errors.New("no certificates found in CA file")
+	ErrUnknownTLSVersion = errors.New("unknown tls version")
+)
+
+type Logger interface {
+	Info(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, err error, msg string, args ...any)
+}
+
+// Files - пути к файлам в формате PEM. Для сервера CAFile - сертификаты CA, которыми подписаны
+// сертификаты клиентов (если задан, включается mTLS); для клиента - CA, которым подписан сертификат сервера.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Reloader хранит текущие сертификат и пул CA и перечитывает их при изменении файлов.
+type Reloader struct {
+	files      Files
+	minVersion uint16
+	state      atomic.Pointer[state]
+}
+
+type state struct {
+	cert     *tls.Certificate
+	caPool   *x509.CertPool
+	modTimes []time.Time
+}
+
+// New загружает сертификаты сервера. minVersion - минимальная версия TLS ("1.2", "1.3"; пусто - 1.2).
+func New(files Files, minVersion string) (*Reloader, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, ErrNoCertificate
+	}
+	return newReloader(files, minVersion)
+}
+
+// NewClient загружает сертификаты клиента; сертификат клиента нужен только для mTLS и может быть не задан.
+func NewClient(files Files, minVersion string) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, ErrNoCertificate
+	}
+	return newReloader(files, minVersion)
+}
+
+func newReloader(files Files, minVersion string) (*Reloader, error) {
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader{files: files, minVersion: version}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ParseVersion переводит версию TLS из конфига в константу crypto/tls.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w %q", ErrUnknownTLSVersion, version)
+	}
+}
+
+// Reload перечитывает файлы. При ошибке продолжают использоваться ранее загруженные сертификаты.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert := &tls.Certificate{}
+	if r.files.CertFile != "" {
+		loaded, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot load certificate: %w", err)
+		}
+		cert = &loaded
+	}
+
+	var caPool *x509.CertPool
+	if r.files.CAFile != "" {
+		data, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("cannot read CA file: %w", err)
+		}
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(data) {
+			return ErrNoCACertificates
+		}
+	}
+
+	r.state.Store(&state{cert: cert, caPool: caPool, modTimes: modTimes})
+	return nil
+}
+
+// Watch раз в period проверяет время изменения файлов и перечитывает их, если они изменились.
+// Работает до отмены ctx.
+func (r *Reloader) Watch(ctx context.Context, logger Logger, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				logger.Error(ctx, err, "failed to check tls certificates")
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger.Error(ctx, err, "failed to reload tls certificates")
+				continue
+			}
+			logger.Info(ctx, "tls certificates reloaded", "cert", r.files.CertFile)
+		}
+	}
+}
+
+// ServerConfig возвращает настройки TLS сервера. Сертификат и CA клиентов берутся
+// из последней загрузки при каждом рукопожатии.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			current := r.state.Load()
+			config := &tls.Config{
+				MinVersion:   r.minVersion,
+				Certificates: []tls.Certificate{*current.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if current.caPool != nil {
+				config.ClientCAs = current.caPool
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig возвращает настройки TLS клиента с сертификатом для mTLS (пустой сертификат, если он не задан).
+// Сертификат сервера проверяется по CA из последней загрузки (или по системным CA, если CAFile не задан).
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.state.Load().cert, nil
+		},
+		// стандартная проверка не видит перезагруженный пул CA, поэтому сертификат проверяется в VerifyConnection
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server did not provide a certificate")
+			}
+			opts := x509.VerifyOptions{
+				Roots:         r.state.Load().caPool,
+				DNSName:       cs.ServerName,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+func (r *Reloader) changed() (bool, error) {
+	modTimes, err := r.modTimes()
+	if err != nil {
+		return false, err
+	}
+	for i, modTime := range r.state.Load().modTimes {
+		if !modTime.Equal(modTimes[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Reloader) modTimes() ([]time.Time, error) {
+	modTimes := make([]time.Time, 0, 3)
+	for _, file := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+// UserID возвращает ID пользователя из проверенного сертификата клиента: им считается CommonName,
+// если это положительное число. Сертификаты сервисов (например, grpc-gateway) с нечисловым CommonName
+// пользователя не задают - передавать его в заголовке X-USER-ID может только доверенный сервис (см. CommonName).
+func UserID(verifiedChains [][]*x509.Certificate) (uint64, bool) {
+	userID, err := strconv.ParseUint(CommonName(verifiedChains), 10, 64)
+	if err != nil || userID == 0 {
+		return 0, false
+	}
+	return userID, true
+}
+
+// CommonName возвращает CommonName проверенного сертификата клиента (пусто, если сертификата нет).
+func CommonName(verifiedChains [][]*x509.Certificate) string {
+	if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+		return ""
+	}
+	return verifiedChains[0][0].Subject.CommonName
+}
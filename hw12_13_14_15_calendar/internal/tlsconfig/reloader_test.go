@@ -0,0 +1,236 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newCA(t)
+	serverFiles := Files{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	clientFiles := Files{
+		CertFile: filepath.Join(dir, "client.crt"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+		CAFile:   serverFiles.CAFile,
+	}
+	writeFile(t, serverFiles.CAFile, ca.certPEM)
+	ca.issue(t, "server-1", serverFiles)
+	ca.issue(t, "12345", clientFiles)
+
+	t.Run("mutual tls handshake", func(t *testing.T) {
+		server, err := New(serverFiles, "1.3")
+		require.NoError(t, err)
+		client, err := NewClient(clientFiles, "1.3")
+		require.NoError(t, err)
+
+		serverState, clientState := handshake(t, server.ServerConfig(), client.ClientConfig("localhost"))
+		require.NoError(t, clientState.err)
+		require.NoError(t, serverState.err)
+		require.Equal(t, uint16(tls.VersionTLS13), clientState.state.Version)
+
+		userID, ok := UserID(serverState.state.VerifiedChains)
+		require.True(t, ok)
+		require.Equal(t, uint64(12345), userID)
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		server, err := New(serverFiles, "")
+		require.NoError(t, err)
+		client, err := NewClient(Files{CAFile: clientFiles.CAFile}, "")
+		require.NoError(t, err)
+
+		serverState, _ := handshake(t, server.ServerConfig(), client.ClientConfig("localhost"))
+		require.Error(t, serverState.err)
+	})
+
+	t.Run("reload certificate", func(t *testing.T) {
+		files := Files{
+			CertFile: filepath.Join(t.TempDir(), "server.crt"),
+			KeyFile:  filepath.Join(t.TempDir(), "server.key"),
+		}
+		ca.issue(t, "server-1", files)
+
+		server, err := New(files, "")
+		require.NoError(t, err)
+		client, err := NewClient(Files{CAFile: serverFiles.CAFile}, "")
+		require.NoError(t, err)
+
+		_, clientState := handshake(t, server.ServerConfig(), client.ClientConfig("localhost"))
+		require.NoError(t, clientState.err)
+		require.Equal(t, "server-1", clientState.state.PeerCertificates[0].Subject.CommonName)
+
+		ca.issue(t, "server-2", files)
+		require.NoError(t, server.Reload())
+
+		_, clientState = handshake(t, server.ServerConfig(), client.ClientConfig("localhost"))
+		require.NoError(t, clientState.err)
+		require.Equal(t, "server-2", clientState.state.PeerCertificates[0].Subject.CommonName)
+	})
+
+	t.Run("keep certificate on failed reload", func(t *testing.T) {
+		files := Files{
+			CertFile: filepath.Join(t.TempDir(), "server.crt"),
+			KeyFile:  filepath.Join(t.TempDir(), "server.key"),
+		}
+		ca.issue(t, "server-1", files)
+
+		server, err := New(files, "")
+		require.NoError(t, err)
+
+		writeFile(t, files.CertFile, []byte("broken"))
+		require.Error(t, server.Reload())
+
+		client, err := NewClient(Files{CAFile: serverFiles.CAFile}, "")
+		require.NoError(t, err)
+		_, clientState := handshake(t, server.ServerConfig(), client.ClientConfig("localhost"))
+		require.NoError(t, clientState.err)
+	})
+
+	t.Run("not valid config", func(t *testing.T) {
+		_, err := New(Files{}, "")
+		require.ErrorIs(t, err, ErrNoCertificate)
+
+		_, err = NewClient(Files{CertFile: clientFiles.CertFile}, "")
+		require.ErrorIs(t, err, ErrNoCertificate)
+
+		_, err = New(serverFiles, "1.1")
+		require.ErrorIs(t, err, ErrUnknownTLSVersion)
+
+		_, err = New(Files{CertFile: serverFiles.CertFile, KeyFile: serverFiles.KeyFile, CAFile: serverFiles.CertFile + ".missing"}, "")
+		require.Error(t, err)
+	})
+}
+
+func TestUserID(t *testing.T) {
+	t.Parallel()
+
+	chain := func(commonName string) [][]*x509.Certificate {
+		return [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}}
+	}
+
+	userID, ok := UserID(chain("12345"))
+	require.True(t, ok)
+	require.Equal(t, uint64(12345), userID)
+
+	for _, chain := range [][][]*x509.Certificate{nil, chain("calendar-gateway"), chain("0"), chain("")} {
+		_, ok := UserID(chain)
+		require.False(t, ok)
+	}
+
+	require.Equal(t, "calendar-gateway", CommonName(chain("calendar-gateway")))
+	require.Equal(t, "", CommonName(nil))
+}
+
+type handshakeResult struct {
+	state tls.ConnectionState
+	err   error
+}
+
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (handshakeResult, handshakeResult) {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		serverConn.Close()
+		clientConn.Close()
+	})
+
+	done := make(chan handshakeResult)
+	go func() {
+		conn := tls.Server(serverConn, serverConfig)
+		err := conn.Handshake()
+		if err != nil {
+			// клиент ждёт ответа сервера; без закрытия соединения рукопожатие клиента зависнет
+			serverConn.Close()
+		}
+		done <- handshakeResult{state: conn.ConnectionState(), err: err}
+	}()
+
+	conn := tls.Client(clientConn, clientConfig)
+	clientErr := conn.Handshake()
+	if clientErr == nil {
+		// в TLS 1.3 ошибку проверки сертификата клиента сервер возвращает после завершения рукопожатия клиента
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _ = conn.Read(make([]byte, 1))
+	} else {
+		clientConn.Close()
+	}
+	return <-done, handshakeResult{state: conn.ConnectionState(), err: clientErr}
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// выпускает сертификат для localhost, годный и для сервера, и для клиента.
+func (ca *testCA) issue(t *testing.T, commonName string, files Files) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writeFile(t, files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
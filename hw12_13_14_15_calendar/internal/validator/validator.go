@@ -0,0 +1,220 @@
+// Package validator - валидатор структур по тэгу validate (подход из hw09_struct_validator).
+// В отличие от hw09 поддерживаются int64 и time.Duration, float, time.Time и сравнение с другим полем,
+// а в ошибках поле называется по json-тэгу (или mapstructure-тэгу для конфигурации),
+// чтобы имя совпадало с именем в API или в файле конфигурации.
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var ErrNotStruct = errors.New("not a struct")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	errs := make([]string, len(v))
+	for i, e := range v {
+		errs[i] = e.Error()
+	}
+	return strings.Join(errs, "\n")
+}
+
+// Add добавляет ошибку поля, если err не nil; используется для проверок, которые не выражаются тэгами.
+func (v *ValidationErrors) Add(field string, err error) {
+	if err != nil {
+		*v = append(*v, ValidationError{Field: field, Err: err})
+	}
+}
+
+// Unwrap позволяет проверять ошибки отдельных полей через errors.Is.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("field: %s, error: %s", v.Field, v.Err.Error())
+}
+
+func (v ValidationError) Unwrap() error {
+	return v.Err
+}
+
+func Validate(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("not valid interface type %v: error %w", val.Kind().String(), ErrNotStruct)
+	}
+	return validateStruct(val, "")
+}
+
+// Правило nested проверяет вложенную структуру, указатель на неё (nil не проверяется) или срез структур;
+// имена полей в ошибках - через точку от имени родителя, у элементов среза - с индексом: attachments.0.url.
+func validateStruct(val reflect.Value, prefix string) error {
+	var validationErrors ValidationErrors
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		fieldVal := val.Field(i)
+		var err error
+		if tag == "nested" {
+			err = validateNested(fieldVal, prefix+fieldName(field))
+		} else {
+			err = validateField(val, prefix+fieldName(field), fieldVal, tag)
+		}
+
+		if err = processError(&validationErrors, err); err != nil {
+			return err
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func validateNested(val reflect.Value, name string) error {
+	switch {
+	case val.Kind() == reflect.Struct:
+		return validateStruct(val, name+".")
+	case val.Kind() == reflect.Pointer && val.Type().Elem().Kind() == reflect.Struct:
+		if val.IsNil() {
+			return nil
+		}
+		return validateStruct(val.Elem(), name+".")
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Struct:
+		var validationErrors ValidationErrors
+		for i := 0; i < val.Len(); i++ {
+			err := validateStruct(val.Index(i), fmt.Sprintf("%s.%d.", name, i))
+			if err = processError(&validationErrors, err); err != nil {
+				return err
+			}
+		}
+		if len(validationErrors) > 0 {
+			return validationErrors
+		}
+		return nil
+	default:
+		return fmt.Errorf("nested rule for %s of kind %v: %w", name, val.Kind(), ErrNotStruct)
+	}
+}
+
+// имя поля из json-тэга, затем из mapstructure-тэга, а без них - имя поля структуры.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "mapstructure"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Указатель на значение проверяется по тем же правилам, что и само значение; nil (поле не задано) не проверяется.
+func validateField(parent reflect.Value, fieldName string, value reflect.Value, tag string) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	var validationErrors ValidationErrors
+	rules := strings.Split(tag, "|")
+	for _, rule := range rules {
+		switch {
+		case value.Type() == timeType:
+			err := validateTime(parent, fieldName, value.Interface().(time.Time), rule)
+			if err = processError(&validationErrors, err); err != nil {
+				return err
+			}
+		case value.Kind() == reflect.String:
+			err := validateString(fieldName, value.String(), rule)
+			if err = processError(&validationErrors, err); err != nil {
+				return err
+			}
+		case value.CanInt():
+			err := validateInt(fieldName, value.Int(), rule)
+			if err = processError(&validationErrors, err); err != nil {
+				return err
+			}
+		case value.CanFloat():
+			err := validateFloat(fieldName, value.Float(), rule)
+			if err = processError(&validationErrors, err); err != nil {
+				return err
+			}
+		case value.Kind() == reflect.Slice:
+			for i := 0; i < value.Len(); i++ {
+				elem := value.Index(i)
+				switch {
+				case elem.Kind() == reflect.String:
+					err := validateString(fmt.Sprintf("%s index=%d", fieldName, i), elem.String(), rule)
+					if err = processError(&validationErrors, err); err != nil {
+						return err
+					}
+				case elem.CanInt():
+					err := validateInt(fmt.Sprintf("%s index=%d", fieldName, i), elem.Int(), rule)
+					if err = processError(&validationErrors, err); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// Проверка типа ошибки и её обработка
+// Если ошибка err имеет тип ValidationError или ValidationErrors,
+//
+//	то она добавляется в validationErrors, а возвращаемое значение - null
+//
+// Иначе, возвращаемое значение == ошибке err (т.е. это программная ошибка).
+func processError(validationErrors *ValidationErrors, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// ValidationErrors проверяется первым: через Unwrap в нём нашлась бы только первая ValidationError
+	var fieldValidationErrors ValidationErrors
+	if errors.As(err, &fieldValidationErrors) { // ошибки валидации
+		*validationErrors = append(*validationErrors, fieldValidationErrors...)
+		return nil
+	}
+
+	var fieldValidationError ValidationError
+	if errors.As(err, &fieldValidationError) { // ошибка валидации
+		*validationErrors = append(*validationErrors, fieldValidationError)
+		return nil
+	}
+
+	// программная ошибка
+	return err
+}
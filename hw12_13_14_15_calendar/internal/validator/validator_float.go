@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	reMinFloat = regexp.MustCompile(`^min:(.+)$`)
+	reMaxFloat = regexp.MustCompile(`^max:(.+)$`)
+)
+
+var (
+	ErrFloatMin         = errors.New("the number is less than min")
+	ErrFloatMax         = errors.New("the number is more than max")
+	ErrFloatInvalidRule = errors.New("invalid float rule")
+)
+
+func validateFloat(fieldName string, value float64, rule string) error {
+	if len(rule) < 1 {
+		return nil
+	}
+
+	minRuleMatch := reMinFloat.FindStringSubmatch(rule)
+	if len(minRuleMatch) > 1 {
+		number, err := strconv.ParseFloat(minRuleMatch[1], 64)
+		if err != nil {
+			return fmt.Errorf("%w: failed converting min rule %s for %s: %w", ErrFloatInvalidRule, rule, fieldName, err)
+		}
+		if value < number {
+			return ValidationError{Field: fieldName, Err: ErrFloatMin}
+		}
+		return nil
+	}
+
+	maxRuleMatch := reMaxFloat.FindStringSubmatch(rule)
+	if len(maxRuleMatch) > 1 {
+		number, err := strconv.ParseFloat(maxRuleMatch[1], 64)
+		if err != nil {
+			return fmt.Errorf("%w: failed converting max rule %s for %s: %w", ErrFloatInvalidRule, rule, fieldName, err)
+		}
+		if value > number {
+			return ValidationError{Field: fieldName, Err: ErrFloatMax}
+		}
+		return nil
+	}
+
+	return ErrFloatInvalidRule
+}
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	reLen    = regexp.MustCompile(`^len:(\d+)$`)
+	reMinLen = regexp.MustCompile(`^minlen:(\d+)$`)
+	reMaxLen = regexp.MustCompile(`^maxlen:(\d+)$`)
+	reExpr   = regexp.MustCompile(`^regexp:(.+)$`)
+	reIn     = regexp.MustCompile(`^in:(.+)$`)
+)
+
+var (
+	ErrStringIncorrectLen   = errors.New("incorrect len of the string")
+	ErrStringTooShort       = errors.New("the string is too short")
+	ErrStringTooLong        = errors.New("the string is too long")
+	ErrStringNotMatchRegexp = errors.New("the string does not match regexp")
+	ErrStringNotInValues    = errors.New("the string is not in the values")
+	ErrStringRequired       = errors.New("the string is required")
+	ErrStringNotURI         = errors.New("the string is not a valid uri")
+	ErrStringInvalidRule    = errors.New("invalid string rule")
+)
+
+// Длина в правилах minlen и maxlen считается в символах, как у varchar в БД.
+// Правило uri проверяет только непустые строки (обязательность задаётся правилом required).
+func validateString(fieldName string, value string, rule string) error {
+	if len(rule) < 1 {
+		return nil
+	}
+
+	switch rule {
+	case "required":
+		if value == "" {
+			return ValidationError{Field: fieldName, Err: ErrStringRequired}
+		}
+		return nil
+	case "uri":
+		if value == "" {
+			return nil
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return ValidationError{Field: fieldName, Err: ErrStringNotURI}
+		}
+		return nil
+	}
+
+	lenRuleMatch := reLen.FindStringSubmatch(rule)
+	if len(lenRuleMatch) > 1 {
+		number, err := strconv.Atoi(lenRuleMatch[1])
+		if err != nil {
+			return fmt.Errorf("%w: failed converting len rule %s for %s: %w", ErrStringInvalidRule, rule, fieldName, err)
+		}
+		if len(value) != number {
+			return ValidationError{Field: fieldName, Err: ErrStringIncorrectLen}
+		}
+		return nil
+	}
+
+	minLenRuleMatch := reMinLen.FindStringSubmatch(rule)
+	if len(minLenRuleMatch) > 1 {
+		number, err := strconv.Atoi(minLenRuleMatch[1])
+		if err != nil {
+			return fmt.Errorf("%w: failed converting minlen rule %s for %s: %w", ErrStringInvalidRule, rule, fieldName, err)
+		}
+		if utf8.RuneCountInString(value) < number {
+			return ValidationError{Field: fieldName, Err: ErrStringTooShort}
+		}
+		return nil
+	}
+
+	maxLenRuleMatch := reMaxLen.FindStringSubmatch(rule)
+	if len(maxLenRuleMatch) > 1 {
+		number, err := strconv.Atoi(maxLenRuleMatch[1])
+		if err != nil {
+			return fmt.Errorf("%w: failed converting maxlen rule %s for %s: %w", ErrStringInvalidRule, rule, fieldName, err)
+		}
+		if utf8.RuneCountInString(value) > number {
+			return ValidationError{Field: fieldName, Err: ErrStringTooLong}
+		}
+		return nil
+	}
+
+	exprRuleMatch := reExpr.FindStringSubmatch(rule)
+	if len(exprRuleMatch) > 1 {
+		expr, err := regexp.Compile(exprRuleMatch[1])
+		if err != nil {
+			return fmt.Errorf("%w: failed converting regexp rule %s for %s: %w", ErrStringInvalidRule, rule, fieldName, err)
+		}
+		if !expr.MatchString(value) {
+			return ValidationError{Field: fieldName, Err: ErrStringNotMatchRegexp}
+		}
+		return nil
+	}
+
+	inRuleMatch := reIn.FindStringSubmatch(rule)
+	if len(inRuleMatch) > 1 {
+		possibleValues := strings.Split(inRuleMatch[1], ",")
+		matched := false
+		for _, possibleValue := range possibleValues {
+			if value == possibleValue {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return ValidationError{Field: fieldName, Err: ErrStringNotInValues}
+		}
+		return nil
+	}
+
+	return ErrStringInvalidRule
+}
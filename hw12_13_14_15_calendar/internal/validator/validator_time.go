@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+var reAfter = regexp.MustCompile(`^after:(\w+)$`)
+
+var (
+	ErrTimeRequired    = errors.New("the time is required")
+	ErrTimeNotAfter    = errors.New("the time is not after the other field")
+	ErrTimeInvalidRule = errors.New("invalid time rule")
+)
+
+// Правило after:Field сравнивает значение с другим полем той же структуры (по имени поля в Go).
+func validateTime(parent reflect.Value, fieldName string, value time.Time, rule string) error {
+	if len(rule) < 1 {
+		return nil
+	}
+
+	if rule == "required" {
+		if value.IsZero() {
+			return ValidationError{Field: fieldName, Err: ErrTimeRequired}
+		}
+		return nil
+	}
+
+	afterRuleMatch := reAfter.FindStringSubmatch(rule)
+	if len(afterRuleMatch) > 1 {
+		other := parent.FieldByName(afterRuleMatch[1])
+		if !other.IsValid() || other.Type() != timeType {
+			return fmt.Errorf("%w: field %s of after rule for %s is not a time", ErrTimeInvalidRule, afterRuleMatch[1], fieldName)
+		}
+		if !value.After(other.Interface().(time.Time)) {
+			return ValidationError{Field: fieldName, Err: ErrTimeNotAfter}
+		}
+		return nil
+	}
+
+	return ErrTimeInvalidRule
+}
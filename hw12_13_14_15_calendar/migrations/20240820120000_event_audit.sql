@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists event_audit (
+    audit_id        bigserial,
+    event_id        bigint not null,
+    user_id         bigint not null,
+    actor_id        bigint not null,
+    action          varchar(16) not null,
+    changes         jsonb not null default '[]',
+    source          varchar(32),
+    created_at      timestamptz not null default now(),
+	constraint event_audit_pk primary key (audit_id)
+);
+CREATE INDEX event_audit_event_id_idx ON event_audit (event_id, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists event_audit;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD transparent boolean NOT NULL DEFAULT false;
+create table if not exists user_settings (
+    user_id         bigint,
+    overlap_policy  varchar(16) not null default 'REJECT',
+	constraint user_settings_pk primary key (user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists user_settings;
+ALTER TABLE events DROP COLUMN transparent;
+-- +goose StatementEnd
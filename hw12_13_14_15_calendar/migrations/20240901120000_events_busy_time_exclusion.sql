@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE events ADD during tstzrange GENERATED ALWAYS AS (tstzrange(start_date, end_date, '[]')) STORED;
+
+-- событие занимает время эксклюзивно, если оно непрозрачное и пользователь запрещает пересечения
+ALTER TABLE events ADD exclusive boolean NOT NULL DEFAULT false;
+UPDATE events e
+SET exclusive = NOT e.transparent
+    AND COALESCE((SELECT s.overlap_policy FROM user_settings s WHERE s.user_id = e.user_id), 'REJECT') = 'REJECT';
+
+-- уже пересекающиеся эксклюзивные события не дали бы создать ограничение:
+-- эксклюзивным остаётся самое раннее из них, остальные попадают в лог БД
+DO $$
+DECLARE
+    e record;
+    current_user_id bigint;
+    busy_until timestamptz;
+BEGIN
+    FOR e IN
+        SELECT event_id, user_id, start_date, end_date FROM events
+        WHERE exclusive AND deleted_at IS NULL
+        ORDER BY user_id, start_date, event_id
+    LOOP
+        IF current_user_id IS DISTINCT FROM e.user_id THEN
+            current_user_id := e.user_id;
+            busy_until := NULL;
+        END IF;
+        IF e.start_date <= busy_until THEN
+            UPDATE events SET exclusive = false WHERE event_id = e.event_id;
+            RAISE WARNING 'event % of user % overlaps an earlier event and is no longer exclusive',
+                e.event_id, e.user_id;
+        ELSE
+            busy_until := GREATEST(busy_until, e.end_date);
+        END IF;
+    END LOOP;
+END $$;
+
+ALTER TABLE events ADD CONSTRAINT events_busy_time_excl
+    EXCLUDE USING gist (user_id WITH =, during WITH &&)
+    WHERE (exclusive AND deleted_at IS NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_busy_time_excl;
+ALTER TABLE events DROP COLUMN exclusive;
+ALTER TABLE events DROP COLUMN during;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists idempotency_keys (
+    user_id         bigint not null,
+    idempotency_key varchar(255) not null,
+    event_id        bigint not null,
+    created_at      timestamptz not null default now(),
+	constraint idempotency_keys_pk primary key (user_id, idempotency_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists idempotency_keys;
+-- +goose StatementEnd
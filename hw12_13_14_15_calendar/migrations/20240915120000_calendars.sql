@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists calendars (
+    calendar_id     bigserial,
+    user_id         bigint not null,
+    name            varchar(256) not null,
+    color           varchar(16) not null default '',
+    notify_before   interval,
+    is_default      boolean not null default false,
+	constraint calendars_pk primary key (calendar_id)
+);
+CREATE INDEX calendars_user_id_idx ON calendars (user_id);
+-- у пользователя ровно один календарь по умолчанию
+CREATE UNIQUE INDEX calendars_default_idx ON calendars (user_id) WHERE is_default;
+
+-- существующие события переносятся в календари по умолчанию их пользователей
+INSERT INTO calendars (user_id, name, is_default)
+SELECT DISTINCT user_id, 'Default', true FROM events WHERE user_id IS NOT NULL;
+
+ALTER TABLE events ADD calendar_id bigint;
+UPDATE events e
+SET calendar_id = c.calendar_id
+FROM calendars c
+WHERE c.user_id = e.user_id AND c.is_default;
+
+-- календарь с событиями (в том числе в корзине) удалить нельзя
+ALTER TABLE events ADD CONSTRAINT events_calendar_fk
+    FOREIGN KEY (calendar_id) REFERENCES calendars (calendar_id) ON DELETE RESTRICT;
+CREATE INDEX events_calendar_id_idx ON events (calendar_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_calendar_fk;
+ALTER TABLE events DROP COLUMN calendar_id;
+drop table if exists calendars;
+-- +goose StatementEnd
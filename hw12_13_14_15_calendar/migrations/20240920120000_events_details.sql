@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD location varchar(512) not null default '';
+ALTER TABLE events ADD latitude double precision;
+ALTER TABLE events ADD longitude double precision;
+ALTER TABLE events ADD category varchar(64) not null default '';
+ALTER TABLE events ADD tags jsonb not null default '[]';
+ALTER TABLE events ADD attachments jsonb not null default '[]';
+-- координаты задаются парой
+ALTER TABLE events ADD CONSTRAINT events_geo_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+CREATE INDEX events_category_idx ON events (user_id, category);
+CREATE INDEX events_tags_idx ON events USING gin (tags jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_tags_idx;
+DROP INDEX IF EXISTS events_category_idx;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_geo_check;
+ALTER TABLE events DROP COLUMN attachments;
+ALTER TABLE events DROP COLUMN tags;
+ALTER TABLE events DROP COLUMN category;
+ALTER TABLE events DROP COLUMN longitude;
+ALTER TABLE events DROP COLUMN latitude;
+ALTER TABLE events DROP COLUMN location;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists resources (
+    resource_id     bigserial,
+    name            varchar(256) not null,
+    kind            varchar(64) not null default '',
+    capacity        int not null default 0,
+    attributes      jsonb not null default '{}',
+	constraint resources_pk primary key (resource_id),
+	constraint resources_capacity_check check (capacity >= 0)
+);
+CREATE INDEX resources_kind_idx ON resources (kind);
+
+-- бронь ресурса событием; время брони дублирует время события, чтобы пересечения отсекались ограничением
+create table if not exists event_resources (
+    event_id        bigint not null,
+    resource_id     bigint not null,
+    start_date      timestamptz not null,
+    end_date        timestamptz not null,
+    during          tstzrange GENERATED ALWAYS AS (tstzrange(start_date, end_date, '[]')) STORED,
+    -- бронь события в корзине не активна
+    active          boolean not null default true,
+	constraint event_resources_pk primary key (event_id, resource_id),
+	constraint event_resources_event_fk foreign key (event_id) references events (event_id) on delete cascade,
+	-- ресурс с бронями (в том числе событий в корзине) удалить нельзя
+	constraint event_resources_resource_fk foreign key (resource_id) references resources (resource_id) on delete restrict
+);
+
+-- ресурс бронируется эксклюзивно независимо от прозрачности события и политики пользователя
+ALTER TABLE event_resources ADD CONSTRAINT event_resources_busy_time_excl
+    EXCLUDE USING gist (resource_id WITH =, during WITH &&)
+    WHERE (active);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists event_resources;
+drop table if exists resources;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings ADD time_zone varchar(64) not null default '';
+ALTER TABLE user_settings ADD working_hours jsonb not null default '[]';
+ALTER TABLE user_settings ADD out_of_office jsonb not null default '[]';
+ALTER TABLE user_settings ADD availability_policy varchar(16) not null default 'ALLOW';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings DROP COLUMN availability_policy;
+ALTER TABLE user_settings DROP COLUMN out_of_office;
+ALTER TABLE user_settings DROP COLUMN working_hours;
+ALTER TABLE user_settings DROP COLUMN time_zone;
+-- +goose StatementEnd
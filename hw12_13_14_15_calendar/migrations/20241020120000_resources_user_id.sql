@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- создатель ресурса: только он может менять и удалять ресурс; у ресурсов, созданных раньше, создателя нет (0)
+ALTER TABLE resources ADD user_id bigint not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE resources DROP COLUMN user_id;
+-- +goose StatementEnd
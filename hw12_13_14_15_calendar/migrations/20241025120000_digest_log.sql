@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- начало последнего периода, за который пользователю отправлена сводка; общий для всех экземпляров планировщика
+create table if not exists digest_log (
+    user_id    bigint not null,
+    start_date timestamptz not null,
+	constraint digest_log_pk primary key (user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists digest_log;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+// Package migrations содержит миграции БД, встраиваемые в бинарный файл календаря.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// Postgres возвращает миграции для PostgreSQL.
+func Postgres() fs.FS {
+	return postgres
+}
+
+// SQLite возвращает миграции для SQLite.
+func SQLite() fs.FS {
+	sub, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
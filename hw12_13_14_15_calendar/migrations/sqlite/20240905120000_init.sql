@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- даты хранятся в виде unix-времени в наносекундах, notify_before - в наносекундах
+create table if not exists events (
+    event_id        integer primary key autoincrement,
+    title           varchar(256) not null,
+    start_date      integer not null,
+    end_date        integer not null,
+    description     text,
+    user_id         integer,
+    notify_before   integer,
+    notify_status   integer not null default 0,
+    transparent     boolean not null default false,
+    deleted_at      integer
+);
+CREATE INDEX events_user_id_idx ON events (user_id, start_date);
+CREATE INDEX events_deleted_at_idx ON events (deleted_at) WHERE deleted_at IS NOT NULL;
+
+create table if not exists event_audit (
+    audit_id        integer primary key autoincrement,
+    event_id        integer not null,
+    user_id         integer not null,
+    actor_id        integer not null,
+    action          varchar(16) not null,
+    changes         text not null default '[]',
+    source          varchar(32),
+    created_at      integer not null
+);
+CREATE INDEX event_audit_event_id_idx ON event_audit (event_id, user_id);
+
+create table if not exists user_settings (
+    user_id         integer primary key,
+    overlap_policy  varchar(16) not null default 'REJECT'
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists user_settings;
+drop table if exists event_audit;
+drop table if exists events;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- created_at хранится в виде unix-времени в наносекундах
+create table if not exists idempotency_keys (
+    user_id         integer not null,
+    idempotency_key varchar(255) not null,
+    event_id        integer not null,
+    created_at      integer not null,
+    primary key (user_id, idempotency_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists idempotency_keys;
+-- +goose StatementEnd
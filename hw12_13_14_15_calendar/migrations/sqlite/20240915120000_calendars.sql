@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- notify_before хранится в наносекундах
+create table if not exists calendars (
+    calendar_id     integer primary key autoincrement,
+    user_id         integer not null,
+    name            varchar(256) not null,
+    color           varchar(16) not null default '',
+    notify_before   integer not null default 0,
+    is_default      boolean not null default false
+);
+CREATE INDEX calendars_user_id_idx ON calendars (user_id);
+-- у пользователя ровно один календарь по умолчанию
+CREATE UNIQUE INDEX calendars_default_idx ON calendars (user_id) WHERE is_default;
+
+-- существующие события переносятся в календари по умолчанию их пользователей
+INSERT INTO calendars (user_id, name, is_default)
+SELECT DISTINCT user_id, 'Default', true FROM events WHERE user_id IS NOT NULL;
+
+ALTER TABLE events ADD calendar_id integer;
+UPDATE events
+SET calendar_id = (SELECT c.calendar_id FROM calendars c WHERE c.user_id = events.user_id AND c.is_default);
+CREATE INDEX events_calendar_id_idx ON events (calendar_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_calendar_id_idx;
+ALTER TABLE events DROP COLUMN calendar_id;
+drop table if exists calendars;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- tags и attachments хранятся в виде json
+ALTER TABLE events ADD location varchar(512) not null default '';
+ALTER TABLE events ADD latitude real;
+ALTER TABLE events ADD longitude real;
+ALTER TABLE events ADD category varchar(64) not null default '';
+ALTER TABLE events ADD tags text not null default '[]';
+ALTER TABLE events ADD attachments text not null default '[]';
+CREATE INDEX events_category_idx ON events (user_id, category);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_category_idx;
+ALTER TABLE events DROP COLUMN attachments;
+ALTER TABLE events DROP COLUMN tags;
+ALTER TABLE events DROP COLUMN category;
+ALTER TABLE events DROP COLUMN longitude;
+ALTER TABLE events DROP COLUMN latitude;
+ALTER TABLE events DROP COLUMN location;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- attributes хранится как JSON-объект
+create table if not exists resources (
+    resource_id     integer primary key autoincrement,
+    name            varchar(256) not null,
+    kind            varchar(64) not null default '',
+    capacity        integer not null default 0,
+    attributes      text not null default '{}'
+);
+CREATE INDEX resources_kind_idx ON resources (kind);
+
+-- бронь ресурса событием; пересечения броней проверяются в приложении
+create table if not exists event_resources (
+    event_id        integer not null,
+    resource_id     integer not null,
+    start_date      integer not null,
+    end_date        integer not null,
+    active          boolean not null default true,
+    primary key (event_id, resource_id)
+);
+CREATE INDEX event_resources_resource_id_idx ON event_resources (resource_id, start_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists event_resources;
+drop table if exists resources;
+-- +goose StatementEnd
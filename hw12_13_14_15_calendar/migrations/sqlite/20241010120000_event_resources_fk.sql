@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite не умеет добавлять ограничения в существующую таблицу, поэтому таблица пересоздаётся;
+-- брони уже удалённых событий не переносятся
+create table event_resources_fk (
+    event_id        integer not null references events (event_id) on delete cascade,
+    resource_id     integer not null references resources (resource_id) on delete restrict,
+    start_date      integer not null,
+    end_date        integer not null,
+    active          boolean not null default true,
+    primary key (event_id, resource_id)
+);
+INSERT INTO event_resources_fk (event_id, resource_id, start_date, end_date, active)
+SELECT event_id, resource_id, start_date, end_date, active
+FROM event_resources
+WHERE event_id IN (SELECT event_id FROM events);
+drop table event_resources;
+ALTER TABLE event_resources_fk RENAME TO event_resources;
+CREATE INDEX event_resources_resource_id_idx ON event_resources (resource_id, start_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create table event_resources_nofk (
+    event_id        integer not null,
+    resource_id     integer not null,
+    start_date      integer not null,
+    end_date        integer not null,
+    active          boolean not null default true,
+    primary key (event_id, resource_id)
+);
+INSERT INTO event_resources_nofk (event_id, resource_id, start_date, end_date, active)
+SELECT event_id, resource_id, start_date, end_date, active
+FROM event_resources;
+drop table event_resources;
+ALTER TABLE event_resources_nofk RENAME TO event_resources;
+CREATE INDEX event_resources_resource_id_idx ON event_resources (resource_id, start_date);
+-- +goose StatementEnd
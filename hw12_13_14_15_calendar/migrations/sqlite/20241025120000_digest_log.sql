@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- начало последнего периода, за который пользователю отправлена сводка;
+-- start_date хранится в виде unix-времени в наносекундах
+create table if not exists digest_log (
+    user_id    integer not null primary key,
+    start_date integer not null
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists digest_log;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- пользователи, которым календарь открыт на чтение; доступ удаляется вместе с календарём
+create table if not exists calendar_shares (
+    calendar_id     integer not null references calendars (calendar_id) on delete cascade,
+    user_id         integer not null,
+    primary key (calendar_id, user_id)
+);
+CREATE INDEX calendar_shares_user_id_idx ON calendar_shares (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists calendar_shares;
+-- +goose StatementEnd
@@ -0,0 +1,133 @@
+// Package client - клиент API календаря по HTTP и gRPC.
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Client повторяет методы приложения календаря для работы с событиями пользователя.
+// Реализации: HTTPClient, GRPCClient и Fake для тестов.
+type Client interface {
+	Create(ctx context.Context, event Event) (uint64, []*Event, error)
+	GetByID(ctx context.Context, userID uint64, eventID uint64) (*Event, error)
+	Update(ctx context.Context, event Event) ([]*Event, error)
+	Delete(ctx context.Context, userID uint64, eventID uint64) error
+	ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*Event, error)
+	ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error)
+	ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error)
+}
+
+// Event - событие. NotifyBefore == nil при создании - напоминание календаря,
+// при обновлении - прежнее напоминание; нулевое NotifyBefore - событие без напоминания.
+type Event struct {
+	ID           uint64         `json:"id"`
+	Title        string         `json:"title"`
+	StartDate    time.Time      `json:"startDate"`
+	EndDate      time.Time      `json:"endDate"`
+	Description  string         `json:"description"`
+	UserID       uint64         `json:"userId"`
+	CalendarID   uint64         `json:"calendarId"`
+	NotifyBefore *time.Duration `json:"notifyBefore,omitempty"`
+	Transparent  bool           `json:"transparent"`
+	DeletedAt    *time.Time     `json:"deletedAt,omitempty"`
+	Location     *Location      `json:"location,omitempty"`
+	Category     string         `json:"category"`
+	Tags         []string       `json:"tags,omitempty"`
+	Attachments  []Attachment   `json:"attachments,omitempty"`
+	ResourceIDs  []uint64       `json:"resourceIds,omitempty"`
+}
+
+type Location struct {
+	Text string    `json:"text"`
+	Geo  *GeoPoint `json:"geo,omitempty"`
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type Attachment struct {
+	URL      string `json:"url"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey задаёт ключ идемпотентности для Create. Сервер не создаёт событие повторно
+// по тому же ключу, поэтому такой запрос на создание повторяется при временных ошибках.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+// Config - настройки клиента.
+type Config struct {
+	Timeout      time.Duration // таймаут одной попытки; 0 - без таймаута
+	Retries      int           // количество повторов после неудачной попытки
+	RetryBackoff time.Duration // пауза перед первым повтором, далее удваивается
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:      5 * time.Second,
+		Retries:      2,
+		RetryBackoff: 100 * time.Millisecond,
+	}
+}
+
+// retryPolicy возвращает, можно ли повторить запрос после ошибки, и паузу перед повтором,
+// которую попросил сервер (0 - не указана).
+type retryPolicy func(err error) (bool, time.Duration)
+
+// выполняет запрос с повторами при временных ошибках.
+// Неидемпотентные запросы (создание события без ключа идемпотентности) не повторяются,
+// т.к. сервер мог успеть их выполнить.
+func (c Config) do(ctx context.Context, idempotent bool, policy retryPolicy, call func(ctx context.Context) error) error {
+	backoff := c.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, call)
+		if err == nil || !idempotent || attempt >= c.Retries || ctx.Err() != nil {
+			return err
+		}
+		retryable, delay := policy(err)
+		if !retryable {
+			return err
+		}
+
+		wait := backoff
+		if delay > 0 {
+			wait = delay
+		}
+		// не ждём повтора, который всё равно не успеет выполниться
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (c Config) attempt(ctx context.Context, call func(ctx context.Context) error) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	return call(ctx)
+}
@@ -0,0 +1,380 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/app"
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/logger"
+	internalgrpc "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc"
+	internalhttp "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/http"
+	memorystorage "github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Одни и те же сценарии проверяются на настоящих серверах и на Fake,
+// чтобы Fake вёл себя так же, как API.
+func TestClients(t *testing.T) {
+	t.Parallel()
+
+	logg, err := logger.New("ERROR")
+	require.NoError(t, err)
+	calendar := app.New(logg, memorystorage.New())
+
+	httpAddr := freeAddr(t)
+	httpServer := internalhttp.NewServer(logg, calendar, httpAddr, time.Second)
+	go httpServer.Start(context.Background())
+	t.Cleanup(func() { httpServer.Stop(context.Background()) })
+
+	grpcAddr := freeAddr(t)
+	_, grpcPort, err := net.SplitHostPort(grpcAddr)
+	require.NoError(t, err)
+	grpcServer := internalgrpc.NewServer(logg, calendar, grpcPort)
+	go grpcServer.Start(context.Background())
+	t.Cleanup(func() { grpcServer.Stop(context.Background()) })
+
+	waitListening(t, httpAddr)
+	waitListening(t, grpcAddr)
+
+	grpcClient, err := NewGRPCClient(grpcAddr, DefaultConfig())
+	require.NoError(t, err)
+	t.Cleanup(func() { grpcClient.Close() })
+
+	clients := map[string]struct {
+		client Client
+		userID uint64
+	}{
+		"http": {client: NewHTTPClient("http://"+httpAddr, DefaultConfig()), userID: 1},
+		"grpc": {client: grpcClient, userID: 2},
+		"fake": {client: NewFake(), userID: 3},
+	}
+
+	for name, tc := range clients {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			testClient(t, tc.client, tc.userID)
+		})
+	}
+}
+
+func testClient(t *testing.T, client Client, userID uint64) {
+	t.Helper()
+	ctx := context.Background()
+
+	event := Event{
+		Title:        "my event",
+		StartDate:    getTime(t, "2024-07-06 10:00:00"),
+		EndDate:      getTime(t, "2024-07-06 12:00:00"),
+		Description:  "my event description",
+		UserID:       userID,
+		NotifyBefore: ptr(time.Hour),
+		Location:     &Location{Text: "Office", Geo: &GeoPoint{Lat: 55.75, Lon: 37.62}},
+		Category:     "work",
+		Tags:         []string{"urgent"},
+		Attachments:  []Attachment{{URL: "https://example.com/a.pdf", Name: "a.pdf", Size: 1024, MimeType: "application/pdf"}},
+	}
+
+	eventID, conflicts, err := client.Create(ctx, event)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+	require.NotZero(t, eventID)
+	event.ID = eventID
+
+	actual, err := client.GetByID(ctx, userID, eventID)
+	require.NoError(t, err)
+	requireEvent(t, &event, actual)
+
+	_, err = client.GetByID(ctx, userID+100, eventID)
+	require.ErrorIs(t, err, ErrEventNotFound)
+
+	overlapping := event
+	overlapping.StartDate = getTime(t, "2024-07-06 11:00:00")
+	overlapping.EndDate = getTime(t, "2024-07-06 13:00:00")
+	_, _, err = client.Create(ctx, overlapping)
+	var busyTimeErr *BusyTimeError
+	require.ErrorAs(t, err, &busyTimeErr)
+	require.ErrorIs(t, err, ErrBusyTime)
+	require.Len(t, busyTimeErr.Conflicts, 1)
+	requireEvent(t, &event, busyTimeErr.Conflicts[0])
+
+	event.Title = "my updated event"
+	event.EndDate = getTime(t, "2024-07-08 12:00:00")
+	conflicts, err = client.Update(ctx, event)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+
+	events, err := client.ListForDay(ctx, userID, getTime(t, "2024-07-07 00:00:00"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	requireEvent(t, &event, events[0])
+
+	events, err = client.ListForWeek(ctx, userID, getTime(t, "2024-07-01 00:00:00"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	events, err = client.ListForMonth(ctx, userID, getTime(t, "2024-08-01 00:00:00"))
+	require.NoError(t, err)
+	require.NotNil(t, events)
+	require.Empty(t, events)
+
+	require.NoError(t, client.Delete(ctx, userID, eventID))
+	require.ErrorIs(t, client.Delete(ctx, userID, eventID), ErrEventNotFound)
+
+	_, err = client.Update(ctx, event)
+	require.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	t.Parallel()
+
+	config := Config{Timeout: time.Second, Retries: 2, RetryBackoff: time.Millisecond}
+
+	t.Run("retry server errors of idempotent requests", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"events":[]}`))
+		}))
+		defer server.Close()
+
+		events, err := NewHTTPClient(server.URL, config).ListForDay(context.Background(), 1, time.Now())
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("stop after retries", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPClient(server.URL, config).GetByID(context.Background(), 1, 1)
+		require.Error(t, err)
+		require.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("do not retry create", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, _, err := NewHTTPClient(server.URL, config).Create(context.Background(), Event{UserID: 1})
+		require.Error(t, err)
+		require.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("retry create with idempotency key", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "my-key", r.Header.Get(idempotencyKeyHeader))
+			if attempts.Add(1) < 2 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"eventId":1000}`))
+		}))
+		defer server.Close()
+
+		ctx := WithIdempotencyKey(context.Background(), "my-key")
+		eventID, _, err := NewHTTPClient(server.URL, config).Create(ctx, Event{UserID: 1})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1000), eventID)
+		require.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("do not retry client errors", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.Header().Set("Content-Type", problemContentType)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"detail":"userID is not valid","code":"INVALID_ARGUMENT",` +
+				`"fields":[{"field":"header.X-USER-ID","message":"userID is not valid"}]}`))
+		}))
+		defer server.Close()
+
+		err := NewHTTPClient(server.URL, config).Delete(context.Background(), 1, 1)
+		require.ErrorIs(t, err, ErrInvalidArgument)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, []FieldViolation{{Field: "header.X-USER-ID", Message: "userID is not valid"}}, apiErr.FieldViolations)
+		require.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("retry rate limited after retry-after", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 2 {
+				w.Header().Set("Content-Type", problemContentType)
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"status":429,"detail":"too many requests","code":"RATE_LIMITED"}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"events":[]}`))
+		}))
+		defer server.Close()
+
+		start := time.Now()
+		_, err := NewHTTPClient(server.URL, config).ListForDay(context.Background(), 1, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, int32(2), attempts.Load())
+		require.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("do not retry exceeded quota", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.Header().Set("Content-Type", problemContentType)
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":429,"detail":"events quota exceeded","code":"QUOTA_EXCEEDED"}`))
+		}))
+		defer server.Close()
+
+		ctx := WithIdempotencyKey(context.Background(), "my-key")
+		_, _, err := NewHTTPClient(server.URL, config).Create(ctx, Event{UserID: 1})
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		require.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("timeout of attempt", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		config := config
+		config.Timeout = 10 * time.Millisecond
+		_, err := NewHTTPClient(server.URL, config).GetByID(context.Background(), 1, 1)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Equal(t, int32(3), attempts.Load())
+	})
+}
+
+func TestGRPCRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	withDetails := func(t *testing.T, code codes.Code, details ...protoadapt.MessageV1) error {
+		t.Helper()
+		st, err := status.New(code, "error").WithDetails(details...)
+		require.NoError(t, err)
+		return st.Err()
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		expectedRetry bool
+		expectedDelay time.Duration
+	}{
+		{name: "unavailable", err: status.Error(codes.Unavailable, "unavailable"), expectedRetry: true},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "timeout"), expectedRetry: true},
+		{
+			name: "rate limited",
+			err: withDetails(t, codes.ResourceExhausted,
+				&errdetails.ErrorInfo{Domain: errorDomain, Reason: CodeRateLimited},
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)}),
+			expectedRetry: true,
+			expectedDelay: 2 * time.Second,
+		},
+		{
+			name:          "quota exceeded",
+			err:           withDetails(t, codes.ResourceExhausted, &errdetails.ErrorInfo{Domain: errorDomain, Reason: CodeQuotaExceeded}),
+			expectedRetry: false,
+		},
+		{name: "resource exhausted without reason", err: status.Error(codes.ResourceExhausted, "too large")},
+		{name: "not found", err: status.Error(codes.NotFound, "not found")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, delay := grpcRetryPolicy(tt.err)
+			require.Equal(t, tt.expectedRetry, retry)
+			require.Equal(t, tt.expectedDelay, delay)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, time.Duration(0), parseRetryAfter(""))
+	require.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	require.Equal(t, time.Duration(0), parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+	delay := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	require.InDelta(t, time.Minute, delay, float64(2*time.Second))
+}
+
+func requireEvent(t *testing.T, expected *Event, actual *Event) {
+	t.Helper()
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Title, actual.Title)
+	require.True(t, expected.StartDate.Equal(actual.StartDate))
+	require.True(t, expected.EndDate.Equal(actual.EndDate))
+	require.Equal(t, expected.Description, actual.Description)
+	require.Equal(t, expected.UserID, actual.UserID)
+	require.Equal(t, expected.NotifyBefore, actual.NotifyBefore)
+	require.Equal(t, expected.Transparent, actual.Transparent)
+	require.Equal(t, expected.Location, actual.Location)
+	require.Equal(t, expected.Category, actual.Category)
+	require.Equal(t, expected.Tags, actual.Tags)
+	require.Equal(t, expected.Attachments, actual.Attachments)
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lsn.Close()
+	return "127.0.0.1:" + strconv.Itoa(lsn.Addr().(*net.TCPAddr).Port)
+}
+
+func waitListening(t *testing.T, addr string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func getTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	time, err := time.Parse(time.DateTime, value)
+	require.NoError(t, err)
+	return time
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrEventNotFound   = errors.New("event not found")
+	ErrBusyTime        = errors.New("time is busy by another event")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrRateLimited     = errors.New("too many requests")
+	ErrQuotaExceeded   = errors.New("events quota exceeded")
+	// ErrFailedPrecondition - операция невозможна в текущем состоянии, например удаление непустого календаря.
+	ErrFailedPrecondition = errors.New("failed precondition")
+)
+
+// BusyTimeError содержит события, с которыми пересекается добавляемое событие.
+type BusyTimeError struct {
+	Conflicts []*Event
+}
+
+func (e *BusyTimeError) Error() string {
+	return ErrBusyTime.Error()
+}
+
+func (e *BusyTimeError) Unwrap() error {
+	return ErrBusyTime
+}
+
+// Коды ошибок из каталога ошибок API.
+const (
+	CodeInvalidArgument    = "INVALID_ARGUMENT"
+	CodeNotFound           = "NOT_FOUND"
+	CodeBusyTime           = "BUSY_TIME"
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeQuotaExceeded      = "QUOTA_EXCEEDED"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeInternal           = "INTERNAL"
+)
+
+// errorDomain - домен ошибок календаря в google.rpc.ErrorInfo.
+const errorDomain = "calendar"
+
+// FieldViolation - ошибка отдельного поля запроса.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError - ошибка, которую вернул сервер: код из каталога, сообщение и ошибки полей.
+// Через errors.Is сопоставляется по коду с ErrEventNotFound, ErrInvalidArgument, ErrRateLimited, ErrQuotaExceeded и ErrFailedPrecondition.
+type APIError struct {
+	Code            string
+	Message         string
+	FieldViolations []FieldViolation
+	// RetryAfter - через сколько сервер предлагает повторить запрос (для RATE_LIMITED); 0 - не указано.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case CodeNotFound:
+		return ErrEventNotFound
+	case CodeInvalidArgument:
+		return ErrInvalidArgument
+	case CodeBusyTime:
+		return ErrBusyTime
+	case CodeRateLimited:
+		return ErrRateLimited
+	case CodeQuotaExceeded:
+		return ErrQuotaExceeded
+	case CodeFailedPrecondition:
+		return ErrFailedPrecondition
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake - клиент, хранящий события в памяти, для тестов потребителей API.
+// Повторяет поведение сервера с политикой пересечений по умолчанию: пересекающиеся
+// непрозрачные события одного пользователя отклоняются с BusyTimeError.
+type Fake struct {
+	mu     sync.Mutex
+	events map[uint64]*Event
+	lastID uint64
+}
+
+var _ Client = (*Fake)(nil)
+
+func NewFake() *Fake {
+	return &Fake{
+		events: make(map[uint64]*Event),
+	}
+}
+
+func (f *Fake) Create(_ context.Context, event Event) (uint64, []*Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event.ID = 0
+	if err := f.checkBusyTime(&event); err != nil {
+		return 0, nil, err
+	}
+
+	f.lastID++
+	event.ID = f.lastID
+	event.DeletedAt = nil
+	f.events[event.ID] = &event
+	return event.ID, nil, nil
+}
+
+func (f *Fake) GetByID(_ context.Context, userID uint64, eventID uint64) (*Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event, exists := f.events[eventID]
+	if !exists || event.UserID != userID {
+		return nil, ErrEventNotFound
+	}
+	return copyEvent(event), nil
+}
+
+func (f *Fake) Update(_ context.Context, event Event) ([]*Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, exists := f.events[event.ID]
+	if !exists || existing.UserID != event.UserID {
+		return nil, ErrEventNotFound
+	}
+	if err := f.checkBusyTime(&event); err != nil {
+		return nil, err
+	}
+
+	event.DeletedAt = nil
+	f.events[event.ID] = &event
+	return nil, nil
+}
+
+func (f *Fake) Delete(_ context.Context, userID uint64, eventID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event, exists := f.events[eventID]
+	if !exists || event.UserID != userID {
+		return ErrEventNotFound
+	}
+	delete(f.events, eventID)
+	return nil
+}
+
+func (f *Fake) ListForDay(_ context.Context, userID uint64, date time.Time) ([]*Event, error) {
+	return f.listForPeriod(userID, date, date.Add(24*time.Hour)), nil
+}
+
+func (f *Fake) ListForWeek(_ context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return f.listForPeriod(userID, startDate, startDate.AddDate(0, 0, 7)), nil
+}
+
+func (f *Fake) ListForMonth(_ context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return f.listForPeriod(userID, startDate, startDate.AddDate(0, 1, 0)), nil
+}
+
+func (f *Fake) listForPeriod(userID uint64, startDate time.Time, endDateExclusive time.Time) []*Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := make([]*Event, 0)
+	for _, event := range f.events {
+		if event.UserID == userID && event.StartDate.Before(endDateExclusive) && !event.EndDate.Before(startDate) {
+			events = append(events, copyEvent(event))
+		}
+	}
+	sortEvents(events)
+	return events
+}
+
+func (f *Fake) checkBusyTime(event *Event) error {
+	if event.Transparent {
+		return nil
+	}
+
+	conflicts := make([]*Event, 0)
+	for _, existing := range f.events {
+		if existing.UserID == event.UserID && existing.ID != event.ID && !existing.Transparent &&
+			!event.StartDate.After(existing.EndDate) && !event.EndDate.Before(existing.StartDate) {
+			conflicts = append(conflicts, copyEvent(existing))
+		}
+	}
+	if len(conflicts) > 0 {
+		sortEvents(conflicts)
+		return &BusyTimeError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+func sortEvents(events []*Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].StartDate.Equal(events[j].StartDate) {
+			return events[i].StartDate.Before(events[j].StartDate)
+		}
+		if !events[i].EndDate.Equal(events[j].EndDate) {
+			return events[i].EndDate.Before(events[j].EndDate)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+func copyEvent(event *Event) *Event {
+	eventCopy := *event
+	return &eventCopy
+}
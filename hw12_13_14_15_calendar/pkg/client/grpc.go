@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olga-larina/otus-golang/hw12_13_14_15_calendar/internal/server/grpc/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCClient - клиент gRPC API календаря.
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	client pb.EventServiceClient
+	config Config
+}
+
+var _ Client = (*GRPCClient)(nil)
+
+// NewGRPCClient создаёт клиент; target - адрес сервера, например localhost:8081.
+// По умолчанию соединение без TLS, это можно изменить через opts.
+func NewGRPCClient(target string, config Config, opts ...grpc.DialOption) (*GRPCClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create grpc connection: %w", err)
+	}
+	return &GRPCClient{
+		conn:   conn,
+		client: pb.NewEventServiceClient(conn),
+		config: config,
+	}, nil
+}
+
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCClient) Create(ctx context.Context, event Event) (uint64, []*Event, error) {
+	var resp *pb.CreateEventResponse
+	idempotencyKey := idempotencyKeyFromContext(ctx)
+	if idempotencyKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(idempotencyKeyHeader), idempotencyKey)
+	}
+	err := c.do(ctx, idempotencyKey != "", event.UserID, func(ctx context.Context) (err error) {
+		resp, err = c.client.CreateEvent(ctx, &pb.CreateEventRequest{Event: repackEventToProto(&event)})
+		return
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.Id, repackEventsFromProto(resp.Conflicts, event.UserID), nil
+}
+
+func (c *GRPCClient) GetByID(ctx context.Context, userID uint64, eventID uint64) (*Event, error) {
+	var resp *pb.Event
+	err := c.do(ctx, true, userID, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetEvent(ctx, &pb.GetEventRequest{Id: eventID})
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repackEventFromProto(resp, userID), nil
+}
+
+func (c *GRPCClient) Update(ctx context.Context, event Event) ([]*Event, error) {
+	var resp *pb.UpdateEventResponse
+	err := c.do(ctx, true, event.UserID, func(ctx context.Context) (err error) {
+		resp, err = c.client.UpdateEvent(ctx, &pb.UpdateEventRequest{Event: repackEventToProto(&event)})
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repackEventsFromProto(resp.Conflicts, event.UserID), nil
+}
+
+func (c *GRPCClient) Delete(ctx context.Context, userID uint64, eventID uint64) error {
+	return c.do(ctx, true, userID, func(ctx context.Context) error {
+		_, err := c.client.DeleteEvent(ctx, &pb.DeleteEventRequest{Id: eventID})
+		return err
+	})
+}
+
+func (c *GRPCClient) ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, date, c.client.EventListForDay)
+}
+
+func (c *GRPCClient) ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, startDate, c.client.EventListForWeek)
+}
+
+func (c *GRPCClient) ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, startDate, c.client.EventListForMonth)
+}
+
+type listMethod func(ctx context.Context, in *pb.EventListRequest, opts ...grpc.CallOption) (*pb.EventList, error)
+
+func (c *GRPCClient) listForPeriod(ctx context.Context, userID uint64, startDate time.Time, method listMethod) ([]*Event, error) {
+	var resp *pb.EventList
+	err := c.do(ctx, true, userID, func(ctx context.Context) (err error) {
+		resp, err = method(ctx, &pb.EventListRequest{StartDate: timestamppb.New(startDate)})
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	// пустой список, как и в HTTP API, - не nil
+	events := repackEventsFromProto(resp.Events, userID)
+	if events == nil {
+		events = make([]*Event, 0)
+	}
+	return events, nil
+}
+
+func (c *GRPCClient) do(ctx context.Context, idempotent bool, userID uint64, call func(ctx context.Context) error) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, userIDHeader, strconv.FormatUint(userID, 10))
+	err := c.config.do(ctx, idempotent, grpcRetryPolicy, call)
+	return convertGRPCError(err, userID)
+}
+
+func convertGRPCError(err error, userID uint64) error {
+	st, ok := status.FromError(err)
+	if err == nil || !ok {
+		return err
+	}
+
+	apiErr := &APIError{Message: st.Message()}
+	var conflicts []*Event
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetDomain() == errorDomain {
+				apiErr.Code = detail.GetReason()
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				apiErr.FieldViolations = append(apiErr.FieldViolations, FieldViolation{
+					Field:   violation.GetField(),
+					Message: violation.GetDescription(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			apiErr.RetryAfter = detail.GetRetryDelay().AsDuration()
+		case *pb.EventList:
+			conflicts = repackEventsFromProto(detail.Events, userID)
+		}
+	}
+
+	// без ErrorInfo (ошибки транспорта или старый сервер) возвращается исходная ошибка gRPC
+	if apiErr.Code == "" {
+		if st.Code() == codes.InvalidArgument {
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+		}
+		return err
+	}
+	if apiErr.Code == CodeBusyTime {
+		return &BusyTimeError{Conflicts: conflicts}
+	}
+	return apiErr
+}
+
+// повторяются ошибки недоступности сервера, превышения таймаута попытки и ограничения частоты запросов
+// (с паузой из google.rpc.RetryInfo); превышение квоты событий повтором не исправить.
+func grpcRetryPolicy(err error) (bool, time.Duration) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true, 0
+	case codes.ResourceExhausted:
+		var (
+			rateLimited bool
+			delay       time.Duration
+		)
+		for _, detail := range st.Details() {
+			switch detail := detail.(type) {
+			case *errdetails.ErrorInfo:
+				rateLimited = detail.GetDomain() == errorDomain && detail.GetReason() == CodeRateLimited
+			case *errdetails.RetryInfo:
+				delay = detail.GetRetryDelay().AsDuration()
+			}
+		}
+		return rateLimited, delay
+	default:
+		return false, 0
+	}
+}
+
+func repackEventToProto(in *Event) *pb.Event {
+	event := &pb.Event{
+		Id:          in.ID,
+		Title:       in.Title,
+		StartDate:   timestamppb.New(in.StartDate),
+		EndDate:     timestamppb.New(in.EndDate),
+		Description: in.Description,
+		Transparent: in.Transparent,
+		CalendarId:  in.CalendarID,
+		Category:    in.Category,
+		Tags:        in.Tags,
+		ResourceIds: in.ResourceIDs,
+	}
+	if in.NotifyBefore != nil {
+		event.NotifyBefore = durationpb.New(*in.NotifyBefore)
+	}
+	if in.Location != nil {
+		event.Location = &pb.Location{Text: in.Location.Text}
+		if in.Location.Geo != nil {
+			event.Location.Geo = &pb.GeoPoint{Lat: in.Location.Geo.Lat, Lon: in.Location.Geo.Lon}
+		}
+	}
+	for _, attachment := range in.Attachments {
+		event.Attachments = append(event.Attachments, &pb.Attachment{
+			Url:      attachment.URL,
+			Name:     attachment.Name,
+			Size:     attachment.Size,
+			MimeType: attachment.MimeType,
+		})
+	}
+	return event
+}
+
+// gRPC API не возвращает пользователя события, поэтому он берётся из запроса.
+func repackEventFromProto(in *pb.Event, userID uint64) *Event {
+	event := &Event{
+		ID:          in.Id,
+		Title:       in.Title,
+		StartDate:   in.StartDate.AsTime(),
+		EndDate:     in.EndDate.AsTime(),
+		Description: in.Description,
+		UserID:      userID,
+		CalendarID:  in.CalendarId,
+		Transparent: in.Transparent,
+		Category:    in.Category,
+		Tags:        in.Tags,
+		ResourceIDs: in.ResourceIds,
+	}
+	if in.NotifyBefore != nil {
+		notifyBefore := in.NotifyBefore.AsDuration()
+		event.NotifyBefore = &notifyBefore
+	}
+	if in.DeletedAt != nil {
+		deletedAt := in.DeletedAt.AsTime()
+		event.DeletedAt = &deletedAt
+	}
+	if in.Location != nil {
+		event.Location = &Location{Text: in.Location.Text}
+		if in.Location.Geo != nil {
+			event.Location.Geo = &GeoPoint{Lat: in.Location.Geo.Lat, Lon: in.Location.Geo.Lon}
+		}
+	}
+	for _, attachment := range in.Attachments {
+		event.Attachments = append(event.Attachments, Attachment{
+			URL:      attachment.Url,
+			Name:     attachment.Name,
+			Size:     attachment.Size,
+			MimeType: attachment.MimeType,
+		})
+	}
+	return event
+}
+
+func repackEventsFromProto(in []*pb.Event, userID uint64) []*Event {
+	if len(in) == 0 {
+		return nil
+	}
+	events := make([]*Event, 0, len(in))
+	for _, event := range in {
+		events = append(events, repackEventFromProto(event, userID))
+	}
+	return events
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	userIDHeader         = "X-USER-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	problemContentType   = "application/problem+json"
+)
+
+// HTTPClient - клиент HTTP API календаря.
+type HTTPClient struct {
+	baseURL    string
+	config     Config
+	httpClient *http.Client
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewHTTPClient создаёт клиент; baseURL - адрес сервера, например http://localhost:8080.
+func NewHTTPClient(baseURL string, config Config) *HTTPClient {
+	return &HTTPClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		config:     config,
+		httpClient: &http.Client{},
+	}
+}
+
+// WithTLS задаёт настройки TLS, например корневые CA сервера и сертификат клиента для mTLS.
+func (c *HTTPClient) WithTLS(config *tls.Config) *HTTPClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	c.httpClient = &http.Client{Transport: transport}
+	return c
+}
+
+type createEventRequest struct {
+	Event *Event `json:"event"`
+}
+
+type createEventResponse struct {
+	EventID   uint64   `json:"eventId"`
+	Conflicts []*Event `json:"conflicts,omitempty"`
+}
+
+type updateEventRequest struct {
+	Event *Event `json:"event"`
+}
+
+type updateEventResponse struct {
+	Conflicts []*Event `json:"conflicts,omitempty"`
+}
+
+type eventResponse struct {
+	Event *Event `json:"event"`
+}
+
+type eventsResponse struct {
+	Events []*Event `json:"events"`
+}
+
+// ошибка в формате application/problem+json.
+type problemResponse struct {
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail"`
+	Code      string           `json:"code"`
+	Fields    []FieldViolation `json:"fields,omitempty"`
+	Conflicts []*Event         `json:"conflicts,omitempty"`
+}
+
+// httpStatusError - ответ сервера с кодом 5xx/429 или без тела problem+json.
+type httpStatusError struct {
+	statusCode int
+	message    string
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.statusCode, e.message)
+}
+
+func (e *httpStatusError) Unwrap() error {
+	return e.err
+}
+
+func (c *HTTPClient) Create(ctx context.Context, event Event) (uint64, []*Event, error) {
+	var resp createEventResponse
+	err := c.do(ctx, http.MethodPost, "/events", nil, event.UserID, createEventRequest{Event: &event}, &resp)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.EventID, resp.Conflicts, nil
+}
+
+func (c *HTTPClient) GetByID(ctx context.Context, userID uint64, eventID uint64) (*Event, error) {
+	var resp eventResponse
+	if err := c.do(ctx, http.MethodGet, eventPath(eventID), nil, userID, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Event, nil
+}
+
+func (c *HTTPClient) Update(ctx context.Context, event Event) ([]*Event, error) {
+	var resp updateEventResponse
+	err := c.do(ctx, http.MethodPut, eventPath(event.ID), nil, event.UserID, updateEventRequest{Event: &event}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Conflicts, nil
+}
+
+func (c *HTTPClient) Delete(ctx context.Context, userID uint64, eventID uint64) error {
+	return c.do(ctx, http.MethodDelete, eventPath(eventID), nil, userID, nil, nil)
+}
+
+// ListForDay возвращает события за день; HTTP API учитывает только дату, без времени.
+func (c *HTTPClient) ListForDay(ctx context.Context, userID uint64, date time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, date, "day")
+}
+
+func (c *HTTPClient) ListForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, startDate, "week")
+}
+
+func (c *HTTPClient) ListForMonth(ctx context.Context, userID uint64, startDate time.Time) ([]*Event, error) {
+	return c.listForPeriod(ctx, userID, startDate, "month")
+}
+
+func (c *HTTPClient) listForPeriod(ctx context.Context, userID uint64, startDate time.Time, period string) ([]*Event, error) {
+	query := url.Values{}
+	query.Set("startDate", startDate.Format(time.DateOnly))
+	query.Set("period", period)
+
+	var resp eventsResponse
+	if err := c.do(ctx, http.MethodGet, "/events", query, userID, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+func (c *HTTPClient) do(ctx context.Context, method string, path string, query url.Values, userID uint64, body any, out any) error {
+	var reqData []byte
+	if body != nil {
+		var err error
+		if reqData, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed encoding request: %w", err)
+		}
+	}
+
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	idempotencyKey := idempotencyKeyFromContext(ctx)
+	idempotent := method != http.MethodPost || idempotencyKey != ""
+	return c.config.do(ctx, idempotent, httpRetryPolicy, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(reqData))
+		if err != nil {
+			return err
+		}
+		req.Header.Set(userIDHeader, strconv.FormatUint(userID, 10))
+		if idempotencyKey != "" {
+			req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		respData, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed reading response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return convertHTTPError(resp, respData)
+		}
+		if out == nil || len(respData) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(respData, out); err != nil {
+			return fmt.Errorf("failed parsing response: %w", err)
+		}
+		return nil
+	})
+}
+
+func convertHTTPError(resp *http.Response, respData []byte) error {
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	var problem problemResponse
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), problemContentType) || json.Unmarshal(respData, &problem) != nil {
+		message := strings.TrimSpace(string(respData))
+		if resp.StatusCode == http.StatusBadRequest {
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, message)
+		}
+		return &httpStatusError{statusCode: resp.StatusCode, message: message, retryAfter: retryAfter}
+	}
+
+	var err error = &APIError{Code: problem.Code, Message: problem.Detail, FieldViolations: problem.Fields, RetryAfter: retryAfter}
+	if problem.Code == CodeBusyTime {
+		err = &BusyTimeError{Conflicts: problem.Conflicts}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return &httpStatusError{statusCode: resp.StatusCode, message: problem.Detail, retryAfter: retryAfter, err: err}
+	}
+	return err
+}
+
+// переводит заголовок Retry-After (секунды или HTTP-дата) в паузу; 0 - заголовка нет или он некорректен.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// повторяются сетевые ошибки, ошибки сервера и ограничение частоты запросов (с паузой из Retry-After);
+// превышение квоты событий повтором не исправить.
+func httpRetryPolicy(err error) (bool, time.Duration) {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.statusCode == http.StatusTooManyRequests {
+			return errors.Is(err, ErrRateLimited), statusErr.retryAfter
+		}
+		return statusErr.statusCode >= http.StatusInternalServerError, statusErr.retryAfter
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr), 0
+}
+
+func eventPath(eventID uint64) string {
+	return "/events/" + strconv.FormatUint(eventID, 10)
+}